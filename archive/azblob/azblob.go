@@ -0,0 +1,50 @@
+// Package azblob reads archived event exports from an Azure Blob storage
+// container, for archive.Consumer. It is a separate module, so that only the
+// users of the adapter depend on the azure sdk.
+package azblob
+
+import (
+	"context"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/datatrails/go-datatrails-simplehash/archive"
+)
+
+// Container is an archive.Bucket over a blob container
+type Container struct {
+	client *container.Client
+}
+
+var _ archive.Bucket = (*Container)(nil)
+
+// NewContainer returns the container read with client
+func NewContainer(client *container.Client) *Container {
+	return &Container{client: client}
+}
+
+// List returns the names of the blobs whose names begin with prefix, paging
+// through the listing
+func (c *Container) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	p := c.client.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{Prefix: &prefix})
+	for p.More() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Segment.BlobItems {
+			keys = append(keys, *item.Name)
+		}
+	}
+	return keys, nil
+}
+
+// Open returns a reader streaming the content of the blob
+func (c *Container) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := c.client.NewBlobClient(key).DownloadStream(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.Body, nil
+}
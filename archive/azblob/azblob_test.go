@@ -0,0 +1,88 @@
+package azblob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlobServer serves the list blobs and get blob operations of a single
+// container, listing one blob per page
+func newBlobServer(t *testing.T, names []string, blobs map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("comp") == "list" {
+			var matched []string
+			for _, name := range names {
+				if strings.HasPrefix(name, q.Get("prefix")) {
+					matched = append(matched, name)
+				}
+			}
+			start := 0
+			for i, name := range matched {
+				if name == q.Get("marker") {
+					start = i
+				}
+			}
+			next := ""
+			if start+1 < len(matched) {
+				next = matched[start+1]
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>`+
+				`<EnumerationResults><Blobs><Blob><Name>%s</Name><Properties/></Blob></Blobs>`+
+				`<NextMarker>%s</NextMarker></EnumerationResults>`, matched[start], next)
+			return
+		}
+		content, ok := blobs[strings.TrimPrefix(r.URL.Path, "/exports/")]
+		if !ok {
+			w.Header().Set("x-ms-error-code", string(bloberror.BlobNotFound))
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		_, err := io.WriteString(w, content)
+		assert.NoError(t, err)
+	}))
+}
+
+// TestContainer tests:
+//
+// 1. List pages through every blob with the prefix.
+// 2. Open streams the content of a blob, and fails for a missing blob.
+func TestContainer(t *testing.T) {
+	server := newBlobServer(t,
+		[]string{"2024/01.jsonl", "2024/02.jsonl", "2025/01.jsonl"},
+		map[string]string{"2024/01.jsonl": "a\n", "2024/02.jsonl": "b\n", "2025/01.jsonl": "c\n"})
+	defer server.Close()
+
+	client, err := container.NewClientWithNoCredential(server.URL+"/exports", nil)
+	require.NoError(t, err)
+	c := NewContainer(client)
+
+	keys, err := c.List(context.Background(), "2024/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024/01.jsonl", "2024/02.jsonl"}, keys)
+
+	r, err := c.Open(context.Background(), "2024/02.jsonl")
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", string(content))
+	require.NoError(t, r.Close())
+
+	_, err = c.Open(context.Background(), "2024/03.jsonl")
+	var responseErr *azcore.ResponseError
+	require.ErrorAs(t, err, &responseErr)
+	assert.True(t, bloberror.HasCode(err, bloberror.BlobNotFound))
+}
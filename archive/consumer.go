@@ -0,0 +1,234 @@
+// Package archive feeds archived event exports, held as objects in S3, Azure
+// Blob storage or similar, into a simplehash.StreamHasher. Each object is
+// streamed into the hasher as it is read, so cold archives can be verified
+// without downloading them first. It mirrors packages kafka and eventhub.
+//
+// The objects are read from a Bucket. Adapters for S3 and Azure Blob storage
+// are nested modules, archive/s3 and archive/azblob, so that a storage sdk is
+// only a dependency of the applications which read from that storage:
+//
+//	consumer := archive.NewConsumer(stream, archive.FormatJSONLines)
+//	err := consumer.Consume(ctx, s3.NewBucket(s3Client, "exports"), "2024/")
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+const (
+	keyMetadata   = "archive/key"
+	indexMetadata = "archive/index"
+)
+
+var (
+	ErrInvalidObject = errors.New("invalid archive object")
+)
+
+// Format is the encoding of the archived objects
+type Format int
+
+const (
+	// FormatJSONLines objects hold one api formatted event per line. This is
+	// the default.
+	FormatJSONLines Format = iota
+	// FormatListEvents objects hold pages of the list events api,
+	// {"events": [...], "next_page_token": "..."}
+	FormatListEvents
+)
+
+// Bucket lists and reads the archived objects
+type Bucket interface {
+	// List returns the keys of the objects whose keys begin with prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Open returns a reader of the content of the object
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// Consumer accumulates the events of archived objects. The key of the object,
+// and the index within it, of the last event accumulated are saved with the
+// hash state in the stream checkpoints, so that after a restart Consume
+// resumes where it stopped.
+type Consumer struct {
+	stream *simplehash.StreamHasher
+	format Format
+}
+
+// NewConsumer creates a consumer feeding stream with objects in format
+func NewConsumer(stream *simplehash.StreamHasher, format Format) *Consumer {
+	return &Consumer{stream: stream, format: format}
+}
+
+// StartAfter returns the key of the object, and the index of the event within
+// it, of the last event accumulated, recovered from the stream checkpoint. ok
+// is false if no object has been consumed before.
+func (c *Consumer) StartAfter() (key string, index int, ok bool, err error) {
+	metadata := c.stream.Metadata()
+	key, ok = metadata[keyMetadata]
+	if !ok {
+		return "", 0, false, nil
+	}
+	index, err = strconv.Atoi(metadata[indexMetadata])
+	if err != nil {
+		return "", 0, false, fmt.Errorf("checkpointed index %q: %w", metadata[indexMetadata], err)
+	}
+	return key, index, true, nil
+}
+
+// Consume accumulates the objects whose keys begin with prefix, in key order,
+// so that the digest does not depend on the listing order of the bucket.
+// Events already accumulated, according to StartAfter, are skipped. A
+// checkpoint is saved when Consume returns.
+func (c *Consumer) Consume(ctx context.Context, b Bucket, prefix string) error {
+	keys, err := b.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	sort.Strings(keys)
+
+	lastKey, lastIndex, resumed, err := c.StartAfter()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		skip := -1
+		if resumed {
+			if key < lastKey {
+				continue
+			}
+			if key == lastKey {
+				skip = lastIndex
+			}
+		}
+		if err = c.consumeObject(ctx, b, key, skip); err != nil {
+			return errors.Join(err, c.stream.Checkpoint())
+		}
+	}
+	return c.stream.Checkpoint()
+}
+
+func (c *Consumer) consumeObject(ctx context.Context, b Bucket, key string, skip int) error {
+	r, err := b.Open(ctx, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	defer r.Close()
+	if err = c.handleObject(ctx, key, r, skip); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// HandleObject accumulates the events of a single object, read from r
+func (c *Consumer) HandleObject(ctx context.Context, key string, r io.Reader) error {
+	return c.handleObject(ctx, key, r, -1)
+}
+
+// handleObject accumulates the events of the object after the skip'th
+func (c *Consumer) handleObject(ctx context.Context, key string, r io.Reader, skip int) error {
+	index := -1
+	add := func(eventJson []byte) error {
+		index++
+		if index <= skip {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return c.stream.Add(eventJson, map[string]string{
+			keyMetadata:   key,
+			indexMetadata: strconv.Itoa(index),
+		})
+	}
+
+	switch c.format {
+	case FormatListEvents:
+		return eachListedEvent(r, add)
+	default:
+		return eachLine(r, add)
+	}
+}
+
+// eachLine calls fn with each line of r which is not blank
+func eachLine(r io.Reader, fn func([]byte) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) != 0 {
+			if ferr := fn(line); ferr != nil {
+				return ferr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// eachListedEvent calls fn with each event of a list events page, decoding
+// the page incrementally
+func eachListedEvent(r io.Reader, fn func([]byte) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidObject, err)
+		}
+		if token != "events" {
+			var skipped json.RawMessage
+			if err = dec.Decode(&skipped); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidObject, err)
+			}
+			continue
+		}
+		if err = expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			var eventJson json.RawMessage
+			if err = dec.Decode(&eventJson); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidObject, err)
+			}
+			if err = fn(eventJson); err != nil {
+				return err
+			}
+		}
+		if err = expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("%w: data after the page", ErrInvalidObject)
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidObject, err)
+	}
+	if token != delim {
+		return fmt.Errorf("%w: expected %v, found %v", ErrInvalidObject, delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+// memBucket holds objects in memory. Opening a key in unavailable fails.
+type memBucket struct {
+	objects     map[string][]byte
+	unavailable map[string]bool
+}
+
+func (b *memBucket) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (b *memBucket) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	if b.unavailable[key] {
+		return nil, errUnavailable
+	}
+	return io.NopCloser(bytes.NewReader(b.objects[key])), nil
+}
+
+func testEvents(t *testing.T, n int) [][]byte {
+	var events [][]byte
+	for i := 1; i <= n; i++ {
+		eventJson, err := simplehash.NewEventMarshaler().Marshal(&v2assets.EventResponse{
+			Identity:      fmt.Sprintf("assets/1/events/%d", i),
+			AssetIdentity: "assets/1",
+			Operation:     "Record",
+		})
+		require.NoError(t, err)
+		events = append(events, eventJson)
+	}
+	return events
+}
+
+func jsonLines(events ...[]byte) []byte {
+	return append(bytes.Join(events, []byte("\n")), '\n')
+}
+
+func listEvents(t *testing.T, events ...[]byte) []byte {
+	raw := make([]json.RawMessage, 0, len(events))
+	for _, e := range events {
+		raw = append(raw, e)
+	}
+	b, err := json.Marshal(map[string]any{"next_page_token": "x", "events": raw})
+	require.NoError(t, err)
+	return b
+}
+
+// TestConsumer tests:
+//
+// 1. json lines and list events objects accumulate, in key order, to the
+// same digest as hashing the events directly.
+// 2. objects outside the prefix are ignored.
+func TestConsumer(t *testing.T) {
+	events := testEvents(t, 5)
+	h := simplehash.NewHasherV3()
+	expected, err := h.HashEventsFromJSON(events)
+	require.NoError(t, err)
+
+	buckets := map[Format]*memBucket{
+		FormatJSONLines: {objects: map[string][]byte{
+			"export/2": jsonLines(events[3:]...),
+			"export/1": jsonLines(events[:3]...),
+			"other/1":  jsonLines(events[0]),
+		}},
+		FormatListEvents: {objects: map[string][]byte{
+			"export/2": listEvents(t, events[3:]...),
+			"export/1": listEvents(t, events[:3]...),
+			"other/1":  listEvents(t, events[0]),
+		}},
+	}
+	for format, bucket := range buckets {
+		t.Run(fmt.Sprint(format), func(t *testing.T) {
+			stream, err := simplehash.NewStreamHasher(nil, "archive", 0)
+			require.NoError(t, err)
+			require.NoError(t, NewConsumer(stream, format).Consume(context.Background(), bucket, "export/"))
+			assert.Equal(t, expected.Digest, stream.Digest())
+			assert.Equal(t, len(events), stream.Count())
+		})
+	}
+}
+
+// TestConsumer_Resume tests:
+//
+// 1. when an object can not be read, the position of the last event is
+// checkpointed.
+// 2. a consumer restored from the checkpoint skips the events already
+// accumulated, and completes the digest.
+// 3. an object which is not a list events page fails with ErrInvalidObject.
+func TestConsumer_Resume(t *testing.T) {
+	events := testEvents(t, 5)
+	h := simplehash.NewHasherV3()
+	expected, err := h.HashEventsFromJSON(events)
+	require.NoError(t, err)
+
+	bucket := &memBucket{
+		objects: map[string][]byte{
+			"export/1": jsonLines(events[:3]...),
+			"export/2": jsonLines(events[3:]...),
+		},
+		unavailable: map[string]bool{"export/2": true},
+	}
+	store, err := simplehash.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	stream, err := simplehash.NewStreamHasher(store, "archive", 0)
+	require.NoError(t, err)
+	err = NewConsumer(stream, FormatJSONLines).Consume(context.Background(), bucket, "export/")
+	assert.ErrorIs(t, err, errUnavailable)
+
+	stream, err = simplehash.NewStreamHasher(store, "archive", 0)
+	require.NoError(t, err)
+	c := NewConsumer(stream, FormatJSONLines)
+	key, index, ok, err := c.StartAfter()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "export/1", key)
+	assert.Equal(t, 2, index)
+
+	bucket.unavailable = nil
+	require.NoError(t, c.Consume(context.Background(), bucket, "export/"))
+	assert.Equal(t, expected.Digest, stream.Digest())
+	assert.Equal(t, len(events), stream.Count())
+
+	stream, err = simplehash.NewStreamHasher(nil, "archive", 0)
+	require.NoError(t, err)
+	err = NewConsumer(stream, FormatListEvents).HandleObject(context.Background(), "export/1", bytes.NewReader(jsonLines(events...)))
+	assert.ErrorIs(t, err, ErrInvalidObject)
+}
@@ -0,0 +1,57 @@
+// Package s3 reads archived event exports from an Amazon S3 bucket, for
+// archive.Consumer. It is a separate module, so that only the users of the
+// adapter depend on the aws sdk.
+package s3
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/datatrails/go-datatrails-simplehash/archive"
+)
+
+// Client is the subset of *s3.Client used by Bucket
+type Client interface {
+	s3.ListObjectsV2APIClient
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Bucket is an archive.Bucket over an S3 bucket
+type Bucket struct {
+	client Client
+	name   string
+}
+
+var _ archive.Bucket = (*Bucket)(nil)
+
+// NewBucket returns the bucket name, read with client
+func NewBucket(client Client, name string) *Bucket {
+	return &Bucket{client: client, name: name}
+}
+
+// List returns the keys of the objects whose keys begin with prefix, paging
+// through the listing
+func (b *Bucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	p := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{Bucket: &b.name, Prefix: &prefix})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range page.Contents {
+			keys = append(keys, *o.Key)
+		}
+	}
+	return keys, nil
+}
+
+// Open returns a reader streaming the content of the object
+func (b *Bucket) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	o, err := b.client.GetObject(ctx, &s3.GetObjectInput{Bucket: &b.name, Key: &key})
+	if err != nil {
+		return nil, err
+	}
+	return o.Body, nil
+}
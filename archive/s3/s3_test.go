@@ -0,0 +1,93 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNoSuchKey = errors.New("no such key")
+
+// fakeClient lists its objects one per page
+type fakeClient struct {
+	bucket  string
+	objects map[string]string
+	keys    []string
+}
+
+func (c *fakeClient) ListObjectsV2(
+	_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options),
+) (*s3.ListObjectsV2Output, error) {
+	if *params.Bucket != c.bucket {
+		return nil, errors.New("no such bucket")
+	}
+	var matched []string
+	for _, key := range c.keys {
+		if strings.HasPrefix(key, *params.Prefix) {
+			matched = append(matched, key)
+		}
+	}
+	start := 0
+	if params.ContinuationToken != nil {
+		for i, key := range matched {
+			if key == *params.ContinuationToken {
+				start = i
+			}
+		}
+	}
+	out := &s3.ListObjectsV2Output{}
+	if start < len(matched) {
+		out.Contents = []types.Object{{Key: aws.String(matched[start])}}
+	}
+	if start+1 < len(matched) {
+		out.IsTruncated = aws.Bool(true)
+		out.NextContinuationToken = aws.String(matched[start+1])
+	}
+	return out, nil
+}
+
+func (c *fakeClient) GetObject(
+	_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options),
+) (*s3.GetObjectOutput, error) {
+	content, ok := c.objects[*params.Key]
+	if !ok {
+		return nil, errNoSuchKey
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(content))}, nil
+}
+
+// TestBucket tests:
+//
+// 1. List pages through every object with the prefix.
+// 2. Open streams the content of an object, and returns the client error for
+// a missing object.
+func TestBucket(t *testing.T) {
+	client := &fakeClient{
+		bucket:  "exports",
+		objects: map[string]string{"2024/01.jsonl": "a\n", "2024/02.jsonl": "b\n", "2025/01.jsonl": "c\n"},
+		keys:    []string{"2024/01.jsonl", "2024/02.jsonl", "2025/01.jsonl"},
+	}
+	b := NewBucket(client, "exports")
+
+	keys, err := b.List(context.Background(), "2024/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024/01.jsonl", "2024/02.jsonl"}, keys)
+
+	r, err := b.Open(context.Background(), "2024/02.jsonl")
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", string(content))
+	require.NoError(t, r.Close())
+
+	_, err = b.Open(context.Background(), "2024/03.jsonl")
+	assert.ErrorIs(t, err, errNoSuchKey)
+}
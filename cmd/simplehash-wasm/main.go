@@ -0,0 +1,81 @@
+//go:build js && wasm
+
+// Command simplehash-wasm exports simple hash v3 hashing to javascript, so
+// that public events can be verified in the browser. Build it without the
+// proto dependencies:
+//
+//	GOOS=js GOARCH=wasm go build -tags simplehash_noproto -o simplehash.wasm ./cmd/simplehash-wasm
+//
+// and load it using the wasm_exec.js support file distributed with go. Once
+// running it defines the global function
+//
+//	hashEventV3(eventJson, options)
+//
+// which returns the hex encoded v3 digest of an api formatted event, or an
+// Error if the event could not be hashed. The options object is optional, and
+// has the fields
+//
+//	useNumber: true hashes integer attribute values exactly, without it events
+//	           with numeric attribute values are rejected
+//	prefix:    hex encoded prefix prepended to the pre-image
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"syscall/js"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+// hashOptions converts the javascript options object
+func hashOptions(options js.Value) ([]simplehash.HashOption, error) {
+	if options.IsUndefined() || options.IsNull() {
+		return nil, nil
+	}
+	if options.Type() != js.TypeObject {
+		return nil, fmt.Errorf("hashEventV3 options must be an object")
+	}
+	var opts []simplehash.HashOption
+	if useNumber := options.Get("useNumber"); useNumber.Type() == js.TypeBoolean && useNumber.Bool() {
+		opts = append(opts, simplehash.WithUseNumber())
+	}
+	if prefix := options.Get("prefix"); !prefix.IsUndefined() && !prefix.IsNull() {
+		if prefix.Type() != js.TypeString {
+			return nil, fmt.Errorf("hashEventV3 prefix must be a hex string")
+		}
+		b, err := hex.DecodeString(prefix.String())
+		if err != nil {
+			return nil, fmt.Errorf("prefix: %w", err)
+		}
+		opts = append(opts, simplehash.WithPrefix(b))
+	}
+	return opts, nil
+}
+
+func hashEventV3(this js.Value, args []js.Value) any {
+	if len(args) < 1 || len(args) > 2 || args[0].Type() != js.TypeString {
+		return js.Global().Get("Error").New("hashEventV3 expects a json string argument and optional options")
+	}
+	var options js.Value
+	if len(args) == 2 {
+		options = args[1]
+	}
+	opts, err := hashOptions(options)
+	if err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+
+	h := simplehash.NewHasherV3()
+	if err := h.HashEventFromJSON([]byte(args[0].String()), opts...); err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func main() {
+	js.Global().Set("hashEventV3", js.FuncOf(hashEventV3))
+
+	// keep the exported function available for the life of the page
+	select {}
+}
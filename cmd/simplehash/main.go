@@ -0,0 +1,168 @@
+// Command simplehash runs simple hash tooling against local files.
+//
+//	simplehash conformance [-json] [-v] -manifest manifest.json corpusdir
+//
+// checks that the hashes of the events in corpusdir agree with those recorded
+// in the manifest by another implementation, see package conformance. It
+// exits with status 1 if any event does not agree, and 2 if the check could
+// not be run.
+//
+//	simplehash analyze [-json] [-glob pattern] [-use-number] exportdir
+//
+// reports the events of an export which would fail to hash, or would hash
+// ambiguously, before an anchor reproduction run starts, see
+// simplehash.Analyzer. It exits with status 1 if any problem is found, and 2
+// if the analysis could not be run.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/datatrails/go-datatrails-simplehash/conformance"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+const (
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+const usage = `usage: simplehash <command> [arguments]
+
+commands:
+  analyze      report the events of an export which can't be hashed reliably
+  conformance  check the hashes of a corpus against another implementation
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	switch args[0] {
+	case "analyze":
+		return runAnalyze(ctx, args[1:], stdout, stderr)
+	case "conformance":
+		return runConformance(ctx, args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "simplehash: unknown command %q\n%s", args[0], usage)
+		return exitUsage
+	}
+}
+
+func runConformance(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("conformance", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	manifestPath := flags.String("manifest", "", "`path` of the json manifest of expected hashes")
+	asJSON := flags.Bool("json", false, "write the report as json")
+	verbose := flags.Bool("v", false, "list the events which agree, as well as those which do not")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: simplehash conformance [-json] [-v] -manifest manifest.json corpusdir")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *manifestPath == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	report, err := conformance.RunDir(ctx, flags.Arg(0), *manifestPath)
+	if err != nil && !errors.Is(err, conformance.ErrDisagreement) {
+		fmt.Fprintf(stderr, "simplehash conformance: %v\n", err)
+		return exitUsage
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "simplehash conformance: %v\n", err)
+			return exitUsage
+		}
+	} else {
+		for _, r := range report.Results {
+			if r.Status == conformance.StatusAgree && !*verbose {
+				continue
+			}
+			fmt.Fprintf(stdout, "%s\t%s\t%s", r.Status, r.Path, r.Identity)
+			switch r.Status {
+			case conformance.StatusDisagree:
+				fmt.Fprintf(stdout, "\texpected %s got %s", r.Expected, r.Actual)
+			case conformance.StatusError:
+				fmt.Fprintf(stdout, "\t%s", r.Error)
+			}
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "%d agreed, %d disagreed, %d missing, %d errors\n",
+			report.Agreed, report.Disagreed, report.Missing, report.Errors)
+	}
+
+	if !report.OK() {
+		return exitFailed
+	}
+	return 0
+}
+
+func runAnalyze(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	glob := flags.String("glob", "*.json", "`pattern` selecting the event files, see simplehash.HashEventsFS")
+	useNumber := flags.Bool("use-number", false, "hash numeric attribute values, see simplehash.WithUseNumber")
+	asJSON := flags.Bool("json", false, "write the analysis as json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: simplehash analyze [-json] [-glob pattern] [-use-number] exportdir")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var opts []simplehash.HashOption
+	if *useNumber {
+		opts = append(opts, simplehash.WithUseNumber())
+	}
+	analysis, err := simplehash.AnalyzeEventsFS(ctx, os.DirFS(flags.Arg(0)), *glob, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "simplehash analyze: %v\n", err)
+		return exitUsage
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(analysis); err != nil {
+			fmt.Fprintf(stderr, "simplehash analyze: %v\n", err)
+			return exitUsage
+		}
+	} else {
+		for _, f := range analysis.Findings {
+			fmt.Fprintln(stdout, f)
+		}
+		fmt.Fprintf(stdout, "%d events, %d unhashable, %d ambiguous\n", analysis.Events,
+			analysis.Count(simplehash.FindingUnhashable), analysis.Count(simplehash.FindingAmbiguous))
+	}
+
+	if !analysis.OK() {
+		return exitFailed
+	}
+	return 0
+}
@@ -0,0 +1,191 @@
+// Package conformance checks that this implementation agrees with another on
+// the schema v3 hashes of a corpus of events, for periodic interop assurance.
+//
+// The other implementation hashes each api formatted event of a corpus
+// directory and records the hashes in a manifest, in the format of a
+// verification bundle manifest, see simplehash.BundleManifest. The path of
+// each entry is relative to the corpus directory, and the hash is either hex
+// or a digest string, see simplehash.FormatDigest. Run re-computes the hash
+// of every listed event and reports the agreement of each.
+package conformance
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+var (
+	ErrInvalidManifest = errors.New("invalid conformance manifest")
+	ErrDisagreement    = errors.New("implementations disagree")
+)
+
+// Status is the outcome of checking a single event
+type Status string
+
+const (
+	// StatusAgree is an event hashed to the expected hash
+	StatusAgree Status = "agree"
+	// StatusDisagree is an event hashed to a different hash
+	StatusDisagree Status = "disagree"
+	// StatusMissing is an event listed in the manifest but not in the corpus
+	StatusMissing Status = "missing"
+	// StatusError is an event which could not be read or hashed, or whose
+	// expected hash is not valid
+	StatusError Status = "error"
+)
+
+// Result is the outcome of checking a single event of the manifest
+type Result struct {
+	Path string `json:"path"`
+	// Identity is the identity recorded in the manifest
+	Identity string `json:"identity"`
+	Status   Status `json:"status"`
+	// Expected is the hash recorded in the manifest, unnormalised
+	Expected string `json:"expected"`
+	// Actual is the hex encoded hash computed by this implementation, empty if
+	// the event could not be hashed
+	Actual string `json:"actual,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the outcome of checking every event of the manifest, in manifest
+// order
+type Report struct {
+	Results   []Result `json:"results"`
+	Agreed    int      `json:"agreed"`
+	Disagreed int      `json:"disagreed"`
+	Missing   int      `json:"missing"`
+	Errors    int      `json:"errors"`
+}
+
+// OK returns true if every event of the manifest agreed
+func (r Report) OK() bool {
+	return r.Agreed == len(r.Results)
+}
+
+func (r *Report) add(result Result) {
+	r.Results = append(r.Results, result)
+	switch result.Status {
+	case StatusAgree:
+		r.Agreed++
+	case StatusDisagree:
+		r.Disagreed++
+	case StatusMissing:
+		r.Missing++
+	default:
+		r.Errors++
+	}
+}
+
+// ReadManifest reads a json manifest of expected hashes. Only schema v3
+// sha256 manifests are supported.
+func ReadManifest(r io.Reader) (simplehash.BundleManifest, error) {
+	manifest := simplehash.BundleManifest{}
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return simplehash.BundleManifest{}, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if manifest.SchemaVersion != simplehash.SchemaVersionV3 || manifest.HashAlgorithm != simplehash.DigestAlgorithmSHA256 {
+		return simplehash.BundleManifest{}, fmt.Errorf(
+			"%w: unsupported schema %d hash %s", ErrInvalidManifest, manifest.SchemaVersion, manifest.HashAlgorithm)
+	}
+	return manifest, nil
+}
+
+// Run hashes each event listed in the manifest, reading it from corpus, with
+// the options recorded in the manifest, and compares the result with the
+// expected hash. Events in the corpus which are not listed are not checked.
+//
+// The report lists every event. If any event did not agree the returned
+// error wraps ErrDisagreement. On cancellation the partial report is
+// returned with a *simplehash.CanceledError.
+func Run(ctx context.Context, corpus fs.FS, manifest simplehash.BundleManifest) (Report, error) {
+
+	opts, err := manifest.Options.HashOptions()
+	if err != nil {
+		return Report{}, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+
+	report := Report{Results: make([]Result, 0, len(manifest.Events))}
+	for i, entry := range manifest.Events {
+		if err := ctx.Err(); err != nil {
+			return report, &simplehash.CanceledError{Processed: i, Err: err}
+		}
+		report.add(check(corpus, entry, opts))
+	}
+
+	if !report.OK() {
+		return report, fmt.Errorf("%w: %d disagreed, %d missing and %d errors of %d events",
+			ErrDisagreement, report.Disagreed, report.Missing, report.Errors, len(report.Results))
+	}
+	return report, nil
+}
+
+// RunDir is Run, reading the manifest from manifestPath and the corpus from
+// the directory dir
+func RunDir(ctx context.Context, dir string, manifestPath string) (Report, error) {
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return Report{}, err
+	}
+	defer f.Close()
+	manifest, err := ReadManifest(f)
+	if err != nil {
+		return Report{}, err
+	}
+	return Run(ctx, os.DirFS(dir), manifest)
+}
+
+func check(corpus fs.FS, entry simplehash.BundleEntry, opts []simplehash.HashOption) Result {
+	result := Result{Path: entry.Path, Identity: entry.Identity, Expected: entry.SimpleHash}
+
+	eventJson, err := fs.ReadFile(corpus, entry.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		result.Status = StatusMissing
+		return result
+	}
+	if err != nil {
+		result.Status, result.Error = StatusError, err.Error()
+		return result
+	}
+
+	digest, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+	if err != nil {
+		result.Status, result.Error = StatusError, err.Error()
+		return result
+	}
+	result.Actual = hex.EncodeToString(digest)
+
+	expected, err := parseDigest(entry.SimpleHash)
+	if err != nil {
+		result.Status, result.Error = StatusError, err.Error()
+		return result
+	}
+	result.Status = StatusDisagree
+	if hex.EncodeToString(expected) == result.Actual {
+		result.Status = StatusAgree
+	}
+	return result
+}
+
+// parseDigest parses an expected hash, hex or a v3 sha256 digest string
+func parseDigest(s string) ([]byte, error) {
+	if sum, err := hex.DecodeString(s); err == nil && len(sum) != 0 {
+		return sum, nil
+	}
+	d, err := simplehash.ParseDigest(s)
+	if err != nil {
+		return nil, err
+	}
+	if d.Schema != simplehash.SchemaVersionV3 || d.Algorithm != simplehash.DigestAlgorithmSHA256 {
+		return nil, fmt.Errorf("%w: %s is not a v3 sha256 digest", simplehash.ErrInvalidDigest, s)
+	}
+	return d.Sum, nil
+}
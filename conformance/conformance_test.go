@@ -0,0 +1,92 @@
+package conformance
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCorpus = fstest.MapFS{
+	"events/a.json":       {Data: []byte(`{"identity":"assets/1234/events/5678","operation":"Record"}`)},
+	"events/b.json":       {Data: []byte(`{"identity":"assets/1234/events/9abc","operation":"Record"}`)},
+	"events/c.json":       {Data: []byte(`{"identity":"assets/1234/events/def0","operation":"Record"}`)},
+	"events/invalid.json": {Data: []byte(`{"identity":`)},
+}
+
+func testDigest(t *testing.T, path string, opts ...simplehash.HashOption) []byte {
+	digest, err := simplehash.DigestEventFromJSON(testCorpus[path].Data, opts...)
+	require.NoError(t, err)
+	return digest
+}
+
+// TestRun tests:
+//
+// 1. hashes recorded as hex or as digest strings agree, using the options
+// recorded in the manifest.
+// 2. a different hash disagrees, and reports the hash computed.
+// 3. an event not in the corpus is missing, and an event which can't be
+// hashed is an error.
+// 4. the run fails with ErrDisagreement, and the report counts each outcome.
+func TestRun(t *testing.T) {
+	prefix := []byte{0x01}
+	manifest := simplehash.BundleManifest{
+		SchemaVersion: 3,
+		HashAlgorithm: "sha256",
+		Options:       simplehash.BundleOptions{Prefix: hex.EncodeToString(prefix)},
+		Events: []simplehash.BundleEntry{
+			{
+				Path:       "events/a.json",
+				SimpleHash: hex.EncodeToString(testDigest(t, "events/a.json", simplehash.WithPrefix(prefix))),
+			},
+			{
+				Path: "events/b.json",
+				SimpleHash: simplehash.FormatDigest(
+					3, "sha256", testDigest(t, "events/b.json", simplehash.WithPrefix(prefix))),
+			},
+			{Path: "events/c.json", SimpleHash: hex.EncodeToString(testDigest(t, "events/c.json"))},
+			{Path: "events/d.json", Identity: "assets/1234/events/1111", SimpleHash: "00"},
+			{Path: "events/invalid.json", SimpleHash: "00"},
+		},
+	}
+
+	report, err := Run(context.Background(), testCorpus, manifest)
+	require.ErrorIs(t, err, ErrDisagreement)
+	assert.False(t, report.OK())
+
+	statuses := []Status{}
+	for _, r := range report.Results {
+		statuses = append(statuses, r.Status)
+	}
+	assert.Equal(t, []Status{StatusAgree, StatusAgree, StatusDisagree, StatusMissing, StatusError}, statuses)
+	assert.Equal(t, hex.EncodeToString(testDigest(t, "events/c.json", simplehash.WithPrefix(prefix))), report.Results[2].Actual)
+	assert.Equal(t, "assets/1234/events/1111", report.Results[3].Identity)
+	assert.NotEmpty(t, report.Results[4].Error)
+	assert.Equal(t, Report{Results: report.Results, Agreed: 2, Disagreed: 1, Missing: 1, Errors: 1}, report)
+
+	manifest.Events = manifest.Events[:2]
+	report, err = Run(context.Background(), testCorpus, manifest)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+}
+
+// TestReadManifest tests:
+//
+// 1. a v3 sha256 manifest is read.
+// 2. other schemas, and invalid json, fail with ErrInvalidManifest.
+func TestReadManifest(t *testing.T) {
+	manifest, err := ReadManifest(strings.NewReader(
+		`{"schema_version":3,"hash_alg":"sha256","events":[{"path":"a.json","simplehash":"00"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "a.json", manifest.Events[0].Path)
+
+	_, err = ReadManifest(strings.NewReader(`{"schema_version":2,"hash_alg":"sha256"}`))
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+	_, err = ReadManifest(strings.NewReader(`{`))
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+}
@@ -0,0 +1,404 @@
+package eventhub
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// This file implements the subset of the avro object container format read
+// by CaptureReader: the header, null and deflate compressed blocks, and
+// generic decoding of records against the writer schema. Writing is not
+// supported.
+
+var avroMagic = []byte{'O', 'b', 'j', 1}
+
+const avroSyncSize = 16
+
+// maxAvroBlockSize limits the decompressed size of a block, so that a small
+// deflate block can not expand without bound. Capture writes blocks far
+// smaller, of a few MiB at most.
+var maxAvroBlockSize int64 = 64 << 20
+
+// avroSchema is a parsed avro schema. Named types are resolved when parsed.
+type avroSchema struct {
+	typ     string
+	fields  []avroField
+	items   *avroSchema
+	values  *avroSchema
+	union   []*avroSchema
+	symbols []string
+	size    int
+}
+
+type avroField struct {
+	name   string
+	schema *avroSchema
+}
+
+// parseAvroSchema parses the json of a schema
+func parseAvroSchema(schemaJson []byte) (*avroSchema, error) {
+	var v any
+	if err := json.Unmarshal(schemaJson, &v); err != nil {
+		return nil, fmt.Errorf("%w: schema: %v", ErrInvalidCapture, err)
+	}
+	return newSchemaParser().parse(v, "")
+}
+
+type schemaParser struct {
+	named map[string]*avroSchema
+}
+
+func newSchemaParser() *schemaParser {
+	return &schemaParser{named: map[string]*avroSchema{}}
+}
+
+func (p *schemaParser) parse(v any, namespace string) (*avroSchema, error) {
+	switch s := v.(type) {
+	case string:
+		switch s {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroSchema{typ: s}, nil
+		}
+		if named, ok := p.named[qualify(s, namespace)]; ok {
+			return named, nil
+		}
+		if named, ok := p.named[s]; ok {
+			return named, nil
+		}
+		return nil, fmt.Errorf("%w: unknown schema type %q", ErrInvalidCapture, s)
+	case []any:
+		union := &avroSchema{typ: "union"}
+		for _, branch := range s {
+			b, err := p.parse(branch, namespace)
+			if err != nil {
+				return nil, err
+			}
+			union.union = append(union.union, b)
+		}
+		return union, nil
+	case map[string]any:
+		return p.parseComplex(s, namespace)
+	default:
+		return nil, fmt.Errorf("%w: invalid schema %v", ErrInvalidCapture, v)
+	}
+}
+
+func (p *schemaParser) parseComplex(s map[string]any, namespace string) (*avroSchema, error) {
+	typ, _ := s["type"].(string)
+	if ns, ok := s["namespace"].(string); ok {
+		namespace = ns
+	}
+	schema := &avroSchema{typ: typ}
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		name, _ := s["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("%w: %s without a name", ErrInvalidCapture, typ)
+		}
+		// registered before the fields are parsed, records may refer to
+		// themselves
+		p.named[qualify(name, namespace)] = schema
+	}
+
+	var err error
+	switch typ {
+	case "record", "error":
+		schema.typ = "record"
+		fields, _ := s["fields"].([]any)
+		for _, f := range fields {
+			field, _ := f.(map[string]any)
+			name, _ := field["name"].(string)
+			fieldSchema, err := p.parse(field["type"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			schema.fields = append(schema.fields, avroField{name: name, schema: fieldSchema})
+		}
+	case "enum":
+		symbols, _ := s["symbols"].([]any)
+		for _, symbol := range symbols {
+			name, _ := symbol.(string)
+			schema.symbols = append(schema.symbols, name)
+		}
+	case "fixed":
+		size, _ := s["size"].(float64)
+		schema.size = int(size)
+	case "array":
+		schema.items, err = p.parse(s["items"], namespace)
+	case "map":
+		schema.values, err = p.parse(s["values"], namespace)
+	default:
+		// a primitive type, possibly with a logical type annotation
+		return p.parse(s["type"], namespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func qualify(name string, namespace string) string {
+	if namespace == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// avroReader is satisfied by bufio.Reader and bytes.Reader
+type avroReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// avroDecoder decodes avro binary encoded values
+type avroDecoder struct {
+	r avroReader
+}
+
+func (d avroDecoder) long() (int64, error) {
+	u, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, err
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+func (d avroDecoder) fixed(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w: negative length %d", ErrInvalidCapture, n)
+	}
+	// lengths are read from the file, so large values are not trusted with
+	// an allocation up front
+	if n > 1<<16 {
+		var buf bytes.Buffer
+		if _, err := io.CopyN(&buf, d.r, int64(n)); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return buf.Bytes(), nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (d avroDecoder) bytes() ([]byte, error) {
+	n, err := d.long()
+	if err != nil {
+		return nil, err
+	}
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidCapture, n)
+	}
+	return d.fixed(int(n))
+}
+
+// blockCount reads the count of an array or map block. A negative count is
+// followed by the size of the block in bytes, which is not needed.
+func (d avroDecoder) blockCount() (int64, error) {
+	n, err := d.long()
+	if err != nil || n >= 0 {
+		return n, err
+	}
+	if _, err = d.long(); err != nil {
+		return 0, err
+	}
+	return -n, nil
+}
+
+// decode decodes a value of the schema. Records and maps decode to
+// map[string]any, arrays to []any, ints and longs to int64, enums to their
+// symbol, and bytes and fixed to []byte.
+func (d avroDecoder) decode(s *avroSchema) (any, error) {
+	switch s.typ {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := d.r.ReadByte()
+		return b != 0, err
+	case "int", "long":
+		return d.long()
+	case "float":
+		b, err := d.fixed(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case "double":
+		b, err := d.fixed(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case "bytes":
+		return d.bytes()
+	case "string":
+		b, err := d.bytes()
+		return string(b), err
+	case "fixed":
+		return d.fixed(s.size)
+	case "enum":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(s.symbols)) {
+			return nil, fmt.Errorf("%w: enum index %d", ErrInvalidCapture, i)
+		}
+		return s.symbols[i], nil
+	case "union":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(s.union)) {
+			return nil, fmt.Errorf("%w: union index %d", ErrInvalidCapture, i)
+		}
+		return d.decode(s.union[i])
+	case "record":
+		record := make(map[string]any, len(s.fields))
+		for _, f := range s.fields {
+			v, err := d.decode(f.schema)
+			if err != nil {
+				return nil, err
+			}
+			record[f.name] = v
+		}
+		return record, nil
+	case "array":
+		var items []any
+		for {
+			n, err := d.blockCount()
+			if err != nil || n == 0 {
+				return items, err
+			}
+			for ; n > 0; n-- {
+				v, err := d.decode(s.items)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+			}
+		}
+	case "map":
+		m := map[string]any{}
+		for {
+			n, err := d.blockCount()
+			if err != nil || n == 0 {
+				return m, err
+			}
+			for ; n > 0; n-- {
+				k, err := d.bytes()
+				if err != nil {
+					return nil, err
+				}
+				v, err := d.decode(s.values)
+				if err != nil {
+					return nil, err
+				}
+				m[string(k)] = v
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported schema type %q", ErrInvalidCapture, s.typ)
+	}
+}
+
+// avroContainer reads the records of an object container file
+type avroContainer struct {
+	r      *bufio.Reader
+	schema *avroSchema
+	codec  string
+	sync   []byte
+
+	block     avroDecoder
+	remaining int64
+}
+
+func newAvroContainer(r io.Reader) (*avroContainer, error) {
+	c := &avroContainer{r: bufio.NewReader(r)}
+	header := avroDecoder{c.r}
+
+	magic, err := header.fixed(len(avroMagic))
+	if err != nil || !bytes.Equal(magic, avroMagic) {
+		return nil, fmt.Errorf("%w: not an avro container file", ErrInvalidCapture)
+	}
+	metadata, err := header.decode(&avroSchema{typ: "map", values: &avroSchema{typ: "bytes"}})
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidCapture, err)
+	}
+	meta := metadata.(map[string]any)
+	schemaJson, _ := meta["avro.schema"].([]byte)
+	if c.schema, err = parseAvroSchema(schemaJson); err != nil {
+		return nil, err
+	}
+	codec, _ := meta["avro.codec"].([]byte)
+	c.codec = string(codec)
+	if c.codec != "" && c.codec != "null" && c.codec != "deflate" {
+		return nil, fmt.Errorf("%w: unsupported codec %q", ErrInvalidCapture, c.codec)
+	}
+	if c.sync, err = header.fixed(avroSyncSize); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidCapture, err)
+	}
+	return c, nil
+}
+
+// next returns the next record, or io.EOF after the last
+func (c *avroContainer) next() (any, error) {
+	for c.remaining == 0 {
+		if err := c.nextBlock(); err != nil {
+			return nil, err
+		}
+	}
+	c.remaining--
+	v, err := c.block.decode(c.schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCapture, err)
+	}
+	return v, nil
+}
+
+func (c *avroContainer) nextBlock() error {
+	d := avroDecoder{c.r}
+	count, err := d.long()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("%w: block: %v", ErrInvalidCapture, err)
+	}
+	data, err := d.bytes()
+	if err != nil {
+		return fmt.Errorf("%w: block: %v", ErrInvalidCapture, err)
+	}
+	sync, err := d.fixed(avroSyncSize)
+	if err != nil || !bytes.Equal(sync, c.sync) {
+		return fmt.Errorf("%w: block sync marker", ErrInvalidCapture)
+	}
+	if count < 0 {
+		return fmt.Errorf("%w: block count %d", ErrInvalidCapture, count)
+	}
+
+	if c.codec == "deflate" {
+		// read one byte beyond the limit to detect a block which exceeds it
+		r := io.LimitReader(flate.NewReader(bytes.NewReader(data)), maxAvroBlockSize+1)
+		if data, err = io.ReadAll(r); err != nil {
+			return fmt.Errorf("%w: block: %v", ErrInvalidCapture, err)
+		}
+		if int64(len(data)) > maxAvroBlockSize {
+			return fmt.Errorf("%w: block exceeds %d bytes decompressed", ErrInvalidCapture, maxAvroBlockSize)
+		}
+	}
+	c.block = avroDecoder{bytes.NewReader(data)}
+	c.remaining = count
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package azure receives events for eventhub.Consumer from an Event Hubs
+// partition, with the azeventhubs client. It is a separate module, so that
+// the azure sdk is only a dependency of the applications which consume from
+// Event Hubs:
+//
+//	start, err := azure.StartPosition(consumer)
+//	client, err := consumerClient.NewPartitionClient(partitionID,
+//		&azeventhubs.PartitionClientOptions{StartPosition: start})
+//	err = consumer.Consume(ctx, azure.NewReceiver(client))
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs"
+	"github.com/datatrails/go-datatrails-simplehash/eventhub"
+)
+
+// PartitionClient is the subset of *azeventhubs.PartitionClient used by
+// Receiver
+type PartitionClient interface {
+	ReceiveEvents(
+		ctx context.Context, count int, options *azeventhubs.ReceiveEventsOptions,
+	) ([]*azeventhubs.ReceivedEventData, error)
+}
+
+// Receiver is an eventhub.Receiver over a partition client
+type Receiver struct {
+	client PartitionClient
+}
+
+var _ eventhub.Receiver = (*Receiver)(nil)
+
+// NewReceiver returns a receiver of the events of client
+func NewReceiver(client PartitionClient) *Receiver {
+	return &Receiver{client: client}
+}
+
+// ReceiveEvents receives up to count events. As for the partition client,
+// the events received before an error are returned with it.
+func (r *Receiver) ReceiveEvents(ctx context.Context, count int) ([]eventhub.Event, error) {
+	received, err := r.client.ReceiveEvents(ctx, count, nil)
+	events := make([]eventhub.Event, len(received))
+	for i, e := range received {
+		events[i] = eventhub.Event{Body: e.Body, SequenceNumber: e.SequenceNumber}
+	}
+	return events, err
+}
+
+// StartPosition returns the position to open the partition client at: after
+// the last event accumulated by the consumer, recovered from its checkpoint,
+// or the earliest event if the partition has not been consumed before.
+func StartPosition(consumer *eventhub.Consumer) (azeventhubs.StartPosition, error) {
+	sequence, ok, err := consumer.StartSequence()
+	if err != nil {
+		return azeventhubs.StartPosition{}, err
+	}
+	if !ok {
+		earliest := true
+		return azeventhubs.StartPosition{Earliest: &earliest}, nil
+	}
+	return azeventhubs.StartPosition{SequenceNumber: &sequence}, nil
+}
@@ -0,0 +1,84 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs"
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-simplehash/eventhub"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakePartitionClient returns its events in batches, then cancels the
+// consumer
+type fakePartitionClient struct {
+	events []*azeventhubs.ReceivedEventData
+	cancel context.CancelFunc
+}
+
+func (c *fakePartitionClient) ReceiveEvents(
+	ctx context.Context, count int, _ *azeventhubs.ReceiveEventsOptions,
+) ([]*azeventhubs.ReceivedEventData, error) {
+	if len(c.events) == 0 {
+		c.cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	n := min(count, len(c.events))
+	events := c.events[:n]
+	c.events = c.events[n:]
+	return events, nil
+}
+
+// TestReceiver tests:
+//
+// 1. events received from the partition client accumulate to the same digest
+// as hashing them directly.
+// 2. the start position is the earliest event before the partition is
+// consumed, and after the checkpointed sequence number once it has been.
+func TestReceiver(t *testing.T) {
+	var events []*azeventhubs.ReceivedEventData
+	expected := simplehash.NewHasherV3()
+	for i := 1; i <= 3; i++ {
+		event := &v2assets.EventResponse{
+			Identity:      fmt.Sprintf("assets/1/events/%d", i),
+			AssetIdentity: "assets/1",
+			Operation:     "Record",
+		}
+		require.NoError(t, expected.HashEvent(event, simplehash.WithAccumulate()))
+		body, err := proto.Marshal(event)
+		require.NoError(t, err)
+		received := &azeventhubs.ReceivedEventData{SequenceNumber: int64(10 + i)}
+		received.Body = body
+		events = append(events, received)
+	}
+
+	store, err := simplehash.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	stream, err := simplehash.NewStreamHasher(store, "partition-0", 0)
+	require.NoError(t, err)
+	consumer := eventhub.NewConsumer(stream, "0")
+	consumer.SetBatchSize(2)
+
+	start, err := StartPosition(consumer)
+	require.NoError(t, err)
+	require.NotNil(t, start.Earliest)
+	assert.True(t, *start.Earliest)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = consumer.Consume(ctx, NewReceiver(&fakePartitionClient{events: events, cancel: cancel}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, expected.Sum(nil), stream.Digest())
+
+	start, err = StartPosition(consumer)
+	require.NoError(t, err)
+	assert.Nil(t, start.Earliest)
+	require.NotNil(t, start.SequenceNumber)
+	assert.Equal(t, int64(13), *start.SequenceNumber)
+	assert.False(t, start.Inclusive)
+}
@@ -0,0 +1,170 @@
+package eventhub
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// captureSchema is the schema Event Hubs Capture writes
+const captureSchema = `{"type":"record","name":"EventData","namespace":"Microsoft.ServiceBus.Messaging","fields":[
+{"name":"SequenceNumber","type":"long"},
+{"name":"Offset","type":"string"},
+{"name":"EnqueuedTimeUtc","type":"string"},
+{"name":"SystemProperties","type":{"type":"map","values":["long","double","string","bytes"]}},
+{"name":"Properties","type":{"type":"map","values":["long","double","string","bytes","null"]}},
+{"name":"Body","type":["null","bytes"]}]}`
+
+type avroWriter struct {
+	bytes.Buffer
+}
+
+func (w *avroWriter) long(n int64) {
+	w.Write(binary.AppendUvarint(nil, uint64((n<<1)^(n>>63))))
+}
+
+func (w *avroWriter) bytes(b []byte) {
+	w.long(int64(len(b)))
+	w.Write(b)
+}
+
+// writeCapture writes a capture file holding events, split into blocks of
+// blockSize records, compressed with codec
+func writeCapture(t *testing.T, codec string, blockSize int, events []Event) []byte {
+	sync := []byte("0123456789abcdef")
+	w := &avroWriter{}
+	w.Write(avroMagic)
+	w.long(2)
+	w.bytes([]byte("avro.schema"))
+	w.bytes([]byte(captureSchema))
+	w.bytes([]byte("avro.codec"))
+	w.bytes([]byte(codec))
+	w.long(0)
+	w.Write(sync)
+
+	for start := 0; start < len(events); start += blockSize {
+		block := &avroWriter{}
+		end := min(start+blockSize, len(events))
+		for _, e := range events[start:end] {
+			block.long(e.SequenceNumber)
+			block.bytes([]byte(fmt.Sprint(e.SequenceNumber * 100)))
+			block.bytes([]byte("2024-01-02T03:04:05Z"))
+			// SystemProperties, with one block of a negative count and
+			// its size
+			entry := &avroWriter{}
+			entry.bytes([]byte("x-opt-sequence-number"))
+			entry.long(0)
+			entry.long(e.SequenceNumber)
+			block.long(-1)
+			block.long(int64(entry.Len()))
+			block.Write(entry.Bytes())
+			block.long(0)
+			// Properties, empty
+			block.long(0)
+			if e.Body == nil {
+				block.long(0)
+			} else {
+				block.long(1)
+				block.bytes(e.Body)
+			}
+		}
+		data := block.Bytes()
+		if codec == "deflate" {
+			var compressed bytes.Buffer
+			fw, err := flate.NewWriter(&compressed, flate.DefaultCompression)
+			require.NoError(t, err)
+			_, err = fw.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, fw.Close())
+			data = compressed.Bytes()
+		}
+		w.long(int64(end - start))
+		w.bytes(data)
+		w.Write(sync)
+	}
+	return w.Bytes()
+}
+
+// TestConsumeCapture tests:
+//
+// 1. the events of null and deflate compressed captures accumulate to the
+// same digest as hashing them directly.
+// 2. events up to the checkpointed sequence number are skipped.
+// 3. an event without a body is recorded as invalid.
+// 4. a file which is not an avro container fails with ErrInvalidCapture.
+func TestConsumeCapture(t *testing.T) {
+	var events []Event
+	expected := simplehash.NewHasherV3()
+	for i := 1; i <= 5; i++ {
+		event := &v2assets.EventResponse{
+			Identity:      fmt.Sprintf("assets/1/events/%d", i),
+			AssetIdentity: "assets/1",
+			Operation:     "Record",
+		}
+		require.NoError(t, expected.HashEvent(event, simplehash.WithAccumulate()))
+		body, err := proto.Marshal(event)
+		require.NoError(t, err)
+		events = append(events, Event{Body: body, SequenceNumber: int64(10 + i)})
+	}
+
+	for _, codec := range []string{"null", "deflate"} {
+		t.Run(codec, func(t *testing.T) {
+			stream, err := simplehash.NewStreamHasher(nil, "partition-0", 0)
+			require.NoError(t, err)
+			c := NewConsumer(stream, "0")
+
+			require.NoError(t, c.ConsumeCapture(bytes.NewReader(writeCapture(t, codec, 2, events[:3]))))
+			// the second capture overlaps the first
+			require.NoError(t, c.ConsumeCapture(bytes.NewReader(writeCapture(t, codec, 2, events[1:]))))
+			assert.Equal(t, expected.Sum(nil), stream.Digest())
+			assert.Equal(t, len(events), stream.Count())
+
+			sequence, ok, err := c.StartSequence()
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, int64(15), sequence)
+		})
+	}
+
+	stream, err := simplehash.NewStreamHasher(nil, "partition-0", 0, simplehash.WithContinueOnError())
+	require.NoError(t, err)
+	c := NewConsumer(stream, "0")
+	require.NoError(t, c.ConsumeCapture(bytes.NewReader(writeCapture(t, "null", 2, []Event{{SequenceNumber: 1}}))))
+	_, err = stream.Result()
+	assert.ErrorIs(t, err, ErrInvalidCapture)
+
+	err = c.ConsumeCapture(bytes.NewReader([]byte("not avro")))
+	assert.ErrorIs(t, err, ErrInvalidCapture)
+}
+
+// TestCaptureReader_BlockLimit tests that a deflate block which decompresses
+// beyond the block size limit fails with ErrInvalidCapture, rather than being
+// read into memory whole.
+func TestCaptureReader_BlockLimit(t *testing.T) {
+	capture := writeCapture(t, "deflate", 2, []Event{
+		{Body: bytes.Repeat([]byte{0}, 4096), SequenceNumber: 1},
+		{Body: bytes.Repeat([]byte{0}, 4096), SequenceNumber: 2},
+	})
+
+	r, err := NewCaptureReader(bytes.NewReader(capture))
+	require.NoError(t, err)
+	_, err = r.Next()
+	require.NoError(t, err)
+
+	defer func(size int64) { maxAvroBlockSize = size }(maxAvroBlockSize)
+	maxAvroBlockSize = 4096
+
+	r, err = NewCaptureReader(bytes.NewReader(capture))
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.ErrorIs(t, err, ErrInvalidCapture)
+	assert.ErrorContains(t, err, "exceeds 4096 bytes")
+}
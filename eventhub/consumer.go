@@ -0,0 +1,112 @@
+// Package eventhub feeds events consumed from Azure Event Hubs, the platform
+// message bus transport, into a simplehash.StreamHasher.
+//
+// The consumer reads from a Receiver. The receiver over an azeventhubs
+// partition client is in the nested module eventhub/azure, which, unlike this
+// package, depends on the azure sdk. Tests and other transports implement
+// Receiver directly.
+//
+// Events captured to blob storage by Event Hubs Capture are read with
+// ConsumeCapture.
+package eventhub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+const defaultBatchSize = 100
+
+// Event is a single message received from an Event Hub partition. The body is
+// an EventResponse in proto wire format.
+type Event struct {
+	Body           []byte
+	SequenceNumber int64
+}
+
+// Receiver receives batches of events from a single Event Hub partition. As
+// for azeventhubs, events may be returned along with an error.
+type Receiver interface {
+	ReceiveEvents(ctx context.Context, count int) ([]Event, error)
+}
+
+// Consumer accumulates the events of a single partition. The sequence number
+// of each event is saved, with the hash state, in the stream checkpoints, so
+// that after a restart the partition can be resumed from StartSequence.
+type Consumer struct {
+	stream      *simplehash.StreamHasher
+	partitionID string
+	batchSize   int
+}
+
+// NewConsumer creates a consumer feeding stream from the partition
+func NewConsumer(stream *simplehash.StreamHasher, partitionID string) *Consumer {
+	return &Consumer{
+		stream:      stream,
+		partitionID: partitionID,
+		batchSize:   defaultBatchSize,
+	}
+}
+
+// SetBatchSize sets the maximum number of events requested from the receiver
+// at once
+func (c *Consumer) SetBatchSize(n int) {
+	if n > 0 {
+		c.batchSize = n
+	}
+}
+
+func (c *Consumer) sequenceKey() string {
+	return "eventhub/" + c.partitionID + "/sequence_number"
+}
+
+// StartSequence returns the sequence number of the last event accumulated
+// from the partition, recovered from the stream checkpoint. The receiver
+// should start after it, exclusively. ok is false if the partition has not
+// been consumed before.
+func (c *Consumer) StartSequence() (sequence int64, ok bool, err error) {
+	value, ok := c.stream.Metadata()[c.sequenceKey()]
+	if !ok {
+		return 0, false, nil
+	}
+	sequence, err = strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("checkpointed sequence number %q: %w", value, err)
+	}
+	return sequence, true, nil
+}
+
+// HandleEvent unmarshals a single event and accumulates it
+func (c *Consumer) HandleEvent(e Event) error {
+	return c.stream.AddProto(e.Body, c.metadata(e))
+}
+
+func (c *Consumer) metadata(e Event) map[string]string {
+	return map[string]string{
+		c.sequenceKey(): strconv.FormatInt(e.SequenceNumber, 10),
+	}
+}
+
+// Consume receives and accumulates events until the context is done, or the
+// receiver or the stream fails. A checkpoint is saved when the context is
+// done.
+func (c *Consumer) Consume(ctx context.Context, r Receiver) error {
+	for {
+		events, err := r.ReceiveEvents(ctx, c.batchSize)
+		for _, e := range events {
+			if herr := c.HandleEvent(e); herr != nil {
+				return herr
+			}
+		}
+		if ctx.Err() != nil {
+			return errors.Join(ctx.Err(), c.stream.Checkpoint())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package eventhub
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeReceiver returns its events in batches, then cancels the consumer
+type fakeReceiver struct {
+	events []Event
+	cancel context.CancelFunc
+}
+
+func (r *fakeReceiver) ReceiveEvents(ctx context.Context, count int) ([]Event, error) {
+	if len(r.events) == 0 {
+		r.cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	n := min(count, len(r.events))
+	events := r.events[:n]
+	r.events = r.events[n:]
+	return events, nil
+}
+
+// TestConsumer tests:
+//
+// 1. consumed events accumulate to the same digest as hashing them directly.
+// 2. the sequence number of the last event is checkpointed with the hash
+// state, and recovered by a restarted consumer.
+func TestConsumer(t *testing.T) {
+	var events []Event
+	expected := simplehash.NewHasherV3()
+	for i := 1; i <= 3; i++ {
+		event := &v2assets.EventResponse{
+			Identity:      fmt.Sprintf("assets/1/events/%d", i),
+			AssetIdentity: "assets/1",
+			Operation:     "Record",
+		}
+		require.NoError(t, expected.HashEvent(event, simplehash.WithAccumulate()))
+		body, err := proto.Marshal(event)
+		require.NoError(t, err)
+		events = append(events, Event{Body: body, SequenceNumber: int64(10 + i)})
+	}
+
+	store, err := simplehash.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	stream, err := simplehash.NewStreamHasher(store, "partition-0", 0)
+	require.NoError(t, err)
+
+	c := NewConsumer(stream, "0")
+	c.SetBatchSize(2)
+	_, ok, err := c.StartSequence()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = c.Consume(ctx, &fakeReceiver{events: events, cancel: cancel})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, expected.Sum(nil), stream.Digest())
+
+	stream, err = simplehash.NewStreamHasher(store, "partition-0", 0)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Sum(nil), stream.Digest())
+	sequence, ok, err := NewConsumer(stream, "0").StartSequence()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(13), sequence)
+
+	assert.Error(t, NewConsumer(stream, "0").HandleEvent(Event{Body: []byte{0xff}}))
+}
@@ -0,0 +1,198 @@
+// Package eventpb defines, in event.proto, a protobuf message mirroring
+// simplehash.V3Event, so that services persisting the hashed form of events on
+// a message bus can use a stable schema rather than ad hoc json.
+//
+// FromV3Event and ToV3Event convert between the two. The round trip preserves
+// the digest of the event.
+package eventpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative event.proto
+
+import (
+	"fmt"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+// FromV3Event converts the event to its protobuf message. Attribute values
+// must have one of the types an api attribute can hold, see
+// simplehash.V3Event.Validate, and principal values must be strings.
+func FromV3Event(e simplehash.V3Event) (*V3Event, error) {
+	eventAttributes, err := fromAttributes("event_attributes", e.EventAttributes)
+	if err != nil {
+		return nil, err
+	}
+	assetAttributes, err := fromAttributes("asset_attributes", e.AssetAttributes)
+	if err != nil {
+		return nil, err
+	}
+	principalAccepted, err := fromPrincipal("principal_accepted", e.PrincipalAccepted)
+	if err != nil {
+		return nil, err
+	}
+	principalDeclared, err := fromPrincipal("principal_declared", e.PrincipalDeclared)
+	if err != nil {
+		return nil, err
+	}
+
+	return &V3Event{
+		Identity:           e.Identity,
+		EventAttributes:    eventAttributes,
+		AssetAttributes:    assetAttributes,
+		Operation:          e.Operation,
+		Behaviour:          e.Behaviour,
+		TimestampDeclared:  e.TimestampDeclared,
+		TimestampAccepted:  e.TimestampAccepted,
+		TimestampCommitted: e.TimestampCommitted,
+		PrincipalAccepted:  principalAccepted,
+		PrincipalDeclared:  principalDeclared,
+		TenantIdentity:     e.TenantIdentity,
+	}, nil
+}
+
+// ToV3Event converts the protobuf message to the event. An attribute value
+// with none of its fields set is an error.
+func ToV3Event(m *V3Event) (simplehash.V3Event, error) {
+	eventAttributes, err := toAttributes("event_attributes", m.GetEventAttributes())
+	if err != nil {
+		return simplehash.V3Event{}, err
+	}
+	assetAttributes, err := toAttributes("asset_attributes", m.GetAssetAttributes())
+	if err != nil {
+		return simplehash.V3Event{}, err
+	}
+
+	return simplehash.V3Event{
+		Identity:           m.GetIdentity(),
+		EventAttributes:    eventAttributes,
+		AssetAttributes:    assetAttributes,
+		Operation:          m.GetOperation(),
+		Behaviour:          m.GetBehaviour(),
+		TimestampDeclared:  m.GetTimestampDeclared(),
+		TimestampAccepted:  m.GetTimestampAccepted(),
+		TimestampCommitted: m.GetTimestampCommitted(),
+		PrincipalAccepted:  toPrincipal(m.GetPrincipalAccepted()),
+		PrincipalDeclared:  toPrincipal(m.GetPrincipalDeclared()),
+		TenantIdentity:     m.GetTenantIdentity(),
+	}, nil
+}
+
+// fromAttributes converts the attributes, a nil map converts to a nil message
+// as the two hash differently from an empty map
+func fromAttributes(field string, attributes map[string]any) (*Attributes, error) {
+	if attributes == nil {
+		return nil, nil
+	}
+	values := make(map[string]*AttributeValue, len(attributes))
+	for name, value := range attributes {
+		v, err := fromAttributeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s.%s %v", simplehash.ErrInvalidEvent, field, name, err)
+		}
+		values[name] = v
+	}
+	return &Attributes{Values: values}, nil
+}
+
+func fromAttributeValue(value any) (*AttributeValue, error) {
+	switch v := value.(type) {
+	case string:
+		return &AttributeValue{Value: &AttributeValue_StrVal{StrVal: v}}, nil
+	case map[string]any:
+		d, err := fromDictionary(v)
+		if err != nil {
+			return nil, err
+		}
+		return &AttributeValue{Value: &AttributeValue_DictVal{DictVal: d}}, nil
+	case []any:
+		items := make([]*Dictionary, 0, len(v))
+		for _, item := range v {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("has unsupported list item type %T", item)
+			}
+			d, err := fromDictionary(m)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, d)
+		}
+		return &AttributeValue{Value: &AttributeValue_ListVal{ListVal: &DictionaryList{Items: items}}}, nil
+	default:
+		return nil, fmt.Errorf("has unsupported type %T", value)
+	}
+}
+
+func fromDictionary(m map[string]any) (*Dictionary, error) {
+	values, err := stringValues(m)
+	if err != nil {
+		return nil, err
+	}
+	return &Dictionary{Values: values}, nil
+}
+
+func fromPrincipal(field string, principal map[string]any) (*Principal, error) {
+	if principal == nil {
+		return nil, nil
+	}
+	values, err := stringValues(principal)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %v", simplehash.ErrInvalidEvent, field, err)
+	}
+	return &Principal{Values: values}, nil
+}
+
+func stringValues(m map[string]any) (map[string]string, error) {
+	values := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("has unsupported type %T for %s", v, k)
+		}
+		values[k] = s
+	}
+	return values, nil
+}
+
+func toAttributes(field string, attributes *Attributes) (map[string]any, error) {
+	if attributes == nil {
+		return nil, nil
+	}
+	values := make(map[string]any, len(attributes.GetValues()))
+	for name, value := range attributes.GetValues() {
+		switch v := value.GetValue().(type) {
+		case *AttributeValue_StrVal:
+			values[name] = v.StrVal
+		case *AttributeValue_DictVal:
+			values[name] = toDictionary(v.DictVal)
+		case *AttributeValue_ListVal:
+			items := make([]any, 0, len(v.ListVal.GetItems()))
+			for _, item := range v.ListVal.GetItems() {
+				items = append(items, toDictionary(item))
+			}
+			values[name] = items
+		default:
+			return nil, fmt.Errorf("%w: %s.%s has no value", simplehash.ErrInvalidEvent, field, name)
+		}
+	}
+	return values, nil
+}
+
+func toDictionary(d *Dictionary) map[string]any {
+	m := make(map[string]any, len(d.GetValues()))
+	for k, v := range d.GetValues() {
+		m[k] = v
+	}
+	return m
+}
+
+func toPrincipal(p *Principal) map[string]any {
+	if p == nil {
+		return nil
+	}
+	m := make(map[string]any, len(p.GetValues()))
+	for k, v := range p.GetValues() {
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,84 @@
+package eventpb
+
+import (
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func testEvent() simplehash.V3Event {
+	return simplehash.V3Event{
+		Identity: "assets/a/events/e",
+		EventAttributes: map[string]any{
+			"foo":   "bar",
+			"dict":  map[string]any{"a": "1", "b": "2"},
+			"list":  []any{map[string]any{"c": "3"}, map[string]any{}},
+			"empty": "",
+		},
+		AssetAttributes:   map[string]any{},
+		Operation:         "Record",
+		Behaviour:         "RecordEvidence",
+		TimestampDeclared: "2023-02-23T11:22:33Z",
+		TimestampAccepted: "2023-02-23T11:22:33Z",
+		PrincipalAccepted: map[string]any{
+			"issuer": "idp.example", "subject": "s", "display_name": "d", "email": "e@example.com",
+		},
+		TenantIdentity: "tenant/a",
+	}
+}
+
+// TestRoundTrip tests:
+//
+// 1. an event converted to a message, marshaled, unmarshaled and converted
+// back is unchanged, including the nil and empty maps.
+// 2. the digest of the event is preserved.
+func TestRoundTrip(t *testing.T) {
+	event := testEvent()
+
+	m, err := FromV3Event(event)
+	require.NoError(t, err)
+	b, err := proto.Marshal(m)
+	require.NoError(t, err)
+	decoded := &V3Event{}
+	require.NoError(t, proto.Unmarshal(b, decoded))
+
+	roundTrip, err := ToV3Event(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, event, roundTrip)
+	assert.Nil(t, roundTrip.PrincipalDeclared)
+	assert.NotNil(t, roundTrip.AssetAttributes)
+
+	expected, err := simplehash.HashOfV3(event)
+	require.NoError(t, err)
+	digest, err := simplehash.HashOfV3(roundTrip)
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+}
+
+// TestConvertInvalid tests:
+//
+// 1. attribute and principal values of unsupported types are rejected with
+// ErrInvalidEvent.
+// 2. an attribute value with nothing set is rejected with ErrInvalidEvent.
+func TestConvertInvalid(t *testing.T) {
+	event := testEvent()
+	event.EventAttributes["n"] = 1
+	_, err := FromV3Event(event)
+	assert.ErrorIs(t, err, simplehash.ErrInvalidEvent)
+
+	event = testEvent()
+	event.AssetAttributes["list"] = []any{"x"}
+	_, err = FromV3Event(event)
+	assert.ErrorIs(t, err, simplehash.ErrInvalidEvent)
+
+	event = testEvent()
+	event.PrincipalAccepted["subject"] = 1
+	_, err = FromV3Event(event)
+	assert.ErrorIs(t, err, simplehash.ErrInvalidEvent)
+
+	_, err = ToV3Event(&V3Event{EventAttributes: &Attributes{Values: map[string]*AttributeValue{"x": {}}}})
+	assert.ErrorIs(t, err, simplehash.ErrInvalidEvent)
+}
@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+
+	gogrpc "google.golang.org/grpc"
+)
+
+// Client is a convenience wrapper of SimpleHashClient for go callers
+type Client struct {
+	client SimpleHashClient
+}
+
+// NewClient creates a client using conn
+func NewClient(conn gogrpc.ClientConnInterface) *Client {
+	return &Client{client: NewSimpleHashClient(conn)}
+}
+
+// HashEvent returns the digest of a single api formatted event. options may
+// be nil.
+func (c *Client) HashEvent(ctx context.Context, eventJson []byte, options *HashOptions) ([]byte, error) {
+	response, err := c.client.HashEvent(ctx, &HashEventRequest{EventJson: eventJson, Options: options})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetDigest(), nil
+}
+
+// HashBatch returns the accumulated digest of a batch of api formatted events
+func (c *Client) HashBatch(ctx context.Context, events [][]byte, options *HashOptions) (*HashBatchResponse, error) {
+	return c.client.HashBatch(ctx, &HashBatchRequest{EventsJson: events, Options: options})
+}
+
+// Verify checks the digest of a single api formatted event
+func (c *Client) Verify(ctx context.Context, eventJson []byte, digest []byte, options *HashOptions) (bool, error) {
+	response, err := c.client.Verify(ctx, &VerifyRequest{EventJson: eventJson, Digest: digest, Options: options})
+	if err != nil {
+		return false, err
+	}
+	return response.GetVerified(), nil
+}
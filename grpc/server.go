@@ -0,0 +1,185 @@
+// Package grpc provides a grpc service, defined in simplehash.proto, wrapping
+// the simple hash v3 hashers, so that polyglot environments can call the
+// canonical go implementation rather than re-implementing the scheme.
+//
+// Requests are measured with a metrics.Recorder, see Server.SetRecorder.
+package grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative simplehash.proto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/metrics"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the SimpleHash service
+type Server struct {
+	UnimplementedSimpleHashServer
+	opts     []simplehash.HashOption
+	recorder metrics.Recorder
+}
+
+// NewServer creates a server. opts are applied to every request before the
+// options it carries, for example to set simplehash.WithMaxEventSize.
+func NewServer(opts ...simplehash.HashOption) *Server {
+	return &Server{opts: opts, recorder: metrics.Nop{}}
+}
+
+// SetRecorder records the latency, payload size and outcome of each rpc, and
+// each failed verification, with r
+func (s *Server) SetRecorder(r metrics.Recorder) {
+	s.recorder = r
+}
+
+// Register registers the service with a grpc server
+func (s *Server) Register(r gogrpc.ServiceRegistrar) {
+	RegisterSimpleHashServer(r, s)
+}
+
+func (s *Server) HashEvent(ctx context.Context, req *HashEventRequest) (*HashEventResponse, error) {
+	start := time.Now()
+	digest, err := s.hashEvent(req.GetEventJson(), req.GetOptions())
+	s.recorder.ObserveHash(metrics.ServiceGRPC, "HashEvent", time.Since(start), len(req.GetEventJson()), err)
+	if err != nil {
+		return nil, err
+	}
+	return &HashEventResponse{
+		Digest:       digest,
+		DigestString: formatDigest(digest, req.GetOptions()),
+		Schema:       simplehash.SchemaVersionV3,
+	}, nil
+}
+
+func (s *Server) HashBatch(ctx context.Context, req *HashBatchRequest) (response *HashBatchResponse, err error) {
+	start := time.Now()
+	defer func() {
+		size := 0
+		for _, eventJson := range req.GetEventsJson() {
+			size += len(eventJson)
+		}
+		s.recorder.ObserveHash(metrics.ServiceGRPC, "HashBatch", time.Since(start), size, err)
+	}()
+
+	opts, err := s.hashOptions(req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetContinueOnError() {
+		opts = append(opts, simplehash.WithContinueOnError())
+	}
+
+	h := simplehash.NewHasherV3()
+	result, err := h.HashEventsFromJSONContext(ctx, req.GetEventsJson(), opts...)
+
+	var canceled *simplehash.CanceledError
+	if errors.As(err, &canceled) {
+		return nil, status.FromContextError(canceled.Err).Err()
+	}
+	var batchErr *simplehash.BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	response = &HashBatchResponse{
+		Digest:       result.Digest,
+		DigestString: formatDigest(result.Digest, req.GetOptions()),
+		Schema:       simplehash.SchemaVersionV3,
+		Count:        int32(result.Count),
+	}
+	if batchErr != nil {
+		for _, e := range batchErr.Errors {
+			response.Failures = append(response.Failures, &EventFailure{
+				Index:    int32(e.Index),
+				Identity: e.Identity,
+				Error:    e.Err.Error(),
+			})
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+	if len(req.GetDigest()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "digest is required")
+	}
+	start := time.Now()
+	digest, err := s.hashEvent(req.GetEventJson(), req.GetOptions())
+	s.recorder.ObserveHash(metrics.ServiceGRPC, "Verify", time.Since(start), len(req.GetEventJson()), err)
+	if err != nil {
+		return nil, err
+	}
+	verified := bytes.Equal(digest, req.GetDigest())
+	if !verified {
+		s.recorder.VerifyFailed(metrics.ServiceGRPC, "Verify")
+	}
+	return &VerifyResponse{
+		Verified: verified,
+		Digest:   digest,
+	}, nil
+}
+
+func (s *Server) hashEvent(eventJson []byte, options *HashOptions) ([]byte, error) {
+	opts, err := s.hashOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return digest, nil
+}
+
+// hashOptions converts the request options to hashing options, after those
+// of the server
+func (s *Server) hashOptions(options *HashOptions) ([]simplehash.HashOption, error) {
+	opts := append([]simplehash.HashOption(nil), s.opts...)
+
+	if options != nil && options.IdCommitted != nil {
+		opts = append(opts, simplehash.WithIDCommitted(options.GetIdCommitted()))
+	}
+	if len(options.GetPrefix()) != 0 {
+		opts = append(opts, simplehash.WithPrefix(options.GetPrefix()))
+	}
+	if options.GetUseNumber() {
+		opts = append(opts, simplehash.WithUseNumber())
+	}
+
+	switch options.GetTimestampFormat() {
+	case TimestampFormat_TIMESTAMP_FORMAT_AS_IS:
+	case TimestampFormat_TIMESTAMP_FORMAT_API:
+		opts = append(opts, simplehash.WithTimestampFormat(simplehash.TimestampFormatAPI))
+	default:
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("unknown timestamp format %v", options.GetTimestampFormat()))
+	}
+
+	switch options.GetEncoding() {
+	case Encoding_ENCODING_BENCODE:
+	case Encoding_ENCODING_CBOR:
+		opts = append(opts, simplehash.WithEncoding(simplehash.EncodingCBOR))
+	case Encoding_ENCODING_JCS:
+		opts = append(opts, simplehash.WithEncoding(simplehash.EncodingJCS))
+	default:
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("unknown encoding %v", options.GetEncoding()))
+	}
+
+	return opts, nil
+}
+
+// formatDigest returns the self describing digest. The format only describes
+// bencoded events, so it is empty for the other encodings.
+func formatDigest(digest []byte, options *HashOptions) string {
+	if options.GetEncoding() != Encoding_ENCODING_BENCODE {
+		return ""
+	}
+	return simplehash.FormatDigest(simplehash.SchemaVersionV3, simplehash.DigestAlgorithmSHA256, digest)
+}
@@ -0,0 +1,172 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T) *Client {
+	listener := bufconn.Listen(1 << 20)
+	s := gogrpc.NewServer()
+	NewServer().Register(s)
+	go func() { _ = s.Serve(listener) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := gogrpc.DialContext(context.Background(), "bufnet",
+		gogrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		gogrpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn)
+}
+
+// TestServer tests:
+//
+// 1. HashEvent and HashBatch return the same digests as the hashers,
+// including the request options.
+// 2. Verify reports matching and mismatched digests.
+// 3. invalid events fail with InvalidArgument, or are reported as failures
+// when the batch continues on error.
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	events := [][]byte{
+		[]byte(`{"identity": "assets/1/events/1", "event_attributes": {"n": 1}}`),
+		[]byte(`{"identity": "assets/1/events/2"}`),
+	}
+	options := &HashOptions{IdCommitted: new(uint64), UseNumber: true}
+	*options.IdCommitted = 7
+
+	h := simplehash.NewHasherV3()
+	require.NoError(t, h.HashEventFromJSON(events[0], simplehash.WithIDCommitted(7), simplehash.WithUseNumber()))
+	expected := h.Sum(nil)
+
+	digest, err := client.HashEvent(ctx, events[0], options)
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	verified, err := client.Verify(ctx, events[0], expected, options)
+	require.NoError(t, err)
+	assert.True(t, verified)
+	verified, err = client.Verify(ctx, events[1], expected, options)
+	require.NoError(t, err)
+	assert.False(t, verified)
+
+	_, err = client.HashEvent(ctx, events[0], nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	batch, err := h.HashEventsFromJSON(events, simplehash.WithUseNumber())
+	require.NoError(t, err)
+	response, err := client.HashBatch(ctx, events, &HashOptions{UseNumber: true})
+	require.NoError(t, err)
+	assert.Equal(t, batch.Digest, response.GetDigest())
+	assert.Equal(t, int32(2), response.GetCount())
+
+	_, err = client.HashBatch(ctx, events, nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	response, err = client.client.HashBatch(ctx, &HashBatchRequest{EventsJson: events, ContinueOnError: true})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), response.GetCount())
+	require.Len(t, response.GetFailures(), 1)
+	assert.Equal(t, "assets/1/events/1", response.GetFailures()[0].GetIdentity())
+}
+
+// TestServer_DigestString tests:
+//
+// 1. the bencode encoding returns the self describing digest string.
+// 2. the cbor and jcs encodings, which the digest string can not describe,
+// return no digest string.
+func TestServer_DigestString(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	event := []byte(`{"identity": "assets/1/events/1"}`)
+	tests := []struct {
+		encoding Encoding
+		option   simplehash.Encoding
+		labelled bool
+	}{
+		{Encoding_ENCODING_BENCODE, simplehash.EncodingBencode, true},
+		{Encoding_ENCODING_CBOR, simplehash.EncodingCBOR, false},
+		{Encoding_ENCODING_JCS, simplehash.EncodingJCS, false},
+	}
+	for _, test := range tests {
+		t.Run(test.encoding.String(), func(t *testing.T) {
+			options := &HashOptions{Encoding: test.encoding}
+
+			sum, err := simplehash.DigestEventFromJSON(event, simplehash.WithEncoding(test.option))
+			require.NoError(t, err)
+			expected := ""
+			if test.labelled {
+				expected = simplehash.FormatDigest(simplehash.SchemaVersionV3, simplehash.DigestAlgorithmSHA256, sum)
+			}
+
+			response, err := client.client.HashEvent(ctx, &HashEventRequest{EventJson: event, Options: options})
+			require.NoError(t, err)
+			assert.Equal(t, sum, response.GetDigest())
+			assert.Equal(t, expected, response.GetDigestString())
+
+			batch, err := client.client.HashBatch(ctx, &HashBatchRequest{EventsJson: [][]byte{event}, Options: options})
+			require.NoError(t, err)
+			assert.Equal(t, test.labelled, batch.GetDigestString() != "")
+		})
+	}
+}
+
+type testRecorder struct {
+	observed []string
+	failed   []string
+}
+
+func (r *testRecorder) ObserveHash(service string, method string, _ time.Duration, payloadBytes int, err error) {
+	r.observed = append(r.observed, fmt.Sprintf("%s %s %d %v", service, method, payloadBytes, err != nil))
+}
+
+func (r *testRecorder) VerifyFailed(service string, method string) {
+	r.failed = append(r.failed, service+" "+method)
+}
+
+// TestServerRecorder tests that each rpc, and failed verifications, are
+// recorded.
+func TestServerRecorder(t *testing.T) {
+	ctx := context.Background()
+	recorder := &testRecorder{}
+	s := NewServer()
+	s.SetRecorder(recorder)
+
+	event := []byte(`{"identity": "assets/1/events/1"}`)
+	_, err := s.HashEvent(ctx, &HashEventRequest{EventJson: event})
+	require.NoError(t, err)
+	_, err = s.HashBatch(ctx, &HashBatchRequest{EventsJson: [][]byte{event, event}})
+	require.NoError(t, err)
+	_, err = s.Verify(ctx, &VerifyRequest{EventJson: []byte("{"), Digest: []byte{0}})
+	assert.Error(t, err)
+	_, err = s.Verify(ctx, &VerifyRequest{EventJson: event, Digest: []byte{0}})
+	require.NoError(t, err)
+
+	size := len(event)
+	assert.Equal(t, []string{
+		fmt.Sprintf("grpc HashEvent %d false", size),
+		fmt.Sprintf("grpc HashBatch %d false", 2*size),
+		"grpc Verify 1 true",
+		fmt.Sprintf("grpc Verify %d false", size),
+	}, recorder.observed)
+	assert.Equal(t, []string{"grpc Verify"}, recorder.failed)
+}
@@ -0,0 +1,931 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: simplehash.proto
+
+package grpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Encoding selects the format of the pre-image which is hashed
+type Encoding int32
+
+const (
+	Encoding_ENCODING_BENCODE Encoding = 0
+	Encoding_ENCODING_CBOR    Encoding = 1
+	Encoding_ENCODING_JCS     Encoding = 2
+)
+
+// Enum value maps for Encoding.
+var (
+	Encoding_name = map[int32]string{
+		0: "ENCODING_BENCODE",
+		1: "ENCODING_CBOR",
+		2: "ENCODING_JCS",
+	}
+	Encoding_value = map[string]int32{
+		"ENCODING_BENCODE": 0,
+		"ENCODING_CBOR":    1,
+		"ENCODING_JCS":     2,
+	}
+)
+
+func (x Encoding) Enum() *Encoding {
+	p := new(Encoding)
+	*p = x
+	return p
+}
+
+func (x Encoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Encoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_simplehash_proto_enumTypes[0].Descriptor()
+}
+
+func (Encoding) Type() protoreflect.EnumType {
+	return &file_simplehash_proto_enumTypes[0]
+}
+
+func (x Encoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Encoding.Descriptor instead.
+func (Encoding) EnumDescriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{0}
+}
+
+// TimestampFormat selects how timestamps are formatted before hashing
+type TimestampFormat int32
+
+const (
+	TimestampFormat_TIMESTAMP_FORMAT_AS_IS TimestampFormat = 0
+	TimestampFormat_TIMESTAMP_FORMAT_API   TimestampFormat = 1
+)
+
+// Enum value maps for TimestampFormat.
+var (
+	TimestampFormat_name = map[int32]string{
+		0: "TIMESTAMP_FORMAT_AS_IS",
+		1: "TIMESTAMP_FORMAT_API",
+	}
+	TimestampFormat_value = map[string]int32{
+		"TIMESTAMP_FORMAT_AS_IS": 0,
+		"TIMESTAMP_FORMAT_API":   1,
+	}
+)
+
+func (x TimestampFormat) Enum() *TimestampFormat {
+	p := new(TimestampFormat)
+	*p = x
+	return p
+}
+
+func (x TimestampFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TimestampFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_simplehash_proto_enumTypes[1].Descriptor()
+}
+
+func (TimestampFormat) Type() protoreflect.EnumType {
+	return &file_simplehash_proto_enumTypes[1]
+}
+
+func (x TimestampFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TimestampFormat.Descriptor instead.
+func (TimestampFormat) EnumDescriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{1}
+}
+
+// HashOptions mirror the go hashing options of the same names
+type HashOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdCommitted     *uint64         `protobuf:"varint,1,opt,name=id_committed,json=idCommitted,proto3,oneof" json:"id_committed,omitempty"`
+	Prefix          []byte          `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	UseNumber       bool            `protobuf:"varint,3,opt,name=use_number,json=useNumber,proto3" json:"use_number,omitempty"`
+	TimestampFormat TimestampFormat `protobuf:"varint,4,opt,name=timestamp_format,json=timestampFormat,proto3,enum=datatrails.simplehash.v1.TimestampFormat" json:"timestamp_format,omitempty"`
+	Encoding        Encoding        `protobuf:"varint,5,opt,name=encoding,proto3,enum=datatrails.simplehash.v1.Encoding" json:"encoding,omitempty"`
+}
+
+func (x *HashOptions) Reset() {
+	*x = HashOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_simplehash_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HashOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashOptions) ProtoMessage() {}
+
+func (x *HashOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_simplehash_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashOptions.ProtoReflect.Descriptor instead.
+func (*HashOptions) Descriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *HashOptions) GetIdCommitted() uint64 {
+	if x != nil && x.IdCommitted != nil {
+		return *x.IdCommitted
+	}
+	return 0
+}
+
+func (x *HashOptions) GetPrefix() []byte {
+	if x != nil {
+		return x.Prefix
+	}
+	return nil
+}
+
+func (x *HashOptions) GetUseNumber() bool {
+	if x != nil {
+		return x.UseNumber
+	}
+	return false
+}
+
+func (x *HashOptions) GetTimestampFormat() TimestampFormat {
+	if x != nil {
+		return x.TimestampFormat
+	}
+	return TimestampFormat_TIMESTAMP_FORMAT_AS_IS
+}
+
+func (x *HashOptions) GetEncoding() Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Encoding_ENCODING_BENCODE
+}
+
+type HashEventRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// event_json is a single event in the api json format
+	EventJson []byte       `protobuf:"bytes,1,opt,name=event_json,json=eventJson,proto3" json:"event_json,omitempty"`
+	Options   *HashOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+}
+
+func (x *HashEventRequest) Reset() {
+	*x = HashEventRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_simplehash_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HashEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashEventRequest) ProtoMessage() {}
+
+func (x *HashEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_simplehash_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashEventRequest.ProtoReflect.Descriptor instead.
+func (*HashEventRequest) Descriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HashEventRequest) GetEventJson() []byte {
+	if x != nil {
+		return x.EventJson
+	}
+	return nil
+}
+
+func (x *HashEventRequest) GetOptions() *HashOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type HashEventResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digest []byte `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	// digest_string is the self describing digest, eg simplehash:v3:sha256:<hex>.
+	// The format only describes bencoded events, so it is empty for the cbor
+	// and jcs encodings.
+	DigestString string `protobuf:"bytes,2,opt,name=digest_string,json=digestString,proto3" json:"digest_string,omitempty"`
+	Schema       int32  `protobuf:"varint,3,opt,name=schema,proto3" json:"schema,omitempty"`
+}
+
+func (x *HashEventResponse) Reset() {
+	*x = HashEventResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_simplehash_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HashEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashEventResponse) ProtoMessage() {}
+
+func (x *HashEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_simplehash_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashEventResponse.ProtoReflect.Descriptor instead.
+func (*HashEventResponse) Descriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HashEventResponse) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *HashEventResponse) GetDigestString() string {
+	if x != nil {
+		return x.DigestString
+	}
+	return ""
+}
+
+func (x *HashEventResponse) GetSchema() int32 {
+	if x != nil {
+		return x.Schema
+	}
+	return 0
+}
+
+type HashBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EventsJson [][]byte     `protobuf:"bytes,1,rep,name=events_json,json=eventsJson,proto3" json:"events_json,omitempty"`
+	Options    *HashOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	// continue_on_error skips events which fail, reporting them in failures,
+	// rather than failing the batch
+	ContinueOnError bool `protobuf:"varint,3,opt,name=continue_on_error,json=continueOnError,proto3" json:"continue_on_error,omitempty"`
+}
+
+func (x *HashBatchRequest) Reset() {
+	*x = HashBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_simplehash_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HashBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashBatchRequest) ProtoMessage() {}
+
+func (x *HashBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_simplehash_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashBatchRequest.ProtoReflect.Descriptor instead.
+func (*HashBatchRequest) Descriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HashBatchRequest) GetEventsJson() [][]byte {
+	if x != nil {
+		return x.EventsJson
+	}
+	return nil
+}
+
+func (x *HashBatchRequest) GetOptions() *HashOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *HashBatchRequest) GetContinueOnError() bool {
+	if x != nil {
+		return x.ContinueOnError
+	}
+	return false
+}
+
+type EventFailure struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index    int32  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Identity string `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	Error    string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *EventFailure) Reset() {
+	*x = EventFailure{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_simplehash_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EventFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventFailure) ProtoMessage() {}
+
+func (x *EventFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_simplehash_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventFailure.ProtoReflect.Descriptor instead.
+func (*EventFailure) Descriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EventFailure) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *EventFailure) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *EventFailure) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type HashBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digest []byte `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	// digest_string is as for HashEventResponse
+	DigestString string          `protobuf:"bytes,2,opt,name=digest_string,json=digestString,proto3" json:"digest_string,omitempty"`
+	Schema       int32           `protobuf:"varint,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	Count        int32           `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	Failures     []*EventFailure `protobuf:"bytes,5,rep,name=failures,proto3" json:"failures,omitempty"`
+}
+
+func (x *HashBatchResponse) Reset() {
+	*x = HashBatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_simplehash_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HashBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashBatchResponse) ProtoMessage() {}
+
+func (x *HashBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_simplehash_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashBatchResponse.ProtoReflect.Descriptor instead.
+func (*HashBatchResponse) Descriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HashBatchResponse) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *HashBatchResponse) GetDigestString() string {
+	if x != nil {
+		return x.DigestString
+	}
+	return ""
+}
+
+func (x *HashBatchResponse) GetSchema() int32 {
+	if x != nil {
+		return x.Schema
+	}
+	return 0
+}
+
+func (x *HashBatchResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *HashBatchResponse) GetFailures() []*EventFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+type VerifyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EventJson []byte       `protobuf:"bytes,1,opt,name=event_json,json=eventJson,proto3" json:"event_json,omitempty"`
+	Digest    []byte       `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	Options   *HashOptions `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_simplehash_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_simplehash_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerifyRequest) GetEventJson() []byte {
+	if x != nil {
+		return x.EventJson
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetOptions() *HashOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type VerifyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Verified bool `protobuf:"varint,1,opt,name=verified,proto3" json:"verified,omitempty"`
+	// digest is the digest computed for the event
+	Digest []byte `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_simplehash_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_simplehash_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_simplehash_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VerifyResponse) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+var File_simplehash_proto protoreflect.FileDescriptor
+
+var file_simplehash_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x18, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x73,
+	0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x22, 0x93, 0x02, 0x0a,
+	0x0b, 0x48, 0x61, 0x73, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26, 0x0a, 0x0c,
+	0x69, 0x64, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x48, 0x00, 0x52, 0x0b, 0x69, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65,
+	0x64, 0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x1d, 0x0a, 0x0a,
+	0x75, 0x73, 0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x09, 0x75, 0x73, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x54, 0x0a, 0x10, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69,
+	0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x52, 0x0f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x46, 0x6f, 0x72, 0x6d, 0x61,
+	0x74, 0x12, 0x3e, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x22, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73,
+	0x2e, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x45,
+	0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e,
+	0x67, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x69, 0x64, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74,
+	0x65, 0x64, 0x22, 0x72, 0x0a, 0x10, 0x48, 0x61, 0x73, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f,
+	0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x12, 0x3f, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61,
+	0x69, 0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76,
+	0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x68, 0x0a, 0x11, 0x48, 0x61, 0x73, 0x68, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x73, 0x74,
+	0x72, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65,
+	0x6d, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x22, 0xa0, 0x01, 0x0a, 0x10, 0x48, 0x61, 0x73, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x5f,
+	0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0a, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x4a, 0x73, 0x6f, 0x6e, 0x12, 0x3f, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72,
+	0x61, 0x69, 0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e,
+	0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x63, 0x6f, 0x6e, 0x74, 0x69,
+	0x6e, 0x75, 0x65, 0x5f, 0x6f, 0x6e, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x65, 0x4f, 0x6e, 0x45, 0x72,
+	0x72, 0x6f, 0x72, 0x22, 0x56, 0x0a, 0x0c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x46, 0x61, 0x69, 0x6c,
+	0x75, 0x72, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xc2, 0x01, 0x0a, 0x11,
+	0x48, 0x61, 0x73, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x42, 0x0a, 0x08,
+	0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70,
+	0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x46,
+	0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x52, 0x08, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73,
+	0x22, 0x87, 0x01, 0x0a, 0x0d, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x6a, 0x73, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x4a, 0x73, 0x6f,
+	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x3f, 0x0a, 0x07, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61,
+	0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x44, 0x0a, 0x0e, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08,
+	0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x2a, 0x45, 0x0a, 0x08, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x14, 0x0a, 0x10,
+	0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x42, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x45,
+	0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x43,
+	0x42, 0x4f, 0x52, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e,
+	0x47, 0x5f, 0x4a, 0x43, 0x53, 0x10, 0x02, 0x2a, 0x47, 0x0a, 0x0f, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1a, 0x0a, 0x16, 0x54, 0x49,
+	0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x41,
+	0x53, 0x5f, 0x49, 0x53, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54,
+	0x41, 0x4d, 0x50, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x41, 0x50, 0x49, 0x10, 0x01,
+	0x32, 0xb5, 0x02, 0x0a, 0x0a, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x48, 0x61, 0x73, 0x68, 0x12,
+	0x64, 0x0a, 0x09, 0x48, 0x61, 0x73, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2a, 0x2e, 0x64,
+	0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65,
+	0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74,
+	0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68,
+	0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x64, 0x0a, 0x09, 0x48, 0x61, 0x73, 0x68, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x12, 0x2a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e,
+	0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61,
+	0x73, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70,
+	0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5b, 0x0a, 0x06, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x27, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69,
+	0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31,
+	0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x73, 0x69, 0x6d, 0x70,
+	0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c,
+	0x73, 0x2f, 0x67, 0x6f, 0x2d, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2d,
+	0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x68, 0x61, 0x73, 0x68, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_simplehash_proto_rawDescOnce sync.Once
+	file_simplehash_proto_rawDescData = file_simplehash_proto_rawDesc
+)
+
+func file_simplehash_proto_rawDescGZIP() []byte {
+	file_simplehash_proto_rawDescOnce.Do(func() {
+		file_simplehash_proto_rawDescData = protoimpl.X.CompressGZIP(file_simplehash_proto_rawDescData)
+	})
+	return file_simplehash_proto_rawDescData
+}
+
+var file_simplehash_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_simplehash_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_simplehash_proto_goTypes = []interface{}{
+	(Encoding)(0),             // 0: datatrails.simplehash.v1.Encoding
+	(TimestampFormat)(0),      // 1: datatrails.simplehash.v1.TimestampFormat
+	(*HashOptions)(nil),       // 2: datatrails.simplehash.v1.HashOptions
+	(*HashEventRequest)(nil),  // 3: datatrails.simplehash.v1.HashEventRequest
+	(*HashEventResponse)(nil), // 4: datatrails.simplehash.v1.HashEventResponse
+	(*HashBatchRequest)(nil),  // 5: datatrails.simplehash.v1.HashBatchRequest
+	(*EventFailure)(nil),      // 6: datatrails.simplehash.v1.EventFailure
+	(*HashBatchResponse)(nil), // 7: datatrails.simplehash.v1.HashBatchResponse
+	(*VerifyRequest)(nil),     // 8: datatrails.simplehash.v1.VerifyRequest
+	(*VerifyResponse)(nil),    // 9: datatrails.simplehash.v1.VerifyResponse
+}
+var file_simplehash_proto_depIdxs = []int32{
+	1, // 0: datatrails.simplehash.v1.HashOptions.timestamp_format:type_name -> datatrails.simplehash.v1.TimestampFormat
+	0, // 1: datatrails.simplehash.v1.HashOptions.encoding:type_name -> datatrails.simplehash.v1.Encoding
+	2, // 2: datatrails.simplehash.v1.HashEventRequest.options:type_name -> datatrails.simplehash.v1.HashOptions
+	2, // 3: datatrails.simplehash.v1.HashBatchRequest.options:type_name -> datatrails.simplehash.v1.HashOptions
+	6, // 4: datatrails.simplehash.v1.HashBatchResponse.failures:type_name -> datatrails.simplehash.v1.EventFailure
+	2, // 5: datatrails.simplehash.v1.VerifyRequest.options:type_name -> datatrails.simplehash.v1.HashOptions
+	3, // 6: datatrails.simplehash.v1.SimpleHash.HashEvent:input_type -> datatrails.simplehash.v1.HashEventRequest
+	5, // 7: datatrails.simplehash.v1.SimpleHash.HashBatch:input_type -> datatrails.simplehash.v1.HashBatchRequest
+	8, // 8: datatrails.simplehash.v1.SimpleHash.Verify:input_type -> datatrails.simplehash.v1.VerifyRequest
+	4, // 9: datatrails.simplehash.v1.SimpleHash.HashEvent:output_type -> datatrails.simplehash.v1.HashEventResponse
+	7, // 10: datatrails.simplehash.v1.SimpleHash.HashBatch:output_type -> datatrails.simplehash.v1.HashBatchResponse
+	9, // 11: datatrails.simplehash.v1.SimpleHash.Verify:output_type -> datatrails.simplehash.v1.VerifyResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_simplehash_proto_init() }
+func file_simplehash_proto_init() {
+	if File_simplehash_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_simplehash_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_simplehash_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashEventRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_simplehash_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashEventResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_simplehash_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashBatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_simplehash_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EventFailure); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_simplehash_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashBatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_simplehash_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_simplehash_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_simplehash_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_simplehash_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_simplehash_proto_goTypes,
+		DependencyIndexes: file_simplehash_proto_depIdxs,
+		EnumInfos:         file_simplehash_proto_enumTypes,
+		MessageInfos:      file_simplehash_proto_msgTypes,
+	}.Build()
+	File_simplehash_proto = out.File
+	file_simplehash_proto_rawDesc = nil
+	file_simplehash_proto_goTypes = nil
+	file_simplehash_proto_depIdxs = nil
+}
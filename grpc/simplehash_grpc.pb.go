@@ -0,0 +1,189 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: simplehash.proto
+
+package grpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SimpleHash_HashEvent_FullMethodName = "/datatrails.simplehash.v1.SimpleHash/HashEvent"
+	SimpleHash_HashBatch_FullMethodName = "/datatrails.simplehash.v1.SimpleHash/HashBatch"
+	SimpleHash_Verify_FullMethodName    = "/datatrails.simplehash.v1.SimpleHash/Verify"
+)
+
+// SimpleHashClient is the client API for SimpleHash service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SimpleHashClient interface {
+	// HashEvent returns the digest of a single event
+	HashEvent(ctx context.Context, in *HashEventRequest, opts ...grpc.CallOption) (*HashEventResponse, error)
+	// HashBatch returns the accumulated digest of a batch of events
+	HashBatch(ctx context.Context, in *HashBatchRequest, opts ...grpc.CallOption) (*HashBatchResponse, error)
+	// Verify checks the digest of a single event
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+}
+
+type simpleHashClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimpleHashClient(cc grpc.ClientConnInterface) SimpleHashClient {
+	return &simpleHashClient{cc}
+}
+
+func (c *simpleHashClient) HashEvent(ctx context.Context, in *HashEventRequest, opts ...grpc.CallOption) (*HashEventResponse, error) {
+	out := new(HashEventResponse)
+	err := c.cc.Invoke(ctx, SimpleHash_HashEvent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleHashClient) HashBatch(ctx context.Context, in *HashBatchRequest, opts ...grpc.CallOption) (*HashBatchResponse, error) {
+	out := new(HashBatchResponse)
+	err := c.cc.Invoke(ctx, SimpleHash_HashBatch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleHashClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, SimpleHash_Verify_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SimpleHashServer is the server API for SimpleHash service.
+// All implementations must embed UnimplementedSimpleHashServer
+// for forward compatibility
+type SimpleHashServer interface {
+	// HashEvent returns the digest of a single event
+	HashEvent(context.Context, *HashEventRequest) (*HashEventResponse, error)
+	// HashBatch returns the accumulated digest of a batch of events
+	HashBatch(context.Context, *HashBatchRequest) (*HashBatchResponse, error)
+	// Verify checks the digest of a single event
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	mustEmbedUnimplementedSimpleHashServer()
+}
+
+// UnimplementedSimpleHashServer must be embedded to have forward compatible implementations.
+type UnimplementedSimpleHashServer struct {
+}
+
+func (UnimplementedSimpleHashServer) HashEvent(context.Context, *HashEventRequest) (*HashEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HashEvent not implemented")
+}
+func (UnimplementedSimpleHashServer) HashBatch(context.Context, *HashBatchRequest) (*HashBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HashBatch not implemented")
+}
+func (UnimplementedSimpleHashServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedSimpleHashServer) mustEmbedUnimplementedSimpleHashServer() {}
+
+// UnsafeSimpleHashServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimpleHashServer will
+// result in compilation errors.
+type UnsafeSimpleHashServer interface {
+	mustEmbedUnimplementedSimpleHashServer()
+}
+
+func RegisterSimpleHashServer(s grpc.ServiceRegistrar, srv SimpleHashServer) {
+	s.RegisterService(&SimpleHash_ServiceDesc, srv)
+}
+
+func _SimpleHash_HashEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HashEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleHashServer).HashEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleHash_HashEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleHashServer).HashEvent(ctx, req.(*HashEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleHash_HashBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HashBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleHashServer).HashBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleHash_HashBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleHashServer).HashBatch(ctx, req.(*HashBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleHash_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleHashServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleHash_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleHashServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SimpleHash_ServiceDesc is the grpc.ServiceDesc for SimpleHash service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SimpleHash_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "datatrails.simplehash.v1.SimpleHash",
+	HandlerType: (*SimpleHashServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "HashEvent",
+			Handler:    _SimpleHash_HashEvent_Handler,
+		},
+		{
+			MethodName: "HashBatch",
+			Handler:    _SimpleHash_HashBatch_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _SimpleHash_Verify_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "simplehash.proto",
+}
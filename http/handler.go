@@ -0,0 +1,304 @@
+// Package http exposes simple hash event hashing and verification as a small
+// http service, for teams which want an internal verification service
+// without writing their own server.
+//
+// Endpoints:
+//
+//	POST /v3/hash    body: an api formatted event
+//	POST /v3/verify  body: {"event": <api formatted event>, "digest": "<digest>"}
+//
+// Hashing options are given as query parameters:
+//
+//   - id_committed: the decimal idtimestamp, see simplehash.WithIDCommitted
+//   - prefix: hex encoded domain separation prefix, see simplehash.WithPrefix
+//   - use_number: true to hash integer attributes exactly, see
+//     simplehash.WithUseNumber
+//   - timestamp_format: "api" to format timestamps as the api does, see
+//     simplehash.WithTimestampFormat
+//   - encoding: "bencode" (default), "cbor" or "jcs", see
+//     simplehash.WithEncoding
+//
+// Requests are measured with a metrics.Recorder, see SetRecorder.
+package http
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/metrics"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+// DefaultMaxBodySize is the default limit on the size of a request body
+const DefaultMaxBodySize = 1 << 20
+
+var (
+	ErrInvalidParameter = errors.New("invalid query parameter")
+	ErrInvalidRequest   = errors.New("invalid request")
+)
+
+// Options echoes the hashing options a digest was produced with
+type Options struct {
+	IDCommitted     *uint64 `json:"id_committed,omitempty"`
+	Prefix          string  `json:"prefix,omitempty"`
+	UseNumber       bool    `json:"use_number,omitempty"`
+	TimestampFormat string  `json:"timestamp_format,omitempty"`
+	Encoding        string  `json:"encoding"`
+}
+
+// HashResponse is the response to /v3/hash
+type HashResponse struct {
+	// Digest is the hex encoded digest
+	Digest string `json:"digest"`
+	// DigestString is the self describing digest, see simplehash.FormatDigest.
+	// The format only describes bencoded events, so it is empty for the cbor
+	// and jcs encodings.
+	DigestString string  `json:"digest_string,omitempty"`
+	Schema       int     `json:"schema"`
+	Options      Options `json:"options"`
+}
+
+// VerifyRequest is the body of a /v3/verify request. Digest is either hex
+// encoded, or a self describing digest string.
+type VerifyRequest struct {
+	Event  json.RawMessage `json:"event"`
+	Digest string          `json:"digest"`
+}
+
+// VerifyResponse is the response to /v3/verify
+type VerifyResponse struct {
+	Verified bool `json:"verified"`
+	HashResponse
+}
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the hashing endpoints
+type Handler struct {
+	mux         *nethttp.ServeMux
+	opts        []simplehash.HashOption
+	maxBodySize int64
+	recorder    metrics.Recorder
+}
+
+// NewHandler creates a handler. opts are applied to every request before the
+// options from its query parameters, for example to set
+// simplehash.WithMaxDepth, or simplehash.WithCache for a service which
+// verifies the same events repeatedly.
+func NewHandler(opts ...simplehash.HashOption) *Handler {
+	h := &Handler{
+		mux:         nethttp.NewServeMux(),
+		opts:        opts,
+		maxBodySize: DefaultMaxBodySize,
+		recorder:    metrics.Nop{},
+	}
+	h.mux.HandleFunc("/v3/hash", h.post(h.hash))
+	h.mux.HandleFunc("/v3/verify", h.post(h.verify))
+	return h
+}
+
+// SetMaxBodySize limits the size of request bodies
+func (h *Handler) SetMaxBodySize(n int64) {
+	h.maxBodySize = n
+}
+
+// SetRecorder records the latency, payload size and outcome of each hash and
+// verify request, and each failed verification, with r
+func (h *Handler) SetRecorder(r metrics.Recorder) {
+	h.recorder = r
+}
+
+func (h *Handler) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// post restricts an endpoint to POST and limits the size of its body
+func (h *Handler) post(f nethttp.HandlerFunc) nethttp.HandlerFunc {
+	return func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Method != nethttp.MethodPost {
+			w.Header().Set("Allow", nethttp.MethodPost)
+			writeError(w, nethttp.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		r.Body = nethttp.MaxBytesReader(w, r.Body, h.maxBodySize)
+		f(w, r)
+	}
+}
+
+func (h *Handler) hash(w nethttp.ResponseWriter, r *nethttp.Request) {
+	opts, echo, err := h.options(r)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+	var body bytes.Buffer
+	if _, err = body.ReadFrom(r.Body); err != nil {
+		writeError(w, bodyStatus(err), err)
+		return
+	}
+
+	start := time.Now()
+	response, err := hashEvent(body.Bytes(), opts, echo)
+	h.recorder.ObserveHash(metrics.ServiceHTTP, "hash", time.Since(start), body.Len(), err)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, response)
+}
+
+func (h *Handler) verify(w nethttp.ResponseWriter, r *nethttp.Request) {
+	opts, echo, err := h.options(r)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+	request := VerifyRequest{}
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, bodyStatus(err), fmt.Errorf("%w: %v", ErrInvalidRequest, err))
+		return
+	}
+	if len(request.Event) == 0 {
+		writeError(w, nethttp.StatusBadRequest, fmt.Errorf("%w: event is required", ErrInvalidRequest))
+		return
+	}
+	expected, err := parseDigest(request.Digest)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	start := time.Now()
+	response, err := hashEvent(request.Event, opts, echo)
+	h.recorder.ObserveHash(metrics.ServiceHTTP, "verify", time.Since(start), len(request.Event), err)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+	verified := response.Digest == hex.EncodeToString(expected)
+	if !verified {
+		h.recorder.VerifyFailed(metrics.ServiceHTTP, "verify")
+	}
+	writeJSON(w, nethttp.StatusOK, VerifyResponse{
+		Verified:     verified,
+		HashResponse: response,
+	})
+}
+
+func hashEvent(eventJson []byte, opts []simplehash.HashOption, echo Options) (HashResponse, error) {
+	sum, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+	if err != nil {
+		return HashResponse{}, err
+	}
+	response := HashResponse{
+		Digest:  hex.EncodeToString(sum),
+		Schema:  simplehash.SchemaVersionV3,
+		Options: echo,
+	}
+	if echo.Encoding == "bencode" {
+		response.DigestString = simplehash.FormatDigest(simplehash.SchemaVersionV3, simplehash.DigestAlgorithmSHA256, sum)
+	}
+	return response, nil
+}
+
+// parseDigest accepts a hex digest or a v3 sha256 digest string
+func parseDigest(s string) ([]byte, error) {
+	if sum, err := hex.DecodeString(s); err == nil && len(sum) != 0 {
+		return sum, nil
+	}
+	d, err := simplehash.ParseDigest(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if d.Schema != simplehash.SchemaVersionV3 || d.Algorithm != simplehash.DigestAlgorithmSHA256 {
+		return nil, fmt.Errorf("%w: digest %s is not a v3 sha256 digest", ErrInvalidRequest, s)
+	}
+	return d.Sum, nil
+}
+
+// options converts the query parameters to hashing options, and the options
+// to echo in the response
+func (h *Handler) options(r *nethttp.Request) ([]simplehash.HashOption, Options, error) {
+	query := r.URL.Query()
+	opts := append([]simplehash.HashOption(nil), h.opts...)
+	echo := Options{Encoding: "bencode"}
+
+	if v := query.Get("id_committed"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, Options{}, fmt.Errorf("%w: id_committed %q", ErrInvalidParameter, v)
+		}
+		opts = append(opts, simplehash.WithIDCommitted(id))
+		echo.IDCommitted = &id
+	}
+
+	if v := query.Get("prefix"); v != "" {
+		prefix, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, Options{}, fmt.Errorf("%w: prefix %q", ErrInvalidParameter, v)
+		}
+		opts = append(opts, simplehash.WithPrefix(prefix))
+		echo.Prefix = v
+	}
+
+	if v := query.Get("use_number"); v != "" {
+		useNumber, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, Options{}, fmt.Errorf("%w: use_number %q", ErrInvalidParameter, v)
+		}
+		if useNumber {
+			opts = append(opts, simplehash.WithUseNumber())
+		}
+		echo.UseNumber = useNumber
+	}
+
+	switch v := query.Get("timestamp_format"); v {
+	case "":
+	case "api":
+		opts = append(opts, simplehash.WithTimestampFormat(simplehash.TimestampFormatAPI))
+		echo.TimestampFormat = v
+	default:
+		return nil, Options{}, fmt.Errorf("%w: timestamp_format %q", ErrInvalidParameter, v)
+	}
+
+	switch v := query.Get("encoding"); v {
+	case "", "bencode":
+	case "cbor":
+		opts = append(opts, simplehash.WithEncoding(simplehash.EncodingCBOR))
+		echo.Encoding = v
+	case "jcs":
+		opts = append(opts, simplehash.WithEncoding(simplehash.EncodingJCS))
+		echo.Encoding = v
+	default:
+		return nil, Options{}, fmt.Errorf("%w: encoding %q", ErrInvalidParameter, v)
+	}
+
+	return opts, echo, nil
+}
+
+func bodyStatus(err error) int {
+	var tooLarge *nethttp.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nethttp.StatusRequestEntityTooLarge
+	}
+	return nethttp.StatusBadRequest
+}
+
+func writeError(w nethttp.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+func writeJSON(w nethttp.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEvent = `{"identity": "assets/1/events/2", "event_attributes": {"foo": "bar"}}`
+
+func serve(t *testing.T, h *Handler, method string, target string, body string) (int, map[string]any) {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	response := map[string]any{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+// TestHandler tests:
+//
+// 1. /v3/hash returns the same digest as the hasher, with the options echoed.
+// 2. /v3/verify accepts hex and self describing digests, and reports a
+// mismatch.
+// 3. bad parameters, bodies and methods are rejected.
+func TestHandler(t *testing.T) {
+	hasher := simplehash.NewHasherV3()
+	require.NoError(t, hasher.HashEventFromJSON([]byte(testEvent), simplehash.WithIDCommitted(7)))
+	expected := hex.EncodeToString(hasher.Sum(nil))
+
+	h := NewHandler()
+
+	status, response := serve(t, h, nethttp.MethodPost, "/v3/hash?id_committed=7", testEvent)
+	assert.Equal(t, nethttp.StatusOK, status)
+	assert.Equal(t, expected, response["digest"])
+	assert.Equal(t, "simplehash:v3:sha256:"+expected, response["digest_string"])
+	assert.Equal(t, map[string]any{"id_committed": 7.0, "encoding": "bencode"}, response["options"])
+
+	verify := func(digest string) string {
+		return `{"event": ` + testEvent + `, "digest": "` + digest + `"}`
+	}
+	for _, digest := range []string{expected, "simplehash:v3:sha256:" + expected} {
+		status, response = serve(t, h, nethttp.MethodPost, "/v3/verify?id_committed=7", verify(digest))
+		assert.Equal(t, nethttp.StatusOK, status)
+		assert.Equal(t, true, response["verified"])
+	}
+	status, response = serve(t, h, nethttp.MethodPost, "/v3/verify", verify(expected))
+	assert.Equal(t, nethttp.StatusOK, status)
+	assert.Equal(t, false, response["verified"])
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"bad parameter", nethttp.MethodPost, "/v3/hash?encoding=xml", testEvent, nethttp.StatusBadRequest},
+		{"bad event", nethttp.MethodPost, "/v3/hash", `{"identity": 1}`, nethttp.StatusBadRequest},
+		{"bad digest", nethttp.MethodPost, "/v3/verify", verify("simplehash:v2:sha256:" + expected), nethttp.StatusBadRequest},
+		{"missing event", nethttp.MethodPost, "/v3/verify", `{"digest": "` + expected + `"}`, nethttp.StatusBadRequest},
+		{"method", nethttp.MethodGet, "/v3/hash", "", nethttp.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, response := serve(t, h, test.method, test.target, test.body)
+			assert.Equal(t, test.status, status)
+			assert.NotEmpty(t, response["error"])
+		})
+	}
+
+	h.SetMaxBodySize(8)
+	status, _ = serve(t, h, nethttp.MethodPost, "/v3/hash", testEvent)
+	assert.Equal(t, nethttp.StatusRequestEntityTooLarge, status)
+}
+
+// TestHandler_DigestString tests:
+//
+// 1. the bencode encoding returns the self describing digest string.
+// 2. the cbor and jcs encodings, which the digest string can not describe,
+// return no digest string.
+func TestHandler_DigestString(t *testing.T) {
+	h := NewHandler()
+	tests := []struct {
+		encoding string
+		option   simplehash.Encoding
+		labelled bool
+	}{
+		{"bencode", simplehash.EncodingBencode, true},
+		{"cbor", simplehash.EncodingCBOR, false},
+		{"jcs", simplehash.EncodingJCS, false},
+	}
+	for _, test := range tests {
+		t.Run(test.encoding, func(t *testing.T) {
+			sum, err := simplehash.DigestEventFromJSON([]byte(testEvent), simplehash.WithEncoding(test.option))
+			require.NoError(t, err)
+
+			status, response := serve(t, h, nethttp.MethodPost, "/v3/hash?encoding="+test.encoding, testEvent)
+			assert.Equal(t, nethttp.StatusOK, status)
+			assert.Equal(t, hex.EncodeToString(sum), response["digest"])
+			if test.labelled {
+				assert.Equal(t, "simplehash:v3:sha256:"+hex.EncodeToString(sum), response["digest_string"])
+			} else {
+				assert.NotContains(t, response, "digest_string")
+			}
+		})
+	}
+}
+
+type testRecorder struct {
+	observed []string
+	failed   []string
+}
+
+func (r *testRecorder) ObserveHash(service string, method string, _ time.Duration, payloadBytes int, err error) {
+	r.observed = append(r.observed, fmt.Sprintf("%s %s %d %v", service, method, payloadBytes, err != nil))
+}
+
+func (r *testRecorder) VerifyFailed(service string, method string) {
+	r.failed = append(r.failed, service+" "+method)
+}
+
+// TestHandlerRecorder tests that hash and verify requests, and failed
+// verifications, are recorded.
+func TestHandlerRecorder(t *testing.T) {
+	recorder := &testRecorder{}
+	h := NewHandler()
+	h.SetRecorder(recorder)
+
+	serve(t, h, nethttp.MethodPost, "/v3/hash", testEvent)
+	serve(t, h, nethttp.MethodPost, "/v3/hash", "{")
+	serve(t, h, nethttp.MethodPost, "/v3/verify", `{"event": `+testEvent+`, "digest": "00"}`)
+
+	assert.Equal(t, []string{
+		fmt.Sprintf("http hash %d false", len(testEvent)),
+		"http hash 1 true",
+		fmt.Sprintf("http verify %d false", len(testEvent)),
+	}, recorder.observed)
+	assert.Equal(t, []string{"http verify"}, recorder.failed)
+}
@@ -0,0 +1,97 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMarshal tests:
+//
+// 1. encodings match the examples in RFC 8949 appendix A.
+// 2. map keys are sorted by their encoded bytes.
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        any
+		expected string
+	}{
+		{"zero", 0, "00"},
+		{"23", 23, "17"},
+		{"24", 24, "1818"},
+		{"1000000", 1000000, "1a000f4240"},
+		{"max uint64", uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{"-1000", -1000, "3903e7"},
+		{"0.0", 0.0, "f90000"},
+		{"-0.0", math.Copysign(0, -1), "f98000"},
+		{"1.5", 1.5, "f93e00"},
+		{"65504.0", 65504.0, "f97bff"},
+		{"100000.0", 100000.0, "fa47c35000"},
+		{"1.1", 1.1, "fb3ff199999999999a"},
+		{"5.960464477539063e-8", 5.960464477539063e-8, "f90001"},
+		{"infinity", math.Inf(1), "f97c00"},
+		{"nan", math.NaN(), "f97e00"},
+		{"true", true, "f5"},
+		{"null", nil, "f6"},
+		{"bytes", []byte{1, 2, 3, 4}, "4401020304"},
+		{"text", "IETF", "6449455446"},
+		{"array", []any{1, []any{2, 3}}, "8201820203"},
+		{"tag", Tag{Number: 1, Content: 1363896240}, "c11a514b67b0"},
+		{"map sorted", map[any]any{"b": 1, 10: 2, -1: 3, "a": 4}, "a40a0220036161046162 01"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := Marshal(test.v)
+			require.NoError(t, err)
+			expected, err := hex.DecodeString(stripSpaces(test.expected))
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+// TestUnmarshal tests:
+//
+// 1. marshaled values round trip.
+// 2. truncated and trailing data are rejected.
+// 3. maps with duplicate keys are rejected.
+func TestUnmarshal(t *testing.T) {
+	v := map[any]any{
+		int64(1):  int64(-7),
+		"payload": []byte("digest"),
+		"list":    []any{"a", int64(2), 1.5, true, nil},
+		"tagged":  Tag{Number: 18, Content: []any{int64(1)}},
+	}
+	data, err := Marshal(v)
+	require.NoError(t, err)
+
+	actual, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, v, actual)
+
+	_, err = Unmarshal(data[:len(data)-1])
+	assert.ErrorIs(t, err, ErrTruncated)
+
+	_, err = Unmarshal(append(data, 0))
+	assert.ErrorIs(t, err, ErrTrailing)
+
+	// {1: 1, 1: 2}, a protected header read differently by first and last
+	// wins decoders
+	_, err = Unmarshal([]byte{0xa2, 0x01, 0x01, 0x01, 0x02})
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	_, err = Unmarshal([]byte{0xa2, 0x61, 'a', 0x01, 0x61, 'a', 0x02})
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+}
+
+func stripSpaces(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != ' ' {
+			out = append(out, s[i])
+		}
+	}
+	return string(out)
+}
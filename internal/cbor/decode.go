@@ -0,0 +1,211 @@
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxDepth bounds the nesting of decoded items
+const maxDepth = 64
+
+var (
+	ErrTruncated    = errors.New("cbor: truncated data")
+	ErrTrailing     = errors.New("cbor: trailing data")
+	ErrUnsupported  = errors.New("cbor: unsupported data item")
+	ErrDuplicateKey = errors.New("cbor: duplicate map key")
+)
+
+// Unmarshal decodes a single CBOR data item. Integers decode as int64, or
+// uint64 if they are too large, byte strings as []byte, text strings as
+// string, arrays as []any, maps as map[any]any, tags as Tag, floats as
+// float64 and simple values as bool or nil. Indefinite lengths are not
+// supported. Maps with duplicate keys are rejected, as RFC 9052 requires of
+// COSE headers, so no two decoders can read different values from them.
+func Unmarshal(data []byte) (any, error) {
+	d := decoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(d.data) {
+		return nil, ErrTrailing
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, ErrTruncated
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+func (d *decoder) head() (byte, byte, uint64, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+
+	var arg uint64
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info == 24:
+		b, err = d.next(1)
+		if err == nil {
+			arg = uint64(b[0])
+		}
+	case info == 25:
+		b, err = d.next(2)
+		if err == nil {
+			arg = uint64(binary.BigEndian.Uint16(b))
+		}
+	case info == 26:
+		b, err = d.next(4)
+		if err == nil {
+			arg = uint64(binary.BigEndian.Uint32(b))
+		}
+	case info == 27:
+		b, err = d.next(8)
+		if err == nil {
+			arg = binary.BigEndian.Uint64(b)
+		}
+	default:
+		err = fmt.Errorf("%w: additional info %d", ErrUnsupported, info)
+	}
+	return major, info, arg, err
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: nesting deeper than %d", ErrUnsupported, maxDepth)
+	}
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUnsigned:
+		if arg <= math.MaxInt64 {
+			return int64(arg), nil
+		}
+		return arg, nil
+
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: negative integer overflow", ErrUnsupported)
+		}
+		return -1 - int64(arg), nil
+
+	case majorBytes:
+		b, err := d.next(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+
+	case majorText:
+		b, err := d.next(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case majorArray:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, ErrTruncated
+		}
+		a := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+
+	case majorMap:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, ErrTruncated
+		}
+		m := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch k.(type) {
+			case int64, uint64, string, bool, nil, float64:
+			default:
+				return nil, fmt.Errorf("%w: map key of type %T", ErrUnsupported, k)
+			}
+			if _, ok := m[k]; ok {
+				return nil, fmt.Errorf("%w: %v", ErrDuplicateKey, k)
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+
+	case majorTag:
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Tag{Number: arg, Content: v}, nil
+	}
+
+	// major type 7
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return float16ToFloat64(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	}
+	return nil, fmt.Errorf("%w: simple value %d", ErrUnsupported, arg)
+}
+
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	}
+	return sign * math.Ldexp(mant+1024, exp-25)
+}
@@ -0,0 +1,256 @@
+// Package cbor is a minimal CBOR (RFC 8949) codec covering the data model
+// needed for COSE structures and canonical event encoding. Encoding is always
+// deterministic, following the core deterministic encoding requirements of
+// RFC 8949 section 4.2.1: preferred (shortest) argument and float encodings,
+// definite lengths, and map keys sorted by their encoded bytes.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+// Tag is a tagged data item
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+// RawMessage is pre-encoded CBOR which is copied to the output unchanged
+type RawMessage []byte
+
+// Marshal returns the deterministic CBOR encoding of v. Supported types are
+// nil, bool, all integer types, float32, float64, string, []byte, RawMessage,
+// Tag, slices and arrays of supported types, and maps whose keys and values
+// are supported types.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
+
+func encode(buf *bytes.Buffer, v reflect.Value) error {
+
+	if !v.IsValid() {
+		buf.WriteByte(majorSimple<<5 | 22) // null
+		return nil
+	}
+
+	switch x := v.Interface().(type) {
+	case RawMessage:
+		buf.Write(x)
+		return nil
+	case Tag:
+		writeHead(buf, majorTag, x.Number)
+		return encode(buf, reflect.ValueOf(x.Content))
+	case []byte:
+		writeHead(buf, majorBytes, uint64(len(x)))
+		buf.Write(x)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			buf.WriteByte(majorSimple<<5 | 22)
+			return nil
+		}
+		return encode(buf, v.Elem())
+
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteByte(majorSimple<<5 | 21)
+		} else {
+			buf.WriteByte(majorSimple<<5 | 20)
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if i < 0 {
+			writeHead(buf, majorNegative, uint64(-(i + 1)))
+		} else {
+			writeHead(buf, majorUnsigned, uint64(i))
+		}
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeHead(buf, majorUnsigned, v.Uint())
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		encodeFloat(buf, v.Float())
+		return nil
+
+	case reflect.String:
+		writeHead(buf, majorText, uint64(v.Len()))
+		buf.WriteString(v.String())
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteByte(majorSimple<<5 | 22)
+			return nil
+		}
+		writeHead(buf, majorArray, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := encode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteByte(majorSimple<<5 | 22)
+			return nil
+		}
+		return encodeMap(buf, v)
+	}
+
+	return fmt.Errorf("cbor: unsupported type %s", v.Type())
+}
+
+func encodeMap(buf *bytes.Buffer, v reflect.Value) error {
+
+	type entry struct {
+		key   []byte
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		var kbuf bytes.Buffer
+		if err := encode(&kbuf, iter.Key()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: kbuf.Bytes(), value: iter.Value()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	writeHead(buf, majorMap, uint64(len(entries)))
+	for i, e := range entries {
+		if i > 0 && bytes.Equal(entries[i-1].key, e.key) {
+			return fmt.Errorf("cbor: duplicate map key %x", e.key)
+		}
+		buf.Write(e.key)
+		if err := encode(buf, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeFloat writes the shortest of the half, single and double precision
+// encodings which preserves the value exactly. NaN is always the canonical
+// half precision quiet NaN.
+func encodeFloat(buf *bytes.Buffer, f float64) {
+
+	if math.IsNaN(f) {
+		buf.Write([]byte{majorSimple<<5 | 25, 0x7e, 0x00})
+		return
+	}
+
+	f32 := float32(f)
+	if float64(f32) != f {
+		buf.WriteByte(majorSimple<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+		return
+	}
+
+	if h, ok := float16Exact(f32); ok {
+		buf.WriteByte(majorSimple<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, h))
+		return
+	}
+
+	buf.WriteByte(majorSimple<<5 | 26)
+	buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f32)))
+}
+
+// float16Exact returns the IEEE 754 half precision bits for f, if f can be
+// represented exactly.
+func float16Exact(f float32) (uint16, bool) {
+
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int((bits >> 23) & 0xff)
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp == 0 && mant == 0:
+		return sign, true
+	case exp == 0xff:
+		// Infinity, NaN has already been dealt with
+		return sign | 0x7c00, true
+	case exp == 0:
+		// single precision subnormals are too small for half precision
+		return 0, false
+	}
+
+	e := exp - 127 + 15
+	if e >= 31 {
+		return 0, false
+	}
+	if e >= 1 {
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(e)<<10 | uint16(mant>>13), true
+	}
+
+	// half precision subnormal, value = m * 2^-24
+	shift := 126 - exp
+	if shift >= 24 {
+		return 0, false
+	}
+	full := mant | 1<<23
+	if full&(1<<shift-1) != 0 {
+		return 0, false
+	}
+	m := full >> shift
+	if m >= 1<<10 {
+		return 0, false
+	}
+	return sign | uint16(m), true
+}
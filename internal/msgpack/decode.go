@@ -0,0 +1,202 @@
+// Package msgpack decodes MessagePack data items. Only decoding is
+// implemented, events are never hashed in MessagePack form.
+package msgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxDepth bounds the nesting of decoded items
+const maxDepth = 64
+
+var (
+	ErrTruncated   = errors.New("msgpack: truncated data")
+	ErrTrailing    = errors.New("msgpack: trailing data")
+	ErrUnsupported = errors.New("msgpack: unsupported data item")
+)
+
+// Unmarshal decodes a single MessagePack data item. Integers decode as int64,
+// or uint64 if they are too large, str as string, bin as []byte, arrays as
+// []any, maps as map[string]any, floats as float64, and nil and booleans as
+// nil and bool. Maps with keys which are not str, and extension types, are
+// not supported.
+func Unmarshal(data []byte) (any, error) {
+	d := decoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(d.data) {
+		return nil, ErrTrailing
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, ErrTruncated
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// uint reads a big endian unsigned integer of n bytes
+func (d *decoder) uint(n uint64) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: nesting deeper than %d", ErrUnsupported, maxDepth)
+	}
+
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(uint64(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.decodeArray(uint64(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return d.decodeString(uint64(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xca:
+		n, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := d.uint(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+		return n, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := uint64(1) << (c - 0xd0)
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// sign extend from the encoded width
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n, depth)
+	}
+	return nil, fmt.Errorf("%w: format 0x%02x", ErrUnsupported, c)
+}
+
+func (d *decoder) decodeString(n uint64) (any, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) decodeArray(n uint64, depth int) (any, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, ErrTruncated
+	}
+	a := make([]any, 0, n)
+	for i := uint64(0); i < n; i++ {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func (d *decoder) decodeMap(n uint64, depth int) (any, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, ErrTruncated
+	}
+	m := make(map[string]any, n)
+	for i := uint64(0); i < n; i++ {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: map key of type %T", ErrUnsupported, k)
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
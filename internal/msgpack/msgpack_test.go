@@ -0,0 +1,66 @@
+package msgpack
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnmarshal tests:
+//
+// 1. each format decodes to the documented go type, from the examples of the
+// MessagePack specification.
+// 2. truncated data, trailing data, non str map keys and extension types are
+// rejected.
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected any
+	}{
+		{"positive fixint", "07", int64(7)},
+		{"negative fixint", "f9", int64(-7)},
+		{"uint8", "ccff", int64(255)},
+		{"uint16", "cd0100", int64(256)},
+		{"uint32", "ce00010000", int64(65536)},
+		{"uint64 max", "cfffffffffffffffff", uint64(math.MaxUint64)},
+		{"int8", "d080", int64(-128)},
+		{"int16", "d1ff00", int64(-256)},
+		{"int32", "d2ffff0000", int64(-65536)},
+		{"int64", "d38000000000000000", int64(math.MinInt64)},
+		{"float32", "ca3fc00000", 1.5},
+		{"float64", "cb3ff199999999999a", 1.1},
+		{"nil", "c0", nil},
+		{"false", "c2", false},
+		{"true", "c3", true},
+		{"fixstr", "a3666f6f", "foo"},
+		{"str8", "d903666f6f", "foo"},
+		{"str16", "da0003666f6f", "foo"},
+		{"bin8", "c403010203", []byte{1, 2, 3}},
+		{"fixarray", "9201a161", []any{int64(1), "a"}},
+		{"array16", "dc000101", []any{int64(1)}},
+		{"fixmap", "82a16101a162c0", map[string]any{"a": int64(1), "b": nil}},
+		{"map16", "de0001a16101", map[string]any{"a": int64(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			actual, err := Unmarshal(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, err := Unmarshal([]byte{0xa3, 'f', 'o'})
+	assert.ErrorIs(t, err, ErrTruncated)
+	_, err = Unmarshal([]byte{0x01, 0x02})
+	assert.ErrorIs(t, err, ErrTrailing)
+	_, err = Unmarshal([]byte{0x81, 0x01, 0x01})
+	assert.ErrorIs(t, err, ErrUnsupported)
+	_, err = Unmarshal([]byte{0xd4, 0x01, 0x01})
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
@@ -0,0 +1,126 @@
+// Package kafka feeds events consumed from Kafka, for example an on-prem
+// mirror of the platform event stream, into a simplehash.StreamHasher. It
+// mirrors package eventhub.
+//
+// No kafka client is imported. The consumer reads from a Reader, which is a
+// few lines to implement over the client of your choice, for example
+// segmentio/kafka-go:
+//
+//	type reader struct{ *kafkago.Reader }
+//
+//	func (r reader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+//		m, err := r.Reader.FetchMessage(ctx)
+//		return kafka.Message{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset, Value: m.Value}, err
+//	}
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Format is the encoding of the message payloads
+type Format int
+
+const (
+	// FormatProto payloads are EventResponse messages in proto wire format, as
+	// on the platform message bus. This is the default.
+	FormatProto Format = iota
+	// FormatProtoJSON payloads are EventResponse messages in protojson format
+	FormatProtoJSON
+	// FormatJSON payloads are events in the api json format
+	FormatJSON
+)
+
+// Message is a single message fetched from a topic partition
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Value     []byte
+}
+
+// Reader fetches messages, one at a time, from one or more partitions
+type Reader interface {
+	FetchMessage(ctx context.Context) (Message, error)
+}
+
+// Consumer accumulates the fetched messages. The offset of the last message
+// of each partition is saved, with the hash state, in the stream checkpoints,
+// so that after a restart each partition can be resumed from StartOffset.
+// Offsets are not committed to the broker.
+//
+// The digest depends on the order the messages are consumed in. To produce a
+// digest which can be reproduced elsewhere, consume a single partition.
+type Consumer struct {
+	stream *simplehash.StreamHasher
+	format Format
+}
+
+// NewConsumer creates a consumer feeding stream with messages in format
+func NewConsumer(stream *simplehash.StreamHasher, format Format) *Consumer {
+	return &Consumer{stream: stream, format: format}
+}
+
+func offsetKey(topic string, partition int) string {
+	return "kafka/" + topic + "/" + strconv.Itoa(partition) + "/offset"
+}
+
+// StartOffset returns the offset of the last message accumulated from the
+// partition, recovered from the stream checkpoint. The reader should resume
+// from the following offset. ok is false if the partition has not been
+// consumed before.
+func (c *Consumer) StartOffset(topic string, partition int) (offset int64, ok bool, err error) {
+	value, ok := c.stream.Metadata()[offsetKey(topic, partition)]
+	if !ok {
+		return 0, false, nil
+	}
+	offset, err = strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("checkpointed offset %q: %w", value, err)
+	}
+	return offset, true, nil
+}
+
+// HandleMessage decodes a single message and accumulates it
+func (c *Consumer) HandleMessage(m Message) error {
+	metadata := map[string]string{
+		offsetKey(m.Topic, m.Partition): strconv.FormatInt(m.Offset, 10),
+	}
+	switch c.format {
+	case FormatJSON:
+		return c.stream.Add(m.Value, metadata)
+	case FormatProtoJSON:
+		event := &v2assets.EventResponse{}
+		err := protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(m.Value, event)
+		if err != nil {
+			return c.stream.AddInvalid(m.Value, err, metadata)
+		}
+		return c.stream.AddEvent(event, metadata)
+	default:
+		return c.stream.AddProto(m.Value, metadata)
+	}
+}
+
+// Consume fetches and accumulates messages until the context is done, or the
+// reader or the stream fails. A checkpoint is saved when the context is done.
+func (c *Consumer) Consume(ctx context.Context, r Reader) error {
+	for {
+		m, err := r.FetchMessage(ctx)
+		if ctx.Err() != nil {
+			return errors.Join(ctx.Err(), c.stream.Checkpoint())
+		}
+		if err != nil {
+			return err
+		}
+		if err = c.HandleMessage(m); err != nil {
+			return err
+		}
+	}
+}
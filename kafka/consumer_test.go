@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeReader returns its messages, then cancels the consumer
+type fakeReader struct {
+	messages []Message
+	cancel   context.CancelFunc
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (Message, error) {
+	if len(r.messages) == 0 {
+		r.cancel()
+		return Message{}, ctx.Err()
+	}
+	m := r.messages[0]
+	r.messages = r.messages[1:]
+	return m, nil
+}
+
+// TestConsumer tests:
+//
+// 1. proto, protojson and api json payloads accumulate to the same digest as
+// hashing the events directly.
+// 2. the offset of the last message is checkpointed with the hash state.
+func TestConsumer(t *testing.T) {
+	var events []*v2assets.EventResponse
+	expected := simplehash.NewHasherV3()
+	for i := 1; i <= 3; i++ {
+		event := &v2assets.EventResponse{
+			Identity:      fmt.Sprintf("assets/1/events/%d", i),
+			AssetIdentity: "assets/1",
+			Operation:     "Record",
+		}
+		require.NoError(t, expected.HashEvent(event, simplehash.WithAccumulate()))
+		events = append(events, event)
+	}
+
+	encoders := map[Format]func(*v2assets.EventResponse) ([]byte, error){
+		FormatProto:     func(e *v2assets.EventResponse) ([]byte, error) { return proto.Marshal(e) },
+		FormatProtoJSON: func(e *v2assets.EventResponse) ([]byte, error) { return protojson.Marshal(e) },
+		FormatJSON:      func(e *v2assets.EventResponse) ([]byte, error) { return simplehash.NewEventMarshaler().Marshal(e) },
+	}
+	for format, encode := range encoders {
+		t.Run(fmt.Sprint(format), func(t *testing.T) {
+			var messages []Message
+			for i, e := range events {
+				value, err := encode(e)
+				require.NoError(t, err)
+				messages = append(messages, Message{Topic: "events", Partition: 1, Offset: int64(i), Value: value})
+			}
+
+			store, err := simplehash.NewFileCheckpointStore(t.TempDir())
+			require.NoError(t, err)
+			stream, err := simplehash.NewStreamHasher(store, "mirror", 0)
+			require.NoError(t, err)
+
+			c := NewConsumer(stream, format)
+			ctx, cancel := context.WithCancel(context.Background())
+			assert.ErrorIs(t, c.Consume(ctx, &fakeReader{messages: messages, cancel: cancel}), context.Canceled)
+			assert.Equal(t, expected.Sum(nil), stream.Digest())
+
+			stream, err = simplehash.NewStreamHasher(store, "mirror", 0)
+			require.NoError(t, err)
+			offset, ok, err := NewConsumer(stream, format).StartOffset("events", 1)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, int64(2), offset)
+		})
+	}
+}
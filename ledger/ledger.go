@@ -0,0 +1,222 @@
+// Package ledger records computed event digests in SQLite, giving auditors a
+// durable, queryable record of verification runs. Each entry records the
+// event identity, tenant, schema, hashing options and digest, along with the
+// run which computed it and when.
+//
+// The sqlite driver is not imported, so that the choice of driver, and of
+// cgo, is left to the application. Open the database with any driver and
+// pass it to New:
+//
+//	import _ "github.com/mattn/go-sqlite3"
+//
+//	db, err := sql.Open("sqlite3", "ledger.db")
+//	l, err := ledger.New(ctx, db)
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+var (
+	ErrNotFound = errors.New("no ledger entry for the event")
+)
+
+const schemaSQL = `
+CREATE TABLE IF NOT EXISTS digests (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	run            TEXT    NOT NULL,
+	identity       TEXT    NOT NULL,
+	tenant         TEXT    NOT NULL,
+	schema_version INTEGER NOT NULL,
+	options        TEXT    NOT NULL,
+	digest         BLOB    NOT NULL,
+	computed_at    TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS digests_event ON digests (identity, schema_version, options);
+`
+
+// Entry is a digest recorded in the ledger
+type Entry struct {
+	Run      string
+	Identity string
+	Tenant   string
+	Schema   int
+	// Options describes the hashing options, see simplehash.DescribeOptions
+	Options    map[string]string
+	Digest     []byte
+	ComputedAt time.Time
+}
+
+// Verification is the outcome of checking events against the ledger. Each
+// list holds event identities, in the order the events were provided.
+type Verification struct {
+	// Verified lists the events whose digest matches the latest entry
+	Verified []string
+	// Mismatched lists the events whose digest differs from the latest entry.
+	// DigestA is the recorded digest and DigestB the digest computed now.
+	Mismatched []simplehash.DigestMismatch
+	// Missing lists the events with no entry for the schema and options
+	Missing []string
+}
+
+// Consistent is true if every event matched its ledger entry
+func (v Verification) Consistent() bool {
+	return len(v.Mismatched) == 0 && len(v.Missing) == 0
+}
+
+// Ledger records digests in a sqlite database
+type Ledger struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// New creates the ledger tables in db, if they do not already exist
+func New(ctx context.Context, db *sql.DB) (*Ledger, error) {
+	if _, err := db.ExecContext(ctx, schemaSQL); err != nil {
+		return nil, fmt.Errorf("ledger: failed to create schema: %w", err)
+	}
+	return &Ledger{db: db, now: time.Now}, nil
+}
+
+// Record hashes each api formatted event individually, as
+// simplehash.DigestEventFromJSON does, and records the digests under run, a
+// caller chosen name for the verification run. The entries are recorded in a
+// single transaction, so a failed event records nothing.
+func (l *Ledger) Record(ctx context.Context, run string, events [][]byte, opts ...simplehash.HashOption) ([]Entry, error) {
+
+	options := simplehash.DescribeOptions(opts...)
+	optionsJson, err := marshalOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(events))
+	for i, eventJson := range events {
+		v3Event, digest, err := digestEvent(eventJson, opts)
+		if err != nil {
+			return nil, fmt.Errorf("ledger event %d: %w", i, err)
+		}
+		entries = append(entries, Entry{
+			Run:        run,
+			Identity:   v3Event.Identity,
+			Tenant:     v3Event.TenantIdentity,
+			Schema:     simplehash.SchemaVersionV3,
+			Options:    options,
+			Digest:     digest,
+			ComputedAt: l.now().UTC(),
+		})
+	}
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, e := range entries {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO digests (run, identity, tenant, schema_version, options, digest, computed_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			e.Run, e.Identity, e.Tenant, e.Schema, optionsJson, e.Digest, e.ComputedAt.Format(time.RFC3339Nano))
+		if err != nil {
+			return nil, fmt.Errorf("ledger: failed to record %s: %w", e.Identity, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Latest returns the most recently recorded entry for the event identity
+// hashed with the schema and options, or an error wrapping ErrNotFound
+func (l *Ledger) Latest(ctx context.Context, identity string, schema int, options map[string]string) (Entry, error) {
+
+	optionsJson, err := marshalOptions(options)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{Identity: identity, Schema: schema, Options: options}
+	var computedAt string
+	err = l.db.QueryRowContext(ctx,
+		`SELECT run, tenant, digest, computed_at FROM digests
+		 WHERE identity = ? AND schema_version = ? AND options = ?
+		 ORDER BY id DESC LIMIT 1`,
+		identity, schema, optionsJson).Scan(&e.Run, &e.Tenant, &e.Digest, &computedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, identity)
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	if e.ComputedAt, err = time.Parse(time.RFC3339Nano, computedAt); err != nil {
+		return Entry{}, fmt.Errorf("ledger: %s: %w", identity, err)
+	}
+	return e, nil
+}
+
+// VerifyAgainstLedger hashes each api formatted event and compares the digest
+// with the latest ledger entry for the event, schema and options. Nothing is
+// recorded. An error is returned only if an event can not be hashed or the
+// ledger can not be read.
+func (l *Ledger) VerifyAgainstLedger(ctx context.Context, events [][]byte, opts ...simplehash.HashOption) (Verification, error) {
+
+	options := simplehash.DescribeOptions(opts...)
+	v := Verification{}
+
+	for i, eventJson := range events {
+		v3Event, digest, err := digestEvent(eventJson, opts)
+		if err != nil {
+			return Verification{}, fmt.Errorf("ledger event %d: %w", i, err)
+		}
+		e, err := l.Latest(ctx, v3Event.Identity, simplehash.SchemaVersionV3, options)
+		if errors.Is(err, ErrNotFound) {
+			v.Missing = append(v.Missing, v3Event.Identity)
+			continue
+		}
+		if err != nil {
+			return Verification{}, err
+		}
+		if string(e.Digest) != string(digest) {
+			v.Mismatched = append(v.Mismatched, simplehash.DigestMismatch{
+				Identity: v3Event.Identity, DigestA: e.Digest, DigestB: digest,
+			})
+			continue
+		}
+		v.Verified = append(v.Verified, v3Event.Identity)
+	}
+	simplehash.AddMismatches(ctx, len(v.Mismatched), opts...)
+	return v, nil
+}
+
+// digestEvent returns the decoded event, for its identity and tenant, and its
+// digest
+func digestEvent(eventJson []byte, opts []simplehash.HashOption) (simplehash.V3Event, []byte, error) {
+	v3Event, err := simplehash.V3FromEventJSON(eventJson)
+	if err != nil {
+		return simplehash.V3Event{}, nil, err
+	}
+	digest, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+	if err != nil {
+		return simplehash.V3Event{}, nil, err
+	}
+	return v3Event, digest, nil
+}
+
+// marshalOptions returns the options as json with sorted keys, so equal
+// options are equal strings in the database
+func marshalOptions(options map[string]string) (string, error) {
+	if options == nil {
+		options = map[string]string{}
+	}
+	b, err := json.Marshal(options)
+	return string(b), err
+}
@@ -0,0 +1,89 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var events = [][]byte{
+	[]byte(`{"identity": "assets/1/events/1", "tenant_identity": "tenant/1", "event_attributes": {"a": "1"}}`),
+	[]byte(`{"identity": "assets/1/events/2", "tenant_identity": "tenant/1", "event_attributes": {"a": "2"}}`),
+}
+
+func newTestLedger(t *testing.T) *Ledger {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "ledger.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	l, err := New(context.Background(), db)
+	require.NoError(t, err)
+	l.now = func() time.Time { return time.Date(2024, 1, 31, 11, 29, 19, 0, time.UTC) }
+	return l
+}
+
+// TestLedger_Record tests:
+//
+// 1. each event is recorded with its identity, tenant, options and digest.
+// 2. the latest entry is returned for an event.
+// 3. entries for other options are not returned.
+func TestLedger_Record(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLedger(t)
+
+	entries, err := l.Record(ctx, "run-1", events, simplehash.WithPrefix([]byte{1}))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	expected, err := simplehash.DigestEventFromJSON(events[0], simplehash.WithPrefix([]byte{1}))
+	require.NoError(t, err)
+	assert.Equal(t, expected, entries[0].Digest)
+	assert.Equal(t, "tenant/1", entries[0].Tenant)
+
+	_, err = l.Record(ctx, "run-2", events[:1], simplehash.WithPrefix([]byte{1}))
+	require.NoError(t, err)
+
+	options := simplehash.DescribeOptions(simplehash.WithPrefix([]byte{1}))
+	e, err := l.Latest(ctx, "assets/1/events/1", simplehash.SchemaVersionV3, options)
+	require.NoError(t, err)
+	assert.Equal(t, "run-2", e.Run)
+	assert.Equal(t, expected, e.Digest)
+	assert.Equal(t, l.now(), e.ComputedAt)
+
+	_, err = l.Latest(ctx, "assets/1/events/1", simplehash.SchemaVersionV3, nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = l.Record(ctx, "run-3", [][]byte{events[0], []byte(`{`)})
+	assert.Error(t, err)
+	_, err = l.Latest(ctx, "assets/1/events/1", simplehash.SchemaVersionV3, nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestLedger_VerifyAgainstLedger tests:
+//
+// 1. recorded events verify.
+// 2. changed events are reported as mismatched.
+// 3. unrecorded events are reported as missing.
+func TestLedger_VerifyAgainstLedger(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLedger(t)
+
+	_, err := l.Record(ctx, "run-1", events[:1])
+	require.NoError(t, err)
+
+	changed := []byte(`{"identity": "assets/1/events/1", "tenant_identity": "tenant/1", "event_attributes": {"a": "x"}}`)
+	v, err := l.VerifyAgainstLedger(ctx, [][]byte{events[0], changed, events[1]})
+	require.NoError(t, err)
+
+	assert.False(t, v.Consistent())
+	assert.Equal(t, []string{"assets/1/events/1"}, v.Verified)
+	require.Len(t, v.Mismatched, 1)
+	assert.Equal(t, "assets/1/events/1", v.Mismatched[0].Identity)
+	assert.Equal(t, []string{"assets/1/events/2"}, v.Missing)
+}
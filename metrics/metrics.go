@@ -0,0 +1,33 @@
+// Package metrics defines the measurements made by the http and grpc
+// services, so operators running them as shared internal services can
+// monitor hash latency, payload sizes and verification failures. The
+// services record to a Recorder, see the nested module metrics/prom for a
+// Prometheus implementation.
+package metrics
+
+import (
+	"time"
+)
+
+// Services recording measurements
+const (
+	ServiceHTTP = "http"
+	ServiceGRPC = "grpc"
+)
+
+// Recorder receives the measurements of a service. method is the endpoint
+// or rpc, for example "hash" or "Verify". Implementations must be safe for
+// concurrent use.
+type Recorder interface {
+	// ObserveHash records a request which hashed payloadBytes of events in
+	// duration. err is the error returned to the caller, if any.
+	ObserveHash(service string, method string, duration time.Duration, payloadBytes int, err error)
+	// VerifyFailed records a verification whose digest did not match
+	VerifyFailed(service string, method string)
+}
+
+// Nop is a Recorder which records nothing
+type Nop struct{}
+
+func (Nop) ObserveHash(string, string, time.Duration, int, error) {}
+func (Nop) VerifyFailed(string, string)                           {}
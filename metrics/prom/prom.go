@@ -0,0 +1,76 @@
+// Package prom records service measurements as Prometheus metrics:
+//
+//	simplehash_requests_total{service,method,outcome}
+//	simplehash_hash_duration_seconds{service,method}
+//	simplehash_payload_bytes{service,method}
+//	simplehash_verify_failures_total{service,method}
+//
+// outcome is "ok" or "error". It is a separate module, so that only the
+// services which export Prometheus metrics depend on the client library.
+package prom
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Recorder is a metrics.Recorder which updates Prometheus collectors
+type Recorder struct {
+	requests       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	payloadBytes   *prometheus.HistogramVec
+	verifyFailures *prometheus.CounterVec
+}
+
+// NewRecorder creates the collectors and registers them with reg, for example
+// prometheus.DefaultRegisterer
+func NewRecorder(reg prometheus.Registerer) (*Recorder, error) {
+	labels := []string{"service", "method"}
+	r := &Recorder{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "simplehash",
+			Name:      "requests_total",
+			Help:      "Hashing requests served, by outcome.",
+		}, []string{"service", "method", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "simplehash",
+			Name:      "hash_duration_seconds",
+			Help:      "Time taken to serve hashing requests.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 9),
+		}, labels),
+		payloadBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "simplehash",
+			Name:      "payload_bytes",
+			Help:      "Size of the events hashed by each request.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 9),
+		}, labels),
+		verifyFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "simplehash",
+			Name:      "verify_failures_total",
+			Help:      "Verifications whose digest did not match.",
+		}, labels),
+	}
+	for _, c := range []prometheus.Collector{r.requests, r.duration, r.payloadBytes, r.verifyFailures} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ObserveHash implements metrics.Recorder
+func (r *Recorder) ObserveHash(service string, method string, duration time.Duration, payloadBytes int, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	r.requests.WithLabelValues(service, method, outcome).Inc()
+	r.duration.WithLabelValues(service, method).Observe(duration.Seconds())
+	r.payloadBytes.WithLabelValues(service, method).Observe(float64(payloadBytes))
+}
+
+// VerifyFailed implements metrics.Recorder
+func (r *Recorder) VerifyFailed(service string, method string) {
+	r.verifyFailures.WithLabelValues(service, method).Inc()
+}
@@ -0,0 +1,50 @@
+package prom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ metrics.Recorder = &Recorder{}
+
+// TestRecorder tests:
+//
+// 1. requests are counted by outcome, and their latency and size observed.
+// 2. verify failures are counted.
+// 3. registering twice with the same registerer fails.
+func TestRecorder(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	r, err := NewRecorder(reg)
+	require.NoError(t, err)
+
+	r.ObserveHash(metrics.ServiceHTTP, "hash", time.Millisecond, 100, nil)
+	r.ObserveHash(metrics.ServiceHTTP, "hash", time.Millisecond, 100, errors.New("bad event"))
+	r.ObserveHash(metrics.ServiceGRPC, "Verify", time.Millisecond, 100, nil)
+	r.VerifyFailed(metrics.ServiceGRPC, "Verify")
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP simplehash_requests_total Hashing requests served, by outcome.
+# TYPE simplehash_requests_total counter
+simplehash_requests_total{method="Verify",outcome="ok",service="grpc"} 1
+simplehash_requests_total{method="hash",outcome="error",service="http"} 1
+simplehash_requests_total{method="hash",outcome="ok",service="http"} 1
+# HELP simplehash_verify_failures_total Verifications whose digest did not match.
+# TYPE simplehash_verify_failures_total counter
+simplehash_verify_failures_total{method="Verify",service="grpc"} 1
+`), "simplehash_requests_total", "simplehash_verify_failures_total"))
+
+	count, err := testutil.GatherAndCount(reg, "simplehash_hash_duration_seconds", "simplehash_payload_bytes")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	_, err = NewRecorder(reg)
+	assert.Error(t, err)
+}
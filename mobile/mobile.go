@@ -0,0 +1,92 @@
+// Package mobile wraps simple hash v3 verification for gomobile, so that iOS
+// and Android apps can verify public event attestations offline:
+//
+//	gomobile bind -target ios -tags simplehash_noproto ./mobile
+//	gomobile bind -target android -tags simplehash_noproto ./mobile
+//
+// The api uses only types gomobile bind supports: events are api formatted
+// json strings and digests are hex strings. It is built on package
+// simplehash, and the simplehash_noproto tag keeps the proto and grpc
+// dependencies out of the app.
+package mobile
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+// digestStringPrefix prefixes a v3 digest formatted by simplehash.FormatDigest
+var digestStringPrefix = simplehash.FormatDigest(3, "sha256", nil)
+
+var (
+	ErrInvalidDigest = errors.New("invalid digest")
+)
+
+// Options adjusts how events are hashed. A nil Options hashes with the
+// defaults.
+type Options struct {
+	// UseNumber hashes integer attribute values exactly. Without it events
+	// with numeric attribute values are rejected.
+	UseNumber bool
+	// PrefixHex is the hex encoded prefix prepended to the pre-image, for
+	// domain separation
+	PrefixHex string
+}
+
+// NewOptions returns the default options
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) hashOptions() ([]simplehash.HashOption, error) {
+	if o == nil {
+		return nil, nil
+	}
+	var opts []simplehash.HashOption
+	if o.UseNumber {
+		opts = append(opts, simplehash.WithUseNumber())
+	}
+	if o.PrefixHex != "" {
+		prefix, err := hex.DecodeString(o.PrefixHex)
+		if err != nil {
+			return nil, fmt.Errorf("prefix: %w", err)
+		}
+		opts = append(opts, simplehash.WithPrefix(prefix))
+	}
+	return opts, nil
+}
+
+// HashEventV3 returns the hex encoded v3 digest of an api formatted event.
+// Public events hash as their permissioned counterparts, as for the anchored
+// digest.
+func HashEventV3(eventJson string, o *Options) (string, error) {
+	opts, err := o.hashOptions()
+	if err != nil {
+		return "", err
+	}
+	h := simplehash.NewHasherV3()
+	if err := h.HashEventFromJSON([]byte(eventJson), opts...); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyEventV3 returns true if the v3 digest of the api formatted event is
+// expectedHash. The expected hash is hex, in either case, or a v3 digest
+// string such as simplehash:v3:sha256:<hex>. An error is returned only if the
+// event can't be hashed or the expected hash is malformed.
+func VerifyEventV3(eventJson string, expectedHash string, o *Options) (bool, error) {
+	expected, err := hex.DecodeString(strings.TrimPrefix(expectedHash, digestStringPrefix))
+	if err != nil || len(expected) == 0 {
+		return false, fmt.Errorf("%w: %q", ErrInvalidDigest, expectedHash)
+	}
+	digest, err := HashEventV3(eventJson, o)
+	if err != nil {
+		return false, err
+	}
+	return digest == hex.EncodeToString(expected), nil
+}
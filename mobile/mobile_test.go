@@ -0,0 +1,70 @@
+package mobile
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEventJSON = `{
+	"identity": "publicassets/0a8d1a3c-2a1b-4d3e-8f5a-1b2c3d4e5f60/events/9b8c7d6e-5f4a-4b3c-8d2e-1f0a9b8c7d6e",
+	"event_attributes": {"foo": "bar", "n": 12},
+	"operation": "Record",
+	"behaviour": "RecordEvidence",
+	"timestamp_accepted": "2024-01-31T11:29:19.043Z"
+}`
+
+// TestHashEventV3 tests:
+//
+// 1. the digest is the hex of the simplehash digest, with the options.
+// 2. numeric attributes are rejected without UseNumber, and an invalid
+// prefix is rejected.
+func TestHashEventV3(t *testing.T) {
+	o := NewOptions()
+	o.UseNumber = true
+	o.PrefixHex = "0102"
+	digest, err := HashEventV3(testEventJSON, o)
+	require.NoError(t, err)
+
+	h := simplehash.NewHasherV3()
+	require.NoError(t, h.HashEventFromJSON(
+		[]byte(testEventJSON), simplehash.WithUseNumber(), simplehash.WithPrefix([]byte{1, 2})))
+	assert.Equal(t, hex.EncodeToString(h.Sum(nil)), digest)
+
+	_, err = HashEventV3(testEventJSON, nil)
+	assert.Error(t, err)
+
+	o.PrefixHex = "xx"
+	_, err = HashEventV3(testEventJSON, o)
+	assert.Error(t, err)
+}
+
+// TestVerifyEventV3 tests:
+//
+// 1. the expected hash may be upper or lower case hex, or a digest string.
+// 2. a different hash does not verify, without an error.
+// 3. a malformed expected hash fails with ErrInvalidDigest.
+func TestVerifyEventV3(t *testing.T) {
+	o := &Options{UseNumber: true}
+	digest, err := HashEventV3(testEventJSON, o)
+	require.NoError(t, err)
+
+	for _, expected := range []string{digest, strings.ToUpper(digest), "simplehash:v3:sha256:" + digest} {
+		ok, err := VerifyEventV3(testEventJSON, expected, o)
+		require.NoError(t, err)
+		assert.True(t, ok, expected)
+	}
+
+	ok, err := VerifyEventV3(testEventJSON, strings.Repeat("00", 32), o)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = VerifyEventV3(testEventJSON, "simplehash:v2:sha256:"+digest, o)
+	assert.ErrorIs(t, err, ErrInvalidDigest)
+	_, err = VerifyEventV3(testEventJSON, "", o)
+	assert.ErrorIs(t, err, ErrInvalidDigest)
+}
@@ -0,0 +1,86 @@
+// Package otel reports the simplehash spans and counters to OpenTelemetry. It
+// is a separate module, so that the otel api is only a dependency of the
+// services which are instrumented:
+//
+//	telemetry := otel.New(tracerProvider.Tracer("verifier"), meterProvider.Meter("verifier"))
+//	result, err := h.HashEventsFromJSON(events, simplehash.WithTelemetry(telemetry))
+package otel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Telemetry is a simplehash.Telemetry which starts spans with an otel tracer
+// and adds to int64 counters of an otel meter. It is safe for concurrent use.
+type Telemetry struct {
+	tracer trace.Tracer
+	meter  metric.Meter
+
+	mu       sync.Mutex
+	counters map[string]metric.Int64Counter
+}
+
+var _ simplehash.Telemetry = (*Telemetry)(nil)
+
+// New returns the telemetry reporting to tracer and meter
+func New(tracer trace.Tracer, meter metric.Meter) *Telemetry {
+	return &Telemetry{
+		tracer:   tracer,
+		meter:    meter,
+		counters: map[string]metric.Int64Counter{},
+	}
+}
+
+// StartSpan starts a span named name, as a child of any span in ctx
+func (t *Telemetry) StartSpan(ctx context.Context, name string) (context.Context, simplehash.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, spanAdapter{span}
+}
+
+// AddCount adds n to the counter. The counter is created on first use, if
+// that fails the count is dropped, as for an unavailable meter.
+func (t *Telemetry) AddCount(ctx context.Context, counter string, n int64) {
+	c, err := t.counter(counter)
+	if err != nil {
+		return
+	}
+	c.Add(ctx, n)
+}
+
+func (t *Telemetry) counter(name string) (metric.Int64Counter, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c, ok := t.counters[name]; ok {
+		return c, nil
+	}
+	c, err := t.meter.Int64Counter(name)
+	if err != nil {
+		return nil, err
+	}
+	t.counters[name] = c
+	return c, nil
+}
+
+type spanAdapter struct {
+	span trace.Span
+}
+
+func (s spanAdapter) SetAttribute(key string, value int64) {
+	s.span.SetAttributes(attribute.Int64(key, value))
+}
+
+// End records a non nil err as the status of the span, and ends it
+func (s spanAdapter) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,75 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTelemetry tests:
+//
+// 1. a batch is a span with the batch counts as attributes, and a failed
+// batch has an error status.
+// 2. the events hashed are added to the counters.
+func TestTelemetry(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	telemetry := New(tracerProvider.Tracer("test"), meterProvider.Meter("test"))
+
+	events := [][]byte{
+		[]byte(`{"identity": "assets/1/events/1"}`),
+		[]byte(`{"identity": "assets/1/events/2"}`),
+		[]byte(`not json`),
+	}
+	h := simplehash.NewHasherV3()
+	_, err := h.HashEventsFromJSON(events[:2], simplehash.WithTelemetry(telemetry))
+	require.NoError(t, err)
+	_, err = h.HashEventsFromJSON(events, simplehash.WithTelemetry(telemetry))
+	require.Error(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, simplehash.SpanHashBatch, ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), attribute.Int64("events", 2))
+	assert.Equal(t, codes.Unset, ended[0].Status().Code)
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	counts := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				counts[m.Name] += dp.Value
+			}
+		}
+	}
+	assert.Equal(t, int64(4), counts[simplehash.CounterEventsHashed])
+	assert.Positive(t, counts[simplehash.CounterBytesCanonicalized])
+}
+
+// TestSpan_End tests that ending a span with an error records it.
+func TestSpan_End(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	telemetry := New(tracerProvider.Tracer("test"), sdkmetric.NewMeterProvider().Meter("test"))
+
+	_, span := telemetry.StartSpan(context.Background(), "op")
+	span.End(errors.New("failed"))
+
+	require.Len(t, spans.Ended(), 1)
+	assert.Equal(t, "failed", spans.Ended()[0].Status().Description)
+	require.Len(t, spans.Ended()[0].Events(), 1)
+}
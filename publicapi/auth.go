@@ -0,0 +1,117 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTokenURL is the token endpoint of the datatrails app registrations
+const DefaultTokenURL = "https://app.datatrails.ai/archivist/iam/v1/appidp/token"
+
+// tokenExpiryMargin is how long before its expiry a token is refreshed, so
+// that a token is not presented just as it expires
+const tokenExpiryMargin = 30 * time.Second
+
+var (
+	ErrTokenRequest = errors.New("token request failed")
+)
+
+// TokenProvider provides the bearer token sent with each request
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// ClientCredentials acquires tokens with the OAuth2 client credentials grant.
+// Tokens are cached, and refreshed when they are about to expire. It is safe
+// for concurrent use.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	// HTTPClient is used for token requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+	now     func() time.Time
+}
+
+// NewClientCredentials creates a token provider for the app registration
+// clientID, using DefaultTokenURL
+func NewClientCredentials(clientID string, clientSecret string) *ClientCredentials {
+	return &ClientCredentials{TokenURL: DefaultTokenURL, ClientID: clientID, ClientSecret: clientSecret}
+}
+
+// Token returns the cached token, acquiring a new one if there is none or it
+// is about to expire
+func (c *ClientCredentials) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	if c.token != "" && now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	token, expiresIn, err := c.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expires = now().Add(expiresIn - tokenExpiryMargin)
+	return c.token, nil
+}
+
+func (c *ClientCredentials) requestToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %v", ErrTokenRequest, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("%w: %s", ErrTokenRequest, resp.Status)
+	}
+
+	response := struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", 0, fmt.Errorf("%w: %v", ErrTokenRequest, err)
+	}
+	if response.AccessToken == "" {
+		return "", 0, fmt.Errorf("%w: no access token", ErrTokenRequest)
+	}
+	if response.TokenType != "" && !strings.EqualFold(response.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("%w: unsupported token type %s", ErrTokenRequest, response.TokenType)
+	}
+	return response.AccessToken, time.Duration(response.ExpiresIn) * time.Second, nil
+}
@@ -0,0 +1,110 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTokenServer issues numbered tokens to the client credentials id/secret,
+// counting the requests made
+func newTokenServer(t *testing.T, expiresIn int, requests *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.PostForm.Get("grant_type") != "client_credentials" ||
+			r.PostForm.Get("client_id") != "id" || r.PostForm.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		*requests++
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token%d", *requests),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		}))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestClientCredentials tests:
+//
+// 1. a token is acquired with the client credentials grant and cached.
+// 2. a token about to expire is refreshed.
+// 3. rejected credentials fail with ErrTokenRequest.
+func TestClientCredentials(t *testing.T) {
+	requests := 0
+	server := newTokenServer(t, 3600, &requests)
+	now := time.Now()
+	c := NewClientCredentials("id", "secret")
+	c.TokenURL = server.URL
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	token, err := c.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token1", token)
+	token, err = c.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token1", token)
+	assert.Equal(t, 1, requests)
+
+	now = now.Add(time.Hour - tokenExpiryMargin)
+	token, err = c.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token2", token)
+	assert.Equal(t, 2, requests)
+
+	c = NewClientCredentials("id", "wrong")
+	c.TokenURL = server.URL
+	_, err = c.Token(ctx)
+	assert.ErrorIs(t, err, ErrTokenRequest)
+}
+
+// TestHashEvents_Authenticated tests:
+//
+// 1. with a token provider the permissioned listing is requested with the
+// bearer token.
+// 2. the permissioned events are hashed.
+func TestHashEvents_Authenticated(t *testing.T) {
+	requests := 0
+	tokenServer := newTokenServer(t, 3600, &requests)
+	credentials := NewClientCredentials("id", "secret")
+	credentials.TokenURL = tokenServer.URL
+
+	asset := uuid.New()
+	eventJson, err := simplehash.PermissionedFromPublicJSON(
+		testEventJSON(t, fmt.Sprintf("publicassets/%s/events/%s", asset, uuid.New())))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != fmt.Sprintf("/assets/%s/events", asset) {
+			http.NotFound(w, r)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(ListEventsResponse{Events: []json.RawMessage{eventJson}}))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(WithBaseURL(server.URL), WithTokenProvider(credentials))
+	digests, err := client.HashEvents(context.Background(), asset)
+	require.NoError(t, err)
+	require.Len(t, digests, 1)
+
+	expected, err := simplehash.DigestEventFromJSON(eventJson)
+	require.NoError(t, err)
+	assert.Equal(t, expected, digests[0].Digest)
+}
@@ -0,0 +1,287 @@
+// Package publicapi fetches events from the public event listings of the
+// datatrails api and hashes them, so that third parties can verify public
+// attestations. The public listings need no tenancy authentication.
+//
+// Public events carry publicassets identities. Schema v3 hashes the
+// permissioned form of the identity, so the digests returned by HashEvents
+// match those of the permissioned counterparts of the events.
+//
+// Given a TokenProvider, for example ClientCredentials, the client instead
+// lists the permissioned events of a private tenancy, so that anchors can be
+// reproduced without the caller managing tokens.
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/google/uuid"
+)
+
+// DefaultBaseURL is the base url of the datatrails api
+const DefaultBaseURL = "https://app.datatrails.ai/archivist/v2"
+
+var (
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+	ErrInvalidResponse  = errors.New("invalid response")
+)
+
+// ListEventsResponse is the envelope of a page of an event listing
+type ListEventsResponse struct {
+	Events        []json.RawMessage `json:"events"`
+	NextPageToken string            `json:"next_page_token"`
+}
+
+// EventDigest is the schema v3 digest of a listed event
+type EventDigest struct {
+	// Identity is the identity of the event, as listed
+	Identity string
+	Digest   []byte
+}
+
+// Client fetches public events, or with a TokenProvider the events of a
+// tenancy
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	tokens     TokenProvider
+	retry      RetryPolicy
+	limiter    *rateLimiter
+	progress   ProgressFunc
+	requests   atomic.Int64
+	now        func() time.Time
+	sleep      func(context.Context, time.Duration) error
+}
+
+type ClientOption func(*Client)
+
+// WithBaseURL sets the base url of the api, the default is DefaultBaseURL
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the http client used for requests, the default is
+// http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTokenProvider authenticates requests with the bearer tokens of p. The
+// client then lists the permissioned events of the tenancy, rather than the
+// public events.
+func WithTokenProvider(p TokenProvider) ClientOption {
+	return func(c *Client) {
+		c.tokens = p
+	}
+}
+
+// NewClient creates a client
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{baseURL: DefaultBaseURL, httpClient: http.DefaultClient, now: time.Now, sleep: sleepContext}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ListEvents returns a page of the public events of the asset. uuid.Nil lists
+// the events of every public asset. pageToken is empty for the first page,
+// and is the NextPageToken of the previous page otherwise.
+//
+// With a TokenProvider the permissioned events of the asset are listed.
+func (c *Client) ListEvents(ctx context.Context, asset uuid.UUID, pageToken string) (ListEventsResponse, error) {
+	assetID := "-"
+	if asset != uuid.Nil {
+		assetID = asset.String()
+	}
+	assets := "publicassets"
+	if c.tokens != nil {
+		assets = "assets"
+	}
+	u := fmt.Sprintf("%s/%s/%s/events", c.baseURL, assets, assetID)
+	if pageToken != "" {
+		u += "?" + url.Values{"page_token": {pageToken}}.Encode()
+	}
+
+	page := ListEventsResponse{}
+	if err := c.get(ctx, u, &page); err != nil {
+		return ListEventsResponse{}, err
+	}
+	return page, nil
+}
+
+// get decodes the json response to a GET of u into v. Transient failures
+// are retried according to the RetryPolicy of the client.
+func (c *Client) get(ctx context.Context, u string, v any) error {
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := c.getOnce(ctx, u, v)
+		if err == nil || !c.retry.retryable(ctx, err, attempt) {
+			return err
+		}
+		if err = c.sleep(ctx, c.retry.backoff(attempt, retryAfter)); err != nil {
+			return err
+		}
+	}
+}
+
+// getOnce makes a single attempt at get. Transient failures are returned as
+// a *transientError, along with the Retry-After of the response if any.
+func (c *Client) getOnce(ctx context.Context, u string, v any) (time.Duration, error) {
+	if err := c.wait(ctx); err != nil {
+		return 0, err
+	}
+	c.requests.Add(1)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.tokens != nil {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, &transientError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return retryAfter(resp), &transientError{err}
+		}
+		return 0, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return 0, nil
+}
+
+// Events returns every event of the asset, following the page tokens of the
+// listing. uuid.Nil lists the events of every asset. See ListEvents.
+func (c *Client) Events(ctx context.Context, asset uuid.UUID) ([]json.RawMessage, error) {
+	return c.EventsFrom(ctx, asset, "")
+}
+
+// EventsFrom returns the events of the asset from the page with pageToken
+// onwards, see Events. If a page cannot be fetched the events of the pages
+// before it are returned with a *PageError, from which the listing can be
+// resumed.
+func (c *Client) EventsFrom(ctx context.Context, asset uuid.UUID, pageToken string) ([]json.RawMessage, error) {
+	var events []json.RawMessage
+	err := c.eachPage(ctx, asset, pageToken, func(page ListEventsResponse) error {
+		events = append(events, page.Events...)
+		return nil
+	})
+	return events, err
+}
+
+// HashEvents fetches every event of the asset and returns the schema v3
+// digest of each, in listing order. Events with permissioned identities are
+// not expected in a public listing, nor public identities in a permissioned
+// listing, and fail with ErrInvalidResponse.
+func (c *Client) HashEvents(ctx context.Context, asset uuid.UUID, opts ...simplehash.HashOption) ([]EventDigest, error) {
+	return c.HashEventsFrom(ctx, asset, "", opts...)
+}
+
+// HashEventsFrom hashes the events of the asset from the page with pageToken
+// onwards, see HashEvents. Pages are hashed whole, if a page cannot be
+// fetched or hashed the digests of the pages before it are returned with a
+// *PageError. Resuming from the PageToken of the error hashes every event
+// exactly once.
+func (c *Client) HashEventsFrom(ctx context.Context, asset uuid.UUID, pageToken string, opts ...simplehash.HashOption) ([]EventDigest, error) {
+	var digests []EventDigest
+	err := c.eachPage(ctx, asset, pageToken, func(page ListEventsResponse) error {
+		pageDigests := make([]EventDigest, 0, len(page.Events))
+		for i, eventJson := range page.Events {
+			identity, err := listedIdentity(eventJson, c.tokens == nil)
+			if err != nil {
+				return fmt.Errorf("event %d: %w", i, err)
+			}
+			digest, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+			if err != nil {
+				return fmt.Errorf("event %d: %s: %w", i, identity, err)
+			}
+			pageDigests = append(pageDigests, EventDigest{Identity: identity, Digest: digest})
+		}
+		digests = append(digests, pageDigests...)
+		return nil
+	})
+	return digests, err
+}
+
+// eachPage calls fn with each page of the listing from pageToken onwards,
+// reporting progress after each. Failures are reported as a *PageError for
+// the page.
+func (c *Client) eachPage(ctx context.Context, asset uuid.UUID, pageToken string, fn func(ListEventsResponse) error) error {
+	start := c.now()
+	startRequests := c.requests.Load()
+	progress := Progress{RateLimit: c.rateLimit()}
+	for {
+		page, err := c.ListEvents(ctx, asset, pageToken)
+		if err == nil {
+			err = fn(page)
+		}
+		if err != nil {
+			return &PageError{PageToken: pageToken, Err: err}
+		}
+		if c.progress != nil {
+			progress.Pages++
+			progress.Events += len(page.Events)
+			progress.Requests = int(c.requests.Load() - startRequests)
+			progress.Elapsed = c.now().Sub(start)
+			if progress.Elapsed > 0 {
+				progress.Rate = float64(progress.Requests) / progress.Elapsed.Seconds()
+			}
+			c.progress(progress)
+		}
+		if page.NextPageToken == "" {
+			return nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// listedIdentity returns the identity of a listed event, which must be public
+// if public is true and permissioned otherwise
+func listedIdentity(eventJson []byte, public bool) (string, error) {
+	event := struct {
+		Identity string `json:"identity"`
+	}{}
+	if err := json.Unmarshal(eventJson, &event); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	id, err := simplehash.ParseEventIdentity(event.Identity)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if id.IsPublic != public {
+		return "", fmt.Errorf("%w: %s is not a %s identity", ErrInvalidResponse, event.Identity, identityKind(public))
+	}
+	return event.Identity, nil
+}
+
+func identityKind(public bool) string {
+	if public {
+		return "public"
+	}
+	return "permissioned"
+}
@@ -0,0 +1,124 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEventJSON(t *testing.T, identity string) json.RawMessage {
+	b, err := json.Marshal(map[string]any{
+		"identity":            identity,
+		"asset_identity":      identity[:len("publicassets/")+36],
+		"event_attributes":    map[string]any{"foo": "bar"},
+		"asset_attributes":    map[string]any{},
+		"operation":           "Record",
+		"behaviour":           "RecordEvidence",
+		"timestamp_declared":  "2023-02-23T11:22:33Z",
+		"timestamp_accepted":  "2023-02-23T11:22:33Z",
+		"timestamp_committed": "2023-02-23T11:22:34Z",
+		"principal_accepted":  map[string]any{"issuer": "", "subject": "", "display_name": "", "email": ""},
+		"principal_declared":  map[string]any{"issuer": "", "subject": "", "display_name": "", "email": ""},
+		"tenant_identity":     "tenant/a",
+	})
+	require.NoError(t, err)
+	return b
+}
+
+// newTestServer serves the events as the listing of assetID, one event per
+// page
+func newTestServer(t *testing.T, assetID string, events []json.RawMessage) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/publicassets/%s/events", assetID) {
+			http.NotFound(w, r)
+			return
+		}
+		page := 0
+		if token := r.URL.Query().Get("page_token"); token != "" {
+			_, err := fmt.Sscanf(token, "page%d", &page)
+			require.NoError(t, err)
+		}
+		response := ListEventsResponse{Events: events[page : page+1]}
+		if page+1 < len(events) {
+			response.NextPageToken = fmt.Sprintf("page%d", page+1)
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestHashEvents tests:
+//
+// 1. every page of the listing is fetched, in order.
+// 2. the digest of each public event is that of its permissioned
+// counterpart.
+// 3. uuid.Nil lists the events of every asset.
+func TestHashEvents(t *testing.T) {
+	asset := uuid.New()
+	var events []json.RawMessage
+	for i := 0; i < 3; i++ {
+		events = append(events, testEventJSON(t, fmt.Sprintf("publicassets/%s/events/%s", asset, uuid.New())))
+	}
+
+	server := newTestServer(t, asset.String(), events)
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	digests, err := client.HashEvents(context.Background(), asset)
+	require.NoError(t, err)
+	require.Len(t, digests, len(events))
+	for i, eventJson := range events {
+		permissioned, err := simplehash.PermissionedFromPublicJSON(eventJson)
+		require.NoError(t, err)
+		expected, err := simplehash.DigestEventFromJSON(permissioned)
+		require.NoError(t, err)
+
+		id, err := listedIdentity(eventJson, true)
+		require.NoError(t, err)
+		assert.Equal(t, id, digests[i].Identity)
+		assert.Equal(t, expected, digests[i].Digest)
+	}
+
+	server = newTestServer(t, "-", events)
+	client = NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	all, err := client.HashEvents(context.Background(), uuid.Nil)
+	require.NoError(t, err)
+	assert.Equal(t, digests, all)
+}
+
+// TestHashEvents_Errors tests:
+//
+// 1. a permissioned event in a public listing fails with
+// ErrInvalidResponse.
+// 2. a response other than 200 fails with ErrUnexpectedStatus.
+// 3. a response which is not a listing fails with ErrInvalidResponse.
+func TestHashEvents_Errors(t *testing.T) {
+	asset := uuid.New()
+	permissioned := testEventJSON(t, fmt.Sprintf("publicassets/%s/events/%s", asset, uuid.New()))
+	permissioned, err := simplehash.PermissionedFromPublicJSON(permissioned)
+	require.NoError(t, err)
+
+	server := newTestServer(t, asset.String(), []json.RawMessage{permissioned})
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	_, err = client.HashEvents(context.Background(), asset)
+	assert.ErrorIs(t, err, ErrInvalidResponse)
+
+	_, err = client.HashEvents(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrUnexpectedStatus)
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not json"))
+	}))
+	t.Cleanup(server.Close)
+	client = NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	_, err = client.HashEvents(context.Background(), asset)
+	assert.ErrorIs(t, err, ErrInvalidResponse)
+}
@@ -0,0 +1,101 @@
+package publicapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Progress reports the progress of a listing, after each page
+type Progress struct {
+	// Pages and Events are the number fetched so far
+	Pages  int
+	Events int
+	// Requests is the number of requests made, including retries
+	Requests int
+	Elapsed  time.Duration
+	// Rate is the effective request rate over the listing, in requests per
+	// second
+	Rate float64
+	// RateLimit is the configured limit, see WithRateLimit, or zero
+	RateLimit float64
+}
+
+// ProgressFunc receives the progress of a listing. It is called on the
+// goroutine making the requests.
+type ProgressFunc func(Progress)
+
+// WithProgress reports the progress of listings after each page, so that
+// long verification jobs can display progress and their effective request
+// rate
+func WithProgress(progress ProgressFunc) ClientOption {
+	return func(c *Client) {
+		c.progress = progress
+	}
+}
+
+// WithRateLimit limits requests, including retries, to rate per second with
+// bursts of up to burst requests, so that large verification jobs stay within
+// the platform rate limits. The limit is shared by every listing made with the
+// client. A rate which is not positive removes the limit.
+func WithRateLimit(rate float64, burst int) ClientOption {
+	return func(c *Client) {
+		if rate <= 0 {
+			c.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+	}
+}
+
+// rateLimiter is a token bucket, filled at rate tokens a second up to burst
+// tokens. Each request takes a token.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token, returning how long the caller must wait before
+// using it
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait blocks until the limiter allows a request
+func (c *Client) wait(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	if d := c.limiter.reserve(c.now()); d > 0 {
+		return c.sleep(ctx, d)
+	}
+	return nil
+}
+
+func (c *Client) rateLimit() float64 {
+	if c.limiter == nil {
+		return 0
+	}
+	return c.limiter.rate
+}
@@ -0,0 +1,54 @@
+package publicapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithRateLimit tests:
+//
+// 1. requests beyond the burst wait for the bucket to refill.
+// 2. the bucket refills while requests are not being made.
+// 3. progress is reported after each page, with the effective rate and the
+// limit.
+func TestWithRateLimit(t *testing.T) {
+	asset := uuid.New()
+	events := testEvents(t, asset, 4)
+	server := newFlakyServer(t, asset, events, func(int) int { return http.StatusOK })
+
+	var reports []Progress
+	client := NewClient(WithBaseURL(server.URL), WithRateLimit(2, 2), WithProgress(func(p Progress) {
+		reports = append(reports, p)
+	}))
+	now := time.Unix(0, 0)
+	var waits []time.Duration
+	client.now = func() time.Time { return now }
+	client.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		now = now.Add(d)
+		return nil
+	}
+
+	digests, err := client.HashEvents(context.Background(), asset)
+	require.NoError(t, err)
+	assert.Len(t, digests, len(events))
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}, waits)
+
+	require.Len(t, reports, len(events))
+	last := reports[len(reports)-1]
+	assert.Equal(t, Progress{
+		Pages: 4, Events: 4, Requests: 4, Elapsed: time.Second, Rate: 4, RateLimit: 2,
+	}, last)
+
+	waits = nil
+	now = now.Add(time.Second)
+	_, err = client.HashEvents(context.Background(), asset)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}, waits)
+}
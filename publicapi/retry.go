@@ -0,0 +1,115 @@
+package publicapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryPolicy retries transient failures five times, backing off from
+// one second to a minute
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    6,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+}
+
+// RetryPolicy configures the retry of requests which fail transiently: 429
+// and 5xx responses, and transport errors. The zero policy does not retry.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts made at each request, including
+	// the first
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It doubles for each
+	// subsequent retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts. A longer Retry-After from
+	// the server is also capped.
+	MaxBackoff time.Duration
+}
+
+// PageError reports the page of a listing which could not be fetched or
+// hashed. Listings resumed from PageToken continue with the failed page.
+type PageError struct {
+	PageToken string
+	Err       error
+}
+
+func (e *PageError) Error() string {
+	if e.PageToken == "" {
+		return fmt.Sprintf("first page: %v", e.Err)
+	}
+	return fmt.Sprintf("page %s: %v", e.PageToken, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// WithRetry retries transient failures according to p, see
+// DefaultRetryPolicy. Listings are retried page by page, so a retry never
+// repeats a page which has already been returned.
+func WithRetry(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// transientError marks a failure as worth retrying
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// retryable returns true if a further attempt should be made after err
+func (p RetryPolicy) retryable(ctx context.Context, err error, attempt int) bool {
+	var transient *transientError
+	return attempt < p.MaxAttempts && ctx.Err() == nil && errors.As(err, &transient)
+}
+
+// backoff returns the wait after the attempt, retryAfter takes precedence if
+// the server gave one
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	d := retryAfter
+	if d == 0 {
+		d = p.InitialBackoff
+		for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+			d *= 2
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// retryAfter returns the delay of the Retry-After header of the response,
+// given in seconds, or zero
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
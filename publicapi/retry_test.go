@@ -0,0 +1,150 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer serves the events of the asset one per page, failing
+// requests for which fail returns a status other than 200
+func newFlakyServer(t *testing.T, asset uuid.UUID, events []json.RawMessage, fail func(page int) int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := 0
+		if token := r.URL.Query().Get("page_token"); token != "" {
+			_, err := fmt.Sscanf(token, "page%d", &page)
+			require.NoError(t, err)
+		}
+		if status := fail(page); status != http.StatusOK {
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "7")
+			}
+			w.WriteHeader(status)
+			return
+		}
+		response := ListEventsResponse{Events: events[page : page+1]}
+		if page+1 < len(events) {
+			response.NextPageToken = fmt.Sprintf("page%d", page+1)
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testEvents(t *testing.T, asset uuid.UUID, n int) []json.RawMessage {
+	var events []json.RawMessage
+	for i := 0; i < n; i++ {
+		events = append(events, testEventJSON(t, fmt.Sprintf("publicassets/%s/events/%s", asset, uuid.New())))
+	}
+	return events
+}
+
+// TestWithRetry tests:
+//
+// 1. 5xx responses are retried with exponential backoff, capped at
+// MaxBackoff.
+// 2. the Retry-After of a 429 response is honoured, capped at MaxBackoff.
+// 3. a request which fails MaxAttempts times fails with ErrUnexpectedStatus.
+// 4. other failures, for example 404, are not retried.
+func TestWithRetry(t *testing.T) {
+	asset := uuid.New()
+	events := testEvents(t, asset, 3)
+	failures := 0
+	server := newFlakyServer(t, asset, events, func(page int) int {
+		if page == 1 && failures < 4 {
+			failures++
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+
+	var waits []time.Duration
+	client := NewClient(WithBaseURL(server.URL), WithRetry(RetryPolicy{
+		MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}))
+	client.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	digests, err := client.HashEvents(context.Background(), asset)
+	require.NoError(t, err)
+	assert.Len(t, digests, len(events))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}, waits)
+
+	waits = nil
+	limited := false
+	server = newFlakyServer(t, asset, events, func(page int) int {
+		if !limited {
+			limited = true
+			return http.StatusTooManyRequests
+		}
+		return http.StatusOK
+	})
+	client.baseURL = server.URL
+	_, err = client.HashEvents(context.Background(), asset)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Second}, waits)
+
+	waits = nil
+	server = newFlakyServer(t, asset, events, func(page int) int { return http.StatusBadGateway })
+	client.baseURL = server.URL
+	_, err = client.HashEvents(context.Background(), asset)
+	assert.ErrorIs(t, err, ErrUnexpectedStatus)
+	assert.Len(t, waits, 4)
+
+	waits = nil
+	server = newFlakyServer(t, asset, events, func(page int) int { return http.StatusNotFound })
+	client.baseURL = server.URL
+	_, err = client.HashEvents(context.Background(), asset)
+	assert.ErrorIs(t, err, ErrUnexpectedStatus)
+	assert.Empty(t, waits)
+}
+
+// TestHashEventsFrom tests:
+//
+// 1. a listing which fails returns the digests of the pages before the
+// failed page, and a PageError for it.
+// 2. resuming from the PageToken of the error hashes the remaining events,
+// each event being hashed once.
+func TestHashEventsFrom(t *testing.T) {
+	asset := uuid.New()
+	events := testEvents(t, asset, 4)
+	down := true
+	server := newFlakyServer(t, asset, events, func(page int) int {
+		if page == 2 && down {
+			return http.StatusInternalServerError
+		}
+		return http.StatusOK
+	})
+	client := NewClient(WithBaseURL(server.URL))
+
+	expected, err := client.HashEventsFrom(context.Background(), asset, "page0")
+	require.Error(t, err)
+	assert.Len(t, expected, 2)
+
+	digests, err := client.HashEvents(context.Background(), asset)
+	var pageErr *PageError
+	require.True(t, errors.As(err, &pageErr))
+	assert.Equal(t, "page2", pageErr.PageToken)
+	assert.ErrorIs(t, err, ErrUnexpectedStatus)
+	assert.Equal(t, expected, digests)
+
+	down = false
+	rest, err := client.HashEventsFrom(context.Background(), asset, pageErr.PageToken)
+	require.NoError(t, err)
+	digests = append(digests, rest...)
+
+	all, err := client.HashEvents(context.Background(), asset)
+	require.NoError(t, err)
+	assert.Equal(t, all, digests)
+}
@@ -0,0 +1,82 @@
+package publicapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+var (
+	ErrInvalidURL     = errors.New("invalid event url")
+	ErrInvalidDigest  = errors.New("invalid expected digest")
+	ErrDigestMismatch = errors.New("event does not match the expected digest")
+)
+
+// VerifyPublicEventURL fetches the public event at eventURL, using a default
+// client, and checks its schema v3 digest is expectedHash. See
+// Client.VerifyPublicEventURL.
+func VerifyPublicEventURL(ctx context.Context, eventURL string, expectedHash string, opts ...simplehash.HashOption) error {
+	return NewClient().VerifyPublicEventURL(ctx, eventURL, expectedHash, opts...)
+}
+
+// VerifyPublicEventURL fetches the public event at eventURL and checks its
+// schema v3 digest is expectedHash, which is hex encoded or a self describing
+// v3 sha256 digest string, see simplehash.FormatDigest.
+//
+// eventURL must be https, for example
+//
+//	https://app.datatrails.ai/archivist/v2/publicassets/{uuid}/events/{uuid}
+//
+// and the event it returns must have a public identity. A digest which does
+// not match fails with ErrDigestMismatch.
+func (c *Client) VerifyPublicEventURL(ctx context.Context, eventURL string, expectedHash string, opts ...simplehash.HashOption) error {
+	u, err := url.Parse(eventURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: %s is not https", ErrInvalidURL, eventURL)
+	}
+	expected, err := parseDigest(expectedHash)
+	if err != nil {
+		return err
+	}
+
+	var eventJson json.RawMessage
+	if err = c.get(ctx, u.String(), &eventJson); err != nil {
+		return err
+	}
+	identity, err := listedIdentity(eventJson, true)
+	if err != nil {
+		return err
+	}
+	digest, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", identity, err)
+	}
+	if !bytes.Equal(digest, expected) {
+		return fmt.Errorf("%w: %s has digest %x", ErrDigestMismatch, identity, digest)
+	}
+	return nil
+}
+
+// parseDigest accepts a hex digest or a v3 sha256 digest string
+func parseDigest(s string) ([]byte, error) {
+	if sum, err := hex.DecodeString(s); err == nil && len(sum) != 0 {
+		return sum, nil
+	}
+	d, err := simplehash.ParseDigest(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDigest, err)
+	}
+	if d.Schema != simplehash.SchemaVersionV3 || d.Algorithm != simplehash.DigestAlgorithmSHA256 {
+		return nil, fmt.Errorf("%w: %s is not a v3 sha256 digest", ErrInvalidDigest, s)
+	}
+	return d.Sum, nil
+}
@@ -0,0 +1,55 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyPublicEventURL tests:
+//
+// 1. the event verifies against its hex digest and its digest string.
+// 2. a different digest fails with ErrDigestMismatch.
+// 3. a url which is not https fails with ErrInvalidURL.
+// 4. an unparsable digest fails with ErrInvalidDigest.
+func TestVerifyPublicEventURL(t *testing.T) {
+	identity := fmt.Sprintf("publicassets/%s/events/%s", uuid.New(), uuid.New())
+	eventJson := testEventJSON(t, identity)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+identity {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(eventJson)
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(WithHTTPClient(server.Client()))
+	eventURL := server.URL + "/" + identity
+
+	digest, err := simplehash.DigestEventFromJSON(eventJson)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, client.VerifyPublicEventURL(ctx, eventURL, hex.EncodeToString(digest)))
+	assert.NoError(t, client.VerifyPublicEventURL(ctx, eventURL,
+		simplehash.FormatDigest(simplehash.SchemaVersionV3, simplehash.DigestAlgorithmSHA256, digest)))
+
+	other := append([]byte(nil), digest...)
+	other[0] ^= 1
+	err = client.VerifyPublicEventURL(ctx, eventURL, hex.EncodeToString(other))
+	assert.ErrorIs(t, err, ErrDigestMismatch)
+
+	err = client.VerifyPublicEventURL(ctx, "http://example.com/"+identity, hex.EncodeToString(digest))
+	assert.ErrorIs(t, err, ErrInvalidURL)
+
+	err = client.VerifyPublicEventURL(ctx, eventURL, "not a digest")
+	assert.ErrorIs(t, err, ErrInvalidDigest)
+}
@@ -0,0 +1,43 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// CSVWriter writes a report as RFC 4180 CSV, with a header line of Columns
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter returns a writer of a CSV report to w
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (c *CSVWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write(Columns)
+}
+
+// Write writes the row. Rows are buffered, so an error writing to the
+// underlying writer may not be returned until Close.
+func (c *CSVWriter) Write(row Row) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.w.Write(row.values())
+}
+
+// Close writes the header, if no rows were written, and flushes the report
+func (c *CSVWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,279 @@
+package report
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const (
+	parquetMagic = "PAR1"
+
+	// defaultRowGroupSize is the number of rows buffered before they are
+	// written as a row group
+	defaultRowGroupSize = 64 * 1024
+)
+
+// parquet enumerations, see parquet.thrift in apache/parquet-format
+const (
+	parquetTypeByteArray       = 6
+	parquetRepetitionRequired  = 0
+	parquetConvertedTypeUTF8   = 0
+	parquetEncodingPlain       = 0
+	parquetEncodingRLE         = 3
+	parquetCodecUncompressed   = 0
+	parquetPageTypeDataPage    = 0
+	parquetFileMetaDataVersion = 1
+)
+
+// ParquetWriter writes a report as an Apache Parquet file. Every column is a
+// required UTF8 string, PLAIN encoded and uncompressed, which every parquet
+// reader supports. Rows are buffered and written in row groups of up to 64K
+// rows, the file metadata is written by Close.
+type ParquetWriter struct {
+	w            io.Writer
+	offset       int64
+	rowGroupSize int
+	columns      [][]string
+	groups       []parquetRowGroup
+	err          error
+}
+
+type parquetRowGroup struct {
+	rows   int64
+	chunks []parquetColumnChunk
+}
+
+type parquetColumnChunk struct {
+	offset int64
+	size   int64
+}
+
+// NewParquetWriter returns a writer of a Parquet report to w
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{w: w, rowGroupSize: defaultRowGroupSize, columns: make([][]string, len(Columns))}
+}
+
+// write writes b, counting the offset into the file
+func (p *ParquetWriter) write(b []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.offset == 0 {
+		n, err := io.WriteString(p.w, parquetMagic)
+		p.offset += int64(n)
+		if err != nil {
+			p.err = err
+			return err
+		}
+	}
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	p.err = err
+	return err
+}
+
+// Write buffers the row, writing a row group once enough rows are buffered
+func (p *ParquetWriter) Write(row Row) error {
+	if p.err != nil {
+		return p.err
+	}
+	for i, value := range row.values() {
+		p.columns[i] = append(p.columns[i], value)
+	}
+	if len(p.columns[0]) >= p.rowGroupSize {
+		return p.flush()
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a row group, with a single data page for
+// each column
+func (p *ParquetWriter) flush() error {
+	rows := len(p.columns[0])
+	if rows == 0 {
+		return nil
+	}
+	group := parquetRowGroup{rows: int64(rows)}
+	for i, values := range p.columns {
+		page := make([]byte, 0, len(values)*16)
+		for _, value := range values {
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(value)))
+			page = append(page, value...)
+		}
+
+		t := thriftWriter{}
+		t.begin()
+		t.i32(1, parquetPageTypeDataPage)
+		t.i32(2, int32(len(page)))
+		t.i32(3, int32(len(page)))
+		t.beginStruct(5)
+		t.i32(1, int32(rows))
+		t.i32(2, parquetEncodingPlain)
+		t.i32(3, parquetEncodingRLE)
+		t.i32(4, parquetEncodingRLE)
+		t.end()
+		t.end()
+
+		chunk := parquetColumnChunk{offset: max(p.offset, int64(len(parquetMagic)))}
+		if err := p.write(t.b); err != nil {
+			return err
+		}
+		if err := p.write(page); err != nil {
+			return err
+		}
+		chunk.size = p.offset - chunk.offset
+		group.chunks = append(group.chunks, chunk)
+		p.columns[i] = values[:0]
+	}
+	p.groups = append(p.groups, group)
+	return nil
+}
+
+// Close writes any buffered rows and the file metadata
+func (p *ParquetWriter) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	t := thriftWriter{}
+	t.begin()
+	t.i32(1, parquetFileMetaDataVersion)
+
+	t.list(2, thriftStruct, len(Columns)+1)
+	t.begin()
+	t.string(4, "schema")
+	t.i32(5, int32(len(Columns)))
+	t.end()
+	for _, name := range Columns {
+		t.begin()
+		t.i32(1, parquetTypeByteArray)
+		t.i32(3, parquetRepetitionRequired)
+		t.string(4, name)
+		t.i32(6, parquetConvertedTypeUTF8)
+		t.beginStruct(10) // LogicalType
+		t.beginStruct(1)  // STRING
+		t.end()
+		t.end()
+		t.end()
+	}
+
+	var rows int64
+	for _, group := range p.groups {
+		rows += group.rows
+	}
+	t.i64(3, rows)
+
+	t.list(4, thriftStruct, len(p.groups))
+	for _, group := range p.groups {
+		t.begin()
+		t.list(1, thriftStruct, len(group.chunks))
+		var size int64
+		for i, chunk := range group.chunks {
+			t.begin()
+			t.i64(2, chunk.offset)
+			t.beginStruct(3)
+			t.i32(1, parquetTypeByteArray)
+			t.list(2, thriftI32, 1)
+			t.appendI32(parquetEncodingPlain)
+			t.list(3, thriftBinary, 1)
+			t.appendString(Columns[i])
+			t.i32(4, parquetCodecUncompressed)
+			t.i64(5, group.rows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.end()
+			t.end()
+			size += chunk.size
+		}
+		t.i64(2, size)
+		t.i64(3, group.rows)
+		t.end()
+	}
+	t.end()
+
+	footer := binary.LittleEndian.AppendUint32(t.b, uint32(len(t.b)))
+	footer = append(footer, parquetMagic...)
+	return p.write(footer)
+}
+
+// thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the thrift compact protocol, as used for the parquet
+// metadata. Only the types parquet metadata needs are supported.
+type thriftWriter struct {
+	b []byte
+	// last holds the last field id written to each open struct, as field
+	// ids are delta encoded
+	last []int16
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.b = append(t.b, byte(delta)<<4|typ)
+	} else {
+		t.b = append(t.b, typ)
+		t.b = binary.AppendVarint(t.b, int64(id))
+	}
+	*last = id
+}
+
+// begin starts a struct which is not a field, a list element or the message
+func (t *thriftWriter) begin() {
+	t.last = append(t.last, 0)
+}
+
+// beginStruct starts a struct field
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.begin()
+}
+
+// end ends the innermost struct
+func (t *thriftWriter) end() {
+	t.b = append(t.b, 0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.appendI32(v)
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.b = binary.AppendVarint(t.b, v)
+}
+
+func (t *thriftWriter) string(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.appendString(s)
+}
+
+// list starts a list field of n elements, which are then appended
+func (t *thriftWriter) list(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.b = append(t.b, byte(n)<<4|elem)
+		return
+	}
+	t.b = append(t.b, 0xf0|elem)
+	t.b = binary.AppendUvarint(t.b, uint64(n))
+}
+
+func (t *thriftWriter) appendI32(v int32) {
+	t.b = binary.AppendVarint(t.b, int64(v))
+}
+
+func (t *thriftWriter) appendString(s string) {
+	t.b = binary.AppendUvarint(t.b, uint64(len(s)))
+	t.b = append(t.b, s...)
+}
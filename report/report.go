@@ -0,0 +1,89 @@
+// Package report writes the digests of verified events as rows, in CSV or
+// Parquet, for ingestion into analytics and audit tooling after large
+// verification runs. Every report has the columns
+//
+//	identity, asset_identity, tenant, timestamp_accepted, digest
+//
+// where digest is the lower case hex sha256 digest of the event, as returned
+// by simplehash.DigestEventFromJSON with the same options.
+//
+// The outcomes of verifying events against expected digests are written as
+// json lines, see Verification, so that CI jobs and auditors can consume them
+// without scraping logs.
+package report
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+// Columns are the column names of every report, in order
+var Columns = []string{"identity", "asset_identity", "tenant", "timestamp_accepted", "digest"}
+
+// Row is the report of a single event
+type Row struct {
+	Identity          string
+	AssetIdentity     string
+	Tenant            string
+	TimestampAccepted string
+	Digest            []byte
+}
+
+// values returns the row as strings, in the order of Columns
+func (r Row) values() []string {
+	return []string{r.Identity, r.AssetIdentity, r.Tenant, r.TimestampAccepted, hex.EncodeToString(r.Digest)}
+}
+
+// Writer writes report rows. Close completes the report, it does not close
+// the underlying io.Writer.
+type Writer interface {
+	Write(row Row) error
+	Close() error
+}
+
+// RowFromJSON hashes an api formatted event with opts and returns its row
+func RowFromJSON(eventJson []byte, opts ...simplehash.HashOption) (Row, error) {
+	v3Event, err := simplehash.V3FromEventJSON(eventJson)
+	if err != nil {
+		return Row{}, err
+	}
+	digest, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+	if err != nil {
+		return Row{}, err
+	}
+	return Row{
+		Identity:          v3Event.Identity,
+		AssetIdentity:     assetIdentity(v3Event.Identity),
+		Tenant:            v3Event.TenantIdentity,
+		TimestampAccepted: v3Event.TimestampAccepted,
+		Digest:            digest,
+	}, nil
+}
+
+// assetIdentity returns the asset part of an event identity, or "" if it is
+// not an event identity
+func assetIdentity(identity string) string {
+	asset, _, ok := strings.Cut(identity, "/events/")
+	if !ok {
+		return ""
+	}
+	return asset
+}
+
+// WriteJSON hashes each api formatted event with opts and writes its row to
+// w. w is not closed.
+func WriteJSON(w Writer, events [][]byte, opts ...simplehash.HashOption) error {
+	for i, eventJson := range events {
+		row, err := RowFromJSON(eventJson, opts...)
+		if err != nil {
+			return fmt.Errorf("report event %d: %w", i, err)
+		}
+		if err = w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
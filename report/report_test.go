@@ -0,0 +1,182 @@
+package report
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var events = [][]byte{
+	[]byte(`{"identity": "assets/1/events/1", "tenant_identity": "tenant/1", "timestamp_accepted": "2024-01-31T11:29:19Z"}`),
+	[]byte(`{"identity": "assets/2/events/1", "tenant_identity": "tenant/1", "timestamp_accepted": "2024-01-31T11:30:00Z"}`),
+}
+
+// expectedRows returns the rows of the events, as strings
+func expectedRows(t *testing.T, events [][]byte, opts ...simplehash.HashOption) [][]string {
+	rows := [][]string{}
+	for _, eventJson := range events {
+		v3Event, err := simplehash.V3FromEventJSON(eventJson)
+		require.NoError(t, err)
+		digest, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+		require.NoError(t, err)
+		asset, _, _ := bytes.Cut([]byte(v3Event.Identity), []byte("/events/"))
+		rows = append(rows, []string{
+			v3Event.Identity, string(asset), v3Event.TenantIdentity, v3Event.TimestampAccepted, hex.EncodeToString(digest),
+		})
+	}
+	return rows
+}
+
+// TestCSVWriter tests:
+//
+// 1. the report has a header and a row for each event, with its digest.
+// 2. an empty report has only the header.
+// 3. an event which can not be hashed is reported with its index.
+func TestCSVWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewCSVWriter(&b)
+	require.NoError(t, WriteJSON(w, events, simplehash.WithPrefix([]byte{1})))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&b).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, append([][]string{Columns}, expectedRows(t, events, simplehash.WithPrefix([]byte{1}))...), records)
+
+	b.Reset()
+	require.NoError(t, NewCSVWriter(&b).Close())
+	assert.Equal(t, "identity,asset_identity,tenant,timestamp_accepted,digest\n", b.String())
+
+	err = WriteJSON(NewCSVWriter(&b), [][]byte{events[0], []byte(`{"identity": `)})
+	assert.ErrorContains(t, err, "report event 1")
+}
+
+// TestParquetWriter tests:
+//
+// 1. the file metadata records the schema and the number of rows.
+// 2. each column chunk holds the values of the column, in row order.
+// 3. rows are split into row groups.
+// 4. an empty report is a valid file with no rows.
+func TestParquetWriter(t *testing.T) {
+	many := make([][]byte, 5)
+	for i := range many {
+		many[i] = []byte(fmt.Sprintf(`{"identity": "assets/%d/events/1", "tenant_identity": "tenant/1"}`, i))
+	}
+
+	tests := []struct {
+		name   string
+		events [][]byte
+		groups int
+	}{
+		{name: "rows", events: events, groups: 1},
+		{name: "row groups", events: many, groups: 3},
+		{name: "empty", events: nil, groups: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := NewParquetWriter(&b)
+			w.rowGroupSize = 2
+			require.NoError(t, WriteJSON(w, test.events))
+			require.NoError(t, w.Close())
+
+			file := b.Bytes()
+			require.Equal(t, parquetMagic, string(file[:4]))
+			require.Equal(t, parquetMagic, string(file[len(file)-4:]))
+			footerSize := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+			metadata, _ := readThriftStruct(t, file[len(file)-8-footerSize:len(file)-8])
+
+			assert.Equal(t, int64(len(test.events)), metadata[3])
+			schema := metadata[2].([]any)
+			require.Len(t, schema, len(Columns)+1)
+			for i, name := range Columns {
+				assert.Equal(t, []byte(name), schema[i+1].(map[int16]any)[4])
+			}
+
+			columns := make([][]string, len(Columns))
+			groups := metadata[4].([]any)
+			require.Len(t, groups, test.groups)
+			for _, group := range groups {
+				for i, chunk := range group.(map[int16]any)[1].([]any) {
+					offset := chunk.(map[int16]any)[3].(map[int16]any)[9].(int64)
+					page, n := readThriftStruct(t, file[offset:])
+					values := file[offset+int64(n):]
+					values = values[:page[3].(int64)]
+					for len(values) != 0 {
+						size := binary.LittleEndian.Uint32(values)
+						columns[i] = append(columns[i], string(values[4:4+size]))
+						values = values[4+size:]
+					}
+				}
+			}
+
+			expected := expectedRows(t, test.events)
+			for i := range Columns {
+				require.Len(t, columns[i], len(expected))
+				for j, row := range expected {
+					assert.Equal(t, row[i], columns[i][j])
+				}
+			}
+		})
+	}
+}
+
+// readThriftStruct decodes a thrift compact struct as a map of field id to
+// value, returning the number of bytes read. Only the types written by
+// thriftWriter are supported.
+func readThriftStruct(t *testing.T, b []byte) (map[int16]any, int) {
+	s := map[int16]any{}
+	n := 0
+	var last int16
+	for {
+		header := b[n]
+		n++
+		if header == 0 {
+			return s, n
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			v, size := binary.Varint(b[n:])
+			id = int16(v)
+			n += size
+		}
+		last = id
+		value, size := readThriftValue(t, header&0x0f, b[n:])
+		s[id] = value
+		n += size
+	}
+}
+
+func readThriftValue(t *testing.T, typ byte, b []byte) (any, int) {
+	switch typ {
+	case thriftI32, thriftI64:
+		v, n := binary.Varint(b)
+		return v, n
+	case thriftBinary:
+		size, n := binary.Uvarint(b)
+		return b[n : n+int(size)], n + int(size)
+	case thriftStruct:
+		return readThriftStruct(t, b)
+	case thriftList:
+		elem, count, n := b[0]&0x0f, int(b[0]>>4), 1
+		if count == 15 {
+			size, m := binary.Uvarint(b[1:])
+			count, n = int(size), n+m
+		}
+		list := []any{}
+		for i := 0; i < count; i++ {
+			v, m := readThriftValue(t, elem, b[n:])
+			list = append(list, v)
+			n += m
+		}
+		return list, n
+	}
+	t.Fatalf("unexpected thrift type %d", typ)
+	return nil, 0
+}
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+var (
+	ErrInvalidVerification = errors.New("invalid verification record")
+)
+
+// maxVerificationLineSize bounds the length of a record read from untrusted
+// input
+const maxVerificationLineSize = 1 << 20
+
+// Status is the outcome of verifying a single event
+type Status string
+
+const (
+	// StatusVerified is an event whose digest matches the expected digest
+	StatusVerified Status = "verified"
+	// StatusMismatch is an event whose digest differs from the expected
+	// digest
+	StatusMismatch Status = "mismatch"
+	// StatusMissing is an event with no expected digest
+	StatusMissing Status = "missing"
+	// StatusError is an event which could not be hashed
+	StatusError Status = "error"
+)
+
+// Digest is a digest which is hex encoded in json
+type Digest []byte
+
+// String returns the digest as lower case hex
+func (d Digest) String() string {
+	return hex.EncodeToString(d)
+}
+
+// MarshalText encodes the digest as lower case hex
+func (d Digest) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a hex encoded digest
+func (d *Digest) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*d = b
+	return nil
+}
+
+// Verification is the machine readable outcome of verifying a single event,
+// written one per line by a VerificationWriter:
+//
+//	{"identity":"assets/.../events/...","expected":"<hex>","actual":"<hex>","status":"verified"}
+//
+// Expected is omitted for StatusMissing, Actual for StatusError, and Error is
+// only present for StatusError.
+type Verification struct {
+	Identity string `json:"identity"`
+	Expected Digest `json:"expected,omitempty"`
+	Actual   Digest `json:"actual,omitempty"`
+	Status   Status `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// VerifyEvent hashes an api formatted event with opts and compares its digest
+// with expected, which is nil if there is no expected digest. A failure to
+// hash the event is recorded in the result, with the identity if the event
+// could be decoded.
+func VerifyEvent(eventJson []byte, expected []byte, opts ...simplehash.HashOption) Verification {
+
+	v := Verification{Expected: expected}
+	if v3Event, err := simplehash.V3FromEventJSON(eventJson); err == nil {
+		v.Identity = v3Event.Identity
+	}
+
+	actual, err := simplehash.DigestEventFromJSON(eventJson, opts...)
+	switch {
+	case err != nil:
+		v.Status, v.Error = StatusError, err.Error()
+	case expected == nil:
+		v.Actual, v.Status = actual, StatusMissing
+	case bytes.Equal(expected, actual):
+		v.Actual, v.Status = actual, StatusVerified
+	default:
+		v.Actual, v.Status = actual, StatusMismatch
+		simplehash.AddMismatches(context.Background(), 1, opts...)
+	}
+	return v
+}
+
+// VerificationWriter writes verification records as json lines
+type VerificationWriter struct {
+	w *bufio.Writer
+}
+
+// NewVerificationWriter returns a writer of verification records to w
+func NewVerificationWriter(w io.Writer) *VerificationWriter {
+	return &VerificationWriter{w: bufio.NewWriter(w)}
+}
+
+// Write writes the record as a single line
+func (vw *VerificationWriter) Write(v Verification) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = vw.w.Write(append(b, '\n'))
+	return err
+}
+
+// Flush writes any buffered records to the underlying writer
+func (vw *VerificationWriter) Flush() error {
+	return vw.w.Flush()
+}
+
+// VerificationReader reads verification records written by a
+// VerificationWriter
+type VerificationReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewVerificationReader returns a reader of verification records from r
+func NewVerificationReader(r io.Reader) *VerificationReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxVerificationLineSize)
+	return &VerificationReader{scanner: scanner}
+}
+
+// Read returns the next record, or io.EOF when there are no more. Blank lines
+// are skipped. A record which is not valid json, or has an unknown status,
+// is an error wrapping ErrInvalidVerification.
+func (vr *VerificationReader) Read() (Verification, error) {
+	for vr.scanner.Scan() {
+		vr.line++
+		line := bytes.TrimSpace(vr.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		v := Verification{}
+		if err := json.Unmarshal(line, &v); err != nil {
+			return Verification{}, fmt.Errorf("%w: line %d: %v", ErrInvalidVerification, vr.line, err)
+		}
+		switch v.Status {
+		case StatusVerified, StatusMismatch, StatusMissing, StatusError:
+		default:
+			return Verification{}, fmt.Errorf("%w: line %d: status %q", ErrInvalidVerification, vr.line, v.Status)
+		}
+		return v, nil
+	}
+	if err := vr.scanner.Err(); err != nil {
+		return Verification{}, err
+	}
+	return Verification{}, io.EOF
+}
+
+// ReadVerifications reads all the verification records from r
+func ReadVerifications(r io.Reader) ([]Verification, error) {
+	vr := NewVerificationReader(r)
+	var verifications []Verification
+	for {
+		v, err := vr.Read()
+		if err == io.EOF {
+			return verifications, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		verifications = append(verifications, v)
+	}
+}
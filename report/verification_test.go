@@ -0,0 +1,92 @@
+package report
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyEvent tests:
+//
+// 1. an event matching the expected digest is verified.
+// 2. an event differing from the expected digest is a mismatch.
+// 3. an event with no expected digest is missing.
+// 4. an event which can not be hashed is an error, with its identity.
+func TestVerifyEvent(t *testing.T) {
+	digest, err := simplehash.DigestEventFromJSON(events[0])
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		event    []byte
+		expected []byte
+		status   Status
+	}{
+		{name: "verified", event: events[0], expected: digest, status: StatusVerified},
+		{name: "mismatch", event: events[1], expected: digest, status: StatusMismatch},
+		{name: "missing", event: events[0], status: StatusMissing},
+		{name: "error", event: []byte(`{"identity": "assets/1/events/1", "operation": 1}`), expected: digest, status: StatusError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := VerifyEvent(test.event, test.expected)
+			assert.Equal(t, test.status, v.Status)
+			assert.Equal(t, Digest(test.expected), v.Expected)
+			if test.status == StatusError {
+				assert.Nil(t, v.Actual)
+				assert.NotEmpty(t, v.Error)
+				return
+			}
+			assert.Equal(t, "", v.Error)
+			assert.NotEmpty(t, v.Identity)
+			assert.Len(t, v.Actual, 32)
+		})
+	}
+}
+
+// TestVerificationWriter tests:
+//
+// 1. records read back as written, one per line, with hex digests.
+// 2. records with an unknown status, or invalid json, are rejected.
+func TestVerificationWriter(t *testing.T) {
+	digest, err := simplehash.DigestEventFromJSON(events[0])
+	require.NoError(t, err)
+
+	written := []Verification{
+		VerifyEvent(events[0], digest),
+		VerifyEvent(events[1], digest),
+		VerifyEvent(events[1], nil),
+		VerifyEvent([]byte(`{`), digest),
+	}
+
+	var b bytes.Buffer
+	w := NewVerificationWriter(&b)
+	for _, v := range written {
+		require.NoError(t, w.Write(v))
+	}
+	require.NoError(t, w.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	require.Len(t, lines, len(written))
+	assert.Contains(t, lines[0], `"status":"verified"`)
+	assert.Contains(t, lines[0], `"expected":"`+Digest(digest).String()+`"`)
+
+	read, err := ReadVerifications(&b)
+	require.NoError(t, err)
+	assert.Equal(t, written, read)
+
+	for _, line := range []string{`{"identity": "a", "status": "unknown"}`, `{"identity": `} {
+		r := NewVerificationReader(strings.NewReader("\n" + line + "\n"))
+		_, err = r.Read()
+		assert.ErrorIs(t, err, ErrInvalidVerification)
+		assert.ErrorContains(t, err, "line 2")
+	}
+
+	_, err = NewVerificationReader(strings.NewReader("")).Read()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,162 @@
+package signing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/datatrails/go-datatrails-simplehash/internal/cbor"
+)
+
+// COSE header labels, RFC 9052
+const (
+	HeaderLabelAlgorithm   int64 = 1
+	HeaderLabelContentType int64 = 3
+	HeaderLabelKeyID       int64 = 4
+)
+
+// Private protected header labels describing how a simple hash digest was produced
+const (
+	HeaderLabelSchemaVersion = "simplehash_schema_version"
+	HeaderLabelHashAlgorithm = "simplehash_hash_alg"
+)
+
+// DigestContentType is the content type of a receipt whose payload is a simple hash digest
+const DigestContentType = "application/vnd.datatrails.simplehash.digest"
+
+const coseSign1Tag = 18
+
+var (
+	ErrInvalidCOSE = errors.New("invalid COSE_Sign1 message")
+
+	coseAlgorithms = map[Algorithm]int64{
+		AlgorithmEdDSA: -8,
+		AlgorithmES256: -7,
+		AlgorithmES384: -35,
+	}
+)
+
+// Sign1 is a decoded COSE_Sign1 message
+type Sign1 struct {
+	Protected   map[any]any
+	Unprotected map[any]any
+	Payload     []byte
+	Signature   []byte
+}
+
+// SignCOSESign1 produces a tagged COSE_Sign1 message over payload. The
+// algorithm header is set from the signer, any other protected and
+// unprotected headers are included as provided.
+func SignCOSESign1(signer Signer, protected map[any]any, unprotected map[any]any, payload []byte) ([]byte, error) {
+
+	alg, ok := coseAlgorithms[signer.Algorithm()]
+	if !ok {
+		return nil, fmt.Errorf("%w: no COSE algorithm for %s", ErrUnsupportedKey, signer.Algorithm())
+	}
+
+	headers := map[any]any{HeaderLabelAlgorithm: alg}
+	for k, v := range protected {
+		if k != HeaderLabelAlgorithm {
+			headers[k] = v
+		}
+	}
+	protectedBytes, err := cbor.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	toBeSigned, err := sigStructure(protectedBytes, payload)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(toBeSigned)
+	if err != nil {
+		return nil, err
+	}
+
+	if unprotected == nil {
+		unprotected = map[any]any{}
+	}
+	return cbor.Marshal(cbor.Tag{
+		Number:  coseSign1Tag,
+		Content: []any{protectedBytes, unprotected, payload, signature},
+	})
+}
+
+// VerifyCOSESign1 decodes a COSE_Sign1 message and verifies its signature.
+// The algorithm in the protected header must match the verifier.
+func VerifyCOSESign1(verifier Verifier, message []byte) (Sign1, error) {
+
+	decoded, err := cbor.Unmarshal(message)
+	if err != nil {
+		return Sign1{}, fmt.Errorf("%w: %v", ErrInvalidCOSE, err)
+	}
+	if tag, ok := decoded.(cbor.Tag); ok {
+		if tag.Number != coseSign1Tag {
+			return Sign1{}, fmt.Errorf("%w: tag %d", ErrInvalidCOSE, tag.Number)
+		}
+		decoded = tag.Content
+	}
+
+	items, ok := decoded.([]any)
+	if !ok || len(items) != 4 {
+		return Sign1{}, fmt.Errorf("%w: expected an array of 4 items", ErrInvalidCOSE)
+	}
+	protectedBytes, ok1 := items[0].([]byte)
+	unprotected, ok2 := items[1].(map[any]any)
+	payload, ok3 := items[2].([]byte)
+	signature, ok4 := items[3].([]byte)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return Sign1{}, fmt.Errorf("%w: unexpected item types", ErrInvalidCOSE)
+	}
+
+	protected := map[any]any{}
+	if len(protectedBytes) != 0 {
+		decoded, err = cbor.Unmarshal(protectedBytes)
+		if err != nil {
+			return Sign1{}, fmt.Errorf("%w: protected header: %v", ErrInvalidCOSE, err)
+		}
+		if protected, ok = decoded.(map[any]any); !ok {
+			return Sign1{}, fmt.Errorf("%w: protected header is not a map", ErrInvalidCOSE)
+		}
+	}
+
+	if alg := protected[HeaderLabelAlgorithm]; alg != coseAlgorithms[verifier.Algorithm()] {
+		return Sign1{}, fmt.Errorf("%w: algorithm %v does not match %s", ErrInvalidCOSE, alg, verifier.Algorithm())
+	}
+
+	toBeSigned, err := sigStructure(protectedBytes, payload)
+	if err != nil {
+		return Sign1{}, err
+	}
+	if err = verifier.Verify(toBeSigned, signature); err != nil {
+		return Sign1{}, err
+	}
+
+	return Sign1{
+		Protected:   protected,
+		Unprotected: unprotected,
+		Payload:     payload,
+		Signature:   signature,
+	}, nil
+}
+
+// NewDigestReceipt wraps a simple hash digest, or an anchor root, in a signed
+// COSE_Sign1 receipt. The protected headers record the simple hash schema
+// version and the digest algorithm, and the key id if one is provided.
+func NewDigestReceipt(signer Signer, schemaVersion int, digest []byte, kid []byte) ([]byte, error) {
+
+	protected := map[any]any{
+		HeaderLabelContentType:   DigestContentType,
+		HeaderLabelSchemaVersion: schemaVersion,
+		HeaderLabelHashAlgorithm: "sha256",
+	}
+	if len(kid) != 0 {
+		protected[HeaderLabelKeyID] = kid
+	}
+	return SignCOSESign1(signer, protected, nil, digest)
+}
+
+// sigStructure returns the Sig_structure for a COSE_Sign1 with no external aad
+func sigStructure(protectedBytes []byte, payload []byte) ([]byte, error) {
+	return cbor.Marshal([]any{"Signature1", protectedBytes, []byte{}, payload})
+}
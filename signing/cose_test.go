@@ -0,0 +1,44 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewDigestReceipt tests:
+//
+// 1. a receipt verifies and carries the digest and schema headers.
+// 2. a receipt with a modified payload does not verify.
+func TestNewDigestReceipt(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := NewEd25519Signer(private)
+	require.NoError(t, err)
+	verifier, err := NewEd25519Verifier(public)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("event"))
+
+	receipt, err := NewDigestReceipt(signer, 3, digest[:], []byte("key-1"))
+	require.NoError(t, err)
+
+	sign1, err := VerifyCOSESign1(verifier, receipt)
+	require.NoError(t, err)
+	assert.Equal(t, digest[:], sign1.Payload)
+	assert.Equal(t, int64(3), sign1.Protected[HeaderLabelSchemaVersion])
+	assert.Equal(t, DigestContentType, sign1.Protected[HeaderLabelContentType])
+	assert.Equal(t, []byte("key-1"), sign1.Protected[HeaderLabelKeyID])
+
+	// The payload is the last 32 bytes before the 64 byte signature and its
+	// 2 byte header.
+	tampered := append([]byte(nil), receipt...)
+	tampered[len(tampered)-67] ^= 1
+	_, err = VerifyCOSESign1(verifier, tampered)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
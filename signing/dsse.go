@@ -0,0 +1,131 @@
+package signing
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+// ManifestPayloadType is the DSSE payload type of a simple hash manifest
+const ManifestPayloadType = "application/vnd.datatrails.simplehash.manifest+json"
+
+var (
+	ErrInvalidDSSE = errors.New("invalid DSSE envelope")
+)
+
+// ManifestEntry is the simple hash of a single event
+type ManifestEntry struct {
+	Identity   string `json:"identity"`
+	SimpleHash string `json:"simplehash"`
+}
+
+// Manifest lists the V3 simple hashes of a set of events, in the order provided
+type Manifest struct {
+	SchemaVersion int             `json:"schema_version"`
+	HashAlgorithm string          `json:"hash_alg"`
+	Events        []ManifestEntry `json:"events"`
+}
+
+// Envelope is a DSSE envelope, https://github.com/secure-systems-lab/dsse
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// ExportDSSE hashes each api formatted event individually and returns a
+// signed DSSE envelope whose payload is the json Manifest of the event
+// identities and V3 simple hashes, for in-toto and SLSA tooling.
+//
+// Options: as for simplehash.DigestEventsFromJSON. WithAccumulate, which
+// would make each hash depend on the events before it, is rejected with a
+// *simplehash.OptionError.
+func ExportDSSE(events [][]byte, signer Signer, opts ...simplehash.HashOption) ([]byte, error) {
+
+	digests, err := simplehash.DigestEventsFromJSON(events, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dsse: %w", err)
+	}
+
+	manifest := Manifest{
+		SchemaVersion: 3,
+		HashAlgorithm: "sha256",
+		Events:        make([]ManifestEntry, 0, len(digests)),
+	}
+	for _, d := range digests {
+		manifest.Events = append(manifest.Events, ManifestEntry{
+			Identity:   d.Identity,
+			SimpleHash: hex.EncodeToString(d.Digest),
+		})
+	}
+
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := signer.Sign(preAuthEncoding(ManifestPayloadType, payload))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Envelope{
+		PayloadType: ManifestPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []EnvelopeSignature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	})
+}
+
+// VerifyDSSE checks the envelope has a signature accepted by the verifier and
+// returns the manifest it carries.
+func VerifyDSSE(verifier Verifier, envelope []byte) (Manifest, error) {
+
+	env := Envelope{}
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %v", ErrInvalidDSSE, err)
+	}
+	if env.PayloadType != ManifestPayloadType {
+		return Manifest{}, fmt.Errorf("%w: payload type %q", ErrInvalidDSSE, env.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %v", ErrInvalidDSSE, err)
+	}
+
+	pae := preAuthEncoding(env.PayloadType, payload)
+	verified := false
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if verifier.Verify(pae, sig) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return Manifest{}, ErrInvalidSignature
+	}
+
+	manifest := Manifest{}
+	if err = json.Unmarshal(payload, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %v", ErrInvalidDSSE, err)
+	}
+	return manifest, nil
+}
+
+// preAuthEncoding is the DSSE v1 PAE of the payload type and payload
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	pae := fmt.Appendf(nil, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	return append(pae, payload...)
+}
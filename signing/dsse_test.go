@@ -0,0 +1,69 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportDSSE tests:
+//
+// 1. the envelope verifies and the manifest lists each event's simple hash.
+// 2. a different key does not verify the envelope.
+// 3. WithAccumulate is rejected with an *OptionError.
+// 4. the options apply to decoding each event as well as hashing it.
+func TestExportDSSE(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	events := [][]byte{
+		[]byte(`{"identity":"assets/1234/events/5678","operation":"Record"}`),
+		[]byte(`{"identity":"publicassets/1234/events/9abc","operation":"Record"}`),
+	}
+
+	signer, err := NewEd25519Signer(private)
+	require.NoError(t, err)
+	verifier, err := NewEd25519Verifier(public)
+	require.NoError(t, err)
+	otherVerifier, err := NewEd25519Verifier(other)
+	require.NoError(t, err)
+
+	envelope, err := ExportDSSE(events, signer)
+	require.NoError(t, err)
+
+	manifest, err := VerifyDSSE(verifier, envelope)
+	require.NoError(t, err)
+	require.Len(t, manifest.Events, 2)
+	assert.Equal(t, "assets/1234/events/9abc", manifest.Events[1].Identity)
+
+	h := simplehash.NewHasherV3()
+	require.NoError(t, h.HashEventFromJSON(events[0]))
+	assert.Equal(t, hex.EncodeToString(h.Sum(nil)), manifest.Events[0].SimpleHash)
+
+	_, err = VerifyDSSE(otherVerifier, envelope)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = ExportDSSE(events, signer, simplehash.WithAccumulate())
+	var optionErr *simplehash.OptionError
+	require.True(t, errors.As(err, &optionErr), err)
+	assert.Equal(t, "WithAccumulate", optionErr.Option)
+
+	// numeric attributes need WithUseNumber, which applies to decoding the
+	// identity as well as hashing
+	numeric := [][]byte{[]byte(`{"identity":"assets/1/events/2","event_attributes":{"n":1}}`)}
+	_, err = ExportDSSE(numeric, signer)
+	assert.Error(t, err)
+	envelope, err = ExportDSSE(numeric, signer, simplehash.WithUseNumber())
+	require.NoError(t, err)
+	manifest, err = VerifyDSSE(verifier, envelope)
+	require.NoError(t, err)
+	assert.Equal(t, "assets/1/events/2", manifest.Events[0].Identity)
+}
@@ -0,0 +1,98 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+)
+
+// ECDSASigner signs messages with ES256 (P-256) or ES384 (P-384). The message
+// is hashed with the curve's companion hash, and the signature is the fixed
+// size r || s encoding used by JOSE and COSE.
+type ECDSASigner struct {
+	key *ecdsa.PrivateKey
+	alg Algorithm
+}
+
+func NewECDSASigner(key *ecdsa.PrivateKey) (*ECDSASigner, error) {
+	alg, err := ecdsaAlgorithm(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ECDSASigner{key: key, alg: alg}, nil
+}
+
+func (s *ECDSASigner) Algorithm() Algorithm { return s.alg }
+
+func (s *ECDSASigner) Public() crypto.PublicKey { return &s.key.PublicKey }
+
+func (s *ECDSASigner) Sign(message []byte) ([]byte, error) {
+	r, ss, err := ecdsa.Sign(rand.Reader, s.key, ecdsaDigest(s.alg, message))
+	if err != nil {
+		return nil, err
+	}
+
+	size := ecdsaKeySize(&s.key.PublicKey)
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	ss.FillBytes(signature[size:])
+	return signature, nil
+}
+
+// ECDSAVerifier verifies ES256 and ES384 signatures
+type ECDSAVerifier struct {
+	key *ecdsa.PublicKey
+	alg Algorithm
+}
+
+func NewECDSAVerifier(key *ecdsa.PublicKey) (*ECDSAVerifier, error) {
+	alg, err := ecdsaAlgorithm(key)
+	if err != nil {
+		return nil, err
+	}
+	return &ECDSAVerifier{key: key, alg: alg}, nil
+}
+
+func (v *ECDSAVerifier) Algorithm() Algorithm { return v.alg }
+
+func (v *ECDSAVerifier) Verify(message []byte, signature []byte) error {
+	size := ecdsaKeySize(v.key)
+	if len(signature) != 2*size {
+		return ErrInvalidSignature
+	}
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+	if !ecdsa.Verify(v.key, ecdsaDigest(v.alg, message), r, s) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func ecdsaAlgorithm(key *ecdsa.PublicKey) (Algorithm, error) {
+	switch key.Curve {
+	case elliptic.P256():
+		return AlgorithmES256, nil
+	case elliptic.P384():
+		return AlgorithmES384, nil
+	default:
+		return "", fmt.Errorf("%w: ecdsa curve %s", ErrUnsupportedKey, key.Curve.Params().Name)
+	}
+}
+
+func ecdsaKeySize(key *ecdsa.PublicKey) int {
+	return (key.Curve.Params().BitSize + 7) / 8
+}
+
+func ecdsaDigest(alg Algorithm, message []byte) []byte {
+	if alg == AlgorithmES384 {
+		sum := sha512.Sum384(message)
+		return sum[:]
+	}
+	sum := sha256.Sum256(message)
+	return sum[:]
+}
@@ -0,0 +1,52 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"fmt"
+)
+
+// Ed25519Signer signs messages with pure Ed25519
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer returns the signer for key, which must be
+// ed25519.PrivateKeySize bytes. ed25519.Sign panics for other sizes.
+func NewEd25519Signer(key ed25519.PrivateKey) (*Ed25519Signer, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: ed25519 private key of %d bytes", ErrUnsupportedKey, len(key))
+	}
+	return &Ed25519Signer{key: key}, nil
+}
+
+func (s *Ed25519Signer) Algorithm() Algorithm { return AlgorithmEdDSA }
+
+func (s *Ed25519Signer) Public() crypto.PublicKey { return s.key.Public() }
+
+func (s *Ed25519Signer) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, message), nil
+}
+
+// Ed25519Verifier verifies pure Ed25519 signatures
+type Ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+// NewEd25519Verifier returns the verifier for key, which must be
+// ed25519.PublicKeySize bytes. ed25519.Verify panics for other sizes.
+func NewEd25519Verifier(key ed25519.PublicKey) (*Ed25519Verifier, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: ed25519 public key of %d bytes", ErrUnsupportedKey, len(key))
+	}
+	return &Ed25519Verifier{key: key}, nil
+}
+
+func (v *Ed25519Verifier) Algorithm() Algorithm { return AlgorithmEdDSA }
+
+func (v *Ed25519Verifier) Verify(message []byte, signature []byte) error {
+	if !ed25519.Verify(v.key, message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
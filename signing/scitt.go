@@ -0,0 +1,71 @@
+package signing
+
+import (
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+)
+
+// COSE header label for CWT claims, RFC 9597, and the claim keys used by SCITT
+const (
+	HeaderLabelCWTClaims int64 = 15
+
+	CWTClaimIssuer  int64 = 1
+	CWTClaimSubject int64 = 2
+)
+
+// PreimageContentType is the content type of a statement whose payload is the
+// canonical V3 simple hash pre-image of an event.
+const PreimageContentType = "application/vnd.datatrails.simplehash.v3+bencode"
+
+// StatementPayload selects what a signed statement carries as its payload
+type StatementPayload int
+
+const (
+	// StatementPreimage carries the canonical bencoded event, so the
+	// transparency service and relying parties can see what was attested.
+	StatementPreimage StatementPayload = iota
+	// StatementDigest carries only the V3 simple hash of the event.
+	StatementDigest
+)
+
+// NewV3EventStatement builds a SCITT signed statement attesting to the event.
+// The statement is a COSE_Sign1 whose CWT claims name the issuer and, as the
+// subject, the permissioned event identity. The payload is the canonical
+// pre-image or the digest of the event, as selected by payload. The statement
+// is suitable for registration with a SCITT transparency service.
+func NewV3EventStatement(
+	signer Signer, v3Event simplehash.V3Event, issuer string, kid []byte, payload StatementPayload,
+) ([]byte, error) {
+
+	var err error
+	var content []byte
+	contentType := PreimageContentType
+
+	switch payload {
+	case StatementDigest:
+		h := simplehash.NewHasherV3()
+		if err = h.HashEventFromV3(v3Event); err != nil {
+			return nil, err
+		}
+		content = h.Sum(nil)
+		contentType = DigestContentType
+	default:
+		if content, err = simplehash.V3EncodeEvent(v3Event); err != nil {
+			return nil, err
+		}
+	}
+
+	protected := map[any]any{
+		HeaderLabelContentType:   contentType,
+		HeaderLabelSchemaVersion: 3,
+		HeaderLabelHashAlgorithm: "sha256",
+		HeaderLabelCWTClaims: map[any]any{
+			CWTClaimIssuer:  issuer,
+			CWTClaimSubject: v3Event.Identity,
+		},
+	}
+	if len(kid) != 0 {
+		protected[HeaderLabelKeyID] = kid
+	}
+
+	return SignCOSESign1(signer, protected, nil, content)
+}
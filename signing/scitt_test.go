@@ -0,0 +1,50 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/simplehash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewV3EventStatement tests:
+//
+// 1. the statement verifies and the subject is the event identity.
+// 2. the digest payload is the V3 simple hash of the event.
+func TestNewV3EventStatement(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := NewECDSASigner(key)
+	require.NoError(t, err)
+	verifier, err := NewECDSAVerifier(&key.PublicKey)
+	require.NoError(t, err)
+
+	v3Event, err := simplehash.V3FromEventJSON([]byte(`{"identity":"publicassets/1234/events/5678","operation":"Record"}`))
+	require.NoError(t, err)
+
+	statement, err := NewV3EventStatement(signer, v3Event, "did:web:example.com", nil, StatementPreimage)
+	require.NoError(t, err)
+	sign1, err := VerifyCOSESign1(verifier, statement)
+	require.NoError(t, err)
+
+	preimage, err := simplehash.V3EncodeEvent(v3Event)
+	require.NoError(t, err)
+	assert.Equal(t, preimage, sign1.Payload)
+	claims := sign1.Protected[HeaderLabelCWTClaims].(map[any]any)
+	assert.Equal(t, "assets/1234/events/5678", claims[CWTClaimSubject])
+	assert.Equal(t, "did:web:example.com", claims[CWTClaimIssuer])
+
+	statement, err = NewV3EventStatement(signer, v3Event, "did:web:example.com", nil, StatementDigest)
+	require.NoError(t, err)
+	sign1, err = VerifyCOSESign1(verifier, statement)
+	require.NoError(t, err)
+
+	h := simplehash.NewHasherV3()
+	require.NoError(t, h.HashEventFromV3(v3Event))
+	assert.Equal(t, h.Sum(nil), sign1.Payload)
+	assert.Equal(t, DigestContentType, sign1.Protected[HeaderLabelContentType])
+}
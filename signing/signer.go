@@ -0,0 +1,65 @@
+// Package signing signs and verifies simple hash digests, so that integrators
+// can produce attestable receipts over the digests computed by the simplehash
+// package.
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+)
+
+// Algorithm identifies a signature algorithm, using the JOSE/COSE names
+type Algorithm string
+
+const (
+	AlgorithmEdDSA Algorithm = "EdDSA"
+	AlgorithmES256 Algorithm = "ES256"
+	AlgorithmES384 Algorithm = "ES384"
+)
+
+var (
+	ErrUnsupportedKey   = errors.New("unsupported key type")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Signer signs messages, typically a simple hash digest or batch root.
+type Signer interface {
+	Algorithm() Algorithm
+	Public() crypto.PublicKey
+	Sign(message []byte) ([]byte, error)
+}
+
+// Verifier verifies signatures produced by the corresponding Signer.
+type Verifier interface {
+	Algorithm() Algorithm
+	Verify(message []byte, signature []byte) error
+}
+
+// NewSigner returns the Signer for the private key. ed25519 and ecdsa P-256
+// and P-384 keys are supported.
+func NewSigner(key crypto.PrivateKey) (Signer, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return NewEd25519Signer(k)
+	case *ecdsa.PrivateKey:
+		return NewECDSASigner(k)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+}
+
+// NewVerifier returns the Verifier for the public key. ed25519 and ecdsa
+// P-256 and P-384 keys are supported.
+func NewVerifier(key crypto.PublicKey) (Verifier, error) {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return NewEd25519Verifier(k)
+	case *ecdsa.PublicKey:
+		return NewECDSAVerifier(k)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+}
@@ -0,0 +1,72 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSignVerify tests:
+//
+// 1. each supported key type signs a digest which its verifier accepts.
+// 2. a signature over a different digest is rejected.
+func TestSignVerify(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("event"))
+	other := sha256.Sum256([]byte("other"))
+
+	tests := []struct {
+		name string
+		key  crypto.PrivateKey
+		alg  Algorithm
+	}{
+		{"ed25519", edKey, AlgorithmEdDSA},
+		{"p256", p256Key, AlgorithmES256},
+		{"p384", p384Key, AlgorithmES384},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signer, err := NewSigner(test.key)
+			require.NoError(t, err)
+			assert.Equal(t, test.alg, signer.Algorithm())
+
+			signature, err := signer.Sign(digest[:])
+			require.NoError(t, err)
+
+			verifier, err := NewVerifier(signer.Public())
+			require.NoError(t, err)
+			assert.NoError(t, verifier.Verify(digest[:], signature))
+			assert.ErrorIs(t, verifier.Verify(other[:], signature), ErrInvalidSignature)
+		})
+	}
+}
+
+// TestEd25519_KeySize tests that ed25519 keys of the wrong size are rejected
+// with ErrUnsupportedKey, rather than panicking when used.
+func TestEd25519_KeySize(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = NewEd25519Signer(private[:ed25519.SeedSize])
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+	_, err = NewSigner(ed25519.PrivateKey(nil))
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+
+	_, err = NewEd25519Verifier(public[:16])
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+	_, err = NewVerifier(append(public, 0))
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
@@ -0,0 +1,170 @@
+package simplehash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+)
+
+// FindingKind classifies a problem found by an Analyzer
+type FindingKind string
+
+const (
+	// FindingUnhashable is an event which fails to hash
+	FindingUnhashable FindingKind = "unhashable"
+	// FindingAmbiguous is an event which hashes, but not in the form of an
+	// event returned by the api, so its digest is unlikely to match an anchor
+	// or the digest of another implementation
+	FindingAmbiguous FindingKind = "ambiguous"
+)
+
+// Finding is a single problem with an event
+type Finding struct {
+	// Source names the event, for example its path in an export
+	Source string
+	// Identity is the permissioned identity of the event, if it was decoded
+	Identity string
+	Kind     FindingKind
+	Err      error
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %v", f.Source, f.Kind, f.Err)
+}
+
+// MarshalJSON encodes the finding with its error as a string
+func (f Finding) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Source   string      `json:"source"`
+		Identity string      `json:"identity,omitempty"`
+		Kind     FindingKind `json:"kind"`
+		Error    string      `json:"error"`
+	}{f.Source, f.Identity, f.Kind, f.Err.Error()})
+}
+
+// Analysis is the outcome of analysing a set of events
+type Analysis struct {
+	// Events is the number of events analysed
+	Events   int       `json:"events"`
+	Findings []Finding `json:"findings"`
+}
+
+// OK returns true if no problems were found
+func (a Analysis) OK() bool {
+	return len(a.Findings) == 0
+}
+
+// Count returns the number of findings of the kind
+func (a Analysis) Count(kind FindingKind) int {
+	n := 0
+	for _, f := range a.Findings {
+		if f.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Analyzer checks api formatted events for problems before they are hashed,
+// so that an export can be vetted before an anchor reproduction run starts.
+// An event is unhashable if it can't be decoded or hashed with the options,
+// for example an attribute holding a number without WithUseNumber. An event
+// is ambiguous if it hashes but fails Validate, for example a missing or
+// malformed identity, a malformed timestamp or an attribute value of a type
+// the api never returns, or if its identity has already been added.
+type Analyzer struct {
+	o        HashOptions
+	opts     []HashOption
+	seen     map[string]string
+	analysis Analysis
+}
+
+// NewAnalyzer creates an analyzer for events to be hashed with the options.
+//
+// Options: as for HashEventFromJSON, except WithAccumulate and the batch
+// options which are ignored.
+func NewAnalyzer(opts ...HashOption) *Analyzer {
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Analyzer{o: o, opts: opts, seen: map[string]string{}}
+}
+
+// Add analyses a single event, named by source in any findings
+func (a *Analyzer) Add(source string, eventJson []byte) {
+	a.analysis.Events++
+
+	v3Event, err := v3FromEventJSON(eventJson, a.o)
+	if err != nil {
+		a.add(Finding{Source: source, Kind: FindingUnhashable, Err: err})
+		return
+	}
+	finding := func(kind FindingKind, err error) {
+		a.add(Finding{Source: source, Identity: v3Event.Identity, Kind: kind, Err: err})
+	}
+
+	_, hashErr := HashOfV3(v3Event, withoutBatch(a.opts)...)
+	if hashErr != nil {
+		finding(FindingUnhashable, hashErr)
+	}
+
+	for _, err := range v3Event.validateFields() {
+		finding(FindingAmbiguous, err)
+	}
+	// attribute values which fail to hash are already reported
+	if hashErr == nil {
+		for _, err := range validateAttributes("event_attributes", v3Event.EventAttributes) {
+			finding(FindingAmbiguous, err)
+		}
+		for _, err := range validateAttributes("asset_attributes", v3Event.AssetAttributes) {
+			finding(FindingAmbiguous, err)
+		}
+	}
+
+	if v3Event.Identity == "" {
+		return
+	}
+	if first, ok := a.seen[v3Event.Identity]; ok {
+		finding(FindingAmbiguous, fmt.Errorf("%w: %s, first seen in %s", ErrDuplicateIdentity, v3Event.Identity, first))
+		return
+	}
+	a.seen[v3Event.Identity] = source
+}
+
+func (a *Analyzer) add(f Finding) {
+	a.analysis.Findings = append(a.analysis.Findings, f)
+}
+
+// Analysis returns the findings for the events added so far, in the order
+// they were added
+func (a *Analyzer) Analysis() Analysis {
+	return a.analysis
+}
+
+// AnalyzeEventsFS analyses the api formatted events held one per file in
+// fsys, selected by glob as for HashEventsFS. Findings are named by the path
+// of the event, and are in path order.
+//
+// On cancellation the partial analysis is returned with a *CanceledError.
+func AnalyzeEventsFS(ctx context.Context, fsys fs.FS, glob string, opts ...HashOption) (Analysis, error) {
+
+	paths, err := globFS(fsys, glob)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	a := NewAnalyzer(opts...)
+	for i, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return a.Analysis(), &CanceledError{Processed: i, Err: err}
+		}
+		eventJson, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return a.Analysis(), err
+		}
+		a.Add(path, eventJson)
+	}
+	return a.Analysis(), nil
+}
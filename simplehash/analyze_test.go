@@ -0,0 +1,71 @@
+package simplehash
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	analyzeIdentityA = "assets/cb3e7d92-1d1b-4bd5-8c63-6d5a2f4e0a11/events/4a0b8c1e-2d3f-4e5a-9b6c-7d8e9f0a1b2c"
+	analyzeIdentityB = "publicassets/cb3e7d92-1d1b-4bd5-8c63-6d5a2f4e0a11/events/4a0b8c1e-2d3f-4e5a-9b6c-7d8e9f0a1b2c"
+)
+
+// TestAnalyzeEventsFS tests:
+//
+// 1. a well formed event has no findings.
+// 2. events which fail to decode or hash are unhashable.
+// 3. missing identities, malformed timestamps and attribute values of types
+// the api never returns are ambiguous.
+// 4. the permissioned form of an identity already seen is ambiguous.
+// 5. numbers are ambiguous, rather than unhashable, with WithUseNumber.
+func TestAnalyzeEventsFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/1.json": {Data: []byte(`{"identity":"` + analyzeIdentityA + `","timestamp_accepted":"2023-02-23T11:22:33Z"}`)},
+		"a/2.json": {Data: []byte(`{"identity":`)},
+		"a/3.json": {Data: []byte(`{"identity":"` + analyzeIdentityB + `","event_attributes":{"n":1}}`)},
+		"b/4.json": {Data: []byte(`{"timestamp_accepted":"yesterday","asset_attributes":{"b":true}}`)},
+	}
+
+	analysis, err := AnalyzeEventsFS(context.Background(), fsys, "*.json")
+	require.NoError(t, err)
+	assert.Equal(t, 4, analysis.Events)
+	assert.False(t, analysis.OK())
+
+	type finding struct {
+		source string
+		kind   FindingKind
+	}
+	findings := []finding{}
+	for _, f := range analysis.Findings {
+		findings = append(findings, finding{f.Source, f.Kind})
+	}
+	assert.Equal(t, []finding{
+		{"a/2.json", FindingUnhashable},
+		{"a/3.json", FindingUnhashable},
+		{"a/3.json", FindingAmbiguous},
+		{"b/4.json", FindingAmbiguous},
+		{"b/4.json", FindingAmbiguous},
+		{"b/4.json", FindingAmbiguous},
+	}, findings)
+	assert.ErrorIs(t, analysis.Findings[1].Err, ErrNumberNeedsUseNumber)
+	assert.ErrorIs(t, analysis.Findings[2].Err, ErrDuplicateIdentity)
+	assert.Equal(t, permissionedIdentity(analyzeIdentityB), analysis.Findings[2].Identity)
+	for _, f := range analysis.Findings[3:] {
+		assert.ErrorIs(t, f.Err, ErrInvalidEvent)
+	}
+	assert.Equal(t, 2, analysis.Count(FindingUnhashable))
+	assert.Equal(t, 4, analysis.Count(FindingAmbiguous))
+
+	delete(fsys, "a/1.json")
+	delete(fsys, "a/2.json")
+	delete(fsys, "b/4.json")
+	analysis, err = AnalyzeEventsFS(context.Background(), fsys, "*.json", WithUseNumber())
+	require.NoError(t, err)
+	require.Len(t, analysis.Findings, 1)
+	assert.Equal(t, FindingAmbiguous, analysis.Findings[0].Kind)
+	assert.ErrorIs(t, analysis.Findings[0].Err, ErrInvalidEvent)
+}
@@ -0,0 +1,197 @@
+package simplehash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	attachmentAttributeType = "arc_attachment"
+	attachmentsAttribute    = "arc_attachments"
+)
+
+var (
+	ErrAttachmentHashAlg   = errors.New("unsupported attachment hash algorithm")
+	ErrAttachmentMismatch  = errors.New("attachment content does not match its digest")
+	ErrAttachmentsRejected = errors.New("event attachments failed verification")
+)
+
+// Attachment is a reference to an attachment, and its content digest, carried
+// in the attributes of an event. Attachments are attribute dictionaries with
+// arc_attribute_type "arc_attachment", either as the value of an attribute or
+// as an item in the arc_attachments list.
+type Attachment struct {
+	// Scope is ScopeEventAttributes or ScopeAssetAttributes
+	Scope string
+	// Name is the name of the attribute holding the attachment
+	Name string
+	// Index is the position of the attachment in a list attribute, or -1
+	Index        int
+	BlobIdentity string
+	FileName     string
+	HashAlg      string
+	HashValue    string
+}
+
+// String identifies the attachment within the event
+func (a Attachment) String() string {
+	if a.Index < 0 {
+		return fmt.Sprintf("%s.%s", a.Scope, a.Name)
+	}
+	return fmt.Sprintf("%s.%s[%d]", a.Scope, a.Name, a.Index)
+}
+
+// AttachmentOpener provides the content of an attachment. An error, for
+// example one wrapping fs.ErrNotExist, is recorded as the reason the
+// attachment failed.
+type AttachmentOpener func(a Attachment) (io.ReadCloser, error)
+
+// AttachmentFailure records an attachment which could not be verified
+type AttachmentFailure struct {
+	Attachment Attachment
+	Reason     error
+}
+
+// AttachmentReport is the combined outcome of verifying the attachments of an
+// event and hashing the event
+type AttachmentReport struct {
+	// Verified lists the attachments whose content matched their digest
+	Verified []Attachment
+	// Failed lists the attachments which were missing, unreadable or did not
+	// match their digest
+	Failed []AttachmentFailure
+	// Digest is the V3 digest of the event. It is only set if every
+	// attachment was verified.
+	Digest []byte
+}
+
+// Attachments returns the attachments referenced by the event, ordered by
+// scope, attribute name and list position.
+func (e *V3Event) Attachments() []Attachment {
+	var attachments []Attachment
+	for _, scoped := range []struct {
+		scope      string
+		attributes map[string]any
+	}{
+		{ScopeEventAttributes, e.EventAttributes},
+		{ScopeAssetAttributes, e.AssetAttributes},
+	} {
+		names := make([]string, 0, len(scoped.attributes))
+		for name := range scoped.attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			switch v := scoped.attributes[name].(type) {
+			case map[string]any:
+				if a, ok := attachmentFromAttribute(v); ok {
+					a.Scope, a.Name, a.Index = scoped.scope, name, -1
+					attachments = append(attachments, a)
+				}
+			case []any:
+				for i, item := range v {
+					m, ok := item.(map[string]any)
+					if !ok {
+						continue
+					}
+					if a, ok := attachmentFromAttribute(m); ok {
+						a.Scope, a.Name, a.Index = scoped.scope, name, i
+						attachments = append(attachments, a)
+					}
+				}
+			}
+		}
+	}
+	return attachments
+}
+
+func attachmentFromAttribute(m map[string]any) (Attachment, bool) {
+	if m["arc_attribute_type"] != attachmentAttributeType {
+		return Attachment{}, false
+	}
+	str := func(k string) string {
+		s, _ := m[k].(string)
+		return s
+	}
+	return Attachment{
+		BlobIdentity: str("arc_blob_identity"),
+		FileName:     str("arc_file_name"),
+		HashAlg:      str("arc_blob_hash_alg"),
+		HashValue:    str("arc_blob_hash_value"),
+	}, true
+}
+
+// VerifyAttachment reads the attachment content from r and checks it matches
+// the digest recorded in the event.
+func VerifyAttachment(a Attachment, r io.Reader) error {
+	var h hash.Hash
+	switch strings.ToUpper(strings.ReplaceAll(a.HashAlg, "-", "")) {
+	case "SHA256":
+		h = sha256.New()
+	default:
+		return fmt.Errorf("%w: %q", ErrAttachmentHashAlg, a.HashAlg)
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != strings.ToLower(a.HashValue) {
+		return fmt.Errorf("%w: %s", ErrAttachmentMismatch, a)
+	}
+	return nil
+}
+
+// VerifyAttachmentBytes checks the attachment content matches the digest
+// recorded in the event.
+func VerifyAttachmentBytes(a Attachment, content []byte) error {
+	return VerifyAttachment(a, bytes.NewReader(content))
+}
+
+// HashEventWithAttachments verifies every attachment referenced by the event
+// before hashing the event. The event is only hashed if all of its
+// attachments verify, otherwise the returned error wraps
+// ErrAttachmentsRejected and the report lists the failures.
+//
+// Options: as for HashEventFromV3, except the batch options which are
+// ignored.
+func (h *HasherV3) HashEventWithAttachments(
+	v3Event V3Event, open AttachmentOpener, opts ...HashOption,
+) (AttachmentReport, error) {
+
+	report := AttachmentReport{}
+
+	for _, a := range v3Event.Attachments() {
+		err := verifyOpenedAttachment(a, open)
+		if err != nil {
+			report.Failed = append(report.Failed, AttachmentFailure{Attachment: a, Reason: err})
+			continue
+		}
+		report.Verified = append(report.Verified, a)
+	}
+	if len(report.Failed) != 0 {
+		return report, fmt.Errorf("%w: %d of %d", ErrAttachmentsRejected,
+			len(report.Failed), len(report.Failed)+len(report.Verified))
+	}
+
+	if err := h.HashEventFromV3(v3Event, withoutBatch(opts)...); err != nil {
+		return report, err
+	}
+	report.Digest = h.Sum(nil)
+	return report, nil
+}
+
+func verifyOpenedAttachment(a Attachment, open AttachmentOpener) error {
+	r, err := open(a)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return VerifyAttachment(a, r)
+}
@@ -0,0 +1,75 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHashEventWithAttachments tests:
+//
+// 1. attachments in a list and as a single attribute are found.
+// 2. an event whose attachments all verify is hashed.
+// 3. a missing or altered attachment rejects the event, listing the failures.
+func TestHashEventWithAttachments(t *testing.T) {
+	content := map[string]string{
+		"blobs/1": "first attachment",
+		"blobs/2": "second attachment",
+	}
+	digest := func(s string) string {
+		d := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(d[:])
+	}
+	attachment := func(blob string) map[string]any {
+		return map[string]any{
+			"arc_attribute_type":  "arc_attachment",
+			"arc_blob_identity":   blob,
+			"arc_blob_hash_alg":   "SHA256",
+			"arc_blob_hash_value": digest(content[blob]),
+		}
+	}
+	v3Event := V3Event{
+		Identity: "assets/1/events/2",
+		EventAttributes: map[string]any{
+			"arc_attachments": []any{attachment("blobs/1")},
+			"photo":           attachment("blobs/2"),
+			"foo":             "bar",
+		},
+	}
+
+	open := func(a Attachment) (io.ReadCloser, error) {
+		c, ok := content[a.BlobIdentity]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", a.BlobIdentity, fs.ErrNotExist)
+		}
+		return io.NopCloser(strings.NewReader(c)), nil
+	}
+
+	attachments := v3Event.Attachments()
+	require.Len(t, attachments, 2)
+	assert.Equal(t, "event_attributes.arc_attachments[0]", attachments[0].String())
+	assert.Equal(t, "event_attributes.photo", attachments[1].String())
+
+	h := NewHasherV3()
+	report, err := h.HashEventWithAttachments(v3Event, open)
+	require.NoError(t, err)
+	assert.Len(t, report.Verified, 2)
+	require.NoError(t, h.HashEventFromV3(v3Event))
+	assert.Equal(t, h.Sum(nil), report.Digest)
+
+	content["blobs/1"] = "altered"
+	delete(content, "blobs/2")
+	report, err = h.HashEventWithAttachments(v3Event, open)
+	assert.ErrorIs(t, err, ErrAttachmentsRejected)
+	assert.Nil(t, report.Digest)
+	require.Len(t, report.Failed, 2)
+	assert.ErrorIs(t, report.Failed[0].Reason, ErrAttachmentMismatch)
+	assert.ErrorIs(t, report.Failed[1].Reason, fs.ErrNotExist)
+}
@@ -0,0 +1,329 @@
+package simplehash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// BatchResult describes the outcome of hashing a batch of events
+type BatchResult struct {
+	// Digest is the accumulated hash of every event included in the batch
+	Digest []byte
+	// Count is the number of events included in Digest
+	Count int
+	// Quarantined is the number of events diverted by WithQuarantine
+	Quarantined int
+	// Failed is the number of events skipped by WithContinueOnError
+	Failed int
+	// Duplicates is the number of repeated events skipped by
+	// WithSkipDuplicates. Skipping duplicates does not make a result partial.
+	Duplicates int
+	// Order is the order in which the events were accumulated
+	Order OrderPolicy
+	// Partial is true if any event was excluded from Digest. A partial digest
+	// can not be used to reproduce an anchor.
+	Partial bool
+	// EventDigests maps the identity of each event included in Digest to its
+	// individual digest, when requested with WithEventDigests
+	EventDigests map[string][]byte
+
+	errs       []*EventError
+	identities []string
+}
+
+// EventError is the failure of a single event in a batch
+type EventError struct {
+	// Index is the position of the event in the batch
+	Index int
+	// Identity is the event identity, if it could be determined
+	Identity string
+	Err      error
+}
+
+func (e *EventError) Error() string {
+	if e.Identity == "" {
+		return fmt.Sprintf("batch event %d: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("batch event %d (%s): %v", e.Index, e.Identity, e.Err)
+}
+
+func (e *EventError) Unwrap() error { return e.Err }
+
+// BatchError aggregates the events which failed in a batch run with
+// WithContinueOnError. errors.Is and errors.As examine every event error.
+type BatchError struct {
+	Errors []*EventError
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d events failed, first: %v", len(e.Errors), e.Errors[0])
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// batchErr returns the aggregated event errors of the result, if any
+func (r *BatchResult) batchErr() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: r.errs}
+}
+
+// CanceledError is returned when a batch is stopped by its context. The
+// result returned alongside it covers the events processed before
+// cancellation, and is marked Partial. It unwraps to the context error, so
+// errors.Is(err, context.Canceled) and context.DeadlineExceeded work as
+// expected.
+type CanceledError struct {
+	// Processed is the number of events consumed before cancellation,
+	// including any quarantined
+	Processed int
+	Err       error
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("canceled after %d events: %v", e.Processed, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error { return e.Err }
+
+// QuarantinedEvent is an event which could not be hashed, along with the
+// reason it was rejected.
+type QuarantinedEvent struct {
+	// Index is the position of the event in the batch
+	Index int
+	// Identity is the event identity, if it could be determined
+	Identity string
+	// Event is the raw event data, if available
+	Event []byte
+	// Reason is the decode or validation error
+	Reason error
+}
+
+// QuarantineWriter receives the events diverted from a batch in soft-fail mode.
+type QuarantineWriter interface {
+	Quarantine(q QuarantinedEvent) error
+}
+
+type jsonQuarantineWriter struct {
+	enc *json.Encoder
+}
+
+// NewJSONQuarantineWriter returns a QuarantineWriter which writes one json
+// object per quarantined event to w. The raw event is recorded as a string so
+// that events which are not valid json are preserved.
+func NewJSONQuarantineWriter(w io.Writer) QuarantineWriter {
+	return &jsonQuarantineWriter{enc: json.NewEncoder(w)}
+}
+
+func (w *jsonQuarantineWriter) Quarantine(q QuarantinedEvent) error {
+	reason := ""
+	if q.Reason != nil {
+		reason = q.Reason.Error()
+	}
+	return w.enc.Encode(struct {
+		Index    int    `json:"index"`
+		Identity string `json:"identity,omitempty"`
+		Reason   string `json:"reason"`
+		Event    string `json:"event"`
+	}{
+		Index:    q.Index,
+		Identity: q.Identity,
+		Reason:   reason,
+		Event:    string(q.Event),
+	})
+}
+
+// WithEventDigests records the individual digest of each event in a batch,
+// in BatchResult.EventDigests, keyed by the event identity, as well as the
+// accumulated digest. Callers can then index, store and later spot check
+// individual events without re-running the batch. Each digest is the sha256
+// digest DigestEventFromJSON returns for the event with the same options. If
+// an identity occurs more than once the digest of the last occurrence is
+// kept, see WithDuplicateDetection.
+func WithEventDigests() HashOption {
+	return func(o *HashOptions) {
+		o.eventDigests = true
+	}
+}
+
+// HashEventsFromJSON hashes a batch of api formatted events, in the order
+// provided, accumulating them into a single digest.
+//
+// Options: as for HashEvents
+func (h *HasherV3) HashEventsFromJSON(events [][]byte, opts ...HashOption) (BatchResult, error) {
+	return h.HashEventsFromJSONContext(context.Background(), events, opts...)
+}
+
+// HashEventsFromJSONContext is HashEventsFromJSON, stopping promptly if ctx
+// is done. On cancellation the partial result is returned with a
+// *CanceledError.
+func (h *HasherV3) HashEventsFromJSONContext(ctx context.Context, events [][]byte, opts ...HashOption) (BatchResult, error) {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	decode := func(i int) (string, []byte, V3Event, error) {
+		v3Event, err := v3FromEventJSON(events[i], o)
+		if err != nil {
+			return "", events[i], V3Event{}, err
+		}
+		return v3Event.Identity, events[i], v3Event, nil
+	}
+
+	return h.hashBatch(ctx, len(events), decode, o)
+}
+
+// hashBatch accumulates n events produced by decode. Each event is encoded
+// before anything is written to the hasher, so a rejected event never leaves
+// partial data in the digest.
+func (h *HasherV3) hashBatch(
+	ctx context.Context, n int, decode func(i int) (string, []byte, V3Event, error), o HashOptions,
+) (result BatchResult, err error) {
+
+	if err := o.checkValues(); err != nil {
+		return BatchResult{}, err
+	}
+
+	ctx, span := o.startSpan(ctx, SpanHashBatch)
+	defer func() { endBatchSpan(span, result, err) }()
+
+	o = h.startBatch(o)
+
+	result = BatchResult{Order: o.order}
+
+	next := func(i int) batchEntry {
+		identity, raw, v3Event, err := decode(i)
+		return batchEntry{index: i, identity: identity, raw: raw, v3Event: v3Event, err: err}
+	}
+	if o.order != OrderAsReceived {
+		entries := make([]batchEntry, n)
+		for i := range entries {
+			if err := ctx.Err(); err != nil {
+				return h.cancelBatch(result, 0, err, o)
+			}
+			entries[i] = next(i)
+		}
+		sortBatchEntries(entries, o.order)
+		next = func(i int) batchEntry { return entries[i] }
+	}
+
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return h.cancelBatch(result, i, err, o)
+		}
+		e := next(i)
+		if err := h.hashBatchEvent(&result, e.index, e.identity, e.raw, e.v3Event, e.err, o); err != nil {
+			return result, err
+		}
+		o.reportProgress(i+1, n, e.identity)
+	}
+
+	return h.finishBatch(result, o)
+}
+
+// startBatch resets the hasher, unless the caller is continuing an
+// accumulation, and returns the options to use for each event of the batch.
+func (h *HasherV3) startBatch(o HashOptions) HashOptions {
+	if !o.accumulateHash {
+		h.Reset()
+	}
+	o.accumulateHash = true
+	return o
+}
+
+// finishBatch completes the result of a batch which consumed every event
+func (h *HasherV3) finishBatch(result BatchResult, o HashOptions) (BatchResult, error) {
+	result.Digest = h.hasher.Sum(nil)
+	o.logBatch(result)
+	return result, result.batchErr()
+}
+
+// cancelBatch completes the result of a batch stopped after processed events
+func (h *HasherV3) cancelBatch(result BatchResult, processed int, err error, o HashOptions) (BatchResult, error) {
+	result.Digest = h.hasher.Sum(nil)
+	result.Partial = true
+	o.logBatch(result)
+	return result, errors.Join(&CanceledError{Processed: processed, Err: err}, result.batchErr())
+}
+
+// hashBatchEvent accumulates the i'th event of a batch, or quarantines or
+// records it if it failed to decode or encode. An error is only returned if
+// the batch must stop.
+func (h *HasherV3) hashBatchEvent(
+	result *BatchResult, i int, identity string, raw []byte, v3Event V3Event, err error, o HashOptions,
+) error {
+
+	if err == nil {
+		// The individual digest is taken from the same writes as the
+		// accumulation, so the event is only encoded once.
+		var eventHasher hash.Hash
+		if o.eventDigests || o.digestStore != nil {
+			eventHasher = newSHA256()
+			accumulated := h.hasher
+			h.hasher = multiHash{accumulated, eventHasher}
+			defer func() { h.hasher = accumulated }()
+		}
+		if err = h.applyEventOptions(o, &v3Event); err == nil {
+			err = h.hashV3Event(v3Event, o)
+		}
+		var digest []byte
+		if err == nil && eventHasher != nil {
+			digest = eventHasher.Sum(nil)
+			if o.digestStore != nil {
+				if serr := o.digestStore.Put(v3Event.Identity, digest); serr != nil {
+					return fmt.Errorf("batch event %d: failed to store digest: %w", i, serr)
+				}
+			}
+		}
+		if err == nil {
+			result.Count++
+			if o.recordIdentities {
+				result.identities = append(result.identities, v3Event.Identity)
+			}
+			if o.eventDigests {
+				if result.EventDigests == nil {
+					result.EventDigests = map[string][]byte{}
+				}
+				result.EventDigests[v3Event.Identity] = digest
+			}
+			return nil
+		}
+		if err == errDuplicateSkipped {
+			result.Duplicates++
+			return nil
+		}
+	}
+
+	o.logBatchEvent(i, identity, err, o.quarantine != nil)
+	if o.quarantine == nil {
+		if !o.continueOnError {
+			return fmt.Errorf("batch event %d: %w", i, err)
+		}
+		result.errs = append(result.errs, &EventError{Index: i, Identity: identity, Err: err})
+		result.Failed++
+		result.Partial = true
+		return nil
+	}
+
+	if qerr := o.quarantine.Quarantine(QuarantinedEvent{
+		Index: i, Identity: identity, Event: raw, Reason: err,
+	}); qerr != nil {
+		return fmt.Errorf("batch event %d: failed to quarantine: %w", i, qerr)
+	}
+	result.Quarantined++
+	result.Partial = true
+	return nil
+}
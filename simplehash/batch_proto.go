@@ -0,0 +1,53 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"context"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+)
+
+// HashEvents hashes a batch of events, in the order provided, accumulating
+// them into a single digest. The hasher is reset before the first event unless
+// WithAccumulate is set, in which case the batch continues any accumulation
+// already in progress.
+//
+// Options: as for HashEvent, and additionally
+//   - WithQuarantine divert events which fail to decode to the provided
+//     writer and continue the batch. The result is marked Partial.
+//   - WithContinueOnError skip events which fail, returning the digest of
+//     the remaining events, marked Partial, with a *BatchError listing the
+//     failures. WithQuarantine takes precedence.
+//   - WithDuplicateDetection or WithSkipDuplicates reject or skip events
+//     whose identity has already been hashed.
+//   - WithOrder sort the events before accumulating them.
+//   - WithProgress report progress after each event.
+//   - WithEventDigests record the individual digest of each event.
+//   - WithDigestStore save the individual digest of each event to a DigestStore.
+//   - WithLogger log failed events and a summary of the batch.
+func (h *HasherV3) HashEvents(events []*v2assets.EventResponse, opts ...HashOption) (BatchResult, error) {
+	return h.HashEventsContext(context.Background(), events, opts...)
+}
+
+// HashEventsContext is HashEvents, stopping promptly if ctx is done. On
+// cancellation the partial result is returned with a *CanceledError.
+func (h *HasherV3) HashEventsContext(
+	ctx context.Context, events []*v2assets.EventResponse, opts ...HashOption,
+) (BatchResult, error) {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	decode := func(i int) (string, []byte, V3Event, error) {
+		v3Event, err := V3FromEventResponse(h.marshaler, events[i])
+		if err != nil {
+			return events[i].GetIdentity(), nil, V3Event{}, err
+		}
+		return v3Event.Identity, nil, v3Event, nil
+	}
+
+	return h.hashBatch(ctx, len(events), decode, o)
+}
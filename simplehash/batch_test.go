@@ -0,0 +1,235 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHasherV3_HashEvents tests:
+//
+// 1. a batch of proto events produces the same digest as accumulating them one at a time.
+func TestHasherV3_HashEvents(t *testing.T) {
+	h := NewHasherV3()
+
+	result, err := h.HashEvents(validEventsV2)
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedHashAllV3, hex.EncodeToString(result.Digest))
+	assert.Equal(t, 2, result.Count)
+	assert.False(t, result.Partial)
+}
+
+// TestHasherV3_HashEventsFromJSON tests:
+//
+// 1. without quarantine, a bad event fails the batch.
+// 2. with quarantine, a bad event is diverted and the result is partial.
+func TestHasherV3_HashEventsFromJSON(t *testing.T) {
+	marshaler := NewEventMarshaler()
+
+	var events [][]byte
+	for _, event := range validEventsV2 {
+		eventJson, err := marshaler.Marshal(event)
+		require.NoError(t, err)
+		events = append(events, eventJson)
+	}
+	dirty := [][]byte{events[0], []byte(`{"identity": `), events[1]}
+
+	t.Run("strict", func(t *testing.T) {
+		h := NewHasherV3()
+		_, err := h.HashEventsFromJSON(dirty)
+		assert.Error(t, err)
+	})
+
+	t.Run("quarantine", func(t *testing.T) {
+		h := NewHasherV3()
+		var quarantined bytes.Buffer
+
+		result, err := h.HashEventsFromJSON(dirty, WithQuarantine(NewJSONQuarantineWriter(&quarantined)))
+		require.NoError(t, err)
+
+		assert.Equal(t, expectedHashAllV3, hex.EncodeToString(result.Digest))
+		assert.Equal(t, 2, result.Count)
+		assert.Equal(t, 1, result.Quarantined)
+		assert.True(t, result.Partial)
+		assert.Contains(t, quarantined.String(), `"index":1`)
+	})
+}
+
+// TestHasherV3_HashEventsContext tests:
+//
+// 1. a canceled batch stops promptly with a partial result and a typed error.
+// 2. the partial digest covers the events processed before cancellation.
+func TestHasherV3_HashEventsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// cancel once the first event has been quarantined
+	quarantine := quarantineFunc(func(q QuarantinedEvent) error {
+		cancel()
+		return nil
+	})
+	events := [][]byte{[]byte(`{`), []byte(`{"identity": "assets/1/events/2"}`)}
+
+	h := NewHasherV3()
+	result, err := h.HashEventsFromJSONContext(ctx, events, WithQuarantine(quarantine))
+
+	var canceled *CanceledError
+	require.ErrorAs(t, err, &canceled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, canceled.Processed)
+	assert.True(t, result.Partial)
+	assert.Equal(t, 0, result.Count)
+	assert.Equal(t, 1, result.Quarantined)
+
+	empty := NewHasherV3()
+	assert.Equal(t, empty.Sum(nil), result.Digest)
+
+	_, err = HashInventoryFromJSONContext(ctx, events)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+type quarantineFunc func(q QuarantinedEvent) error
+
+func (f quarantineFunc) Quarantine(q QuarantinedEvent) error { return f(q) }
+
+// TestWithProgress tests:
+//
+// 1. progress is reported after every event, including quarantined events.
+func TestWithProgress(t *testing.T) {
+	events := [][]byte{
+		[]byte(`{"identity": "assets/1/events/1"}`),
+		[]byte(`{`),
+		[]byte(`{"identity": "assets/1/events/3"}`),
+	}
+
+	type progress struct {
+		done, total int
+		identity    string
+	}
+	var reported []progress
+
+	h := NewHasherV3()
+	_, err := h.HashEventsFromJSON(events,
+		WithQuarantine(quarantineFunc(func(QuarantinedEvent) error { return nil })),
+		WithProgress(func(done, total int, identity string) {
+			reported = append(reported, progress{done, total, identity})
+		}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []progress{
+		{1, 3, "assets/1/events/1"},
+		{2, 3, ""},
+		{3, 3, "assets/1/events/3"},
+	}, reported)
+}
+
+// TestWithContinueOnError tests:
+//
+// 1. failed events are skipped and the digest covers the remaining events.
+// 2. the failures are aggregated, with their index and identity.
+// 3. inventories continue past failures in the same way.
+func TestWithContinueOnError(t *testing.T) {
+	events := [][]byte{
+		[]byte(`{"identity": "assets/1/events/1"}`),
+		[]byte(`{"identity": "assets/1/events/2", "event_attributes": {"n": 1.5}}`),
+		[]byte(`{`),
+		[]byte(`{"identity": "assets/1/events/4"}`),
+	}
+
+	h := NewHasherV3()
+	result, err := h.HashEventsFromJSON(events, WithContinueOnError())
+
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr.Errors, 2)
+	assert.Equal(t, 1, batchErr.Errors[0].Index)
+	assert.Equal(t, "assets/1/events/2", batchErr.Errors[0].Identity)
+	assert.Equal(t, 2, batchErr.Errors[1].Index)
+	assert.ErrorIs(t, err, ErrNonIntegerNumber)
+
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, 2, result.Failed)
+	assert.True(t, result.Partial)
+
+	expected := NewHasherV3()
+	_, err = expected.HashEventsFromJSON([][]byte{events[0], events[3]})
+	require.NoError(t, err)
+	assert.Equal(t, expected.Sum(nil), result.Digest)
+
+	inventory, err := HashInventoryFromJSON(events, WithContinueOnError())
+	require.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 2)
+	assert.Len(t, inventory, 2)
+}
+
+// TestWithDuplicateDetection tests:
+//
+// 1. a repeated identity in a batch is rejected.
+// 2. with skipping, the repeat is dropped and the digest is as if it was never seen.
+// 3. tracking spans single event accumulation and is cleared by Reset.
+func TestWithDuplicateDetection(t *testing.T) {
+	first := []byte(`{"identity": "assets/1/events/1"}`)
+	second := []byte(`{"identity": "assets/1/events/2"}`)
+	duplicated := [][]byte{first, second, first}
+
+	h := NewHasherV3()
+	_, err := h.HashEventsFromJSON(duplicated, WithDuplicateDetection())
+	assert.ErrorIs(t, err, ErrDuplicateIdentity)
+
+	result, err := h.HashEventsFromJSON(duplicated, WithSkipDuplicates())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, 1, result.Duplicates)
+	assert.False(t, result.Partial)
+
+	expected := NewHasherV3()
+	_, err = expected.HashEventsFromJSON([][]byte{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, expected.Sum(nil), result.Digest)
+
+	h.Reset()
+	require.NoError(t, h.HashEventFromJSON(first, WithAccumulate(), WithDuplicateDetection()))
+	assert.ErrorIs(t, h.HashEventFromJSON(first, WithAccumulate(), WithDuplicateDetection()), ErrDuplicateIdentity)
+	h.Reset()
+	assert.NoError(t, h.HashEventFromJSON(first, WithAccumulate(), WithDuplicateDetection()))
+}
+
+// TestWithEventDigests tests:
+//
+// 1. the batch digest is unchanged by recording the event digests.
+// 2. each event digest is the digest of the event hashed alone, with the
+// same prefix.
+// 3. failed events have no event digest.
+func TestWithEventDigests(t *testing.T) {
+	marshaler := NewEventMarshaler()
+
+	var events [][]byte
+	for _, event := range validEventsV2 {
+		eventJson, err := marshaler.Marshal(event)
+		require.NoError(t, err)
+		events = append(events, eventJson)
+	}
+	events = append(events, []byte(`{"identity": `))
+
+	h := NewHasherV3()
+	expected, err := h.HashEventsFromJSON(events, WithPrefix([]byte{1}), WithContinueOnError())
+	require.Error(t, err)
+
+	result, err := h.HashEventsFromJSON(events, WithPrefix([]byte{1}), WithContinueOnError(), WithEventDigests())
+	require.Error(t, err)
+	assert.Equal(t, expected.Digest, result.Digest)
+	assert.Nil(t, expected.EventDigests)
+
+	require.Len(t, result.EventDigests, len(validEventsV2))
+	for i, event := range validEventsV2 {
+		digest, err := DigestEventFromJSON(events[i], WithPrefix([]byte{1}))
+		require.NoError(t, err)
+		assert.Equal(t, digest, result.EventDigests[event.Identity])
+	}
+}
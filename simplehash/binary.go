@@ -0,0 +1,198 @@
+package simplehash
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// binaryFormatVersion is the first byte of the binary encoding of events, so
+// snapshots cached by an incompatible version are rejected rather than
+// misread
+const binaryFormatVersion = 3
+
+var (
+	ErrInvalidBinary = errors.New("invalid binary event encoding")
+)
+
+func init() {
+	// Attribute values are wrapped as binaryValue in the encoded maps, and
+	// scalars are held in its any field, gob needs them registered to encode
+	// and decode them.
+	gob.Register(binaryValue{})
+	gob.Register(json.Number(""))
+	gob.Register(Redacted{})
+}
+
+// binaryKind is the kind of value held by a binaryValue
+type binaryKind uint8
+
+const (
+	binaryScalar binaryKind = iota
+	binaryMap
+	binaryList
+)
+
+// binaryValue holds a decoded json value in a form gob round trips exactly.
+// Gob decodes an empty list held in an any as nil, and a nil map as empty,
+// and the two hash differently, so maps and lists record whether they are
+// nil.
+type binaryValue struct {
+	Kind   binaryKind
+	Nil    bool
+	Scalar any
+	Map    map[string]binaryValue
+	List   []binaryValue
+}
+
+func toBinaryValue(v any) binaryValue {
+	switch x := v.(type) {
+	case map[string]any:
+		b := binaryValue{Kind: binaryMap, Nil: x == nil, Map: make(map[string]binaryValue, len(x))}
+		for k, vv := range x {
+			b.Map[k] = toBinaryValue(vv)
+		}
+		return b
+	case []any:
+		b := binaryValue{Kind: binaryList, Nil: x == nil, List: make([]binaryValue, 0, len(x))}
+		for _, vv := range x {
+			b.List = append(b.List, toBinaryValue(vv))
+		}
+		return b
+	default:
+		return binaryValue{Scalar: v}
+	}
+}
+
+func (b binaryValue) value() any {
+	switch b.Kind {
+	case binaryMap:
+		if b.Nil {
+			return map[string]any(nil)
+		}
+		m := make(map[string]any, len(b.Map))
+		for k, vv := range b.Map {
+			m[k] = vv.value()
+		}
+		return m
+	case binaryList:
+		if b.Nil {
+			return []any(nil)
+		}
+		l := make([]any, 0, len(b.List))
+		for _, vv := range b.List {
+			l = append(l, vv.value())
+		}
+		return l
+	default:
+		return b.Scalar
+	}
+}
+
+// toBinaryMap wraps each value of the attribute or principal map. Gob
+// preserves nil and empty maps held directly in the event fields, so the map
+// itself is not wrapped.
+func toBinaryMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	wrapped := make(map[string]any, len(m))
+	for k, v := range m {
+		wrapped[k] = toBinaryValue(v)
+	}
+	return wrapped
+}
+
+func fromBinaryMap(m map[string]any) (map[string]any, error) {
+	for k, v := range m {
+		b, ok := v.(binaryValue)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has unexpected type %T", ErrInvalidBinary, k, v)
+		}
+		m[k] = b.value()
+	}
+	return m, nil
+}
+
+// v3EventBinary and v2EventBinary have the fields, but not the methods, of
+// the events, so gob encodes them field by field rather than calling
+// MarshalBinary.
+type (
+	v3EventBinary V3Event
+	v2EventBinary V2Event
+)
+
+// MarshalBinary encodes the event with gob, so that decoded events can be
+// cached between the fetch and hash phases of large jobs. The attribute and
+// principal values keep their types, including nil and empty maps and lists,
+// which hash differently, so a decoded event hashes as the original.
+func (e V3Event) MarshalBinary() ([]byte, error) {
+	toBinaryMaps(&e.EventAttributes, &e.AssetAttributes, &e.PrincipalAccepted, &e.PrincipalDeclared)
+	return marshalBinary(v3EventBinary(e))
+}
+
+// UnmarshalBinary decodes an event encoded by MarshalBinary, replacing every
+// field of e
+func (e *V3Event) UnmarshalBinary(data []byte) error {
+	*e = V3Event{}
+	if err := unmarshalBinary(data, (*v3EventBinary)(e)); err != nil {
+		return err
+	}
+	return fromBinaryMaps(&e.EventAttributes, &e.AssetAttributes, &e.PrincipalAccepted, &e.PrincipalDeclared)
+}
+
+// MarshalBinary encodes the event with gob, see V3Event.MarshalBinary
+func (e V2Event) MarshalBinary() ([]byte, error) {
+	toBinaryMaps(&e.EventAttributes, &e.AssetAttributes, &e.PrincipalAccepted, &e.PrincipalDeclared)
+	return marshalBinary(v2EventBinary(e))
+}
+
+// UnmarshalBinary decodes an event encoded by MarshalBinary, replacing every
+// field of e
+func (e *V2Event) UnmarshalBinary(data []byte) error {
+	*e = V2Event{}
+	if err := unmarshalBinary(data, (*v2EventBinary)(e)); err != nil {
+		return err
+	}
+	return fromBinaryMaps(&e.EventAttributes, &e.AssetAttributes, &e.PrincipalAccepted, &e.PrincipalDeclared)
+}
+
+// toBinaryMaps replaces the maps with wrapped copies, the maps of the
+// caller are not modified
+func toBinaryMaps(maps ...*map[string]any) {
+	for _, m := range maps {
+		*m = toBinaryMap(*m)
+	}
+}
+
+func fromBinaryMaps(maps ...*map[string]any) error {
+	for _, m := range maps {
+		unwrapped, err := fromBinaryMap(*m)
+		if err != nil {
+			return err
+		}
+		*m = unwrapped
+	}
+	return nil
+}
+
+func marshalBinary(event any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(binaryFormatVersion)
+	if err := gob.NewEncoder(&buf).Encode(event); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalBinary(data []byte, event any) error {
+	if len(data) == 0 || data[0] != binaryFormatVersion {
+		return fmt.Errorf("%w: unsupported format version", ErrInvalidBinary)
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data[1:])).Decode(event); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBinary, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMarshalBinary tests:
+//
+// 1. V3 and V2 events round trip, including nested attribute values, numbers
+// decoded with WithUseNumber, and nil and empty maps and lists at any depth,
+// and hash identically.
+// 2. events nested in other gob encoded values round trip.
+// 3. unmarshaling replaces every field of the target event.
+// 4. data of another format version is rejected with ErrInvalidBinary.
+func TestMarshalBinary(t *testing.T) {
+	eventJson, err := NewEventMarshaler().Marshal(validEventsV2[0])
+	require.NoError(t, err)
+	v3Event, err := V3FromEventJSON(eventJson)
+	require.NoError(t, err)
+	v3Event.EventAttributes["list"] = []any{map[string]any{"a": "b"}}
+	v3Event.EventAttributes["count"] = json.Number("12")
+	v3Event.EventAttributes["nested"] = map[string]any{
+		"empty_list": []any{}, "nil_list": []any(nil), "empty_map": map[string]any{}, "nil_map": map[string]any(nil),
+		"lists": []any{[]any{}, map[string]any{}, nil}, "flag": true,
+	}
+	v3Event.AssetAttributes = map[string]any{"l": []any{}}
+	v3Event.PrincipalAccepted = map[string]any{}
+	v3Event.PrincipalDeclared = nil
+
+	data, err := v3Event.MarshalBinary()
+	require.NoError(t, err)
+	decoded := V3Event{Operation: "stale", PrincipalDeclared: map[string]any{}}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, v3Event, decoded)
+	assert.NotNil(t, decoded.AssetAttributes["l"])
+	assert.NotNil(t, decoded.PrincipalAccepted)
+	assert.Nil(t, decoded.PrincipalDeclared)
+	assert.Nil(t, decoded.EventAttributes["nested"].(map[string]any)["nil_list"])
+	assert.NotNil(t, decoded.EventAttributes["nested"].(map[string]any)["empty_list"])
+
+	expected, err := HashOfV3(v3Event, WithUseNumber())
+	require.NoError(t, err)
+	digest, err := HashOfV3(decoded, WithUseNumber())
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	v2Event, err := V2FromEventJSON(eventJson)
+	require.NoError(t, err)
+	data, err = v2Event.MarshalBinary()
+	require.NoError(t, err)
+	decodedV2 := V2Event{}
+	require.NoError(t, decodedV2.UnmarshalBinary(data))
+	assert.Equal(t, v2Event, decodedV2)
+
+	var buf bytes.Buffer
+	snapshot := struct{ Events []V3Event }{Events: []V3Event{v3Event, decoded}}
+	require.NoError(t, gob.NewEncoder(&buf).Encode(snapshot))
+	snapshot.Events = nil
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&snapshot))
+	assert.Equal(t, []V3Event{v3Event, v3Event}, snapshot.Events)
+
+	data[0] = binaryFormatVersion + 1
+	assert.ErrorIs(t, decoded.UnmarshalBinary(data), ErrInvalidBinary)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(nil), ErrInvalidBinary)
+}
@@ -0,0 +1,169 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	ErrInvalidBloomFilter = errors.New("invalid bloom filter")
+)
+
+const (
+	bloomMagic   = "SHBLM"
+	bloomVersion = 1
+
+	// maxBloomBits bounds the filter read from untrusted input, 1GiB of bits
+	maxBloomBits = 1 << 33
+)
+
+// BloomFilter is a compact, probabilistic index over event digests. It
+// answers "have we already committed this event?" without storing every
+// digest: MayContain is always true for a digest which was added, and is
+// false for a digest which was not added except with the false positive rate
+// the filter was sized for. A positive answer must be confirmed against the
+// full record, for example a HashSet, before it is relied on.
+//
+// Event digests are uniformly distributed, so the bit positions are derived
+// directly from the digest by double hashing rather than by rehashing it.
+type BloomFilter struct {
+	bits   []uint64
+	m      uint64
+	k      uint32
+	inputs uint64
+}
+
+// NewBloomFilter creates a filter sized for n digests with the given false
+// positive rate, for example 0.001.
+func NewBloomFilter(n int, falsePositiveRate float64) (*BloomFilter, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: capacity %d", ErrInvalidBloomFilter, n)
+	}
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		return nil, fmt.Errorf("%w: false positive rate %v", ErrInvalidBloomFilter, falsePositiveRate)
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+	return newBloomFilter(m, k), nil
+}
+
+func newBloomFilter(m uint64, k uint32) *BloomFilter {
+	return &BloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// BloomFilter builds a filter over the digests of the set
+func (s HashSet) BloomFilter(falsePositiveRate float64) (*BloomFilter, error) {
+	f, err := NewBloomFilter(max(len(s.Digests), 1), falsePositiveRate)
+	if err != nil {
+		return nil, err
+	}
+	for _, digest := range s.Digests {
+		f.Add(digest)
+	}
+	return f, nil
+}
+
+// bloomSeeds returns the double hashing seeds for the digest. Digests too
+// short to provide them are hashed first.
+func bloomSeeds(digest []byte) (uint64, uint64) {
+	if len(digest) < 16 {
+		sum := sha256.Sum256(digest)
+		digest = sum[:]
+	}
+	return binary.BigEndian.Uint64(digest[:8]), binary.BigEndian.Uint64(digest[8:16]) | 1
+}
+
+// Add adds the digest to the filter
+func (f *BloomFilter) Add(digest []byte) {
+	h1, h2 := bloomSeeds(digest)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.inputs++
+}
+
+// MayContain is false if the digest was certainly not added to the filter
+func (f *BloomFilter) MayContain(digest []byte) bool {
+	h1, h2 := bloomSeeds(digest)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of digests added to the filter
+func (f *BloomFilter) Count() uint64 { return f.inputs }
+
+// MarshalBinary serializes the filter:
+//
+//	"SHBLM" 0x01
+//	uint64 bits, uint32 hash count, uint64 digests added
+//	the bit array, as big endian uint64 words
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(bloomMagic)+1+8+4+8+len(f.bits)*8)
+	b = append(b, bloomMagic...)
+	b = append(b, bloomVersion)
+	b = binary.BigEndian.AppendUint64(b, f.m)
+	b = binary.BigEndian.AppendUint32(b, f.k)
+	b = binary.BigEndian.AppendUint64(b, f.inputs)
+	for _, word := range f.bits {
+		b = binary.BigEndian.AppendUint64(b, word)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary restores a filter serialized by MarshalBinary
+func (f *BloomFilter) UnmarshalBinary(b []byte) error {
+	headerSize := len(bloomMagic) + 1 + 8 + 4 + 8
+	if len(b) < headerSize || string(b[:len(bloomMagic)]) != bloomMagic || b[len(bloomMagic)] != bloomVersion {
+		return fmt.Errorf("%w: not a version %d bloom filter", ErrInvalidBloomFilter, bloomVersion)
+	}
+	b = b[len(bloomMagic)+1:]
+	m := binary.BigEndian.Uint64(b)
+	k := binary.BigEndian.Uint32(b[8:])
+	inputs := binary.BigEndian.Uint64(b[12:])
+	b = b[20:]
+	if m == 0 || m > maxBloomBits || k == 0 {
+		return fmt.Errorf("%w: %d bits, %d hashes", ErrInvalidBloomFilter, m, k)
+	}
+	if uint64(len(b)) != (m+63)/64*8 {
+		return fmt.Errorf("%w: %d bytes for %d bits", ErrInvalidBloomFilter, len(b), m)
+	}
+	*f = *newBloomFilter(m, k)
+	f.inputs = inputs
+	for i := range f.bits {
+		f.bits[i] = binary.BigEndian.Uint64(b[i*8:])
+	}
+	return nil
+}
+
+// WriteTo writes the serialized filter to w
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	b, err := f.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadBloomFilter reads a filter written by WriteTo
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxBloomBits/8+64))
+	if err != nil {
+		return nil, err
+	}
+	f := &BloomFilter{}
+	if err = f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
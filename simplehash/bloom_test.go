@@ -0,0 +1,70 @@
+package simplehash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bloomTestDigest(i int) []byte {
+	sum := sha256.Sum256(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	return sum[:]
+}
+
+// TestBloomFilter tests:
+//
+// 1. the capacity and false positive rate are validated.
+// 2. every added digest may be contained.
+// 3. the false positive rate is close to that requested.
+// 4. the filter round trips through its serialization.
+func TestBloomFilter(t *testing.T) {
+	_, err := NewBloomFilter(0, 0.01)
+	assert.ErrorIs(t, err, ErrInvalidBloomFilter)
+	_, err = NewBloomFilter(10, 1)
+	assert.ErrorIs(t, err, ErrInvalidBloomFilter)
+
+	const n = 10000
+	f, err := NewBloomFilter(n, 0.01)
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		f.Add(bloomTestDigest(i))
+	}
+	assert.Equal(t, uint64(n), f.Count())
+	for i := 0; i < n; i++ {
+		require.True(t, f.MayContain(bloomTestDigest(i)))
+	}
+	falsePositives := 0
+	for i := n; i < 2*n; i++ {
+		if f.MayContain(bloomTestDigest(i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, n/50)
+
+	var buf bytes.Buffer
+	_, err = f.WriteTo(&buf)
+	require.NoError(t, err)
+	read, err := ReadBloomFilter(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, f, read)
+
+	_, err = ReadBloomFilter(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.ErrorIs(t, err, ErrInvalidBloomFilter)
+}
+
+// TestHashSet_BloomFilter tests:
+//
+// 1. a filter built from a hash set may contain each of its digests.
+func TestHashSet_BloomFilter(t *testing.T) {
+	set, err := HashSetFromJSON(hashSetEvents)
+	require.NoError(t, err)
+	f, err := set.BloomFilter(0.001)
+	require.NoError(t, err)
+	for _, digest := range set.Digests {
+		assert.True(t, f.MayContain(digest))
+	}
+}
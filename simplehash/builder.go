@@ -0,0 +1,203 @@
+package simplehash
+
+import (
+	"time"
+)
+
+// V3EventBuilder builds a V3Event, for services which predict the digest of
+// an event before it is committed, without assembling the attribute and
+// principal maps by hand:
+//
+//	event, err := NewV3EventBuilder().
+//		Identity("assets/{uuid}/events/{uuid}").
+//		EventAttribute("arc_display_type", "Inspection").
+//		PrincipalDeclared(Principal{Issuer: issuer, Subject: subject}).
+//		TimestampDeclared(time.Now()).
+//		Build()
+//
+// The zero value is not usable, use NewV3EventBuilder.
+type V3EventBuilder struct {
+	event V3Event
+}
+
+// NewV3EventBuilder creates a builder for an event with no attributes
+func NewV3EventBuilder() *V3EventBuilder {
+	return &V3EventBuilder{event: V3Event{
+		EventAttributes: map[string]any{},
+		AssetAttributes: map[string]any{},
+	}}
+}
+
+func (b *V3EventBuilder) Identity(identity string) *V3EventBuilder {
+	b.event.Identity = identity
+	return b
+}
+
+// EventAttribute sets an event attribute. value must be a string, a map of
+// strings or a list of maps of strings, as the api holds.
+func (b *V3EventBuilder) EventAttribute(name string, value any) *V3EventBuilder {
+	b.event.EventAttributes[name] = value
+	return b
+}
+
+// AssetAttribute sets an asset attribute, see EventAttribute
+func (b *V3EventBuilder) AssetAttribute(name string, value any) *V3EventBuilder {
+	b.event.AssetAttributes[name] = value
+	return b
+}
+
+func (b *V3EventBuilder) Operation(operation string) *V3EventBuilder {
+	b.event.Operation = operation
+	return b
+}
+
+func (b *V3EventBuilder) Behaviour(behaviour string) *V3EventBuilder {
+	b.event.Behaviour = behaviour
+	return b
+}
+
+func (b *V3EventBuilder) TimestampDeclared(t time.Time) *V3EventBuilder {
+	b.event.TimestampDeclared = t.Format(time.RFC3339Nano)
+	return b
+}
+
+func (b *V3EventBuilder) TimestampAccepted(t time.Time) *V3EventBuilder {
+	b.event.TimestampAccepted = t.Format(time.RFC3339Nano)
+	return b
+}
+
+func (b *V3EventBuilder) TimestampCommitted(t time.Time) *V3EventBuilder {
+	b.event.TimestampCommitted = t.Format(time.RFC3339Nano)
+	return b
+}
+
+func (b *V3EventBuilder) PrincipalDeclared(p Principal) *V3EventBuilder {
+	b.event.PrincipalDeclared = p.Map()
+	return b
+}
+
+func (b *V3EventBuilder) PrincipalAccepted(p Principal) *V3EventBuilder {
+	b.event.PrincipalAccepted = p.Map()
+	return b
+}
+
+// TenantIdentity sets the tenant identity, of the form tenant/{uuid}
+func (b *V3EventBuilder) TenantIdentity(tenantIdentity string) *V3EventBuilder {
+	b.event.TenantIdentity = tenantIdentity
+	return b
+}
+
+// Build returns the event, normalized as the api would return it, see
+// NormalizeEvent. The event is checked by Validate, and every problem found
+// is returned. The builder can be reused, the event does not share its maps.
+func (b *V3EventBuilder) Build() (V3Event, error) {
+	event := b.event.Clone()
+	if err := NormalizeEvent(&event); err != nil {
+		return V3Event{}, err
+	}
+	if err := event.Validate(); err != nil {
+		return V3Event{}, err
+	}
+	return event, nil
+}
+
+// V2EventBuilder builds a V2Event, see V3EventBuilder. The asset identity is
+// taken from the event identity unless it is set.
+type V2EventBuilder struct {
+	v3 *V3EventBuilder
+
+	assetIdentity      string
+	confirmationStatus string
+	from               string
+}
+
+// NewV2EventBuilder creates a builder for an event with no attributes
+func NewV2EventBuilder() *V2EventBuilder {
+	return &V2EventBuilder{v3: NewV3EventBuilder()}
+}
+
+func (b *V2EventBuilder) Identity(identity string) *V2EventBuilder {
+	b.v3.Identity(identity)
+	return b
+}
+
+func (b *V2EventBuilder) AssetIdentity(assetIdentity string) *V2EventBuilder {
+	b.assetIdentity = assetIdentity
+	return b
+}
+
+// EventAttribute sets an event attribute, see V3EventBuilder.EventAttribute
+func (b *V2EventBuilder) EventAttribute(name string, value any) *V2EventBuilder {
+	b.v3.EventAttribute(name, value)
+	return b
+}
+
+// AssetAttribute sets an asset attribute, see V3EventBuilder.EventAttribute
+func (b *V2EventBuilder) AssetAttribute(name string, value any) *V2EventBuilder {
+	b.v3.AssetAttribute(name, value)
+	return b
+}
+
+func (b *V2EventBuilder) Operation(operation string) *V2EventBuilder {
+	b.v3.Operation(operation)
+	return b
+}
+
+func (b *V2EventBuilder) Behaviour(behaviour string) *V2EventBuilder {
+	b.v3.Behaviour(behaviour)
+	return b
+}
+
+func (b *V2EventBuilder) TimestampDeclared(t time.Time) *V2EventBuilder {
+	b.v3.TimestampDeclared(t)
+	return b
+}
+
+func (b *V2EventBuilder) TimestampAccepted(t time.Time) *V2EventBuilder {
+	b.v3.TimestampAccepted(t)
+	return b
+}
+
+func (b *V2EventBuilder) TimestampCommitted(t time.Time) *V2EventBuilder {
+	b.v3.TimestampCommitted(t)
+	return b
+}
+
+func (b *V2EventBuilder) PrincipalDeclared(p Principal) *V2EventBuilder {
+	b.v3.PrincipalDeclared(p)
+	return b
+}
+
+func (b *V2EventBuilder) PrincipalAccepted(p Principal) *V2EventBuilder {
+	b.v3.PrincipalAccepted(p)
+	return b
+}
+
+func (b *V2EventBuilder) TenantIdentity(tenantIdentity string) *V2EventBuilder {
+	b.v3.TenantIdentity(tenantIdentity)
+	return b
+}
+
+func (b *V2EventBuilder) ConfirmationStatus(status string) *V2EventBuilder {
+	b.confirmationStatus = status
+	return b
+}
+
+func (b *V2EventBuilder) From(from string) *V2EventBuilder {
+	b.from = from
+	return b
+}
+
+// Build returns the event, normalized and validated as for
+// V3EventBuilder.Build
+func (b *V2EventBuilder) Build() (V2Event, error) {
+	v3Event, err := b.v3.Build()
+	if err != nil {
+		return V2Event{}, err
+	}
+	v2Event := V2FromV3(v3Event, V2OnlyFields{ConfirmationStatus: b.confirmationStatus, From: b.from})
+	if b.assetIdentity != "" {
+		v2Event.AssetIdentity = b.assetIdentity
+	}
+	return v2Event, nil
+}
@@ -0,0 +1,69 @@
+package simplehash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestV3EventBuilder tests:
+//
+// 1. the built event hashes the same as the api json of the same event.
+// 2. the builder can be reused without the events sharing attributes.
+// 3. invalid events are rejected with ErrInvalidEvent.
+func TestV3EventBuilder(t *testing.T) {
+	identity := "assets/03c60f22-588c-4f12-b3c2-e98c7f2e98a0/events/409ae05a-183d-4e55-8aa6-889159edefd3"
+	declared := time.Date(2024, 1, 31, 11, 29, 19, 43000000, time.UTC)
+
+	b := NewV3EventBuilder().
+		Identity(identity).
+		EventAttribute("arc_display_type", "Inspection").
+		Operation("Record").
+		Behaviour("RecordEvidence").
+		TimestampDeclared(declared).
+		PrincipalDeclared(Principal{Issuer: "https://idp", Subject: "alice"})
+	event, err := b.Build()
+	require.NoError(t, err)
+
+	expected, err := DigestEventFromJSON([]byte(`{
+		"identity": "` + identity + `",
+		"event_attributes": {"arc_display_type": "Inspection"},
+		"asset_attributes": {},
+		"operation": "Record",
+		"behaviour": "RecordEvidence",
+		"timestamp_declared": "2024-01-31T11:29:19.043Z",
+		"principal_declared": {"issuer": "https://idp", "subject": "alice", "display_name": "", "email": ""},
+		"principal_accepted": {"issuer": "", "subject": "", "display_name": "", "email": ""}
+	}`))
+	require.NoError(t, err)
+	digest, err := HashOfV3(event)
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	other, err := b.EventAttribute("extra", "x").Build()
+	require.NoError(t, err)
+	assert.Len(t, other.EventAttributes, 2)
+	assert.Len(t, event.EventAttributes, 1)
+
+	_, err = NewV3EventBuilder().Identity(identity).EventAttribute("n", 1.5).Build()
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+	_, err = NewV3EventBuilder().Identity("assets/1").Build()
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+}
+
+// TestV2EventBuilder tests that the asset identity is taken from the event
+// identity, and the V2 only fields are set.
+func TestV2EventBuilder(t *testing.T) {
+	event, err := NewV2EventBuilder().
+		Identity("assets/03c60f22-588c-4f12-b3c2-e98c7f2e98a0/events/409ae05a-183d-4e55-8aa6-889159edefd3").
+		EventAttribute("arc_display_type", "Inspection").
+		ConfirmationStatus("CONFIRMED").
+		From("0xf8dfc073650503aeD429E414bE7e972f8F095e70").
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "assets/03c60f22-588c-4f12-b3c2-e98c7f2e98a0", event.AssetIdentity)
+	assert.Equal(t, "CONFIRMED", event.ConfirmationStatus)
+	assert.Equal(t, map[string]any{"arc_display_type": "Inspection"}, event.EventAttributes)
+}
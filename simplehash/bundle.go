@@ -0,0 +1,297 @@
+package simplehash
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BundleManifestPath is the path of the manifest inside a verification bundle
+const BundleManifestPath = "manifest.json"
+
+// maxBundleEntrySize limits the size of each file read from a bundle, so that
+// a bundle can't exhaust memory with a single oversized entry
+var maxBundleEntrySize int64 = 64 << 20
+
+var (
+	ErrInvalidBundle  = errors.New("invalid verification bundle")
+	ErrBundleMismatch = errors.New("bundle events do not match their recorded hashes")
+)
+
+// BundleOptions records the hashing options used to produce the bundle
+// hashes. Every option which affects the digest, and which WriteBundle
+// accepts, is recorded.
+type BundleOptions struct {
+	// Prefix is the hex encoded WithPrefix bytes
+	Prefix                 string `json:"prefix,omitempty"`
+	PublicFromPermissioned bool   `json:"public_from_permissioned,omitempty"`
+	UseNumber              bool   `json:"use_number,omitempty"`
+	// TenantIdentity is the WithTenantIdentity override, if any. An empty
+	// override differs from none.
+	TenantIdentity  *string         `json:"tenant_identity,omitempty"`
+	ExcludeFields   []string        `json:"exclude_fields,omitempty"`
+	Encoding        Encoding        `json:"encoding,omitempty"`
+	RedactionMode   RedactionMode   `json:"redaction_mode,omitempty"`
+	TimestampFormat TimestampFormat `json:"timestamp_format,omitempty"`
+}
+
+// newBundleOptions records the options which affect the digest
+func newBundleOptions(o HashOptions) BundleOptions {
+	return BundleOptions{
+		Prefix:                 hex.EncodeToString(o.prefix),
+		PublicFromPermissioned: o.publicFromPermissioned,
+		UseNumber:              o.useNumber,
+		TenantIdentity:         o.tenantIdentity,
+		ExcludeFields:          o.excludeFields,
+		Encoding:               o.encoding,
+		RedactionMode:          o.redactionMode,
+		TimestampFormat:        o.timestampFormat,
+	}
+}
+
+// HashOptions returns the hashing options recorded, to reproduce the hashes
+func (b BundleOptions) HashOptions() ([]HashOption, error) {
+	prefix, err := hex.DecodeString(b.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("prefix: %w", err)
+	}
+	opts := []HashOption{}
+	if len(prefix) != 0 {
+		opts = append(opts, WithPrefix(prefix))
+	}
+	if b.PublicFromPermissioned {
+		opts = append(opts, WithPublicFromPermissioned())
+	}
+	if b.UseNumber {
+		opts = append(opts, WithUseNumber())
+	}
+	if b.TenantIdentity != nil {
+		opts = append(opts, WithTenantIdentity(*b.TenantIdentity))
+	}
+	if len(b.ExcludeFields) != 0 {
+		opts = append(opts, WithExcludeFields(b.ExcludeFields...))
+	}
+	if b.Encoding != EncodingBencode {
+		opts = append(opts, WithEncoding(b.Encoding))
+	}
+	if b.RedactionMode != RedactionHashMarker {
+		opts = append(opts, WithRedactionMode(b.RedactionMode))
+	}
+	if b.TimestampFormat != TimestampFormatAsIs {
+		opts = append(opts, WithTimestampFormat(b.TimestampFormat))
+	}
+	return opts, nil
+}
+
+// BundleEntry records the simple hash of one event in the bundle
+type BundleEntry struct {
+	Path       string `json:"path"`
+	Identity   string `json:"identity"`
+	SimpleHash string `json:"simplehash"`
+}
+
+// BundleManifest describes the contents of a verification bundle
+type BundleManifest struct {
+	SchemaVersion int           `json:"schema_version"`
+	HashAlgorithm string        `json:"hash_alg"`
+	Options       BundleOptions `json:"options"`
+	Events        []BundleEntry `json:"events"`
+}
+
+// BundleVerification is the outcome of verifying a bundle
+type BundleVerification struct {
+	Manifest BundleManifest
+	// Verified is the number of events whose hash was reproduced
+	Verified int
+	// Mismatched lists the paths of the events whose hash was not reproduced
+	Mismatched []string
+}
+
+// WriteBundle writes a self contained verification bundle to w. The bundle is
+// a tar archive holding each api formatted event, as provided, and a manifest
+// of their V3 simple hashes, the options used and the schema version. An
+// auditor can re-verify every hash with VerifyBundle, without api access.
+//
+// Options:
+//   - WithPrefix
+//   - WithPublicFromPermissioned
+//   - WithUseNumber
+//   - WithTenantIdentity
+//   - WithExcludeFields
+//   - WithEncoding
+//   - WithRedactionMode
+//   - WithTimestampFormat
+//   - WithProgress
+//
+// Options which apply to a single event, or can't be recorded in the
+// manifest, are rejected with ErrInvalidOption.
+func WriteBundle(w io.Writer, events [][]byte, opts ...HashOption) error {
+	return WriteBundleContext(context.Background(), w, events, opts...)
+}
+
+// WriteBundleContext is WriteBundle, stopping promptly if ctx is done. Nothing
+// is written to w if the context is done before every event is hashed.
+func WriteBundleContext(ctx context.Context, w io.Writer, events [][]byte, opts ...HashOption) error {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.checkOptions("WriteBundle", "the option applies to a single event, or can't be recorded in the manifest",
+		optIDCommitted, optTimestampCommitted, optChain, optAccumulate, optQuarantine); err != nil {
+		return err
+	}
+
+	manifest := BundleManifest{
+		SchemaVersion: 3,
+		HashAlgorithm: "sha256",
+		Options:       newBundleOptions(o),
+		Events:        make([]BundleEntry, 0, len(events)),
+	}
+
+	h := NewHasherV3()
+	for i, eventJson := range events {
+		if err := ctx.Err(); err != nil {
+			return &CanceledError{Processed: i, Err: err}
+		}
+		v3Event, err := v3FromEventJSON(eventJson, o)
+		if err != nil {
+			return fmt.Errorf("bundle event %d: %w", i, err)
+		}
+		if err = h.HashEventFromV3(v3Event, withoutBatch(opts)...); err != nil {
+			return fmt.Errorf("bundle event %d: %w", i, err)
+		}
+		manifest.Events = append(manifest.Events, BundleEntry{
+			Path:       fmt.Sprintf("events/%08d.json", i),
+			Identity:   v3Event.Identity,
+			SimpleHash: hex.EncodeToString(h.Sum(nil)),
+		})
+		o.reportProgress(i+1, len(events), v3Event.Identity)
+	}
+
+	manifestJson, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	if err = writeBundleFile(tw, BundleManifestPath, manifestJson); err != nil {
+		return err
+	}
+	for i, entry := range manifest.Events {
+		if err = writeBundleFile(tw, entry.Path, events[i]); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeBundleFile(tw *tar.Writer, path string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:     path,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// VerifyBundle reads a bundle produced by WriteBundle and re-computes the
+// hash of every event using the options recorded in the manifest. If any
+// event does not reproduce its recorded hash, or is missing, the returned
+// error wraps ErrBundleMismatch and the verification lists the failures.
+// Bundles holding files the manifest does not list, or oversized files, are
+// rejected with ErrInvalidBundle.
+func VerifyBundle(r io.Reader) (BundleVerification, error) {
+	return VerifyBundleContext(context.Background(), r)
+}
+
+// VerifyBundleContext is VerifyBundle, stopping promptly if ctx is done. On
+// cancellation the partial verification is returned with a *CanceledError.
+func VerifyBundleContext(ctx context.Context, r io.Reader) (BundleVerification, error) {
+
+	files := map[string][]byte{}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return BundleVerification{}, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return BundleVerification{}, fmt.Errorf("%w: %s is not a regular file", ErrInvalidBundle, header.Name)
+		}
+		if _, ok := files[header.Name]; ok {
+			return BundleVerification{}, fmt.Errorf("%w: %s is repeated", ErrInvalidBundle, header.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxBundleEntrySize+1))
+		if err != nil {
+			return BundleVerification{}, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if int64(len(data)) > maxBundleEntrySize {
+			return BundleVerification{}, fmt.Errorf("%w: %s exceeds %d bytes", ErrInvalidBundle, header.Name, maxBundleEntrySize)
+		}
+		files[header.Name] = data
+	}
+
+	manifestJson, ok := files[BundleManifestPath]
+	if !ok {
+		return BundleVerification{}, fmt.Errorf("%w: no %s", ErrInvalidBundle, BundleManifestPath)
+	}
+	result := BundleVerification{}
+	if err := json.Unmarshal(manifestJson, &result.Manifest); err != nil {
+		return BundleVerification{}, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	manifest := result.Manifest
+	if manifest.SchemaVersion != 3 || manifest.HashAlgorithm != "sha256" {
+		return result, fmt.Errorf(
+			"%w: unsupported schema %d hash %s", ErrInvalidBundle, manifest.SchemaVersion, manifest.HashAlgorithm)
+	}
+
+	listed := map[string]bool{BundleManifestPath: true}
+	for _, entry := range manifest.Events {
+		listed[entry.Path] = true
+	}
+	for path := range files {
+		if !listed[path] {
+			return result, fmt.Errorf("%w: %s is not listed in the manifest", ErrInvalidBundle, path)
+		}
+	}
+
+	opts, err := manifest.Options.HashOptions()
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+
+	h := NewHasherV3()
+	for i, entry := range manifest.Events {
+		if err := ctx.Err(); err != nil {
+			return result, &CanceledError{Processed: i, Err: err}
+		}
+		eventJson, ok := files[entry.Path]
+		if ok {
+			err = h.HashEventFromJSON(eventJson, opts...)
+		}
+		if !ok || err != nil || hex.EncodeToString(h.Sum(nil)) != entry.SimpleHash {
+			result.Mismatched = append(result.Mismatched, entry.Path)
+			continue
+		}
+		result.Verified++
+	}
+
+	if len(result.Mismatched) != 0 {
+		return result, fmt.Errorf("%w: %d of %d events", ErrBundleMismatch, len(result.Mismatched), len(manifest.Events))
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package simplehash
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyBundle tests:
+//
+// 1. a bundle written with a prefix verifies.
+// 2. a bundle whose event has been modified reports the mismatch.
+func TestVerifyBundle(t *testing.T) {
+	events := [][]byte{
+		[]byte(`{"identity":"assets/1234/events/5678","operation":"Record"}`),
+		[]byte(`{"identity":"assets/1234/events/9abc","operation":"Record"}`),
+	}
+
+	var bundle bytes.Buffer
+	require.NoError(t, WriteBundle(&bundle, events, WithPrefix([]byte{0})))
+
+	result, err := VerifyBundle(bytes.NewReader(bundle.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Verified)
+	assert.Equal(t, "00", result.Manifest.Options.Prefix)
+
+	// Same length, so the tar framing is unaffected
+	tampered := bytes.Replace(bundle.Bytes(), []byte(`"operation":"Record"}`), []byte(`"operation":"Rekord"}`), 1)
+	result, err = VerifyBundle(bytes.NewReader(tampered))
+	assert.ErrorIs(t, err, ErrBundleMismatch)
+	assert.Equal(t, []string{"events/00000000.json"}, result.Mismatched)
+
+	assert.ErrorIs(t, WriteBundle(&bundle, events, WithIDCommitted(1)), ErrInvalidOption)
+}
+
+// TestVerifyBundle_Options tests:
+//
+// 1. a bundle written with each option which changes the digest records the
+// option and verifies.
+func TestVerifyBundle_Options(t *testing.T) {
+	events := [][]byte{[]byte(`{
+		"identity": "assets/1234/events/5678",
+		"event_attributes": {"public": "a"},
+		"_redacted_attributes": {"event_attributes": ["secret"]},
+		"operation": "Record",
+		"timestamp_accepted": "2024-01-31T11:29:19.043000Z",
+		"tenant_identity": "tenant/1234"
+	}`)}
+
+	var plain bytes.Buffer
+	require.NoError(t, WriteBundle(&plain, events))
+	result, err := VerifyBundle(&plain)
+	require.NoError(t, err)
+	plainHash := result.Manifest.Events[0].SimpleHash
+
+	for _, tt := range []struct {
+		name string
+		opt  HashOption
+	}{
+		{"WithTenantIdentity", WithTenantIdentity("tenant/5678")},
+		{"WithTenantIdentity/empty", WithTenantIdentity("")},
+		{"WithExcludeFields", WithExcludeFields("operation")},
+		{"WithEncoding/cbor", WithEncoding(EncodingCBOR)},
+		{"WithEncoding/jcs", WithEncoding(EncodingJCS)},
+		{"WithRedactionMode", WithRedactionMode(RedactionOmit)},
+		{"WithTimestampFormat", WithTimestampFormat(TimestampFormatAPI)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var bundle bytes.Buffer
+			require.NoError(t, WriteBundle(&bundle, events, tt.opt))
+			result, err := VerifyBundle(&bundle)
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.Verified)
+			assert.NotEqual(t, plainHash, result.Manifest.Events[0].SimpleHash)
+		})
+	}
+}
+
+// TestVerifyBundle_Entries tests:
+//
+// 1. a file the manifest does not list is rejected.
+// 2. an oversized file is rejected.
+func TestVerifyBundle_Entries(t *testing.T) {
+	events := [][]byte{[]byte(`{"identity":"assets/1234/events/5678","operation":"Record"}`)}
+
+	var bundle bytes.Buffer
+	require.NoError(t, WriteBundle(&bundle, events))
+
+	// re-write the bundle, without the end of archive marker, then append a
+	// file the manifest does not list
+	var extended bytes.Buffer
+	tr := tar.NewReader(bytes.NewReader(bundle.Bytes()))
+	tw := tar.NewWriter(&extended)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err = io.Copy(tw, tr)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writeBundleFile(tw, "events/unlisted.json", events[0]))
+	require.NoError(t, tw.Close())
+	_, err := VerifyBundle(&extended)
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	defer func(size int64) { maxBundleEntrySize = size }(maxBundleEntrySize)
+	maxBundleEntrySize = int64(len(events[0])) - 1
+	_, err = VerifyBundle(bytes.NewReader(bundle.Bytes()))
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+}
@@ -0,0 +1,143 @@
+package simplehash
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrInvalidCacheSize = errors.New("cache size must be positive")
+)
+
+// CacheKey identifies a cached event digest. The fingerprint covers the raw
+// event and every option which affects the digest, so an event which has
+// changed since it was cached, for example one which has been tampered with,
+// never matches the cached digest of its identity.
+type CacheKey struct {
+	Identity    string
+	Schema      int
+	Fingerprint [sha256.Size]byte
+}
+
+// HashCache caches event digests, for services which repeatedly verify the
+// same events, for example on every api read. The event is always decoded,
+// to check it and find its identity, before the cache is consulted, so a hit
+// saves only the encoding and hashing of the event. Implementations must be
+// safe for concurrent use.
+type HashCache interface {
+	Get(key CacheKey) ([]byte, bool)
+	Add(key CacheKey, digest []byte)
+}
+
+// WithCache looks up, and saves, the digests of single events in cache.
+// Accumulated digests are never cached. It is honoured by
+// DigestEventFromJSON, HashEventJSONAuto for V3 events and
+// HashInventoryFromJSON.
+func WithCache(cache HashCache) HashOption {
+	return func(o *HashOptions) {
+		o.cache = cache
+	}
+}
+
+// newCacheKey fingerprints the event and the options which affect its
+// digest, as listed by digestOptions
+func newCacheKey(identity string, schema int, eventJson []byte, o HashOptions) CacheKey {
+
+	h := sha256.New()
+	writeField := func(b []byte) {
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(b))))
+		h.Write(b)
+	}
+
+	for _, option := range digestOptions {
+		if value, ok := option.value(o); ok {
+			writeField([]byte(option.name))
+			writeField([]byte(value))
+		}
+	}
+	writeField(eventJson)
+
+	key := CacheKey{Identity: identity, Schema: schema}
+	h.Sum(key.Fingerprint[:0])
+	return key
+}
+
+// cachedDigest returns the cached digest for key, or computes and caches it
+func cachedDigest(cache HashCache, key CacheKey, compute func() ([]byte, error)) ([]byte, error) {
+	if digest, ok := cache.Get(key); ok {
+		return digest, nil
+	}
+	digest, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	cache.Add(key, digest)
+	return digest, nil
+}
+
+// LRUHashCache is a HashCache holding a fixed number of digests, evicting
+// the least recently used.
+type LRUHashCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[CacheKey]*list.Element
+}
+
+type lruEntry struct {
+	key    CacheKey
+	digest []byte
+}
+
+// NewLRUHashCache creates a cache holding at most size digests
+func NewLRUHashCache(size int) (*LRUHashCache, error) {
+	if size <= 0 {
+		return nil, ErrInvalidCacheSize
+	}
+	return &LRUHashCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[CacheKey]*list.Element{},
+	}, nil
+}
+
+// Get returns a copy of the cached digest, marking it recently used
+func (c *LRUHashCache) Get(key CacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return bytes.Clone(e.Value.(*lruEntry).digest), true
+}
+
+// Add caches a copy of the digest, evicting the least recently used digest
+// if the cache is full
+func (c *LRUHashCache) Add(key CacheKey, digest []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).digest = bytes.Clone(digest)
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, digest: bytes.Clone(digest)})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of cached digests
+func (c *LRUHashCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,218 @@
+package simplehash
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCache records the lookups which hit
+type countingCache struct {
+	HashCache
+	hits int
+}
+
+func (c *countingCache) Get(key CacheKey) ([]byte, bool) {
+	digest, ok := c.HashCache.Get(key)
+	if ok {
+		c.hits++
+	}
+	return digest, ok
+}
+
+// TestLRUHashCache tests:
+//
+// 1. the size must be positive.
+// 2. the least recently used digest is evicted.
+// 3. cached digests are copies.
+func TestLRUHashCache(t *testing.T) {
+	_, err := NewLRUHashCache(0)
+	assert.ErrorIs(t, err, ErrInvalidCacheSize)
+
+	c, err := NewLRUHashCache(2)
+	require.NoError(t, err)
+	a, b, d := CacheKey{Identity: "a"}, CacheKey{Identity: "b"}, CacheKey{Identity: "d"}
+
+	digest := []byte{1}
+	c.Add(a, digest)
+	digest[0] = 9
+	c.Add(b, []byte{2})
+	got, ok := c.Get(a)
+	require.True(t, ok)
+	assert.Equal(t, []byte{1}, got)
+	got[0] = 9
+
+	c.Add(d, []byte{3})
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get(b)
+	assert.False(t, ok)
+	got, ok = c.Get(a)
+	require.True(t, ok)
+	assert.Equal(t, []byte{1}, got)
+}
+
+// TestDigestEventFromJSON_WithCache tests:
+//
+// 1. a cached digest is the digest computed without the cache.
+// 2. repeating the event and options hits the cache.
+// 3. changed content with the same identity, or different options, misses.
+func TestDigestEventFromJSON_WithCache(t *testing.T) {
+	lru, err := NewLRUHashCache(16)
+	require.NoError(t, err)
+	cache := &countingCache{HashCache: lru}
+
+	eventJson := []byte(`{"identity": "assets/1/events/2", "event_attributes": {"a": "1"}}`)
+	tampered := []byte(`{"identity": "assets/1/events/2", "event_attributes": {"a": "2"}}`)
+
+	expected, err := DigestEventFromJSON(eventJson)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		digest, err := DigestEventFromJSON(eventJson, WithCache(cache), WithAccumulate())
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest)
+	}
+	assert.Equal(t, 1, cache.hits)
+
+	expectedTampered, err := DigestEventFromJSON(tampered)
+	require.NoError(t, err)
+	digest, err := DigestEventFromJSON(tampered, WithCache(cache))
+	require.NoError(t, err)
+	assert.Equal(t, expectedTampered, digest)
+
+	expectedPrefixed, err := DigestEventFromJSON(eventJson, WithPrefix([]byte{1}))
+	require.NoError(t, err)
+	digest, err = DigestEventFromJSON(eventJson, WithCache(cache), WithPrefix([]byte{1}))
+	require.NoError(t, err)
+	assert.Equal(t, expectedPrefixed, digest)
+
+	assert.Equal(t, 1, cache.hits)
+	assert.Equal(t, 3, lru.Len())
+}
+
+// TestDigestEventFromJSON_WithCacheOptions tests:
+//
+// 1. for each option which affects the digest, the digest through a cache
+// warmed with the other options is the digest computed without the cache.
+// 2. each of those options is described, so digests made with them are not
+// recorded as equivalent.
+// 3. each of those options gives a distinct cache key.
+// 4. every option which affects the digest is covered.
+func TestDigestEventFromJSON_WithCacheOptions(t *testing.T) {
+	eventJson := []byte(`{
+		"identity": "assets/1/events/2",
+		"event_attributes": {"a": "1"},
+		"_redacted_attributes": {"event_attributes": ["r"]},
+		"timestamp_accepted": "2023-02-23T11:22:33.000Z",
+		"tenant_identity": "tenant/0"
+	}`)
+	committed := time.Date(2023, 2, 23, 11, 22, 34, 0, time.UTC)
+
+	options := map[string]HashOption{
+		"prefix":                   WithPrefix([]byte{1}),
+		"chain":                    WithChain([]byte{2}),
+		"id_committed":             WithIDCommitted(3),
+		"timestamp_committed":      WithTimestampCommittedTime(committed),
+		"tenant_a":                 WithTenantIdentity("tenant/a"),
+		"tenant_b":                 WithTenantIdentity("tenant/b"),
+		"tenant_empty":             WithTenantIdentity(""),
+		"public_from_permissioned": WithPublicFromPermissioned(),
+		"use_number":               WithUseNumber(),
+		"redaction_mode":           WithRedactionMode(RedactionOmit),
+		"timestamp_format":         WithTimestampFormat(TimestampFormatAPI),
+		"encoding":                 WithEncoding(EncodingCBOR),
+		"exclude_fields":           WithExcludeFields("tenant_identity"),
+	}
+
+	lru, err := NewLRUHashCache(64)
+	require.NoError(t, err)
+	descriptions := map[string]string{}
+	keys := map[CacheKey]string{newCacheKey("", SchemaVersionV3, eventJson, HashOptions{}): "none"}
+	described := map[string]bool{}
+	for name, opt := range options {
+		for other, otherOpt := range options {
+			if other != name {
+				_, err := DigestEventFromJSON(eventJson, WithCache(lru), otherOpt)
+				require.NoError(t, err)
+			}
+		}
+		expected, err := DigestEventFromJSON(eventJson, opt)
+		require.NoError(t, err)
+		digest, err := DigestEventFromJSON(eventJson, WithCache(lru), opt)
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest, name)
+
+		description := fmt.Sprint(DescribeOptions(opt))
+		assert.NotEqual(t, fmt.Sprint(DescribeOptions()), description, name)
+		assert.NotContains(t, descriptions, description, name)
+		descriptions[description] = name
+		for option := range DescribeOptions(opt) {
+			described[option] = true
+		}
+
+		o := HashOptions{}
+		opt(&o)
+		key := newCacheKey("", SchemaVersionV3, eventJson, o)
+		assert.NotContains(t, keys, key, name)
+		keys[key] = name
+	}
+	for _, option := range digestOptions {
+		assert.True(t, described[option.name], option.name)
+	}
+}
+
+// TestDigestEventFromJSON_OptionsNotModified tests:
+//
+// 1. the spare capacity of the callers option slice is not written to, so
+// option slices can be shared between goroutines.
+func TestDigestEventFromJSON_OptionsNotModified(t *testing.T) {
+	opts := make([]HashOption, 2)
+	opts[0], opts[1] = WithPrefix([]byte{1}), WithUseNumber()
+
+	_, err := DigestEventFromJSON([]byte(`{"identity": "assets/1/events/2"}`), opts[:1]...)
+	require.NoError(t, err)
+
+	o := HashOptions{}
+	opts[1](&o)
+	assert.True(t, o.useNumber)
+}
+
+// TestDigestEventsFromJSON tests:
+//
+// 1. each event has its permissioned identity and individual digest, as for
+// DigestEventFromJSON, with or without a cache.
+// 2. WithAccumulate is rejected with an *OptionError.
+// 3. an event which can't be hashed fails, naming its index.
+func TestDigestEventsFromJSON(t *testing.T) {
+	events := [][]byte{
+		[]byte(`{"identity": "assets/1/events/2", "event_attributes": {"n": 1}}`),
+		[]byte(`{"identity": "publicassets/1/events/3"}`),
+	}
+	cache, err := NewLRUHashCache(4)
+	require.NoError(t, err)
+
+	for _, opts := range [][]HashOption{{WithUseNumber()}, {WithUseNumber(), WithCache(cache)}} {
+		digests, err := DigestEventsFromJSON(events, opts...)
+		require.NoError(t, err)
+		require.Len(t, digests, 2)
+		assert.Equal(t, "assets/1/events/2", digests[0].Identity)
+		assert.Equal(t, "assets/1/events/3", digests[1].Identity)
+		for i, eventJson := range events {
+			expected, err := DigestEventFromJSON(eventJson, WithUseNumber())
+			require.NoError(t, err)
+			assert.Equal(t, expected, digests[i].Digest)
+		}
+	}
+
+	_, err = DigestEventsFromJSON(events, WithUseNumber(), WithAccumulate())
+	var optionErr *OptionError
+	require.True(t, errors.As(err, &optionErr), err)
+	assert.Equal(t, "DigestEventsFromJSON", optionErr.Method)
+	assert.Equal(t, "WithAccumulate", optionErr.Option)
+
+	_, err = DigestEventsFromJSON(events)
+	assert.ErrorContains(t, err, "event 0")
+}
@@ -0,0 +1,57 @@
+package simplehash
+
+import (
+	"bytes"
+)
+
+// CanonicalizeOnly returns the bytes HashEventFromJSON would write to the
+// hash for a single V3 api formatted event, without hashing them. The full
+// option pipeline is applied, including WithPublicFromPermissioned,
+// WithTimestampCommitted, WithPrefix, WithChain and WithIDCommitted, so the
+// result is the exact hash input. This is intended for generating test
+// fixtures for other implementations.
+//
+// Options: as for HashEventFromJSON, except WithAccumulate and WithTrace
+// which are ignored.
+func CanonicalizeOnly(eventJson []byte, opts ...HashOption) ([]byte, error) {
+	var input bytes.Buffer
+	h := NewHasherV3()
+	if err := h.HashEventFromJSON(eventJson, canonicalizeOptions(opts, &input)...); err != nil {
+		return nil, err
+	}
+	return input.Bytes(), nil
+}
+
+// CanonicalizeOnlyV3 returns the bytes HashEventFromV3 would write to the
+// hash for the event, without hashing them.
+//
+// Options: as for CanonicalizeOnly
+func CanonicalizeOnlyV3(v3Event V3Event, opts ...HashOption) ([]byte, error) {
+	var input bytes.Buffer
+	h := NewHasherV3()
+	if err := h.HashEventFromV3(v3Event, canonicalizeOptions(opts, &input)...); err != nil {
+		return nil, err
+	}
+	return input.Bytes(), nil
+}
+
+// CanonicalizeOnlyV2 returns the bytes HasherV2.HashEventJSON would write to
+// the hash for a single V2 api formatted event, without hashing them.
+//
+// Options: as for HasherV2.HashEventJSON, except WithAccumulate and WithTrace
+// which are ignored.
+func CanonicalizeOnlyV2(eventJson []byte, opts ...HashOption) ([]byte, error) {
+	var input bytes.Buffer
+	h := NewHasherV2()
+	if err := h.HashEventJSON(eventJson, canonicalizeOptions(opts, &input)...); err != nil {
+		return nil, err
+	}
+	return input.Bytes(), nil
+}
+
+// canonicalizeOptions captures the hash input of a single event in input. The
+// input is captured by tracing a throwaway hasher, so it can not diverge from
+// what the hashers write.
+func canonicalizeOptions(opts []HashOption, input *bytes.Buffer) []HashOption {
+	return append(append([]HashOption(nil), opts...), withoutAccumulate(), WithTrace(input))
+}
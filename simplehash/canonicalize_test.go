@@ -0,0 +1,52 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCanonicalizeOnly tests:
+//
+// 1. the canonical bytes are the prefix, idcommitted and the pre-image of the
+// event with its public identity.
+// 2. hashing the canonical bytes gives the digest of the event.
+// 3. the v2 and pre-decoded v3 entry points agree with their hashers.
+// 4. invalid events fail.
+func TestCanonicalizeOnly(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2", "event_attributes": {"foo": "bar"}}`)
+	opts := []HashOption{WithPrefix([]byte{0x01}), WithIDCommitted(7), WithPublicFromPermissioned()}
+
+	input, err := CanonicalizeOnly(eventJson, opts...)
+	require.NoError(t, err)
+
+	v3Event, err := V3FromEventJSON(eventJson)
+	require.NoError(t, err)
+	public := v3Event
+	public.ToPublicIdentity()
+	preimage, err := V3EncodeEvent(public)
+	require.NoError(t, err)
+	assert.Equal(t, append(append([]byte{0x01}, binary.BigEndian.AppendUint64(nil, 7)...), preimage...), input)
+
+	expected, err := DigestEventFromJSON(eventJson, opts...)
+	require.NoError(t, err)
+	sum := sha256.Sum256(input)
+	assert.Equal(t, expected, sum[:])
+
+	fromV3, err := CanonicalizeOnlyV3(v3Event, append(opts, WithAccumulate())...)
+	require.NoError(t, err)
+	assert.Equal(t, input, fromV3)
+
+	input, err = CanonicalizeOnlyV2(eventJson, WithPrefix([]byte{0x02}))
+	require.NoError(t, err)
+	h := NewHasherV2()
+	require.NoError(t, h.HashEventJSON(eventJson, WithPrefix([]byte{0x02})))
+	sum = sha256.Sum256(input)
+	assert.Equal(t, h.Sum(), sum[:])
+
+	_, err = CanonicalizeOnly([]byte(`{"identity": `))
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package simplehash
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/datatrails/go-datatrails-simplehash/internal/cbor"
+)
+
+// Encoding selects the format of the pre-image which is hashed
+type Encoding int
+
+const (
+	// EncodingBencode is the standard simple hash pre-image. This is the
+	// default and the only encoding which reproduces platform digests.
+	EncodingBencode Encoding = iota
+	// EncodingCBOR is a deterministic CBOR (RFC 8949 section 4.2.1)
+	// pre-image, for integrators who work with COSE and SCITT and want to
+	// avoid bencode. The pre-image is the array
+	//
+	//	[schema, 1, event]
+	//
+	// where schema is the schema name, eg "EventSimpleHashV3", 1 is the
+	// version of the CBOR encoding rules and event is the event map. As for
+	// bencode, null map values are omitted. Integers are encoded as CBOR
+	// integers, using bignums (tags 2 and 3) outside the 64 bit range.
+	EncodingCBOR
+	// EncodingJCS is the RFC 8785 JSON Canonicalization Scheme form of the
+	// hashed fields, see CanonicalJSON.
+	EncodingJCS
+)
+
+// cborEncodingVersion is recorded in every CBOR pre-image, so the encoding
+// rules can be changed without ambiguity.
+const cborEncodingVersion = 1
+
+// WithEncoding selects the pre-image encoding. Digests produced with
+// EncodingCBOR are not comparable with platform digests.
+func WithEncoding(encoding Encoding) HashOption {
+	return func(o *HashOptions) {
+		o.encoding = encoding
+	}
+}
+
+// V3EncodeEventCBOR produces the canonical CBOR pre-image for the event, see
+// EncodingCBOR.
+func V3EncodeEventCBOR(v3Event V3Event) ([]byte, error) {
+	return v3EncodeEvent(v3Event, HashOptions{encoding: EncodingCBOR})
+}
+
+func cborEncodeEvent(schema string, jsonAny any) ([]byte, error) {
+	cborEvent, err := cbor.Marshal([]any{schema, cborEncodingVersion, omitNulls(jsonAny)})
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to cbor encode event: %v", schema, err)
+	}
+	return cborEvent, nil
+}
+
+// cborInteger returns i as a CBOR integer, or as a bignum if it is outside
+// the range of the CBOR major types 0 and 1.
+func cborInteger(i *big.Int) (any, error) {
+	if i.IsInt64() {
+		return i.Int64(), nil
+	}
+	if i.IsUint64() {
+		return i.Uint64(), nil
+	}
+	if i.Sign() > 0 {
+		return cbor.Tag{Number: 2, Content: i.Bytes()}, nil
+	}
+	// negative bignums encode -1 - i
+	n := new(big.Int).Neg(i)
+	n.Sub(n, big.NewInt(1))
+	if n.IsUint64() {
+		return cbor.RawMessage(cborNegativeUint64(n.Uint64())), nil
+	}
+	return cbor.Tag{Number: 3, Content: n.Bytes()}, nil
+}
+
+// cborNegativeUint64 encodes the major type 1 integer -1 - n, for the
+// negative integers which do not fit an int64.
+func cborNegativeUint64(n uint64) []byte {
+	b := []byte{0x3b, 0, 0, 0, 0, 0, 0, 0, 0}
+	for i := 8; i > 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return b
+}
+
+// omitNulls removes null map values, which bencode does not represent, so
+// both encodings describe the same event.
+func omitNulls(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, vv := range x {
+			if vv == nil {
+				delete(x, k)
+				continue
+			}
+			x[k] = omitNulls(vv)
+		}
+	case []any:
+		for i, vv := range x {
+			x[i] = omitNulls(vv)
+		}
+	}
+	return v
+}
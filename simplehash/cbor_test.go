@@ -0,0 +1,81 @@
+package simplehash
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-simplehash/internal/cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestV3EncodeEventCBOR tests:
+//
+// 1. the pre-image is the versioned array, with null and empty fields as for bencode.
+// 2. the pre-image is deterministic regardless of map order.
+// 3. hashing with EncodingCBOR hashes the cbor pre-image.
+func TestV3EncodeEventCBOR(t *testing.T) {
+	v3Event := V3Event{
+		Identity:        "assets/1/events/2",
+		EventAttributes: map[string]any{"b": "2", "a": "1"},
+	}
+
+	encoded, err := V3EncodeEventCBOR(v3Event)
+	require.NoError(t, err)
+
+	decoded, err := cbor.Unmarshal(encoded)
+	require.NoError(t, err)
+	items, ok := decoded.([]any)
+	require.True(t, ok)
+	require.Len(t, items, 3)
+	assert.Equal(t, "EventSimpleHashV3", items[0])
+	assert.Equal(t, int64(1), items[1])
+	event, ok := items[2].(map[any]any)
+	require.True(t, ok)
+	assert.Equal(t, "assets/1/events/2", event["identity"])
+	assert.Equal(t, map[any]any{"a": "1", "b": "2"}, event["event_attributes"])
+	assert.NotContains(t, event, "asset_attributes")
+	assert.Equal(t, "", event["operation"])
+
+	// the attribute map is encoded with sorted keys
+	assert.Contains(t, hex.EncodeToString(encoded), "a2616161316162613"+"2")
+
+	h := NewHasherV3()
+	require.NoError(t, h.HashEventFromV3(v3Event, WithEncoding(EncodingCBOR)))
+	cborDigest := h.Sum(nil)
+	require.NoError(t, h.HashEventFromV3(v3Event))
+	assert.NotEqual(t, cborDigest, h.Sum(nil))
+
+	h.Reset()
+	h.hasher.Write(encoded)
+	assert.Equal(t, cborDigest, h.Sum(nil))
+}
+
+// TestCBORInteger tests:
+//
+// 1. integers use the shortest cbor form, and bignums beyond 64 bits.
+func TestCBORInteger(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"0", "00"},
+		{"-1", "20"},
+		{"18446744073709551615", "1bffffffffffffffff"},
+		{"18446744073709551616", "c249010000000000000000"},
+		{"-18446744073709551616", "3bffffffffffffffff"},
+		{"-18446744073709551617", "c349010000000000000000"},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			i, ok := new(big.Int).SetString(test.in, 10)
+			require.True(t, ok)
+			integer, err := cborInteger(i)
+			require.NoError(t, err)
+			encoded, err := cbor.Marshal(integer)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, hex.EncodeToString(encoded))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCheckpointNotFound    = errors.New("checkpoint not found")
+	ErrInvalidCheckpointName = errors.New("invalid checkpoint name")
+)
+
+// Checkpoint is the saved state of a long running accumulation, so that a
+// consumer hashing a live event stream can resume after a restart rather than
+// replaying from the start.
+type Checkpoint struct {
+	// State is the hasher state saved by MarshalBinary
+	State []byte `json:"state"`
+	// Count is the number of events accumulated into State
+	Count int `json:"count"`
+	// LastIdentity is the identity of the last event accumulated, so the
+	// consumer knows where to resume the stream
+	LastIdentity string `json:"last_identity,omitempty"`
+	// Updated is the time the checkpoint was taken
+	Updated time.Time `json:"updated"`
+	// Metadata is any additional consumer specific state, for example a
+	// partition offset
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// NewCheckpoint saves the state of the hasher as a checkpoint
+func NewCheckpoint(h *Hasher, count int, lastIdentity string) (Checkpoint, error) {
+	state, err := h.MarshalBinary()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return Checkpoint{
+		State:        state,
+		Count:        count,
+		LastIdentity: lastIdentity,
+		Updated:      time.Now().UTC(),
+	}, nil
+}
+
+// Restore restores the checkpoint state into the hasher. The next event
+// should be hashed using WithAccumulate to continue from it.
+func (c Checkpoint) Restore(h *Hasher) error {
+	return h.UnmarshalBinary(c.State)
+}
+
+// CheckpointStore durably saves checkpoints by name. Get returns
+// ErrCheckpointNotFound if no checkpoint has been saved under the name.
+type CheckpointStore interface {
+	Put(name string, checkpoint Checkpoint) error
+	Get(name string) (Checkpoint, error)
+}
+
+// FileCheckpointStore is a CheckpointStore which saves each checkpoint as a
+// json file in a directory. Checkpoints are replaced atomically, so a crash
+// while saving leaves the previous checkpoint in place.
+type FileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore returns a store saving checkpoints in dir, creating
+// the directory if necessary.
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+func (s *FileCheckpointStore) path(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCheckpointName, name)
+	}
+	return filepath.Join(s.dir, name+".json"), nil
+}
+
+// Put saves the checkpoint, replacing any previously saved under the name
+func (s *FileCheckpointStore) Put(name string, checkpoint Checkpoint) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get returns the checkpoint saved under the name
+func (s *FileCheckpointStore) Get(name string) (Checkpoint, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Checkpoint{}, fmt.Errorf("%w: %s", ErrCheckpointNotFound, name)
+	}
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	checkpoint := Checkpoint{}
+	if err = json.Unmarshal(data, &checkpoint); err != nil {
+		return Checkpoint{}, err
+	}
+	return checkpoint, nil
+}
@@ -0,0 +1,54 @@
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFileCheckpointStore tests:
+//
+// 1. an accumulation resumed from a stored checkpoint produces the same
+// digest as one which was never interrupted.
+// 2. a missing checkpoint is reported with ErrCheckpointNotFound.
+// 3. names which would escape the directory are rejected.
+func TestFileCheckpointStore(t *testing.T) {
+	events := [][]byte{
+		[]byte(`{"identity": "assets/1/events/1"}`),
+		[]byte(`{"identity": "assets/1/events/2"}`),
+	}
+
+	expected := NewHasherV3()
+	for _, e := range events {
+		require.NoError(t, expected.HashEventFromJSON(e, WithAccumulate()))
+	}
+
+	store, err := NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Get("stream")
+	assert.ErrorIs(t, err, ErrCheckpointNotFound)
+
+	h := NewHasherV3()
+	require.NoError(t, h.HashEventFromJSON(events[0], WithAccumulate()))
+	checkpoint, err := NewCheckpoint(&h.Hasher, 1, "assets/1/events/1")
+	require.NoError(t, err)
+	checkpoint.Metadata = map[string]string{"offset": "42"}
+	require.NoError(t, store.Put("stream", checkpoint))
+
+	restored, err := store.Get("stream")
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored.Count)
+	assert.Equal(t, "assets/1/events/1", restored.LastIdentity)
+	assert.Equal(t, "42", restored.Metadata["offset"])
+
+	resumed := NewHasherV3()
+	require.NoError(t, restored.Restore(&resumed.Hasher))
+	require.NoError(t, resumed.HashEventFromJSON(events[1], WithAccumulate()))
+	assert.Equal(t, expected.Sum(nil), resumed.Sum(nil))
+
+	for _, name := range []string{"", "..", "a/b", `a\b`} {
+		assert.ErrorIs(t, store.Put(name, checkpoint), ErrInvalidCheckpointName)
+	}
+}
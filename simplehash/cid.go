@@ -0,0 +1,58 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// The CID parameters are pinned, so a CID always identifies the same
+// pre-image:
+//
+//   - version 1
+//   - codec raw (0x55). There is no multicodec for bencode, and the pre-image
+//     is treated as opaque bytes.
+//   - multihash sha2-256, so the CID digest is the simple hash of the event
+//     with no options applied.
+//   - multibase base32, the default string form of CIDv1, "bafkrei..."
+const (
+	cidVersion  = 0x01
+	cidCodecRaw = 0x55
+)
+
+// V3EventCID returns the CIDv1 of the canonical V3 pre-image of the event, so
+// the event can be referenced content addressably in external systems.
+func V3EventCID(v3Event V3Event) (string, error) {
+	preimage, err := V3EncodeEvent(v3Event)
+	if err != nil {
+		return "", err
+	}
+	return CIDFromPreimage(preimage), nil
+}
+
+// CIDFromPreimage returns the CIDv1 of an already encoded pre-image
+func CIDFromPreimage(preimage []byte) string {
+	digest := sha256.Sum256(preimage)
+	return CIDFromDigest(digest[:])
+}
+
+// CIDFromDigest returns the CIDv1 for a sha256 simple hash digest, produced
+// without options
+func CIDFromDigest(digest []byte) string {
+	// both the version and codec are single byte varints
+	cid := append([]byte{cidVersion, cidCodecRaw}, Multihash(digest)...)
+	s, _ := EncodeMultibase(MultibaseBase32, cid)
+	return s
+}
+
+// DigestFromCID returns the sha256 digest from a CID produced by
+// V3EventCID, CIDFromPreimage or CIDFromDigest
+func DigestFromCID(cid string) ([]byte, error) {
+	b, err := DecodeMultibase(cid)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 2 || b[0] != cidVersion || b[1] != cidCodecRaw {
+		return nil, fmt.Errorf("%w: not a CIDv1 with the raw codec", ErrInvalidMultihash)
+	}
+	return DigestFromMultihash(b[2:])
+}
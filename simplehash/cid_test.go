@@ -0,0 +1,33 @@
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestV3EventCID tests:
+//
+// 1. the cid is a base32 CIDv1, raw codec, over the simple hash digest.
+// 2. the cid of the empty pre-image matches the well known raw sha256 cid.
+// 3. the digest is recovered from the cid.
+func TestV3EventCID(t *testing.T) {
+	v3Event := V3Event{Identity: "assets/1/events/2"}
+
+	cid, err := V3EventCID(v3Event)
+	require.NoError(t, err)
+	assert.Equal(t, "bafkrei", cid[:7])
+
+	h := NewHasherV3()
+	require.NoError(t, h.HashEventFromV3(v3Event))
+	digest, err := DigestFromCID(cid)
+	require.NoError(t, err)
+	assert.Equal(t, h.Sum(nil), digest)
+	assert.Equal(t, cid, CIDFromDigest(h.Sum(nil)))
+
+	assert.Equal(t, "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku", CIDFromPreimage(nil))
+
+	_, err = DigestFromCID("bafyreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku")
+	assert.ErrorIs(t, err, ErrInvalidMultihash)
+}
@@ -0,0 +1,52 @@
+package simplehash
+
+// Clone returns a deep copy of the event. The attribute and principal maps,
+// and any maps and lists nested in them, are copied, so options can be
+// applied to the copy without affecting an event shared with other code.
+// Nil maps remain nil, as they hash differently to empty maps.
+func (e V3Event) Clone() V3Event {
+	e.EventAttributes = cloneMap(e.EventAttributes)
+	e.AssetAttributes = cloneMap(e.AssetAttributes)
+	e.PrincipalAccepted = cloneMap(e.PrincipalAccepted)
+	e.PrincipalDeclared = cloneMap(e.PrincipalDeclared)
+	return e
+}
+
+// Clone returns a deep copy of the event, see V3Event.Clone
+func (e V2Event) Clone() V2Event {
+	e.EventAttributes = cloneMap(e.EventAttributes)
+	e.AssetAttributes = cloneMap(e.AssetAttributes)
+	e.PrincipalAccepted = cloneMap(e.PrincipalAccepted)
+	e.PrincipalDeclared = cloneMap(e.PrincipalDeclared)
+	return e
+}
+
+func cloneMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]any, len(m))
+	for k, v := range m {
+		clone[k] = cloneValue(v)
+	}
+	return clone
+}
+
+// cloneValue copies the maps and lists a decoded json value is built from.
+// Other values are immutable, and are shared.
+func cloneValue(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		return cloneMap(x)
+	case []any:
+		if x == nil {
+			return x
+		}
+		clone := make([]any, len(x))
+		for i, vv := range x {
+			clone[i] = cloneValue(vv)
+		}
+		return clone
+	}
+	return v
+}
@@ -0,0 +1,49 @@
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestV3Event_Clone tests:
+//
+// 1. the clone is equal to the event.
+// 2. modifying the nested maps and lists of the clone leaves the event
+// unchanged.
+// 3. nil maps remain nil.
+func TestV3Event_Clone(t *testing.T) {
+	e := V3Event{
+		Identity: "assets/1/events/2",
+		EventAttributes: map[string]any{
+			"a":    "b",
+			"dict": map[string]any{"k": "v"},
+			"list": []any{map[string]any{"k": "v"}},
+		},
+		PrincipalDeclared: map[string]any{"issuer": "idp"},
+	}
+
+	clone := e.Clone()
+	assert.Equal(t, e, clone)
+
+	clone.EventAttributes["a"] = "changed"
+	clone.EventAttributes["dict"].(map[string]any)["k"] = "changed"
+	clone.EventAttributes["list"].([]any)[0].(map[string]any)["k"] = "changed"
+	clone.PrincipalDeclared["issuer"] = "changed"
+	clone.ToPublicIdentity()
+
+	assert.Equal(t, "b", e.EventAttributes["a"])
+	assert.Equal(t, "v", e.EventAttributes["dict"].(map[string]any)["k"])
+	assert.Equal(t, "v", e.EventAttributes["list"].([]any)[0].(map[string]any)["k"])
+	assert.Equal(t, "idp", e.PrincipalDeclared["issuer"])
+	assert.Equal(t, "assets/1/events/2", e.Identity)
+	assert.Nil(t, clone.AssetAttributes)
+}
+
+// TestV2Event_Clone tests that the attribute maps of the clone are copied
+func TestV2Event_Clone(t *testing.T) {
+	e := V2Event{AssetAttributes: map[string]any{"a": "b"}}
+	clone := e.Clone()
+	clone.AssetAttributes["a"] = "changed"
+	assert.Equal(t, "b", e.AssetAttributes["a"])
+}
@@ -0,0 +1,73 @@
+package simplehash
+
+import (
+	"strings"
+)
+
+// V3FromV2 converts a V2 event to the V3 schema, so tooling which stores one
+// schema form can produce digests under the other without round tripping
+// through json. The asset identity, confirmation status and from fields,
+// which V3 does not hash, are dropped. As for V3FromEventJSON the identity is
+// converted to its permissioned form. The maps are shared, not copied.
+func V3FromV2(v2Event V2Event) V3Event {
+	return V3Event{
+		Identity:           permissionedIdentity(v2Event.Identity),
+		EventAttributes:    v2Event.EventAttributes,
+		AssetAttributes:    v2Event.AssetAttributes,
+		Operation:          v2Event.Operation,
+		Behaviour:          v2Event.Behaviour,
+		TimestampDeclared:  v2Event.TimestampDeclared,
+		TimestampAccepted:  v2Event.TimestampAccepted,
+		TimestampCommitted: v2Event.TimestampCommitted,
+		PrincipalAccepted:  v2Event.PrincipalAccepted,
+		PrincipalDeclared:  v2Event.PrincipalDeclared,
+		TenantIdentity:     v2Event.TenantIdentity,
+	}
+}
+
+// V2OnlyFields are the fields of a V2 event which can not be recovered from
+// a V3 event
+type V2OnlyFields struct {
+	ConfirmationStatus string
+	From               string
+	// IsPublic converts the identities to their public form, as the V3 event
+	// only holds the permissioned identity
+	IsPublic bool
+}
+
+// V2FromV3 converts a V3 event to the V2 schema. The conversion is lossy, V3
+// does not hold the fields in V2OnlyFields, so the caller provides them. The
+// asset identity is taken from the event identity. The maps are shared, not
+// copied.
+func V2FromV3(v3Event V3Event, fields V2OnlyFields) V2Event {
+	v2Event := V2Event{
+		Identity:           v3Event.Identity,
+		AssetIdentity:      assetIdentityOf(v3Event.Identity),
+		EventAttributes:    v3Event.EventAttributes,
+		AssetAttributes:    v3Event.AssetAttributes,
+		Operation:          v3Event.Operation,
+		Behaviour:          v3Event.Behaviour,
+		TimestampDeclared:  v3Event.TimestampDeclared,
+		TimestampAccepted:  v3Event.TimestampAccepted,
+		TimestampCommitted: v3Event.TimestampCommitted,
+		PrincipalAccepted:  v3Event.PrincipalAccepted,
+		PrincipalDeclared:  v3Event.PrincipalDeclared,
+		ConfirmationStatus: fields.ConfirmationStatus,
+		From:               fields.From,
+		TenantIdentity:     v3Event.TenantIdentity,
+	}
+	if fields.IsPublic {
+		v2Event.ToPublicIdentity()
+	}
+	return v2Event
+}
+
+// assetIdentityOf returns the asset part of an event identity, or the empty
+// string if it is not an event identity
+func assetIdentityOf(identity string) string {
+	assetIdentity, _, ok := strings.Cut(identity, eventIdentityInfix)
+	if !ok {
+		return ""
+	}
+	return assetIdentity
+}
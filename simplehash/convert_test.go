@@ -0,0 +1,41 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestV3FromV2 tests:
+//
+// 1. a V2 event converted to V3 has the V3 digest of the same event json.
+// 2. converting back, with the V2 only fields, reproduces the V2 event.
+// 3. a public V2 event is converted to the permissioned V3 identity, and
+// back with IsPublic.
+func TestV3FromV2(t *testing.T) {
+	eventJson, err := NewEventMarshaler().Marshal(validEventsV2[0])
+	require.NoError(t, err)
+
+	v2Event, err := V2FromEventJSON(eventJson)
+	require.NoError(t, err)
+	expected, err := DigestEventFromJSON(eventJson)
+	require.NoError(t, err)
+
+	v3Event := V3FromV2(v2Event)
+	digest, err := HashOfV3(v3Event)
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	roundTrip := V2FromV3(v3Event, V2OnlyFields{ConfirmationStatus: v2Event.ConfirmationStatus, From: v2Event.From})
+	assert.Equal(t, v2Event, roundTrip)
+
+	public := v2Event
+	public.ToPublicIdentity()
+	v3Event = V3FromV2(public)
+	assert.Equal(t, v2Event.Identity, v3Event.Identity)
+	roundTrip = V2FromV3(v3Event, V2OnlyFields{ConfirmationStatus: v2Event.ConfirmationStatus, From: v2Event.From, IsPublic: true})
+	assert.Equal(t, public, roundTrip)
+}
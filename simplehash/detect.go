@@ -0,0 +1,75 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The simple hash schema versions
+const (
+	SchemaVersionV2 = 2
+	SchemaVersionV3 = 3
+)
+
+// v2Fields are the fields hashed by schema v2 but not by v3
+var v2Fields = []string{"asset_identity", "confirmation_status", "from"}
+
+// DetectSchema guesses the simple hash schema an api formatted event was
+// produced for, so tooling can process exports which span both eras:
+//
+//   - events with a merklelog_entry are from the merkle log era, schema v3.
+//     Such events also carry the v2 fields, so this check comes first.
+//   - otherwise, events with any of asset_identity, confirmation_status or
+//     from are schema v2.
+//   - otherwise the event has the minimal v3 shape, schema v3.
+//
+// An error is returned if the json is not an object.
+func DetectSchema(eventJson []byte) (int, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(eventJson, &fields); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if _, ok := fields["merklelog_entry"]; ok {
+		return SchemaVersionV3, nil
+	}
+	for _, field := range v2Fields {
+		if _, ok := fields[field]; ok {
+			return SchemaVersionV2, nil
+		}
+	}
+	return SchemaVersionV3, nil
+}
+
+// HashEventJSONAuto hashes an api formatted event with the schema reported by
+// DetectSchema, returning the schema used and the digest.
+//
+// Options: as for HasherV3.HashEventFromJSON or HasherV2.HashEventJSON,
+// according to the schema detected. The batch options are ignored, except
+// WithCache which caches schema v3 digests.
+func HashEventJSONAuto(eventJson []byte, opts ...HashOption) (int, []byte, error) {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	schema, err := DetectSchema(eventJson)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	switch schema {
+	case SchemaVersionV2:
+		h := NewHasherV2()
+		if err = h.HashEventJSON(eventJson, withoutBatch(opts)...); err != nil {
+			return schema, nil, err
+		}
+		return schema, h.Sum(), nil
+	default:
+		digest, err := DigestEventFromJSON(eventJson, append(withoutBatch(opts), WithCache(o.cache))...)
+		if err != nil {
+			return schema, nil, err
+		}
+		return schema, digest, nil
+	}
+}
@@ -0,0 +1,64 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"testing"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDetectSchema tests:
+//
+// 1. each era of event json is detected.
+// 2. json which is not an object is rejected.
+func TestDetectSchema(t *testing.T) {
+	current, err := NewEventMarshaler().Marshal(&v2assets.EventResponse{Identity: "assets/1/events/2"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		eventJson []byte
+		expected  int
+	}{
+		{"merklelog era", current, SchemaVersionV3},
+		{"v2 era", []byte(`{"identity": "assets/1/events/2", "asset_identity": "assets/1", "from": "0x1"}`), SchemaVersionV2},
+		{"minimal v3", []byte(`{"identity": "assets/1/events/2", "event_attributes": {}}`), SchemaVersionV3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := DetectSchema(test.eventJson)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, schema)
+		})
+	}
+
+	_, err = DetectSchema([]byte(`[]`))
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+}
+
+// TestHashEventJSONAuto tests:
+//
+// 1. the digest is that of the detected schema.
+func TestHashEventJSONAuto(t *testing.T) {
+	v2Json := []byte(`{"identity": "assets/1/events/2", "asset_identity": "assets/1"}`)
+
+	schema, digest, err := HashEventJSONAuto(v2Json)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersionV2, schema)
+
+	h2 := NewHasherV2()
+	require.NoError(t, h2.HashEventJSON(v2Json))
+	assert.Equal(t, h2.Sum(), digest)
+
+	v3Json := []byte(`{"identity": "assets/1/events/2"}`)
+	schema, digest, err = HashEventJSONAuto(v3Json)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersionV3, schema)
+
+	h3 := NewHasherV3()
+	require.NoError(t, h3.HashEventFromJSON(v3Json))
+	assert.Equal(t, h3.Sum(nil), digest)
+}
@@ -0,0 +1,172 @@
+package simplehash
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	ErrDuplicateIdentity = errors.New("event identity seen more than once")
+)
+
+// DigestMismatch records an event present in both sources whose digests differ
+type DigestMismatch struct {
+	Identity string
+	DigestA  []byte
+	DigestB  []byte
+}
+
+// InventoryDiff is the set difference between two event inventories. Each
+// list is sorted by identity.
+type InventoryDiff struct {
+	// OnlyInA lists the identities present only in the first source
+	OnlyInA []string
+	// OnlyInB lists the identities present only in the second source
+	OnlyInB []string
+	// Mismatched lists the events present in both sources with different digests
+	Mismatched []DigestMismatch
+}
+
+// Consistent is true if both sources hold exactly the same events with the same digests
+func (d InventoryDiff) Consistent() bool {
+	return len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0 && len(d.Mismatched) == 0
+}
+
+// HashInventoryFromJSON hashes each api formatted event individually and
+// returns the V3 digests keyed by the permissioned event identity. Public and
+// permissioned forms of the same event have the same key.
+//
+// Options: as for HashEventFromJSON, except WithAccumulate which is ignored,
+// and WithProgress and WithCache. With WithContinueOnError, the inventory of the events
+// which could be hashed is returned with a *BatchError.
+func HashInventoryFromJSON(events [][]byte, opts ...HashOption) (map[string][]byte, error) {
+	return HashInventoryFromJSONContext(context.Background(), events, opts...)
+}
+
+// HashInventoryFromJSONContext is HashInventoryFromJSON, stopping promptly if
+// ctx is done. On cancellation the partial inventory is returned with a
+// *CanceledError.
+func HashInventoryFromJSONContext(ctx context.Context, events [][]byte, opts ...HashOption) (map[string][]byte, error) {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	h := NewHasherV3()
+	inventory := make(map[string][]byte, len(events))
+	var errs []*EventError
+
+	for i, eventJson := range events {
+
+		if err := ctx.Err(); err != nil {
+			return inventory, &CanceledError{Processed: i, Err: err}
+		}
+
+		identity, err := hashInventoryEvent(&h, inventory, eventJson, o, opts)
+		if err != nil {
+			if !o.continueOnError {
+				return nil, fmt.Errorf("inventory event %d: %w", i, err)
+			}
+			errs = append(errs, &EventError{Index: i, Identity: identity, Err: err})
+		}
+		o.reportProgress(i+1, len(events), identity)
+	}
+	if len(errs) != 0 {
+		return inventory, &BatchError{Errors: errs}
+	}
+	return inventory, nil
+}
+
+// hashInventoryEvent adds the digest of a single event to the inventory,
+// returning the event identity if it could be determined.
+func hashInventoryEvent(
+	h *HasherV3, inventory map[string][]byte, eventJson []byte, o HashOptions, opts []HashOption,
+) (string, error) {
+
+	v3Event, err := v3FromEventJSON(eventJson, o)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := inventory[v3Event.Identity]; ok {
+		return v3Event.Identity, fmt.Errorf("%w: %s", ErrDuplicateIdentity, v3Event.Identity)
+	}
+
+	digest, err := h.digestV3Event(v3Event, eventJson, o, opts)
+	if err != nil {
+		return v3Event.Identity, err
+	}
+	inventory[v3Event.Identity] = digest
+	return v3Event.Identity, nil
+}
+
+// DiffEventsJSON hashes the api formatted events from two sources, for
+// example the live api and a customer archive, and reports the events found
+// in only one source and the events whose digests disagree.
+//
+// Options: as for HashInventoryFromJSON, applied to both sources.
+func DiffEventsJSON(a [][]byte, b [][]byte, opts ...HashOption) (InventoryDiff, error) {
+	return DiffEventsJSONContext(context.Background(), a, b, opts...)
+}
+
+// DiffEventsJSONContext is DiffEventsJSON, stopping promptly if ctx is done.
+func DiffEventsJSONContext(ctx context.Context, a [][]byte, b [][]byte, opts ...HashOption) (diff InventoryDiff, err error) {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx, span := o.startSpan(ctx, SpanDiffEvents)
+	defer func() {
+		span.SetAttribute("mismatched", int64(len(diff.Mismatched)))
+		span.End(err)
+	}()
+
+	inventoryA, err := HashInventoryFromJSONContext(ctx, a, opts...)
+	if err != nil {
+		return InventoryDiff{}, fmt.Errorf("source a: %w", err)
+	}
+	inventoryB, err := HashInventoryFromJSONContext(ctx, b, opts...)
+	if err != nil {
+		return InventoryDiff{}, fmt.Errorf("source b: %w", err)
+	}
+
+	diff = DiffInventories(inventoryA, inventoryB)
+	o.addCount(ctx, CounterMismatches, len(diff.Mismatched))
+	return diff, nil
+}
+
+// DiffInventories compares two identity to digest inventories
+func DiffInventories(a map[string][]byte, b map[string][]byte) InventoryDiff {
+
+	diff := InventoryDiff{}
+
+	for identity, digestA := range a {
+		digestB, ok := b[identity]
+		if !ok {
+			diff.OnlyInA = append(diff.OnlyInA, identity)
+			continue
+		}
+		if !bytes.Equal(digestA, digestB) {
+			diff.Mismatched = append(diff.Mismatched, DigestMismatch{
+				Identity: identity, DigestA: digestA, DigestB: digestB,
+			})
+		}
+	}
+	for identity := range b {
+		if _, ok := a[identity]; !ok {
+			diff.OnlyInB = append(diff.OnlyInB, identity)
+		}
+	}
+
+	sort.Strings(diff.OnlyInA)
+	sort.Strings(diff.OnlyInB)
+	sort.Slice(diff.Mismatched, func(i, j int) bool {
+		return diff.Mismatched[i].Identity < diff.Mismatched[j].Identity
+	})
+
+	return diff
+}
@@ -0,0 +1,42 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDiffEventsJSON tests:
+//
+// 1. identical sources are consistent, including public vs permissioned identities.
+// 2. missing and modified events are reported.
+func TestDiffEventsJSON(t *testing.T) {
+	marshaler := NewEventMarshaler()
+
+	event0, err := marshaler.Marshal(validEventsV2[0])
+	require.NoError(t, err)
+	event1, err := marshaler.Marshal(validEventsV2[1])
+	require.NoError(t, err)
+
+	public0 := []byte(`{"identity":"publicassets/1234/events/5678","operation":"Record"}`)
+	permissioned0 := []byte(`{"identity":"assets/1234/events/5678","operation":"Record"}`)
+	modified0 := []byte(`{"identity":"assets/1234/events/5678","operation":"Modified"}`)
+
+	diff, err := DiffEventsJSON([][]byte{event0, event1, public0}, [][]byte{permissioned0, event1, event0})
+	require.NoError(t, err)
+	assert.True(t, diff.Consistent())
+
+	diff, err = DiffEventsJSON([][]byte{event0, modified0}, [][]byte{permissioned0, event1})
+	require.NoError(t, err)
+	assert.False(t, diff.Consistent())
+	assert.Equal(t, []string{validEventsV2[0].Identity}, diff.OnlyInA)
+	assert.Equal(t, []string{validEventsV2[1].Identity}, diff.OnlyInB)
+	require.Len(t, diff.Mismatched, 1)
+	assert.Equal(t, "assets/1234/events/5678", diff.Mismatched[0].Identity)
+
+	_, err = DiffEventsJSON([][]byte{event0, event0}, nil)
+	assert.ErrorIs(t, err, ErrDuplicateIdentity)
+}
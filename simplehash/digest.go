@@ -0,0 +1,72 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	digestPrefix = "simplehash"
+
+	// DigestAlgorithmSHA256 is the digest algorithm of every current schema
+	DigestAlgorithmSHA256 = "sha256"
+)
+
+var (
+	ErrInvalidDigest = errors.New("invalid digest string")
+)
+
+// Digest is a simple hash digest along with the schema and algorithm which
+// produced it
+type Digest struct {
+	Schema    int
+	Algorithm string
+	Sum       []byte
+}
+
+// String formats the digest as for FormatDigest
+func (d Digest) String() string {
+	return FormatDigest(d.Schema, d.Algorithm, d.Sum)
+}
+
+// FormatDigest produces a self describing digest string, eg
+//
+//	simplehash:v3:sha256:c52caf06bf525ae7e2fde8e08e2d2cac30ceb8b9f761503d7f671213b07fc576
+//
+// so stored digests remain unambiguous across schema and algorithm changes.
+func FormatDigest(schema int, alg string, sum []byte) string {
+	return fmt.Sprintf("%s:v%d:%s:%s", digestPrefix, schema, alg, hex.EncodeToString(sum))
+}
+
+// ParseDigest parses a digest string produced by FormatDigest
+func ParseDigest(s string) (Digest, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 || parts[0] != digestPrefix {
+		return Digest{}, fmt.Errorf("%w: %q", ErrInvalidDigest, s)
+	}
+
+	version, ok := strings.CutPrefix(parts[1], "v")
+	schema, err := strconv.Atoi(version)
+	if !ok || err != nil || schema <= 0 {
+		return Digest{}, fmt.Errorf("%w: schema %q", ErrInvalidDigest, parts[1])
+	}
+
+	alg := parts[2]
+	if alg == "" || alg != strings.ToLower(alg) {
+		return Digest{}, fmt.Errorf("%w: algorithm %q", ErrInvalidDigest, alg)
+	}
+
+	sum, err := hex.DecodeString(parts[3])
+	if err != nil || len(sum) == 0 {
+		return Digest{}, fmt.Errorf("%w: digest %q", ErrInvalidDigest, parts[3])
+	}
+	if alg == DigestAlgorithmSHA256 && len(sum) != sha256.Size {
+		return Digest{}, fmt.Errorf("%w: sha256 digest of %d bytes", ErrInvalidDigest, len(sum))
+	}
+
+	return Digest{Schema: schema, Algorithm: alg, Sum: sum}, nil
+}
@@ -0,0 +1,43 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFormatDigest tests:
+//
+// 1. a digest formats as a self describing string and parses back.
+// 2. malformed digest strings are rejected.
+func TestFormatDigest(t *testing.T) {
+	sum, err := hex.DecodeString(expectedHashAllV3)
+	require.NoError(t, err)
+
+	s := FormatDigest(SchemaVersionV3, DigestAlgorithmSHA256, sum)
+	assert.Equal(t, "simplehash:v3:sha256:"+expectedHashAllV3, s)
+
+	d, err := ParseDigest(s)
+	require.NoError(t, err)
+	assert.Equal(t, Digest{Schema: SchemaVersionV3, Algorithm: DigestAlgorithmSHA256, Sum: sum}, d)
+	assert.Equal(t, s, d.String())
+
+	for _, bad := range []string{
+		"",
+		expectedHashAllV3,
+		"sha256:" + expectedHashAllV3,
+		"simplehash:3:sha256:" + expectedHashAllV3,
+		"simplehash:v0:sha256:" + expectedHashAllV3,
+		"simplehash:v3:SHA256:" + expectedHashAllV3,
+		"simplehash:v3:sha256:" + expectedHashAllV3[2:],
+		"simplehash:v3:sha256:zz",
+		"simplehash:v3:sha256:" + expectedHashAllV3 + ":x",
+	} {
+		_, err = ParseDigest(bad)
+		assert.ErrorIs(t, err, ErrInvalidDigest, bad)
+	}
+}
@@ -0,0 +1,167 @@
+package simplehash
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+var (
+	ErrDigestNotFound = errors.New("digest not found")
+)
+
+// DigestStore persists individual event digests by event identity, so batch
+// and streaming results can be kept without binding to a particular
+// database. Get returns ErrDigestNotFound if no digest has been saved for the
+// identity. Iterate visits every identity, in ascending order, until fn
+// returns an error, which Iterate then returns. Implementations must be safe
+// for concurrent use.
+type DigestStore interface {
+	Put(identity string, digest []byte) error
+	Get(identity string) ([]byte, error)
+	Iterate(fn func(identity string, digest []byte) error) error
+}
+
+// WithDigestStore saves the individual digest of each event accumulated by a
+// batch or stream to store, as for WithEventDigests. A failure to save stops
+// the batch.
+func WithDigestStore(store DigestStore) HashOption {
+	return func(o *HashOptions) {
+		o.digestStore = store
+	}
+}
+
+// MemoryDigestStore is a DigestStore held in memory
+type MemoryDigestStore struct {
+	mu      sync.Mutex
+	digests map[string][]byte
+}
+
+// NewMemoryDigestStore returns an empty in memory store
+func NewMemoryDigestStore() *MemoryDigestStore {
+	return &MemoryDigestStore{digests: map[string][]byte{}}
+}
+
+// Put saves a copy of the digest, replacing any saved for the identity
+func (s *MemoryDigestStore) Put(identity string, digest []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.digests[identity] = slices.Clone(digest)
+	return nil
+}
+
+// Get returns a copy of the digest saved for the identity
+func (s *MemoryDigestStore) Get(identity string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	digest, ok := s.digests[identity]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDigestNotFound, identity)
+	}
+	return slices.Clone(digest), nil
+}
+
+// Iterate visits the saved digests in identity order. fn sees the digests as
+// they were when Iterate was called.
+func (s *MemoryDigestStore) Iterate(fn func(identity string, digest []byte) error) error {
+	s.mu.Lock()
+	identities := make([]string, 0, len(s.digests))
+	for identity := range s.digests {
+		identities = append(identities, identity)
+	}
+	digests := make(map[string][]byte, len(s.digests))
+	for identity, digest := range s.digests {
+		digests[identity] = digest
+	}
+	s.mu.Unlock()
+
+	slices.Sort(identities)
+	for _, identity := range identities {
+		if err := fn(identity, slices.Clone(digests[identity])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileDigestStore is a DigestStore which appends each digest to a file, one
+// json object per line, and indexes the file in memory. Digests saved again
+// for the same identity replace earlier ones when the file is reopened. Call
+// Sync to make saved digests durable, and Close when done.
+type FileDigestStore struct {
+	MemoryDigestStore
+
+	f *os.File
+	w *bufio.Writer
+}
+
+type fileDigestRecord struct {
+	Identity string `json:"identity"`
+	Digest   []byte `json:"digest"`
+}
+
+// OpenFileDigestStore opens, or creates, the digest file at path
+func OpenFileDigestStore(path string) (*FileDigestStore, error) {
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileDigestStore{
+		MemoryDigestStore: MemoryDigestStore{digests: map[string][]byte{}},
+		f:                 f,
+		w:                 bufio.NewWriter(f),
+	}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		record := fileDigestRecord{}
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		s.digests[record.Identity] = record.Digest
+	}
+	if err = scanner.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put appends the digest to the file and saves it in the index
+func (s *FileDigestStore) Put(identity string, digest []byte) error {
+	data, err := json.Marshal(fileDigestRecord{Identity: identity, Digest: digest})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err = s.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.digests[identity] = slices.Clone(digest)
+	return nil
+}
+
+// Sync flushes the saved digests to stable storage
+func (s *FileDigestStore) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+// Close syncs and closes the file
+func (s *FileDigestStore) Close() error {
+	return errors.Join(s.Sync(), s.f.Close())
+}
@@ -0,0 +1,126 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDigestStores tests:
+//
+// 1. a missing digest is ErrDigestNotFound.
+// 2. a digest put replaces any earlier digest for the identity.
+// 3. iterate visits the identities in order and stops on error.
+// 4. the file store reloads the latest digests when reopened.
+func TestDigestStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digests.jsonl")
+
+	tests := []struct {
+		name   string
+		open   func(t *testing.T) DigestStore
+		reopen func(t *testing.T, s DigestStore) DigestStore
+	}{
+		{
+			name: "memory",
+			open: func(t *testing.T) DigestStore { return NewMemoryDigestStore() },
+		},
+		{
+			name: "file",
+			open: func(t *testing.T) DigestStore {
+				s, err := OpenFileDigestStore(path)
+				require.NoError(t, err)
+				return s
+			},
+			reopen: func(t *testing.T, s DigestStore) DigestStore {
+				require.NoError(t, s.(*FileDigestStore).Close())
+				reopened, err := OpenFileDigestStore(path)
+				require.NoError(t, err)
+				t.Cleanup(func() { reopened.Close() })
+				return reopened
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := test.open(t)
+
+			_, err := s.Get("assets/1/events/1")
+			assert.ErrorIs(t, err, ErrDigestNotFound)
+
+			require.NoError(t, s.Put("assets/2/events/1", []byte{2}))
+			require.NoError(t, s.Put("assets/1/events/1", []byte{0}))
+			require.NoError(t, s.Put("assets/1/events/1", []byte{1}))
+
+			if test.reopen != nil {
+				s = test.reopen(t, s)
+			}
+
+			digest, err := s.Get("assets/1/events/1")
+			require.NoError(t, err)
+			assert.Equal(t, []byte{1}, digest)
+
+			var identities []string
+			require.NoError(t, s.Iterate(func(identity string, digest []byte) error {
+				identities = append(identities, identity)
+				return nil
+			}))
+			assert.Equal(t, []string{"assets/1/events/1", "assets/2/events/1"}, identities)
+
+			stop := errors.New("stop")
+			visited := 0
+			err = s.Iterate(func(identity string, digest []byte) error {
+				visited++
+				return stop
+			})
+			assert.ErrorIs(t, err, stop)
+			assert.Equal(t, 1, visited)
+		})
+	}
+}
+
+// TestWithDigestStore tests:
+//
+// 1. a batch saves the digest of each event, as for WithEventDigests.
+// 2. a stream saves the digest of each event.
+func TestWithDigestStore(t *testing.T) {
+	marshaler := NewEventMarshaler()
+
+	var events [][]byte
+	for _, event := range validEventsV2 {
+		eventJson, err := marshaler.Marshal(event)
+		require.NoError(t, err)
+		events = append(events, eventJson)
+	}
+
+	h := NewHasherV3()
+	expected, err := h.HashEventsFromJSON(events, WithEventDigests())
+	require.NoError(t, err)
+
+	store := NewMemoryDigestStore()
+	_, err = h.HashEventsFromJSON(events, WithDigestStore(store))
+	require.NoError(t, err)
+	for identity, digest := range expected.EventDigests {
+		stored, err := store.Get(identity)
+		require.NoError(t, err)
+		assert.Equal(t, digest, stored)
+	}
+
+	store = NewMemoryDigestStore()
+	checkpoints, err := NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	s, err := NewStreamHasher(checkpoints, "stream", 0, WithDigestStore(store))
+	require.NoError(t, err)
+	for _, eventJson := range events {
+		require.NoError(t, s.Add(eventJson, nil))
+	}
+	for identity, digest := range expected.EventDigests {
+		stored, err := store.Get(identity)
+		require.NoError(t, err)
+		assert.Equal(t, digest, stored)
+	}
+}
@@ -0,0 +1,205 @@
+package simplehash
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Selective disclosure replaces each attribute with a salted commitment, so
+// that the holder of an event can prove the value of a single attribute
+// without revealing the others.
+//
+//	commitment = H(salt || bencode([scope, name, value]))
+//	base       = H(bencode(event without its attributes))
+//	root       = H(base || commitment_1 || ... || commitment_n)
+//
+// The commitments are sorted before the root is computed. The commitments and
+// base reveal nothing about the attribute values, and can be published
+// alongside the root.
+//
+// Commitments are encoded with the options the event is hashed with. Only
+// WithUseNumber changes the encoding, the other options are ignored. The
+// verifier must use the same options as the committer.
+
+// Attribute scopes for selective disclosure
+const (
+	ScopeEventAttributes = "event_attributes"
+	ScopeAssetAttributes = "asset_attributes"
+)
+
+const disclosureSaltSize = 16
+
+var (
+	ErrNoSuchAttribute    = errors.New("no such attribute")
+	ErrInvalidDisclosure  = errors.New("attribute proof does not match the commitments")
+	ErrCommitmentMismatch = errors.New("commitments do not match the root")
+)
+
+// AttributeProof discloses a single attribute value, with the salt needed to
+// reproduce its commitment.
+type AttributeProof struct {
+	Scope string `json:"scope"`
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+	Salt  []byte `json:"salt"`
+}
+
+// UnmarshalJSON decodes a numeric value as a json.Number, so that integers
+// too large for a float64 still reproduce their commitment.
+func (p *AttributeProof) UnmarshalJSON(data []byte) error {
+	type attributeProof AttributeProof
+	var decoded attributeProof
+	if err := decodeJSON(data, &decoded, true); err != nil {
+		return err
+	}
+	*p = AttributeProof(decoded)
+	return nil
+}
+
+// Commitment returns the salted commitment to the disclosed attribute.
+// Options: WithUseNumber, which is needed to commit numeric values.
+func (p AttributeProof) Commitment(opts ...HashOption) ([]byte, error) {
+	return p.commitment(disclosureOptions(opts))
+}
+
+func (p AttributeProof) commitment(o HashOptions) ([]byte, error) {
+	encoded, err := encodeEvent("AttributeCommitment", []any{p.Scope, p.Name, p.Value}, o)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(p.Salt)
+	h.Write(encoded)
+	return h.Sum(nil), nil
+}
+
+// CommittedAttributes is the selective disclosure form of an event
+type CommittedAttributes struct {
+	// Base is the hash of the event without its attributes
+	Base []byte
+	// Commitments is the sorted set of attribute commitments
+	Commitments [][]byte
+	// Root commits to the base and every attribute
+	Root []byte
+
+	proofs map[string]AttributeProof
+}
+
+// CommitAttributes produces the selective disclosure commitments for the
+// event. Salts are read from random, crypto/rand is used if it is nil.
+//
+// Options: WithUseNumber, as for the event's hash.
+func CommitAttributes(v3Event V3Event, random io.Reader, opts ...HashOption) (CommittedAttributes, error) {
+
+	if random == nil {
+		random = rand.Reader
+	}
+	o := disclosureOptions(opts)
+
+	base := v3Event
+	base.EventAttributes = nil
+	base.AssetAttributes = nil
+	encoded, err := v3EncodeEvent(base, o)
+	if err != nil {
+		return CommittedAttributes{}, err
+	}
+	baseHash := sha256.Sum256(encoded)
+
+	c := CommittedAttributes{
+		Base:   baseHash[:],
+		proofs: map[string]AttributeProof{},
+	}
+
+	for _, scoped := range []struct {
+		scope      string
+		attributes map[string]any
+	}{
+		{ScopeEventAttributes, v3Event.EventAttributes},
+		{ScopeAssetAttributes, v3Event.AssetAttributes},
+	} {
+		for name, value := range scoped.attributes {
+			proof := AttributeProof{
+				Scope: scoped.scope,
+				Name:  name,
+				Value: value,
+				Salt:  make([]byte, disclosureSaltSize),
+			}
+			if _, err = io.ReadFull(random, proof.Salt); err != nil {
+				return CommittedAttributes{}, err
+			}
+			commitment, err := proof.commitment(o)
+			if err != nil {
+				return CommittedAttributes{}, fmt.Errorf("%s %s: %w", scoped.scope, name, err)
+			}
+			c.Commitments = append(c.Commitments, commitment)
+			c.proofs[scoped.scope+"/"+name] = proof
+		}
+	}
+
+	sortCommitments(c.Commitments)
+	c.Root = commitmentRoot(c.Base, c.Commitments)
+
+	return c, nil
+}
+
+// Proof returns the disclosure for a single attribute
+func (c CommittedAttributes) Proof(scope string, name string) (AttributeProof, error) {
+	proof, ok := c.proofs[scope+"/"+name]
+	if !ok {
+		return AttributeProof{}, fmt.Errorf("%w: %s %s", ErrNoSuchAttribute, scope, name)
+	}
+	return proof, nil
+}
+
+// VerifyAttributeProof checks the disclosed attribute is one of the
+// commitments, and that the base and commitments reproduce root.
+//
+// Options: WithUseNumber, as for CommitAttributes.
+func VerifyAttributeProof(root []byte, base []byte, commitments [][]byte, proof AttributeProof, opts ...HashOption) error {
+
+	sorted := append([][]byte(nil), commitments...)
+	sortCommitments(sorted)
+	if !bytes.Equal(commitmentRoot(base, sorted), root) {
+		return ErrCommitmentMismatch
+	}
+
+	commitment, err := proof.Commitment(opts...)
+	if err != nil {
+		return err
+	}
+	i := sort.Search(len(sorted), func(i int) bool { return bytes.Compare(sorted[i], commitment) >= 0 })
+	if i == len(sorted) || !bytes.Equal(sorted[i], commitment) {
+		return ErrInvalidDisclosure
+	}
+	return nil
+}
+
+// disclosureOptions keeps only the options which change how a commitment is
+// encoded.
+func disclosureOptions(opts []HashOption) HashOptions {
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return HashOptions{useNumber: o.useNumber}
+}
+
+func sortCommitments(commitments [][]byte) {
+	sort.Slice(commitments, func(i, j int) bool {
+		return bytes.Compare(commitments[i], commitments[j]) < 0
+	})
+}
+
+func commitmentRoot(base []byte, sorted [][]byte) []byte {
+	h := sha256.New()
+	h.Write(base)
+	for _, c := range sorted {
+		h.Write(c)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,81 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCommitAttributes tests:
+//
+// 1. a disclosed attribute verifies against the published commitments.
+// 2. a disclosure with a different value does not verify.
+// 3. a proof survives a json round trip.
+func TestCommitAttributes(t *testing.T) {
+	v3Event := V3Event{
+		Identity:        "assets/1/events/2",
+		EventAttributes: map[string]any{"weight": "10kg", "owner": "alice"},
+		AssetAttributes: map[string]any{"location": map[string]any{"lat": "51.5"}},
+	}
+
+	committed, err := CommitAttributes(v3Event, nil)
+	require.NoError(t, err)
+	assert.Len(t, committed.Commitments, 3)
+
+	proof, err := committed.Proof(ScopeEventAttributes, "weight")
+	require.NoError(t, err)
+	assert.NoError(t, VerifyAttributeProof(committed.Root, committed.Base, committed.Commitments, proof))
+
+	forged := proof
+	forged.Value = "1kg"
+	assert.ErrorIs(t, VerifyAttributeProof(committed.Root, committed.Base, committed.Commitments, forged), ErrInvalidDisclosure)
+
+	proof, err = committed.Proof(ScopeAssetAttributes, "location")
+	require.NoError(t, err)
+	proofJson, err := json.Marshal(proof)
+	require.NoError(t, err)
+	decoded := AttributeProof{}
+	require.NoError(t, json.Unmarshal(proofJson, &decoded))
+	assert.NoError(t, VerifyAttributeProof(committed.Root, committed.Base, committed.Commitments, decoded))
+
+	_, err = committed.Proof(ScopeEventAttributes, "missing")
+	assert.ErrorIs(t, err, ErrNoSuchAttribute)
+}
+
+// TestCommitAttributes_Integer tests:
+//
+// 1. an integer attribute can not be committed without WithUseNumber.
+// 2. an integer attribute committed with WithUseNumber verifies with it.
+// 3. an integer too large for a float64 verifies after a json round trip.
+// 4. a forged integer value does not verify.
+func TestCommitAttributes_Integer(t *testing.T) {
+	v3Event := V3Event{}
+	require.NoError(t, decodeJSON([]byte(`{
+		"identity": "assets/1/events/2",
+		"event_attributes": {"count": 12, "serial": 123456789012345678901},
+		"asset_attributes": {"name": "crate"}
+	}`), &v3Event, true))
+
+	_, err := CommitAttributes(v3Event, nil)
+	assert.ErrorIs(t, err, ErrNumberNeedsUseNumber)
+
+	committed, err := CommitAttributes(v3Event, nil, WithUseNumber())
+	require.NoError(t, err)
+
+	proof, err := committed.Proof(ScopeEventAttributes, "count")
+	require.NoError(t, err)
+	assert.NoError(t, VerifyAttributeProof(committed.Root, committed.Base, committed.Commitments, proof, WithUseNumber()))
+
+	proof, err = committed.Proof(ScopeEventAttributes, "serial")
+	require.NoError(t, err)
+	proofJson, err := json.Marshal(proof)
+	require.NoError(t, err)
+	decoded := AttributeProof{}
+	require.NoError(t, json.Unmarshal(proofJson, &decoded))
+	assert.NoError(t, VerifyAttributeProof(committed.Root, committed.Base, committed.Commitments, decoded, WithUseNumber()))
+
+	decoded.Value = json.Number("123456789012345678900")
+	assert.ErrorIs(t, VerifyAttributeProof(committed.Root, committed.Base, committed.Commitments, decoded, WithUseNumber()), ErrInvalidDisclosure)
+}
@@ -0,0 +1,40 @@
+package simplehash
+
+import (
+	"fmt"
+)
+
+// Domain is a well known hash domain. Its single byte is hashed first, so
+// that digests made for different purposes can never be confused, which
+// guards against second pre-image attacks between them. Use WithDomain
+// rather than passing the raw byte to WithPrefix.
+type Domain uint8
+
+const (
+	// DomainLeafPlain is the leaf type prefixed to every plain event leaf in
+	// the merkle log, providing domain separation from the interior nodes.
+	DomainLeafPlain Domain = 0
+)
+
+var domainNames = map[Domain]string{
+	DomainLeafPlain: "leaf-plain",
+}
+
+func (d Domain) String() string {
+	if name, ok := domainNames[d]; ok {
+		return name
+	}
+	return fmt.Sprintf("domain(%d)", uint8(d))
+}
+
+// Bytes returns the prefix bytes for the domain
+func (d Domain) Bytes() []byte {
+	return []byte{byte(d)}
+}
+
+// WithDomain prefixes the hash with the domain byte. It is built on
+// WithPrefix, so the domain is appended to any prefix already given, and
+// should normally be the first prefix option.
+func WithDomain(d Domain) HashOption {
+	return WithPrefix(d.Bytes())
+}
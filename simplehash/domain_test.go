@@ -0,0 +1,30 @@
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithDomain tests:
+//
+// 1. WithDomain hashes the same as WithPrefix of the domain byte.
+// 2. different domains produce different digests.
+// 3. domains have names, and unknown domains are still printable.
+func TestWithDomain(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2"}`)
+
+	expected, err := DigestEventFromJSON(eventJson, WithPrefix([]byte{0}))
+	require.NoError(t, err)
+	leaf, err := DigestEventFromJSON(eventJson, WithDomain(DomainLeafPlain))
+	require.NoError(t, err)
+	assert.Equal(t, expected, leaf)
+
+	anchor, err := DigestEventFromJSON(eventJson, WithDomain(Domain(2)))
+	require.NoError(t, err)
+	assert.NotEqual(t, leaf, anchor)
+
+	assert.Equal(t, "leaf-plain", DomainLeafPlain.String())
+	assert.Equal(t, "domain(9)", Domain(9).String())
+}
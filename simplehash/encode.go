@@ -0,0 +1,345 @@
+package simplehash
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zeebo/bencode"
+)
+
+// Numeric attribute values are handled according to a fixed policy, so that
+// digests are deterministic and agree with the python implementation:
+//
+//   - Integers are hashed as bencode integers, eg i123e, but only with
+//     WithUseNumber. Without it json numbers decode as float64, which can not
+//     represent large integers exactly, so they are rejected with
+//     ErrNumberNeedsUseNumber.
+//   - Numbers with a fraction or an exponent have no bencode representation
+//     and are always rejected with ErrNonIntegerNumber.
+//   - NaN and the infinities, which can only occur in events built in go, are
+//     always rejected with ErrNonFiniteNumber.
+var (
+	ErrNonIntegerNumber     = errors.New("non integer numbers can not be hashed")
+	ErrNonFiniteNumber      = errors.New("NaN and infinite numbers can not be hashed")
+	ErrNumberNeedsUseNumber = errors.New("numbers can only be hashed using WithUseNumber")
+)
+
+var (
+	ErrUnknownField  = errors.New("unknown event field")
+	ErrEventTooLarge = errors.New("event json is too large")
+	ErrEventTooDeep  = errors.New("event json is too deeply nested")
+)
+
+// decodeEventJSON unmarshals api formatted json into an event struct. With
+// WithUseNumber, numbers in the attribute maps are kept as json.Number rather
+// than converted to float64, so large integers are preserved exactly. The
+// WithMaxEventSize and WithMaxDepth limits are checked before any decoding.
+func decodeEventJSON(eventJson []byte, event any, o HashOptions) error {
+	if o.maxEventSize > 0 && len(eventJson) > o.maxEventSize {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrEventTooLarge, len(eventJson), o.maxEventSize)
+	}
+	if o.maxDepth > 0 {
+		if err := checkJSONDepth(eventJson, o.maxDepth); err != nil {
+			return err
+		}
+	}
+	return decodeJSON(eventJson, event, o.useNumber)
+}
+
+// decodeEventMap sets the fields of the event struct pointed to by event from
+// the pre-decoded json object m, following the same json field names as
+// decodeEventJSON. Fields not in the event struct are ignored, as they are
+// when decoding json. The attribute and principal maps are shared with m, not
+// copied.
+func decodeEventMap(m map[string]any, event any) error {
+	v := reflect.ValueOf(event).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		value, ok := m[name]
+		if !ok || value == nil {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("%w: %s is %T, not a string", ErrInvalidEvent, name, value)
+			}
+			field.SetString(s)
+		case reflect.Map:
+			attributes, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%w: %s is %T, not an object", ErrInvalidEvent, name, value)
+			}
+			field.Set(reflect.ValueOf(attributes))
+		}
+	}
+	return nil
+}
+
+func decodeJSON(data []byte, v any, useNumber bool) error {
+	if !useNumber {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// checkJSONDepth rejects json whose objects and arrays nest deeper than
+// maxDepth. The event object itself is at depth 1, its attribute maps at
+// depth 2. The scan is a single pass which does not allocate, so it is cheap
+// to run on untrusted input before decoding.
+func checkJSONDepth(data []byte, maxDepth int) error {
+	depth := 0
+	inString := false
+	escaped := false
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("%w: exceeds %d", ErrEventTooDeep, maxDepth)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// maxPooledBufferSize bounds the buffers returned to encodeBufferPool, so an
+// occasional very large event does not pin its buffers in memory.
+const maxPooledBufferSize = 64 << 10
+
+// encodeBuffers holds the intermediate json and the encoded pre-image of an
+// event. They are pooled because the double encode otherwise allocates
+// several KB per event, which dominates when reproducing large anchors.
+type encodeBuffers struct {
+	json  bytes.Buffer
+	event bytes.Buffer
+
+	// scratch and keys are used by the fast encoder, see WithFastEncoding
+	scratch []byte
+	keys    []string
+}
+
+var encodeBufferPool = sync.Pool{
+	New: func() any { return new(encodeBuffers) },
+}
+
+func getEncodeBuffers() *encodeBuffers {
+	b := encodeBufferPool.Get().(*encodeBuffers)
+	b.json.Reset()
+	b.event.Reset()
+	b.keys = b.keys[:0]
+	return b
+}
+
+func putEncodeBuffers(b *encodeBuffers) {
+	if b.json.Cap() > maxPooledBufferSize || b.event.Cap() > maxPooledBufferSize ||
+		cap(b.scratch) > maxPooledBufferSize {
+		return
+	}
+	encodeBufferPool.Put(b)
+}
+
+// encodeEvent produces the bencoded pre-image for a schema event struct. The
+// returned slice is owned by the caller.
+func encodeEvent(schema string, event any, o HashOptions) ([]byte, error) {
+	b := getEncodeBuffers()
+	defer putEncodeBuffers(b)
+
+	if err := b.encodeEvent(schema, event, o); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(b.event.Bytes()), nil
+}
+
+// writeEvent encodes the event and, only if that succeeds, calls
+// applyOptions and then writes the pre-image to w. The pre-image is never
+// copied out of the pooled buffers.
+func writeEvent(w io.Writer, schema string, event any, o HashOptions, applyOptions func()) error {
+	b := getEncodeBuffers()
+	defer putEncodeBuffers(b)
+
+	if err := b.encodeEvent(schema, event, o); err != nil {
+		return err
+	}
+	if applyOptions != nil {
+		applyOptions()
+	}
+	if _, err := w.Write(b.event.Bytes()); err != nil {
+		return err
+	}
+	o.traceWrite(b.event.Bytes())
+	o.observeEvent(schema, event, b.event.Len())
+	return nil
+}
+
+// encodeEvent leaves the pre-image for a schema event struct in b.event. The
+// struct is marshaled to json and back, so that the bencoded dictionary uses
+// the json field names.
+func (b *encodeBuffers) encodeEvent(schema string, event any, o HashOptions) error {
+
+	var err error
+
+	// redacted attributes are hashed by name, outside the attribute maps
+	event, redactions := withoutRedactions(event)
+
+	// json.Encoder differs from json.Marshal only by the trailing newline
+	if err = json.NewEncoder(&b.json).Encode(event); err != nil {
+		var unsupported *json.UnsupportedValueError
+		if errors.As(err, &unsupported) && isNonFinite(unsupported.Str) {
+			return fmt.Errorf("%s: %w: %s", schema, ErrNonFiniteNumber, unsupported.Str)
+		}
+		return fmt.Errorf("%s: failed to marshal event : %v", schema, err)
+	}
+
+	var jsonAny any
+
+	if err = decodeJSON(b.json.Bytes(), &jsonAny, o.useNumber); err != nil {
+		return fmt.Errorf("%s: failed to unmarshal events: %v", schema, err)
+	}
+
+	integer := bencodeInteger
+	switch o.encoding {
+	case EncodingCBOR:
+		integer = cborInteger
+	case EncodingJCS:
+		integer = jcsInteger
+	}
+	if jsonAny, err = canonicalNumbers(jsonAny, integer); err != nil {
+		return fmt.Errorf("%s: %w", schema, err)
+	}
+
+	if len(o.excludeFields) != 0 {
+		if err = excludeFields(jsonAny, o.excludeFields); err != nil {
+			return fmt.Errorf("%s: %w", schema, err)
+		}
+	}
+
+	if redactions != nil {
+		jsonAny.(map[string]any)[redactedAttributesKey] = redactions
+	}
+
+	var encoded []byte
+	switch o.encoding {
+	case EncodingCBOR:
+		encoded, err = cborEncodeEvent(schema, jsonAny)
+	case EncodingJCS:
+		encoded, err = jcsEncodeEvent(schema, jsonAny)
+	default:
+		if err = bencode.NewEncoder(&b.event).Encode(jsonAny); err != nil {
+			return fmt.Errorf("%s: failed to bencode events: %v", schema, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	b.event.Write(encoded)
+	return nil
+}
+
+// canonicalNumbers applies the numeric value policy to the decoded json value.
+// With WithUseNumber, every json.Number is replaced by its integer encoding,
+// as returned by integer. For bencode this matches the python implementation,
+// where json integers decode to arbitrary precision ints and bencode as
+// i<digits>e.
+func canonicalNumbers(v any, integer func(*big.Int) (any, error)) (any, error) {
+
+	var err error
+
+	switch x := v.(type) {
+	case json.Number:
+		s := x.String()
+		if strings.ContainsAny(s, ".eE") {
+			return nil, fmt.Errorf("%w: %s", ErrNonIntegerNumber, s)
+		}
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNonIntegerNumber, s)
+		}
+		return integer(i)
+
+	case float64:
+		if x != math.Trunc(x) {
+			return nil, fmt.Errorf("%w: %v", ErrNonIntegerNumber, x)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrNumberNeedsUseNumber, x)
+
+	case map[string]any:
+		for k, vv := range x {
+			if x[k], err = canonicalNumbers(vv, integer); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, vv := range x {
+			if x[i], err = canonicalNumbers(vv, integer); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+func bencodeInteger(i *big.Int) (any, error) {
+	return bencode.RawMessage("i" + i.String() + "e"), nil
+}
+
+func isNonFinite(s string) bool {
+	f, err := strconv.ParseFloat(s, 64)
+	return err == nil && (math.IsNaN(f) || math.IsInf(f, 0))
+}
+
+// excludedFieldsKey records the fields removed by WithExcludeFields in the
+// hashed dictionary, so the digest can not be mistaken for a standard one.
+const excludedFieldsKey = "_excluded_fields"
+
+func excludeFields(jsonAny any, fields []string) error {
+	m, ok := jsonAny.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: event is not an object", ErrUnknownField)
+	}
+
+	names := append([]string(nil), fields...)
+	sort.Strings(names)
+
+	excluded := make([]any, 0, len(names))
+	for _, name := range names {
+		if _, ok := m[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownField, name)
+		}
+		delete(m, name)
+		excluded = append(excluded, name)
+	}
+	m[excludedFieldsKey] = excluded
+	return nil
+}
@@ -0,0 +1,225 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHasherV3_WithUseNumber tests:
+//
+// 1. integers, including those beyond float64 and int64 precision, hash as
+// bencode integers exactly as the python implementation encodes them.
+// 2. numbers with a fraction or exponent are rejected.
+// 3. without the option numeric attributes are rejected with a typed error.
+func TestHasherV3_WithUseNumber(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		bencoded  string
+		wantErr   error
+		noOptions bool
+	}{
+		{name: "small", value: `42`, bencoded: `i42e`},
+		{name: "negative", value: `-7`, bencoded: `i-7e`},
+		{name: "negative zero", value: `-0`, bencoded: `i0e`},
+		{name: "beyond float64", value: `9007199254740993`, bencoded: `i9007199254740993e`},
+		{name: "beyond int64", value: `123456789012345678901234567890`, bencoded: `i123456789012345678901234567890e`},
+		{name: "fraction", value: `1.5`, wantErr: ErrNonIntegerNumber},
+		{name: "exponent", value: `1e3`, wantErr: ErrNonIntegerNumber},
+		{name: "without option", value: `42`, wantErr: ErrNumberNeedsUseNumber, noOptions: true},
+		{name: "fraction without option", value: `1.5`, wantErr: ErrNonIntegerNumber, noOptions: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eventJson := []byte(`{"identity":"assets/1/events/2","event_attributes":{"n":` + test.value + `}}`)
+
+			opts := []HashOption{WithUseNumber()}
+			if test.noOptions {
+				opts = nil
+			}
+
+			h := NewHasherV3()
+			err := h.HashEventFromJSON(eventJson, opts...)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			// The bencoded event, with the keys in sorted order, null values
+			// omitted and the attribute value encoded as an integer
+			expected := "d" +
+				"9:behaviour0:" +
+				"16:event_attributesd1:n" + test.bencoded + "e" +
+				"8:identity17:assets/1/events/2" +
+				"9:operation0:" +
+				"15:tenant_identity0:" +
+				"18:timestamp_accepted0:" +
+				"19:timestamp_committed0:" +
+				"18:timestamp_declared0:" +
+				"e"
+			sum := sha256.Sum256([]byte(expected))
+			assert.Equal(t, hex.EncodeToString(sum[:]), hex.EncodeToString(h.Sum(nil)))
+		})
+	}
+}
+
+// TestHasherV3_NonFiniteNumbers tests:
+//
+// 1. NaN and infinite attribute values in go built events are rejected with
+// ErrNonFiniteNumber, with or without WithUseNumber.
+func TestHasherV3_NonFiniteNumbers(t *testing.T) {
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		v3Event := V3Event{
+			Identity:        "assets/1/events/2",
+			EventAttributes: map[string]any{"n": value},
+		}
+		h := NewHasherV3()
+		assert.ErrorIs(t, h.HashEventFromV3(v3Event), ErrNonFiniteNumber)
+		assert.ErrorIs(t, h.HashEventFromV3(v3Event, WithUseNumber()), ErrNonFiniteNumber)
+	}
+}
+
+// TestHasherV3_InputLimits tests:
+//
+// 1. events within the limits are hashed.
+// 2. oversized and overly nested events are rejected before decoding.
+// 3. brackets inside strings do not count towards the depth.
+func TestHasherV3_InputLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventJson string
+		opts      []HashOption
+		wantErr   error
+	}{
+		{
+			name:      "within limits",
+			eventJson: `{"identity":"assets/1/events/2","event_attributes":{"a":{"b":"c"}}}`,
+			opts:      []HashOption{WithMaxEventSize(1024), WithMaxDepth(3)},
+		},
+		{
+			name:      "too large",
+			eventJson: `{"identity":"assets/1/events/2"}`,
+			opts:      []HashOption{WithMaxEventSize(8)},
+			wantErr:   ErrEventTooLarge,
+		},
+		{
+			name:      "too deep",
+			eventJson: `{"identity":"assets/1/events/2","event_attributes":{"a":[[["b"]]]}}`,
+			opts:      []HashOption{WithMaxDepth(4)},
+			wantErr:   ErrEventTooDeep,
+		},
+		{
+			name:      "brackets in strings",
+			eventJson: `{"identity":"assets/1/events/2","event_attributes":{"a":"[[[{{{\"]]]"}}`,
+			opts:      []HashOption{WithMaxDepth(2)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewHasherV3()
+			err := h.HashEventFromJSON([]byte(test.eventJson), test.opts...)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestHasherV3_WithExcludeFields tests:
+//
+// 1. events differing only in an excluded field hash the same.
+// 2. the digest differs from the standard digest of the event without the field.
+// 3. unknown field names are rejected.
+func TestHasherV3_WithExcludeFields(t *testing.T) {
+	a := V3Event{Identity: "assets/1/events/2", TimestampCommitted: "2024-01-31T11:29:19.043Z"}
+	b := V3Event{Identity: "assets/1/events/2", TimestampCommitted: "2024-01-31T11:29:20Z"}
+
+	digest := func(e V3Event, opts ...HashOption) []byte {
+		h := NewHasherV3()
+		require.NoError(t, h.HashEventFromV3(e, opts...))
+		return h.Sum(nil)
+	}
+
+	assert.NotEqual(t, digest(a), digest(b))
+	assert.Equal(t, digest(a, WithExcludeFields("timestamp_committed")), digest(b, WithExcludeFields("timestamp_committed")))
+
+	empty := V3Event{Identity: "assets/1/events/2"}
+	assert.NotEqual(t, digest(empty), digest(a, WithExcludeFields("timestamp_committed")))
+
+	h := NewHasherV3()
+	assert.ErrorIs(t, h.HashEventFromV3(a, WithExcludeFields("no_such_field")), ErrUnknownField)
+}
+
+// TestFromMap tests:
+//
+// 1. a pre-decoded event converts to the same v3 and v2 events as its json.
+// 2. a public identity is converted to permissioned for v3.
+// 3. a field of the wrong type is rejected.
+func TestFromMap(t *testing.T) {
+	eventJson := []byte(`{
+		"identity": "publicassets/1/events/2",
+		"asset_identity": "publicassets/1",
+		"event_attributes": {"foo": "bar", "list": [{"a": "b"}]},
+		"asset_attributes": null,
+		"timestamp_accepted": "2024-01-31T11:29:19.043Z",
+		"principal_declared": {"issuer": "idp"},
+		"block_number": 12
+	}`)
+	m := map[string]any{}
+	require.NoError(t, json.Unmarshal(eventJson, &m))
+
+	expectedV3, err := V3FromEventJSON(eventJson)
+	require.NoError(t, err)
+	actualV3, err := V3FromMap(m)
+	require.NoError(t, err)
+	assert.Equal(t, expectedV3, actualV3)
+	assert.Equal(t, "assets/1/events/2", actualV3.Identity)
+
+	expectedV2, err := V2FromEventJSON(eventJson)
+	require.NoError(t, err)
+	actualV2, err := V2FromMap(m)
+	require.NoError(t, err)
+	assert.Equal(t, expectedV2, actualV2)
+
+	_, err = V3FromMap(map[string]any{"identity": 1.0})
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+	_, err = V2FromMap(map[string]any{"event_attributes": []any{}})
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+}
+
+// TestEncodeBuffers tests:
+//
+// 1. the pre-image returned by V3EncodeEvent is not reused by later encodes.
+// 2. V3HashEvent writes the same pre-image through the pooled buffers.
+// 3. a failed encode leaves nothing behind for the next event.
+func TestEncodeBuffers(t *testing.T) {
+	first := V3Event{Identity: "assets/1/events/1", EventAttributes: map[string]any{"a": "1"}}
+	second := V3Event{Identity: "assets/2/events/2", EventAttributes: map[string]any{"b": "2"}}
+
+	encodedFirst, err := V3EncodeEvent(first)
+	require.NoError(t, err)
+	expected := append([]byte(nil), encodedFirst...)
+	_, err = V3EncodeEvent(second)
+	require.NoError(t, err)
+	assert.Equal(t, expected, encodedFirst)
+
+	hasher := sha256.New()
+	require.NoError(t, V3HashEvent(hasher, first))
+	sum := sha256.Sum256(encodedFirst)
+	assert.Equal(t, sum[:], hasher.Sum(nil))
+
+	_, err = V3EncodeEvent(V3Event{EventAttributes: map[string]any{"n": math.NaN()}})
+	assert.ErrorIs(t, err, ErrNonFiniteNumber)
+	encoded, err := V3EncodeEvent(first)
+	require.NoError(t, err)
+	assert.Equal(t, expected, encoded)
+}
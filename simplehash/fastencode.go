@@ -0,0 +1,182 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// WithFastEncoding encodes V3 events with a reflection free encoder which
+// emits the bencode pre-image directly, rather than marshaling the event to
+// json and back. The pre-image, and so the digest, is identical. Events
+// containing values the fast encoder does not handle exactly as the standard
+// encoder does, for example numbers without WithUseNumber, go types other
+// than those produced by decoding json, or strings which are not valid utf-8,
+// are encoded by the standard encoder. The option is ignored together with
+// WithEncoding or WithExcludeFields, and for other schemas.
+func WithFastEncoding() HashOption {
+	return func(o *HashOptions) {
+		o.fastEncoding = true
+	}
+}
+
+// errFastEncoding signals an event the fast encoder can not encode exactly as
+// encodeEvent would. It never escapes the package.
+var errFastEncoding = errors.New("event not supported by the fast encoder")
+
+// fastEncodable is true if the options permit the fast encoder
+func (o *HashOptions) fastEncodable() bool {
+	return o.fastEncoding && o.encoding == EncodingBencode && len(o.excludeFields) == 0
+}
+
+// encodeV3EventFast leaves the bencode pre-image of the event in b.event, or
+// returns errFastEncoding. The dictionary keys are emitted in the sorted
+// order bencode requires, and null values are omitted, exactly as for
+// encodeEvent.
+func (b *encodeBuffers) encodeV3EventFast(e V3Event, o HashOptions) error {
+
+	buf := append(b.scratch[:0], 'd')
+	defer func() { b.scratch = buf[:0] }()
+
+	var err error
+	for _, field := range []struct {
+		key string
+		s   string
+		m   map[string]any
+		isM bool
+	}{
+		{key: "asset_attributes", m: e.AssetAttributes, isM: true},
+		{key: "behaviour", s: e.Behaviour},
+		{key: "event_attributes", m: e.EventAttributes, isM: true},
+		{key: "identity", s: e.Identity},
+		{key: "operation", s: e.Operation},
+		{key: "principal_accepted", m: e.PrincipalAccepted, isM: true},
+		{key: "principal_declared", m: e.PrincipalDeclared, isM: true},
+		{key: "tenant_identity", s: e.TenantIdentity},
+		{key: "timestamp_accepted", s: e.TimestampAccepted},
+		{key: "timestamp_committed", s: e.TimestampCommitted},
+		{key: "timestamp_declared", s: e.TimestampDeclared},
+	} {
+		if !field.isM {
+			buf = appendBencodeString(appendBencodeString(buf, field.key), field.s)
+			if !utf8.ValidString(field.s) {
+				return errFastEncoding
+			}
+			continue
+		}
+		if field.m == nil {
+			continue
+		}
+		buf = appendBencodeString(buf, field.key)
+		if buf, err = b.appendBencodeMap(buf, field.m, o); err != nil {
+			return err
+		}
+	}
+
+	buf = append(buf, 'e')
+	b.event.Write(buf)
+	return nil
+}
+
+// appendBencodeValue appends a value of a decoded json attribute map
+func (b *encodeBuffers) appendBencodeValue(buf []byte, v any, o HashOptions) ([]byte, error) {
+	switch x := v.(type) {
+	case string:
+		if !utf8.ValidString(x) {
+			return nil, errFastEncoding
+		}
+		return appendBencodeString(buf, x), nil
+	case bool:
+		if x {
+			return append(buf, "i1e"...), nil
+		}
+		return append(buf, "i0e"...), nil
+	case json.Number:
+		return appendBencodeNumber(buf, x, o)
+	case map[string]any:
+		return b.appendBencodeMap(buf, x, o)
+	case []any:
+		var err error
+		buf = append(buf, 'l')
+		for _, vv := range x {
+			if vv == nil {
+				continue
+			}
+			if buf, err = b.appendBencodeValue(buf, vv, o); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, 'e'), nil
+	}
+	return nil, errFastEncoding
+}
+
+// appendBencodeMap appends a dictionary with its keys sorted. The keys are
+// sorted in b.keys, which is shared by nested maps as a stack, so that
+// sorting does not allocate once the buffers are pooled.
+func (b *encodeBuffers) appendBencodeMap(buf []byte, m map[string]any, o HashOptions) ([]byte, error) {
+
+	start := len(b.keys)
+	for k, v := range m {
+		if !utf8.ValidString(k) {
+			return nil, errFastEncoding
+		}
+		if v != nil {
+			b.keys = append(b.keys, k)
+		}
+	}
+	keys := b.keys[start:]
+	slices.Sort(keys)
+
+	var err error
+	buf = append(buf, 'd')
+	for _, k := range keys {
+		buf = appendBencodeString(buf, k)
+		if buf, err = b.appendBencodeValue(buf, m[k], o); err != nil {
+			return nil, err
+		}
+	}
+	b.keys = b.keys[:start]
+	return append(buf, 'e'), nil
+}
+
+func appendBencodeString(buf []byte, s string) []byte {
+	buf = strconv.AppendInt(buf, int64(len(s)), 10)
+	buf = append(buf, ':')
+	return append(buf, s...)
+}
+
+// appendBencodeNumber appends a json integer as canonicalNumbers does. Only
+// numbers decoded with WithUseNumber, which are plain json integers, are
+// handled, so any error is left to the standard encoder to report.
+func appendBencodeNumber(buf []byte, n json.Number, o HashOptions) ([]byte, error) {
+	s := string(n)
+	if !o.useNumber || !isJSONInteger(s) {
+		return nil, errFastEncoding
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	buf = append(buf, 'i')
+	buf = append(buf, s...)
+	return append(buf, 'e'), nil
+}
+
+// isJSONInteger is true if s is a json number without a fraction or
+// exponent, -?(0|[1-9][0-9]*)
+func isJSONInteger(s string) bool {
+	if len(s) > 0 && s[0] == '-' {
+		s = s[1:]
+	}
+	if len(s) == 0 || (s[0] == '0' && len(s) > 1) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
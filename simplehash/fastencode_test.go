@@ -0,0 +1,113 @@
+package simplehash
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastEncodingSeeds = []string{
+	`{"identity": "assets/1/events/2"}`,
+	`{"identity": "assets/1/events/2", "event_attributes": {}, "asset_attributes": null}`,
+	`{"identity": "assets/1/events/2", "event_attributes": {"b": "2", "a": "1", "n": null}}`,
+	`{"event_attributes": {"list": ["x", null, true, false, {"k": "v"}], "nested": {"z": {"y": "x"}}}}`,
+	`{"event_attributes": {"n": 42, "neg": -0, "big": 123456789012345678901234567890}}`,
+	`{"event_attributes": {"f": 1.5}}`,
+	`{"event_attributes": {"e": 1e3}}`,
+	`{"event_attributes": {"ünïcödé": "☃", "": ""}, "principal_declared": {"issuer": "i", "subject": "s"}}`,
+	`{"operation": "Record", "behaviour": "RecordEvidence", "timestamp_declared": "2024-01-01T00:00:00Z"}`,
+}
+
+// fastEncode returns the fast pre-image of the event, outside the pool
+func fastEncode(e V3Event, o HashOptions) ([]byte, error) {
+	b := getEncodeBuffers()
+	defer putEncodeBuffers(b)
+	if err := b.encodeV3EventFast(e, o); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(b.event.Bytes()), nil
+}
+
+// requireFastEncodingParity checks that the fast encoder either declines the
+// event or produces exactly the pre-image of the standard encoder
+func requireFastEncodingParity(t *testing.T, e V3Event, o HashOptions) {
+	fast, err := fastEncode(e, o)
+	if err != nil {
+		require.ErrorIs(t, err, errFastEncoding)
+		return
+	}
+	standard, err := v3EncodeEvent(e, o)
+	require.NoError(t, err)
+	require.Equal(t, string(standard), string(fast))
+}
+
+// TestHasherV3_WithFastEncoding tests:
+//
+// 1. the fast encoder produces the standard digests for the reference events.
+// 2. events the fast encoder declines still hash, and fail, as standard.
+// 3. the fast encoder does not allocate once its buffers are pooled.
+func TestHasherV3_WithFastEncoding(t *testing.T) {
+	expected := NewHasherV3()
+	h := NewHasherV3()
+	for _, eventJson := range fastEncodingSeeds {
+		for _, opts := range [][]HashOption{nil, {WithUseNumber()}} {
+			expectedErr := expected.HashEventFromJSON([]byte(eventJson), opts...)
+			err := h.HashEventFromJSON([]byte(eventJson), append(opts, WithFastEncoding())...)
+			// map order decides which invalid value is reported
+			assert.Equal(t, expectedErr == nil, err == nil, eventJson)
+			assert.Equal(t, expected.Sum(nil), h.Sum(nil), eventJson)
+		}
+	}
+
+	v3Event, err := V3FromEventJSON([]byte(fastEncodingSeeds[3]))
+	require.NoError(t, err)
+	o := HashOptions{fastEncoding: true}
+	require.NoError(t, h.hashV3Event(v3Event, o))
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = h.hashV3Event(v3Event, o)
+	})
+	assert.Zero(t, allocs)
+}
+
+// FuzzV3FastEncoding checks the fast encoder against the standard encoder for
+// events decoded from json, with and without WithUseNumber
+func FuzzV3FastEncoding(f *testing.F) {
+	for _, seed := range fastEncodingSeeds {
+		f.Add([]byte(seed), false)
+		f.Add([]byte(seed), true)
+	}
+	f.Fuzz(func(t *testing.T, eventJson []byte, useNumber bool) {
+		o := HashOptions{useNumber: useNumber}
+		v3Event, err := v3FromEventJSON(eventJson, o)
+		if err != nil {
+			return
+		}
+		requireFastEncodingParity(t, v3Event, o)
+	})
+}
+
+// FuzzV3FastEncodingStrings checks the fast encoder against the standard
+// encoder for events built in go, whose strings and keys need not be valid
+// utf-8 and whose numbers need not be valid json
+func FuzzV3FastEncodingStrings(f *testing.F) {
+	f.Add("assets/1/events/2", "key", "value", "42")
+	f.Add("", "", "", "-0")
+	f.Add("\xff", "k\xfe", "\xc3", "1.5")
+	f.Add("☃", "ünï", "<&>", "not a number")
+	f.Fuzz(func(t *testing.T, identity string, key string, value string, number string) {
+		v3Event := V3Event{
+			Identity: identity,
+			EventAttributes: map[string]any{
+				key:      value,
+				"list":   []any{value, nil, true, json.Number(number)},
+				"nested": map[string]any{value: key},
+			},
+			PrincipalAccepted: map[string]any{},
+		}
+		requireFastEncodingParity(t, v3Event, HashOptions{})
+		requireFastEncodingParity(t, v3Event, HashOptions{useNumber: true})
+	})
+}
@@ -0,0 +1,84 @@
+package simplehash
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// HashEventsFS hashes the api formatted events held one per file in fsys, for
+// example an exported archive, an embedded test corpus or a zip.Reader. Files
+// are hashed in path order, so the digest does not depend on how fsys lists
+// them. A glob without a slash, such as "*.json", matches file names in any
+// directory, a glob with a slash matches the whole path, see path.Match.
+//
+// The index of an event in the result is its position in path order, and the
+// errors of events name their file.
+//
+// Options: as for HashEventsFromJSON
+func (h *HasherV3) HashEventsFS(fsys fs.FS, glob string, opts ...HashOption) (BatchResult, error) {
+	return h.HashEventsFSContext(context.Background(), fsys, glob, opts...)
+}
+
+// HashEventsFSContext is HashEventsFS, stopping promptly if ctx is done
+func (h *HasherV3) HashEventsFSContext(ctx context.Context, fsys fs.FS, glob string, opts ...HashOption) (BatchResult, error) {
+
+	paths, err := globFS(fsys, glob)
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	decode := func(i int) (string, []byte, V3Event, error) {
+		eventJson, err := fs.ReadFile(fsys, paths[i])
+		if err != nil {
+			return "", nil, V3Event{}, err
+		}
+		v3Event, err := v3FromEventJSON(eventJson, o)
+		if err != nil {
+			return "", eventJson, V3Event{}, fmt.Errorf("%s: %w", paths[i], err)
+		}
+		return v3Event.Identity, eventJson, v3Event, nil
+	}
+
+	return h.hashBatch(ctx, len(paths), decode, o)
+}
+
+// globFS returns the sorted paths of the regular files in fsys matching glob,
+// see HashEventsFS
+func globFS(fsys fs.FS, glob string) ([]string, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, fmt.Errorf("%w: %q", err, glob)
+	}
+	matchBase := !strings.Contains(glob, "/")
+
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		name := p
+		if matchBase {
+			name = path.Base(p)
+		}
+		if ok, _ := path.Match(glob, name); ok {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
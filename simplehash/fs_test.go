@@ -0,0 +1,72 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/hex"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHasherV3_HashEventsFS tests:
+//
+// 1. events are hashed in path order, whichever order the files were added,
+// and files which do not match are ignored.
+// 2. a zip archive hashes the same as the equivalent fs.
+// 3. a glob with a slash matches the whole path.
+// 4. the error of an invalid event names its file.
+// 5. an invalid glob is rejected with path.ErrBadPattern.
+func TestHasherV3_HashEventsFS(t *testing.T) {
+	marshaler := NewEventMarshaler()
+	event0, err := marshaler.Marshal(validEventsV2[0])
+	require.NoError(t, err)
+	event1, err := marshaler.Marshal(validEventsV2[1])
+	require.NoError(t, err)
+
+	fsys := fstest.MapFS{
+		"b/c.json":  {Data: event1},
+		"a.json":    {Data: event0},
+		"README.md": {Data: []byte("not an event")},
+	}
+	h := NewHasherV3()
+	result, err := h.HashEventsFS(fsys, "*.json")
+	require.NoError(t, err)
+	assert.Equal(t, expectedHashAllV3, hex.EncodeToString(result.Digest))
+	assert.Equal(t, 2, result.Count)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"b/c.json", "a.json"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(fsys[name].Data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	result, err = h.HashEventsFS(zr, "*.json")
+	require.NoError(t, err)
+	assert.Equal(t, expectedHashAllV3, hex.EncodeToString(result.Digest))
+
+	result, err = h.HashEventsFS(fsys, "b/*.json")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Count)
+	expected, err := DigestEventFromJSON(event1)
+	require.NoError(t, err)
+	assert.Equal(t, expected, result.Digest)
+
+	fsys["b/bad.json"] = &fstest.MapFile{Data: []byte(`{"identity": `)}
+	_, err = h.HashEventsFS(fsys, "*.json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "b/bad.json")
+
+	_, err = h.HashEventsFS(fsys, "[")
+	assert.ErrorIs(t, err, path.ErrBadPattern)
+}
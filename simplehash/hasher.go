@@ -1,42 +1,118 @@
 package simplehash
 
 import (
-	"crypto/sha256"
+	"encoding"
+	"errors"
 	"hash"
+)
 
-	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
-	"github.com/datatrails/go-datatrails-common-api-gen/marshalers/simpleoneof"
+var (
+	ErrStateNotSupported = errors.New("hash state can not be saved or restored")
 )
 
 type Hasher struct {
 	hasher    hash.Hash
-	marshaler *simpleoneof.Marshaler
+	marshaler eventMarshaler
+
+	// eventCount and bytesHashed are the statistics of the current digest
+	eventCount  int
+	bytesHashed int64
 }
 
-func NewHasher() Hasher {
+// HasherOption configures a hasher when it is constructed
+type HasherOption func(*Hasher)
+
+func NewHasher(opts ...HasherOption) Hasher {
 	h := Hasher{
-		hasher:    sha256.New(),
-		marshaler: NewEventMarshaler(),
+		hasher:    newSHA256(),
+		marshaler: newEventMarshaler(),
+	}
+	for _, opt := range opts {
+		opt(&h)
 	}
 	return h
 }
 
 func (h *Hasher) Sum(b []byte) []byte { return h.hasher.Sum(b) }
 
-// Reset resets the hasher state
+// Reset resets the hasher state, including EventCount and BytesHashed
 // This is only useful in combination with WithAccumulate
-func (h *Hasher) Reset() { h.hasher.Reset() }
-
-// NewEventMarshaler creates a flat marshaler to transform events to api format.
-//
-// otherwise attributes look like this: {"foo":{"str_val": "bar"}} instead of {"foo": "bar"}
-// this mimics the public list events api response, so minimises changes to the
-// public api response, to reproduce the anchor
-func NewEventMarshaler() *simpleoneof.Marshaler {
-	return v2assets.NewFlatMarshalerForEvents()
+func (h *Hasher) Reset() {
+	h.hasher.Reset()
+	h.eventCount = 0
+	h.bytesHashed = 0
+}
+
+// EventCount returns the number of events included in the current digest,
+// that is since the hasher was created or Reset, or since the last event
+// hashed without WithAccumulate. Batch callers can check it against the
+// number of events the api reported for an anchor window. It is not
+// included in the state saved by MarshalBinary.
+func (h *Hasher) EventCount() int { return h.eventCount }
+
+// BytesHashed returns the number of bytes written to the hash for the
+// current digest, including any prefix, chained digest and idcommitted. Like
+// EventCount it is not included in the state saved by MarshalBinary.
+func (h *Hasher) BytesHashed() int64 { return h.bytesHashed }
+
+// hashWriter writes to the hash of a hasher, counting the bytes for
+// BytesHashed
+type hashWriter struct {
+	h *Hasher
+}
+
+func (w hashWriter) Write(b []byte) (int, error) {
+	w.h.bytesHashed += int64(len(b))
+	return w.h.hasher.Write(b)
+}
+
+// MarshalBinary saves the accumulated hash state, so that an accumulation
+// using WithAccumulate can be resumed by UnmarshalBinary, in this or another
+// process.
+func (h *Hasher) MarshalBinary() ([]byte, error) {
+	m, ok := h.hasher.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, ErrStateNotSupported
+	}
+	return m.MarshalBinary()
+}
+
+// UnmarshalBinary restores hash state previously saved by MarshalBinary. The
+// next event should be hashed using WithAccumulate to continue from it.
+func (h *Hasher) UnmarshalBinary(state []byte) error {
+	u, ok := h.hasher.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return ErrStateNotSupported
+	}
+	return u.UnmarshalBinary(state)
+}
+
+// Clone returns an independent copy of the hasher, including any accumulated
+// state. This allows an accumulation to be branched, for example to take an
+// interim digest over the first N events while continuing to accumulate.
+func (h *Hasher) Clone() (Hasher, error) {
+	state, err := h.MarshalBinary()
+	if err != nil {
+		return Hasher{}, err
+	}
+	c := Hasher{
+		hasher:      newSHA256(),
+		marshaler:   h.marshaler,
+		eventCount:  h.eventCount,
+		bytesHashed: h.bytesHashed,
+	}
+	if err = c.UnmarshalBinary(state); err != nil {
+		return Hasher{}, err
+	}
+	return c, nil
 }
 
-func (h *Hasher) applyEventOptions(o HashOptions, event EventOptionApplier) {
+// applyEventOptions modifies event, which must be the hasher's own copy. The
+// events callers pass in, proto or decoded, are never modified, so they can be
+// shared between goroutines. As the copy shares the attribute and principal
+// maps of the callers event, options which change a map must replace it, as
+// StripRedacted does, never modify it in place.
+func (h *Hasher) applyEventOptions(o HashOptions, event eventOptionApplier) error {
 	if o.publicFromPermissioned {
 		event.ToPublicIdentity()
 	}
@@ -45,25 +121,45 @@ func (h *Hasher) applyEventOptions(o HashOptions, event EventOptionApplier) {
 	// actually doing the committing. public consumers only ever see confirmed
 	// events with the timestamp already in place.
 	if o.committed != nil {
-		event.SetTimestampCommitted(o.committed)
+		event.setTimestampCommitted(*o.committed)
 	}
+
+	if o.tenantIdentity != nil {
+		event.setTenantIdentity(*o.tenantIdentity)
+	}
+
+	if o.redactionMode == RedactionOmit {
+		event.StripRedacted()
+	}
+
+	return event.FormatTimestamps(o.timestampFormat)
 }
 
 func (h *Hasher) applyHashingOptions(o HashOptions) {
 
 	// By default, one hash at at time with a reset.
 	if !o.accumulateHash {
-		h.hasher.Reset()
+		h.Reset()
 	}
+	h.eventCount++
+	w := hashWriter{h}
 
 	// If the prefix is provided it must be first.
 	if len(o.prefix) != 0 {
-		h.hasher.Write(o.prefix)
+		w.Write(o.prefix)
+		o.traceWrite(o.prefix)
+	}
+
+	// If chaining, the previous digest binds this event to its predecessor.
+	if len(o.chain) != 0 {
+		w.Write(o.chain)
+		o.traceWrite(o.chain)
 	}
 
 	// If the idcommitted is provided, add it to the hash immediately before the
 	// event data.
 	if o.idcommitted != nil {
-		h.hasher.Write(o.idcommitted)
+		w.Write(o.idcommitted)
+		o.traceWrite(o.idcommitted)
 	}
 }
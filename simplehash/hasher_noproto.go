@@ -0,0 +1,18 @@
+//go:build simplehash_noproto
+
+package simplehash
+
+// Built with the simplehash_noproto tag, the package has no dependency on the
+// grpc proto types, so that it is small and portable enough for targets such
+// as GOOS=js GOARCH=wasm. Only events in the api json format, or already
+// decoded, can be hashed.
+
+// eventMarshaler is a placeholder for the proto marshaler, which is not
+// available in this build
+type eventMarshaler = *noMarshaler
+
+type noMarshaler struct{}
+
+func newEventMarshaler() eventMarshaler { return nil }
+
+func newAssetMarshaler() eventMarshaler { return nil }
@@ -0,0 +1,58 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-common-api-gen/marshalers/simpleoneof"
+)
+
+// ProtoEventMarshaler transforms events, and assets, in grpc proto format to
+// the api json format. The flat simpleoneof marshaler returned by
+// NewEventMarshaler is the default. Callers using a different generated api
+// package may provide their own implementation, provided it produces the
+// same json as the public api.
+type ProtoEventMarshaler interface {
+	Marshal(v any) ([]byte, error)
+}
+
+// eventMarshaler transforms grpc proto events to the api format
+type eventMarshaler = ProtoEventMarshaler
+
+// defaultEventMarshaler returns marshaler, or the default event marshaler if
+// it is nil
+func defaultEventMarshaler(marshaler ProtoEventMarshaler) ProtoEventMarshaler {
+	if marshaler == nil {
+		return NewEventMarshaler()
+	}
+	return marshaler
+}
+
+func newEventMarshaler() eventMarshaler { return NewEventMarshaler() }
+
+// WithMarshaler sets the marshaler used to convert proto events, or assets, to
+// the api format, for services whose flat marshaler is customized, for
+// example to flatten attributes differently. A nil marshaler leaves the
+// default in place.
+func WithMarshaler(marshaler ProtoEventMarshaler) HasherOption {
+	return func(h *Hasher) {
+		if marshaler != nil {
+			h.marshaler = marshaler
+		}
+	}
+}
+
+// NewHasherV3WithMarshaler creates a V3 hasher which converts proto events
+// using marshaler, see WithMarshaler
+func NewHasherV3WithMarshaler(marshaler ProtoEventMarshaler) HasherV3 {
+	return NewHasherV3(WithMarshaler(marshaler))
+}
+
+// NewEventMarshaler creates a flat marshaler to transform events to api format.
+//
+// otherwise attributes look like this: {"foo":{"str_val": "bar"}} instead of {"foo": "bar"}
+// this mimics the public list events api response, so minimises changes to the
+// public api response, to reproduce the anchor
+func NewEventMarshaler() *simpleoneof.Marshaler {
+	return v2assets.NewFlatMarshalerForEvents()
+}
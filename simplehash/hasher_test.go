@@ -0,0 +1,140 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestHasher_MarshalBinary tests:
+//
+// 1. an accumulation saved after the first event and restored into a new
+// hasher produces the same digest as an uninterrupted accumulation.
+func TestHasher_MarshalBinary(t *testing.T) {
+	h := NewHasherV3()
+	require.NoError(t, h.HashEvent(validEventsV2[0], WithAccumulate()))
+
+	state, err := h.MarshalBinary()
+	require.NoError(t, err)
+
+	resumed := NewHasherV3()
+	require.NoError(t, resumed.UnmarshalBinary(state))
+	require.NoError(t, resumed.HashEvent(validEventsV2[1], WithAccumulate()))
+
+	assert.Equal(t, expectedHashAllV3, hex.EncodeToString(resumed.Sum(nil)))
+}
+
+// TestHasherV3_Clone tests:
+//
+// 1. a clone taken part way through an accumulation gives the interim digest,
+// while the original continues to the full digest.
+func TestHasherV3_Clone(t *testing.T) {
+	h := NewHasherV3()
+	require.NoError(t, h.HashEvent(validEventsV2[0], WithAccumulate()))
+
+	interim, err := h.Clone()
+	require.NoError(t, err)
+
+	require.NoError(t, h.HashEvent(validEventsV2[1], WithAccumulate()))
+	assert.Equal(t, expectedHashAllV3, hex.EncodeToString(h.Sum(nil)))
+
+	single := NewHasherV3()
+	require.NoError(t, single.HashEvent(validEventsV2[0]))
+	assert.Equal(t, single.Sum(nil), interim.Sum(nil))
+}
+
+// TestHasherV3_WithChain tests:
+//
+// 1. the first link of a chain is the plain event hash.
+// 2. the second link is H(previous digest || event).
+func TestHasherV3_WithChain(t *testing.T) {
+	h := NewHasherV3()
+
+	require.NoError(t, h.HashEvent(validEventsV2[0], WithChain(nil)))
+	first := h.Sum(nil)
+
+	plain := NewHasherV3()
+	require.NoError(t, plain.HashEvent(validEventsV2[0]))
+	assert.Equal(t, plain.Sum(nil), first)
+
+	require.NoError(t, h.HashEvent(validEventsV2[1], WithChain(first)))
+	second := h.Sum(nil)
+
+	require.NoError(t, plain.HashEvent(validEventsV2[1], WithPrefix(first)))
+	assert.Equal(t, plain.Sum(nil), second)
+}
+
+// TestHasher_Statistics tests:
+//
+// 1. an accumulation counts every event, and the bytes of the prefix and
+// pre-images written.
+// 2. an event hashed without WithAccumulate starts the counts afresh.
+// 3. a batch counts the events it hashed, and Reset clears the counts.
+func TestHasher_Statistics(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2"}`)
+	v3Event, err := V3FromEventJSON(eventJson)
+	require.NoError(t, err)
+	preimage, err := V3EncodeEvent(v3Event)
+	require.NoError(t, err)
+
+	h := NewHasherV3()
+	require.NoError(t, h.HashEventFromJSON(eventJson, WithPrefix([]byte{0x01}), WithAccumulate()))
+	require.NoError(t, h.HashEventFromJSON(eventJson, WithPrefix([]byte{0x01}), WithAccumulate()))
+	assert.Equal(t, 2, h.EventCount())
+	assert.Equal(t, int64(2*(1+len(preimage))), h.BytesHashed())
+
+	require.NoError(t, h.HashEventFromJSON(eventJson))
+	assert.Equal(t, 1, h.EventCount())
+	assert.Equal(t, int64(len(preimage)), h.BytesHashed())
+
+	result, err := h.HashEventsFromJSON([][]byte{eventJson, eventJson, eventJson})
+	require.NoError(t, err)
+	assert.Equal(t, result.Count, h.EventCount())
+	assert.Equal(t, int64(3*len(preimage)), h.BytesHashed())
+
+	h.Reset()
+	assert.Equal(t, 0, h.EventCount())
+	assert.Equal(t, int64(0), h.BytesHashed())
+}
+
+// TestHasher_EventsNotModified tests that hashing with options which change
+// the event leaves the callers events unchanged, when they are shared by
+// concurrent hashers.
+func TestHasher_EventsNotModified(t *testing.T) {
+	event := proto.Clone(validEventsV2[0]).(*v2assets.EventResponse)
+	v3Event, err := V3FromEventResponse(nil, event)
+	require.NoError(t, err)
+	v3Event.EventAttributes["redacted"] = RedactedValue
+	v3Original := v3Event.Clone()
+
+	opts := []HashOption{
+		WithPublicFromPermissioned(),
+		WithTimestampCommittedTime(time.Unix(1706700559, 0)),
+		WithTenantIdentity("tenant/2"),
+		WithRedactionMode(RedactionOmit),
+		WithTimestampFormat(TimestampFormatAPI),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := NewHasherV3()
+			assert.NoError(t, h.HashEvent(event, opts...))
+			assert.NoError(t, h.HashEventFromV3(v3Event, opts...))
+		}()
+	}
+	wg.Wait()
+
+	assert.True(t, proto.Equal(validEventsV2[0], event))
+	assert.Equal(t, v3Original, v3Event)
+}
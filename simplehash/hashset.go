@@ -0,0 +1,356 @@
+package simplehash
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidHashSet  = errors.New("invalid hash set")
+	ErrHashSetMismatch = errors.New("hash sets were produced differently")
+	ErrNotInHashSet    = errors.New("event digest is not in the hash set")
+)
+
+const (
+	hashSetMagic   = "SHSET"
+	hashSetVersion = 1
+
+	// maxHashSetHeaderSize bounds the header read from untrusted input
+	maxHashSetHeaderSize = 1 << 20
+)
+
+// HashSet is a set of event digests, along with the schema, algorithm and
+// hashing options which produced them, so that two parties can exchange and
+// compare sets produced independently, for example during an audit.
+//
+// The serialized form is deterministic, the same set always produces the
+// same bytes:
+//
+//	"SHSET" 0x01
+//	uint32 header length, header json {"schema":3,"algorithm":"sha256","options":{...}}
+//	uint64 digest count
+//	for each digest, in ascending byte order: uint32 length, digest
+//
+// All integers are big endian. The header json is produced by encoding/json,
+// so the option names are sorted.
+type HashSet struct {
+	Schema    int    `json:"schema"`
+	Algorithm string `json:"algorithm"`
+	// Options describes the hashing options which affect the digests, see
+	// NewHashSet
+	Options map[string]string `json:"options,omitempty"`
+	// Digests are sorted in ascending byte order, without duplicates
+	Digests [][]byte `json:"-"`
+}
+
+// HashSetDiff is the difference between two hash sets produced the same way
+type HashSetDiff struct {
+	OnlyInA [][]byte
+	OnlyInB [][]byte
+}
+
+// Consistent is true if both sets hold exactly the same digests
+func (d HashSetDiff) Consistent() bool {
+	return len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0
+}
+
+// NewHashSet creates a set of V3 sha256 digests produced with opts. Options
+// which do not affect the digests, such as WithProgress, are not recorded.
+// The digests are copied, sorted and de-duplicated.
+func NewHashSet(digests [][]byte, opts ...HashOption) HashSet {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sorted := make([][]byte, 0, len(digests))
+	for _, digest := range digests {
+		sorted = append(sorted, bytes.Clone(digest))
+	}
+	slices.SortFunc(sorted, bytes.Compare)
+	sorted = slices.CompactFunc(sorted, bytes.Equal)
+
+	return HashSet{
+		Schema:    SchemaVersionV3,
+		Algorithm: DigestAlgorithmSHA256,
+		Options:   describeOptions(o),
+		Digests:   sorted,
+	}
+}
+
+// HashSetFromJSON hashes each api formatted event individually, as
+// HashInventoryFromJSON does, and returns the set of their digests.
+func HashSetFromJSON(events [][]byte, opts ...HashOption) (HashSet, error) {
+	inventory, err := HashInventoryFromJSON(events, opts...)
+	if err != nil {
+		return HashSet{}, err
+	}
+	digests := make([][]byte, 0, len(inventory))
+	for _, digest := range inventory {
+		digests = append(digests, digest)
+	}
+	return NewHashSet(digests, opts...), nil
+}
+
+// DescribeOptions returns the hashing options which affect event digests, by
+// name, for recording alongside stored digests. Options at their default,
+// and options which do not affect digests, are omitted. Equal descriptions
+// mean the options produce the same digests.
+func DescribeOptions(opts ...HashOption) map[string]string {
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return describeOptions(o)
+}
+
+// digestOption is an option which affects event digests. Both
+// DescribeOptions and the cache key are derived from digestOptions, so an
+// option added here is recorded by both.
+type digestOption struct {
+	name string
+	// value returns the option's value, and false if it is at its default
+	value func(o HashOptions) (string, bool)
+}
+
+var digestOptions = []digestOption{
+	{"prefix", func(o HashOptions) (string, bool) {
+		return hex.EncodeToString(o.prefix), len(o.prefix) != 0
+	}},
+	{"chain", func(o HashOptions) (string, bool) {
+		return hex.EncodeToString(o.chain), len(o.chain) != 0
+	}},
+	{"id_committed", func(o HashOptions) (string, bool) {
+		return hex.EncodeToString(o.idcommitted), len(o.idcommitted) != 0
+	}},
+	{"timestamp_committed", func(o HashOptions) (string, bool) {
+		if o.committed == nil {
+			return "", false
+		}
+		return o.committed.Format(time.RFC3339Nano), true
+	}},
+	// an empty tenant identity override differs from none
+	{"tenant_identity", func(o HashOptions) (string, bool) {
+		if o.tenantIdentity == nil {
+			return "", false
+		}
+		return *o.tenantIdentity, true
+	}},
+	{"public_from_permissioned", func(o HashOptions) (string, bool) {
+		return "true", o.publicFromPermissioned
+	}},
+	{"use_number", func(o HashOptions) (string, bool) {
+		return "true", o.useNumber
+	}},
+	{"redaction_mode", func(o HashOptions) (string, bool) {
+		return strconv.Itoa(int(o.redactionMode)), o.redactionMode != RedactionHashMarker
+	}},
+	{"timestamp_format", func(o HashOptions) (string, bool) {
+		return strconv.Itoa(int(o.timestampFormat)), o.timestampFormat != TimestampFormatAsIs
+	}},
+	{"encoding", func(o HashOptions) (string, bool) {
+		return strconv.Itoa(int(o.encoding)), o.encoding != EncodingBencode
+	}},
+	{"exclude_fields", func(o HashOptions) (string, bool) {
+		fields := slices.Clone(o.excludeFields)
+		slices.Sort(fields)
+		return strings.Join(fields, ","), len(fields) != 0
+	}},
+}
+
+// describeOptions returns the options which affect event digests, see
+// DescribeOptions
+func describeOptions(o HashOptions) map[string]string {
+	options := map[string]string{}
+	for _, option := range digestOptions {
+		if value, ok := option.value(o); ok {
+			options[option.name] = value
+		}
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	return options
+}
+
+// Contains is true if the digest is in the set
+func (s HashSet) Contains(digest []byte) bool {
+	_, found := slices.BinarySearchFunc(s.Digests, digest, bytes.Compare)
+	return found
+}
+
+// sameHeader returns an error wrapping ErrHashSetMismatch if the sets were
+// produced with a different schema, algorithm or options
+func (s HashSet) sameHeader(other HashSet) error {
+	if s.Schema != other.Schema || s.Algorithm != other.Algorithm {
+		return fmt.Errorf("%w: schema v%d %s and v%d %s",
+			ErrHashSetMismatch, s.Schema, s.Algorithm, other.Schema, other.Algorithm)
+	}
+	if !maps.Equal(s.Options, other.Options) {
+		return fmt.Errorf("%w: options %v and %v", ErrHashSetMismatch, s.Options, other.Options)
+	}
+	return nil
+}
+
+// Compare reports the digests found in only one of the sets. An error
+// wrapping ErrHashSetMismatch is returned if the sets were produced with a
+// different schema, algorithm or options, as their digests are not
+// comparable.
+func (s HashSet) Compare(other HashSet) (HashSetDiff, error) {
+	if err := s.sameHeader(other); err != nil {
+		return HashSetDiff{}, err
+	}
+
+	diff := HashSetDiff{}
+	a, b := s.Digests, other.Digests
+	for len(a) != 0 && len(b) != 0 {
+		switch c := bytes.Compare(a[0], b[0]); {
+		case c < 0:
+			diff.OnlyInA = append(diff.OnlyInA, a[0])
+			a = a[1:]
+		case c > 0:
+			diff.OnlyInB = append(diff.OnlyInB, b[0])
+			b = b[1:]
+		default:
+			a, b = a[1:], b[1:]
+		}
+	}
+	diff.OnlyInA = append(diff.OnlyInA, a...)
+	diff.OnlyInB = append(diff.OnlyInB, b...)
+	return diff, nil
+}
+
+// VerifySetMembership hashes an api formatted event with opts and checks
+// that its digest is in the set. The options must describe the same hashing
+// as those the set was produced with, otherwise an error wrapping
+// ErrHashSetMismatch is returned. If the digest is not in the set the error
+// wraps ErrNotInHashSet.
+func VerifySetMembership(set HashSet, eventJson []byte, opts ...HashOption) error {
+
+	if err := set.sameHeader(NewHashSet(nil, opts...)); err != nil {
+		return err
+	}
+
+	digest, err := DigestEventFromJSON(eventJson, opts...)
+	if err != nil {
+		return err
+	}
+	if !set.Contains(digest) {
+		AddMismatches(context.Background(), 1, opts...)
+		return fmt.Errorf("%w: %x", ErrNotInHashSet, digest)
+	}
+	return nil
+}
+
+// MarshalBinary returns the serialized set
+func (s HashSet) MarshalBinary() ([]byte, error) {
+
+	header, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 0, len(hashSetMagic)+1+4+len(header)+8+len(s.Digests)*(4+sha256.Size))
+	b = append(b, hashSetMagic...)
+	b = append(b, hashSetVersion)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(header)))
+	b = append(b, header...)
+	b = binary.BigEndian.AppendUint64(b, uint64(len(s.Digests)))
+	for _, digest := range s.Digests {
+		b = binary.BigEndian.AppendUint32(b, uint32(len(digest)))
+		b = append(b, digest...)
+	}
+	return b, nil
+}
+
+// WriteTo writes the serialized set to w
+func (s HashSet) WriteTo(w io.Writer) (int64, error) {
+	b, err := s.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadHashSet reads a set serialized by WriteTo. The set is checked to be in
+// the deterministic form, so that a set which reads successfully serializes
+// to exactly the bytes read.
+func ReadHashSet(r io.Reader) (HashSet, error) {
+
+	br := bufio.NewReader(r)
+	invalid := func(format string, args ...any) (HashSet, error) {
+		return HashSet{}, fmt.Errorf("%w: %s", ErrInvalidHashSet, fmt.Sprintf(format, args...))
+	}
+
+	magic := make([]byte, len(hashSetMagic)+1)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return invalid("header: %v", err)
+	}
+	if string(magic[:len(hashSetMagic)]) != hashSetMagic || magic[len(hashSetMagic)] != hashSetVersion {
+		return invalid("not a version %d hash set", hashSetVersion)
+	}
+
+	var headerSize uint32
+	if err := binary.Read(br, binary.BigEndian, &headerSize); err != nil {
+		return invalid("header: %v", err)
+	}
+	if headerSize > maxHashSetHeaderSize {
+		return invalid("header of %d bytes", headerSize)
+	}
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return invalid("header: %v", err)
+	}
+	s := HashSet{}
+	if err := json.Unmarshal(header, &s); err != nil {
+		return invalid("header: %v", err)
+	}
+	if canonical, _ := json.Marshal(s); !bytes.Equal(canonical, header) {
+		return invalid("header is not canonical")
+	}
+
+	var count uint64
+	if err := binary.Read(br, binary.BigEndian, &count); err != nil {
+		return invalid("count: %v", err)
+	}
+	s.Digests = make([][]byte, 0, min(count, 1<<16))
+	for i := uint64(0); i < count; i++ {
+		var size uint32
+		if err := binary.Read(br, binary.BigEndian, &size); err != nil {
+			return invalid("digest %d: %v", i, err)
+		}
+		if s.Algorithm == DigestAlgorithmSHA256 && size != sha256.Size {
+			return invalid("digest %d: %d bytes", i, size)
+		}
+		if size > maxHashSetHeaderSize {
+			return invalid("digest %d: %d bytes", i, size)
+		}
+		digest := make([]byte, size)
+		if _, err := io.ReadFull(br, digest); err != nil {
+			return invalid("digest %d: %v", i, err)
+		}
+		if i > 0 && bytes.Compare(s.Digests[i-1], digest) >= 0 {
+			return invalid("digest %d is out of order", i)
+		}
+		s.Digests = append(s.Digests, digest)
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return invalid("trailing data")
+	}
+	return s, nil
+}
@@ -0,0 +1,100 @@
+package simplehash
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var hashSetEvents = [][]byte{
+	[]byte(`{"identity": "assets/1/events/1", "event_attributes": {"a": "1"}}`),
+	[]byte(`{"identity": "assets/1/events/2", "event_attributes": {"a": "2"}}`),
+	[]byte(`{"identity": "assets/1/events/3", "event_attributes": {"a": "3"}}`),
+}
+
+// TestHashSet_RoundTrip tests:
+//
+// 1. sets built from the same events in any order serialize identically.
+// 2. a serialized set reads back to an equal set.
+// 3. non canonical or truncated serializations are rejected.
+func TestHashSet_RoundTrip(t *testing.T) {
+	a, err := HashSetFromJSON(hashSetEvents, WithUseNumber())
+	require.NoError(t, err)
+	b, err := HashSetFromJSON([][]byte{hashSetEvents[2], hashSetEvents[0], hashSetEvents[1]}, WithUseNumber())
+	require.NoError(t, err)
+
+	var bufA, bufB bytes.Buffer
+	_, err = a.WriteTo(&bufA)
+	require.NoError(t, err)
+	_, err = b.WriteTo(&bufB)
+	require.NoError(t, err)
+	assert.Equal(t, bufA.Bytes(), bufB.Bytes())
+
+	read, err := ReadHashSet(bytes.NewReader(bufA.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, a, read)
+	assert.Equal(t, map[string]string{"use_number": "true"}, read.Options)
+
+	serialized := bufA.Bytes()
+	for name, invalid := range map[string][]byte{
+		"magic":     append([]byte("XXSET"), serialized[5:]...),
+		"truncated": serialized[:len(serialized)-1],
+		"trailing":  append(bytes.Clone(serialized), 0),
+	} {
+		_, err = ReadHashSet(bytes.NewReader(invalid))
+		assert.ErrorIs(t, err, ErrInvalidHashSet, name)
+	}
+
+	reordered := HashSet{Schema: a.Schema, Algorithm: a.Algorithm, Options: a.Options,
+		Digests: [][]byte{a.Digests[1], a.Digests[0]}}
+	serialized, err = reordered.MarshalBinary()
+	require.NoError(t, err)
+	_, err = ReadHashSet(bytes.NewReader(serialized))
+	assert.ErrorIs(t, err, ErrInvalidHashSet)
+}
+
+// TestHashSet_Compare tests:
+//
+// 1. the digests only in one set are reported.
+// 2. sets produced with different options are not comparable.
+func TestHashSet_Compare(t *testing.T) {
+	a, err := HashSetFromJSON(hashSetEvents[:2])
+	require.NoError(t, err)
+	b, err := HashSetFromJSON(hashSetEvents[1:])
+	require.NoError(t, err)
+
+	diff, err := a.Compare(b)
+	require.NoError(t, err)
+	assert.False(t, diff.Consistent())
+	onlyA, err := DigestEventFromJSON(hashSetEvents[0])
+	require.NoError(t, err)
+	onlyB, err := DigestEventFromJSON(hashSetEvents[2])
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{onlyA}, diff.OnlyInA)
+	assert.Equal(t, [][]byte{onlyB}, diff.OnlyInB)
+
+	diff, err = a.Compare(a)
+	require.NoError(t, err)
+	assert.True(t, diff.Consistent())
+
+	prefixed, err := HashSetFromJSON(hashSetEvents[:2], WithPrefix([]byte{1}))
+	require.NoError(t, err)
+	_, err = a.Compare(prefixed)
+	assert.ErrorIs(t, err, ErrHashSetMismatch)
+}
+
+// TestVerifySetMembership tests:
+//
+// 1. an event in the set verifies.
+// 2. an event not in the set is reported.
+// 3. options which differ from those of the set are rejected.
+func TestVerifySetMembership(t *testing.T) {
+	set, err := HashSetFromJSON(hashSetEvents[:2], WithPrefix([]byte{1}))
+	require.NoError(t, err)
+
+	assert.NoError(t, VerifySetMembership(set, hashSetEvents[1], WithPrefix([]byte{1})))
+	assert.ErrorIs(t, VerifySetMembership(set, hashSetEvents[2], WithPrefix([]byte{1})), ErrNotInHashSet)
+	assert.ErrorIs(t, VerifySetMembership(set, hashSetEvents[1]), ErrHashSetMismatch)
+}
@@ -0,0 +1,219 @@
+package simplehash
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	publicIdentityPrefix = "public"
+	assetIdentityPrefix  = "assets/"
+	eventIdentityInfix   = "/events/"
+)
+
+var (
+	ErrInvalidIdentity     = errors.New("invalid identity")
+	ErrCounterpartMismatch = errors.New("public event does not match its permissioned counterpart")
+)
+
+// AssetIdentity is a parsed asset identity, assets/{uuid} or
+// publicassets/{uuid}
+type AssetIdentity struct {
+	AssetUUID uuid.UUID
+	IsPublic  bool
+}
+
+// EventIdentity is a parsed event identity, assets/{uuid}/events/{uuid} or
+// publicassets/{uuid}/events/{uuid}
+type EventIdentity struct {
+	AssetUUID uuid.UUID
+	EventUUID uuid.UUID
+	IsPublic  bool
+}
+
+// ParseAssetIdentity parses a public or permissioned asset identity
+func ParseAssetIdentity(identity string) (AssetIdentity, error) {
+	id := AssetIdentity{}
+	rest, public := strings.CutPrefix(identity, publicIdentityPrefix)
+	rest, ok := strings.CutPrefix(rest, assetIdentityPrefix)
+	if !ok {
+		return AssetIdentity{}, fmt.Errorf("%w: %q is not an asset identity", ErrInvalidIdentity, identity)
+	}
+	var err error
+	if id.AssetUUID, err = uuid.Parse(rest); err != nil {
+		return AssetIdentity{}, fmt.Errorf("%w: %q: %v", ErrInvalidIdentity, identity, err)
+	}
+	id.IsPublic = public
+	return id, nil
+}
+
+// ParseEventIdentity parses a public or permissioned event identity
+func ParseEventIdentity(identity string) (EventIdentity, error) {
+	assetPart, eventPart, ok := strings.Cut(identity, eventIdentityInfix)
+	if !ok {
+		return EventIdentity{}, fmt.Errorf("%w: %q is not an event identity", ErrInvalidIdentity, identity)
+	}
+	asset, err := ParseAssetIdentity(assetPart)
+	if err != nil {
+		return EventIdentity{}, fmt.Errorf("%w: %q is not an event identity", ErrInvalidIdentity, identity)
+	}
+	eventUUID, err := uuid.Parse(eventPart)
+	if err != nil {
+		return EventIdentity{}, fmt.Errorf("%w: %q: %v", ErrInvalidIdentity, identity, err)
+	}
+	return EventIdentity{AssetUUID: asset.AssetUUID, EventUUID: eventUUID, IsPublic: asset.IsPublic}, nil
+}
+
+// Public returns the public form of the identity
+func (id AssetIdentity) Public() AssetIdentity {
+	id.IsPublic = true
+	return id
+}
+
+// Permissioned returns the permissioned form of the identity
+func (id AssetIdentity) Permissioned() AssetIdentity {
+	id.IsPublic = false
+	return id
+}
+
+// String formats the identity as the api does
+func (id AssetIdentity) String() string {
+	s := assetIdentityPrefix + id.AssetUUID.String()
+	if id.IsPublic {
+		return publicIdentityPrefix + s
+	}
+	return s
+}
+
+// Asset returns the identity of the asset the event belongs to
+func (id EventIdentity) Asset() AssetIdentity {
+	return AssetIdentity{AssetUUID: id.AssetUUID, IsPublic: id.IsPublic}
+}
+
+// Public returns the public form of the identity
+func (id EventIdentity) Public() EventIdentity {
+	id.IsPublic = true
+	return id
+}
+
+// Permissioned returns the permissioned form of the identity
+func (id EventIdentity) Permissioned() EventIdentity {
+	id.IsPublic = false
+	return id
+}
+
+// String formats the identity as the api does
+func (id EventIdentity) String() string {
+	return id.Asset().String() + eventIdentityInfix + id.EventUUID.String()
+}
+
+// publicIdentity converts a permissioned event or asset identity to its
+// public form. The identity is prefixed exactly as the platform does, and
+// is not parsed or re-formatted, as the identity is part of the hashed data.
+func publicIdentity(identity string) string {
+	return publicIdentityPrefix + identity
+}
+
+// permissionedIdentity converts a public event or asset identity to its
+// permissioned form, by removing the public prefix exactly as the platform
+// does. Permissioned identities are returned unchanged.
+func permissionedIdentity(identity string) string {
+	return strings.TrimPrefix(identity, publicIdentityPrefix)
+}
+
+// identityFields are the event fields holding identities which have public
+// and permissioned forms
+var identityFields = []string{"identity", "asset_identity"}
+
+// PublicFromPermissionedJSON converts the identity and asset_identity of an
+// api formatted event to their public forms. Every other field is preserved
+// as provided, though the fields of the returned object are sorted.
+func PublicFromPermissionedJSON(eventJson []byte) ([]byte, error) {
+	return convertIdentitiesJSON(eventJson, publicIdentity)
+}
+
+// PermissionedFromPublicJSON converts the identity and asset_identity of an
+// api formatted event to their permissioned forms. It is the inverse of
+// PublicFromPermissionedJSON.
+func PermissionedFromPublicJSON(eventJson []byte) ([]byte, error) {
+	return convertIdentitiesJSON(eventJson, permissionedIdentity)
+}
+
+func convertIdentitiesJSON(eventJson []byte, convert func(string) string) ([]byte, error) {
+	event := map[string]json.RawMessage{}
+	if err := json.Unmarshal(eventJson, &event); err != nil {
+		return nil, err
+	}
+	for _, field := range identityFields {
+		raw, ok := event[field]
+		if !ok {
+			continue
+		}
+		var identity string
+		if err := json.Unmarshal(raw, &identity); err != nil {
+			return nil, fmt.Errorf("%w: %s is not a string", ErrInvalidIdentity, field)
+		}
+		if identity == "" {
+			continue
+		}
+		converted, err := json.Marshal(convert(identity))
+		if err != nil {
+			return nil, err
+		}
+		event[field] = converted
+	}
+	return json.Marshal(event)
+}
+
+// VerifyPublicCounterpart checks that a public event and its permissioned
+// counterpart, both api formatted, describe the same event. The permissioned
+// event is hashed with WithPublicFromPermissioned and the public event is
+// hashed directly. Their V2 digests, which include the public identity, and
+// their V3 digests must agree. A mismatch indicates corruption by the
+// platform or in transport, and the returned error wraps
+// ErrCounterpartMismatch.
+func VerifyPublicCounterpart(permissioned []byte, public []byte) error {
+
+	permissionedV2, err := V2FromEventJSON(permissioned)
+	if err != nil {
+		return fmt.Errorf("permissioned event: %w", err)
+	}
+	publicV2, err := V2FromEventJSON(public)
+	if err != nil {
+		return fmt.Errorf("public event: %w", err)
+	}
+
+	h2 := NewHasherV2()
+	if err = h2.applyEventOptions(HashOptions{publicFromPermissioned: true}, &permissionedV2); err != nil {
+		return fmt.Errorf("permissioned event: %w", err)
+	}
+	if err = h2.hashV2Event(permissionedV2, HashOptions{}); err != nil {
+		return fmt.Errorf("permissioned event: %w", err)
+	}
+	permissionedDigest := h2.Sum()
+	if err = h2.hashV2Event(publicV2, HashOptions{}); err != nil {
+		return fmt.Errorf("public event: %w", err)
+	}
+	if !bytes.Equal(permissionedDigest, h2.Sum()) {
+		return fmt.Errorf("%w: v2 digests differ", ErrCounterpartMismatch)
+	}
+
+	h3 := NewHasherV3()
+	if err = h3.HashEventFromJSON(permissioned); err != nil {
+		return fmt.Errorf("permissioned event: %w", err)
+	}
+	permissionedDigest = h3.Sum(nil)
+	if err = h3.HashEventFromJSON(public); err != nil {
+		return fmt.Errorf("public event: %w", err)
+	}
+	if !bytes.Equal(permissionedDigest, h3.Sum(nil)) {
+		return fmt.Errorf("%w: v3 digests differ", ErrCounterpartMismatch)
+	}
+
+	return nil
+}
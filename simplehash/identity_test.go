@@ -0,0 +1,146 @@
+package simplehash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseEventIdentity tests:
+//
+// 1. permissioned and public event identities parse and round trip.
+// 2. conversion between public and permissioned forms is idempotent.
+// 3. asset identities, and identities without uuids, are rejected.
+func TestParseEventIdentity(t *testing.T) {
+	permissioned := "assets/9ccdc19b-44a1-434c-afab-14f8eac3405c/events/e76a03d1-19a5-4f11-bcaf-383bb4f1dfd4"
+	public := "publicassets/9ccdc19b-44a1-434c-afab-14f8eac3405c/events/e76a03d1-19a5-4f11-bcaf-383bb4f1dfd4"
+
+	id, err := ParseEventIdentity(permissioned)
+	require.NoError(t, err)
+	assert.False(t, id.IsPublic)
+	assert.Equal(t, permissioned, id.String())
+	assert.Equal(t, public, id.Public().String())
+	assert.Equal(t, public, id.Public().Public().String())
+	assert.Equal(t, "assets/9ccdc19b-44a1-434c-afab-14f8eac3405c", id.Asset().String())
+
+	id, err = ParseEventIdentity(public)
+	require.NoError(t, err)
+	assert.True(t, id.IsPublic)
+	assert.Equal(t, permissioned, id.Permissioned().String())
+
+	for _, bad := range []string{
+		"",
+		"assets/9ccdc19b-44a1-434c-afab-14f8eac3405c",
+		"assets/1/events/2",
+		"tenant/9ccdc19b-44a1-434c-afab-14f8eac3405c/events/e76a03d1-19a5-4f11-bcaf-383bb4f1dfd4",
+	} {
+		_, err = ParseEventIdentity(bad)
+		assert.ErrorIs(t, err, ErrInvalidIdentity, bad)
+	}
+}
+
+// TestParseAssetIdentity tests:
+//
+// 1. permissioned and public asset identities parse and round trip.
+// 2. event identities are rejected.
+func TestParseAssetIdentity(t *testing.T) {
+	id, err := ParseAssetIdentity("publicassets/9ccdc19b-44a1-434c-afab-14f8eac3405c")
+	require.NoError(t, err)
+	assert.True(t, id.IsPublic)
+	assert.Equal(t, "assets/9ccdc19b-44a1-434c-afab-14f8eac3405c", id.Permissioned().String())
+
+	_, err = ParseAssetIdentity("assets/9ccdc19b-44a1-434c-afab-14f8eac3405c/events/e76a03d1-19a5-4f11-bcaf-383bb4f1dfd4")
+	assert.ErrorIs(t, err, ErrInvalidIdentity)
+}
+
+// TestPublicIdentity tests:
+//
+// 1. identities are converted by prefix only, without re-formatting their
+// uuids, as the platform does.
+// 2. the hashed identity of public events with upper case and unhyphenated
+// uuids is the identity as provided, without the public prefix.
+func TestPublicIdentity(t *testing.T) {
+	assert.Equal(t, "publicassets/1/events/2", publicIdentity("assets/1/events/2"))
+	assert.Equal(t, "assets/1/events/2", permissionedIdentity("publicassets/1/events/2"))
+	assert.Equal(t, "assets/1/events/2", permissionedIdentity("assets/1/events/2"))
+
+	for _, identity := range []string{
+		"assets/ABCDEF01-2345-4678-9ABC-DEF012345678/events/E76A03D1-19A5-4F11-BCAF-383BB4F1DFD4",
+		"assets/abcdef01234546789abcdef012345678/events/e76a03d119a54f11bcaf383bb4f1dfd4",
+	} {
+		public := publicIdentity(identity)
+		assert.Equal(t, "public"+identity, public)
+		assert.Equal(t, identity, permissionedIdentity(public))
+
+		expected := sha256.New()
+		require.NoError(t, V3HashEvent(expected, V3Event{Identity: identity}))
+
+		h := NewHasherV3()
+		for _, eventIdentity := range []string{identity, public} {
+			require.NoError(t, h.HashEventFromJSON([]byte(`{"identity":"`+eventIdentity+`"}`)))
+			assert.Equal(t, expected.Sum(nil), h.Sum(nil), eventIdentity)
+		}
+	}
+}
+
+// TestPublicFromPermissionedJSON tests:
+//
+// 1. identity and asset_identity are converted and other fields preserved.
+// 2. the conversion round trips and hashes as WithPublicFromPermissioned does.
+// 3. a non string identity is rejected.
+func TestPublicFromPermissionedJSON(t *testing.T) {
+	permissioned := []byte(`{
+		"identity": "assets/9ccdc19b-44a1-434c-afab-14f8eac3405c/events/e76a03d1-19a5-4f11-bcaf-383bb4f1dfd4",
+		"asset_identity": "assets/9ccdc19b-44a1-434c-afab-14f8eac3405c",
+		"event_attributes": {"n": "1"},
+		"block_number": 12345678901234567890
+	}`)
+
+	public, err := PublicFromPermissionedJSON(permissioned)
+	require.NoError(t, err)
+	assert.Contains(t, string(public), `"identity":"publicassets/9ccdc19b-44a1-434c-afab-14f8eac3405c/events/e76a03d1-19a5-4f11-bcaf-383bb4f1dfd4"`)
+	assert.Contains(t, string(public), `"asset_identity":"publicassets/9ccdc19b-44a1-434c-afab-14f8eac3405c"`)
+	assert.Contains(t, string(public), `"block_number":12345678901234567890`)
+
+	back, err := PermissionedFromPublicJSON(public)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(permissioned), string(back))
+
+	h := NewHasherV2()
+	require.NoError(t, h.HashEventJSON(public))
+	expected := h.Sum()
+	v2Event, err := V2FromEventJSON(permissioned)
+	require.NoError(t, err)
+	v2Event.ToPublicIdentity()
+	require.NoError(t, h.hashV2Event(v2Event, HashOptions{}))
+	assert.Equal(t, expected, h.Sum())
+
+	_, err = PublicFromPermissionedJSON([]byte(`{"identity": 1}`))
+	assert.ErrorIs(t, err, ErrInvalidIdentity)
+}
+
+// TestVerifyPublicCounterpart tests:
+//
+// 1. a public event converted from its permissioned counterpart verifies.
+// 2. a public event with a modified attribute does not.
+// 3. a public event which still carries the permissioned identity does not.
+func TestVerifyPublicCounterpart(t *testing.T) {
+	permissioned := []byte(`{
+		"identity": "assets/9ccdc19b-44a1-434c-afab-14f8eac3405c/events/e76a03d1-19a5-4f11-bcaf-383bb4f1dfd4",
+		"asset_identity": "assets/9ccdc19b-44a1-434c-afab-14f8eac3405c",
+		"event_attributes": {"foo": "bar"},
+		"timestamp_accepted": "2024-01-31T11:29:19.043Z"
+	}`)
+	public, err := PublicFromPermissionedJSON(permissioned)
+	require.NoError(t, err)
+
+	assert.NoError(t, VerifyPublicCounterpart(permissioned, public))
+
+	tampered := bytes.Replace(public, []byte(`"bar"`), []byte(`"baz"`), 1)
+	assert.ErrorIs(t, VerifyPublicCounterpart(permissioned, tampered), ErrCounterpartMismatch)
+
+	assert.ErrorIs(t, VerifyPublicCounterpart(permissioned, permissioned), ErrCounterpartMismatch)
+}
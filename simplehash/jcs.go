@@ -0,0 +1,140 @@
+package simplehash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// maxSafeInteger is the largest integer JCS can represent exactly, 2^53 - 1.
+// JCS numbers are IEEE 754 doubles.
+const maxSafeInteger = 1<<53 - 1
+
+var (
+	ErrUnsafeInteger = errors.New("integer can not be represented exactly in canonical json")
+)
+
+// jcsNumber is an integer already formatted for JCS
+type jcsNumber string
+
+// CanonicalJSON returns the RFC 8785 (JCS) canonical json of the fields which
+// are hashed for the event. It is a human readable equivalent of the bencode
+// pre-image which round trips with standard json tools, and the pre-image
+// hashed with WithEncoding(EncodingJCS).
+//
+// As for bencode, null fields are omitted. Integers, which require
+// WithUseNumber when hashing, must be within the JCS safe range of +/-2^53-1.
+func CanonicalJSON(v3Event V3Event) ([]byte, error) {
+	return v3EncodeEvent(v3Event, HashOptions{encoding: EncodingJCS})
+}
+
+func jcsEncodeEvent(schema string, jsonAny any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jcsEncode(&buf, omitNulls(jsonAny)); err != nil {
+		return nil, fmt.Errorf("%s: %w", schema, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func jcsInteger(i *big.Int) (any, error) {
+	if !i.IsInt64() || i.Int64() > maxSafeInteger || i.Int64() < -maxSafeInteger {
+		return nil, fmt.Errorf("%w: %s", ErrUnsafeInteger, i)
+	}
+	return jcsNumber(i.String()), nil
+}
+
+func jcsEncode(buf *bytes.Buffer, v any) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case string:
+		jcsString(buf, x)
+	case jcsNumber:
+		buf.WriteString(string(x))
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := jcsEncode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		// keys are sorted by their utf-16 code units, RFC 8785 section 3.2.3
+		sort.Slice(keys, func(i, j int) bool {
+			return utf16Less(keys[i], keys[j])
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			jcsString(buf, k)
+			buf.WriteByte(':')
+			if err := jcsEncode(buf, x[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("canonical json: unsupported type %T", v)
+	}
+	return nil
+}
+
+func utf16Less(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// jcsString writes s as a json string, escaping only what ECMAScript
+// JSON.stringify escapes, RFC 8785 section 3.2.2.2.
+func jcsString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[r>>4])
+				buf.WriteByte(hex[r&0xf])
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
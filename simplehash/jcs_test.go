@@ -0,0 +1,54 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCanonicalJSON tests:
+//
+// 1. keys are sorted, whitespace removed and null fields omitted.
+// 2. strings are escaped as JSON.stringify does, without html escaping.
+// 3. keys are sorted by utf-16 code units, RFC 8785 section 3.2.3.
+// 4. the output round trips through encoding/json.
+func TestCanonicalJSON(t *testing.T) {
+	v3Event := V3Event{
+		Identity: "assets/1/events/2",
+		EventAttributes: map[string]any{
+			"b":          "<&> ",
+			"a":          "line\nbreak \"quoted\" \x01",
+			"\U0001F600": "astral",
+			"דּ":          "bmp",
+		},
+	}
+
+	actual, err := CanonicalJSON(v3Event)
+	require.NoError(t, err)
+
+	expected := `{"behaviour":"",` +
+		`"event_attributes":{"a":"line\nbreak \"quoted\" \u0001","b":"<&>` + " " + `","` + "\U0001F600" + `":"astral","` + "דּ" + `":"bmp"},` +
+		`"identity":"assets/1/events/2","operation":"","tenant_identity":"","timestamp_accepted":"","timestamp_committed":"","timestamp_declared":""}`
+	assert.Equal(t, expected, string(actual))
+
+	var roundTrip V3Event
+	require.NoError(t, json.Unmarshal(actual, &roundTrip))
+	assert.Equal(t, v3Event, roundTrip)
+}
+
+// TestCanonicalJSON_Integers tests:
+//
+// 1. integers within the safe range are exact.
+// 2. integers beyond the safe range are rejected.
+func TestCanonicalJSON_Integers(t *testing.T) {
+	h := NewHasherV3()
+
+	safe := []byte(`{"identity": "assets/1/events/2", "event_attributes": {"n": 9007199254740991}}`)
+	require.NoError(t, h.HashEventFromJSON(safe, WithUseNumber(), WithEncoding(EncodingJCS)))
+
+	unsafe := []byte(`{"identity": "assets/1/events/2", "event_attributes": {"n": 9007199254740992}}`)
+	err := h.HashEventFromJSON(unsafe, WithUseNumber(), WithEncoding(EncodingJCS))
+	assert.ErrorIs(t, err, ErrUnsafeInteger)
+}
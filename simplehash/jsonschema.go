@@ -0,0 +1,122 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	ErrUnknownSchema = errors.New("unknown schema version")
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema document describing the api formatted
+// event fields hashed by the schema version, SchemaVersionV2 or
+// SchemaVersionV3, and their formats. Api consumers which build their own
+// payloads can validate them against it before hashing. Fields not in the
+// schema are allowed, as the hashers ignore them. Attribute values are
+// described as the api holds them, integers are only hashed with
+// WithUseNumber.
+//
+// The properties are taken from the event structs, so the document always
+// describes exactly the fields the hashers encode, together with the listing
+// of redacted attributes, see RedactedValue.
+func JSONSchema(schemaVersion int) ([]byte, error) {
+	var event any
+	switch schemaVersion {
+	case SchemaVersionV2:
+		event = V2Event{}
+	case SchemaVersionV3:
+		event = V3Event{}
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownSchema, schemaVersion)
+	}
+
+	properties := map[string]any{}
+	t := reflect.TypeOf(event)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		properties[name] = jsonSchemaField(name)
+	}
+	properties[redactedAttributesKey] = jsonSchemaRedactions()
+
+	return json.MarshalIndent(map[string]any{
+		"$schema":              jsonSchemaDialect,
+		"title":                fmt.Sprintf("simplehash v%d event", schemaVersion),
+		"type":                 "object",
+		"properties":           properties,
+		"required":             []string{"identity"},
+		"additionalProperties": true,
+	}, "", "  ")
+}
+
+// jsonSchemaRedactions describes the listing of redacted attributes, see
+// RedactedValue
+func jsonSchemaRedactions() map[string]any {
+	names := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	return map[string]any{
+		"type": "object",
+		"description": "The names of the redacted attributes, which are absent from their attribute maps. " +
+			"The names are hashed in place of the values.",
+		"properties": map[string]any{
+			"event_attributes": names,
+			"asset_attributes": names,
+		},
+		"additionalProperties": false,
+	}
+}
+
+// jsonSchemaField describes the format of a hashed field
+func jsonSchemaField(name string) map[string]any {
+	switch {
+	case name == "identity":
+		return map[string]any{
+			"type":    "string",
+			"pattern": "^(public)?assets/[^/]+/events/[^/]+$",
+		}
+	case name == "asset_identity":
+		return map[string]any{
+			"type":    "string",
+			"pattern": "^(public)?assets/[^/]+$",
+		}
+	case name == "tenant_identity":
+		return map[string]any{
+			"type":    "string",
+			"pattern": "^(tenant/.+)?$",
+		}
+	case strings.HasPrefix(name, "timestamp_"):
+		return map[string]any{
+			"type":  "string",
+			"anyOf": []any{map[string]any{"const": ""}, map[string]any{"format": "date-time"}},
+		}
+	case strings.HasSuffix(name, "_attributes"):
+		stringMap := map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"type": "string"},
+		}
+		return map[string]any{
+			"type": "object",
+			"additionalProperties": map[string]any{
+				"anyOf": []any{
+					map[string]any{"type": "string"},
+					stringMap,
+					map[string]any{"type": "array", "items": stringMap},
+				},
+			},
+		}
+	case strings.HasPrefix(name, "principal_"):
+		fields := map[string]any{}
+		for _, k := range principalKeys {
+			fields[k] = map[string]any{"type": "string"}
+		}
+		return map[string]any{
+			"type":       "object",
+			"properties": fields,
+		}
+	}
+	return map[string]any{"type": "string"}
+}
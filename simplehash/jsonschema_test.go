@@ -0,0 +1,48 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSONSchema tests:
+//
+// 1. the v3 schema describes exactly the fields of V3Event, with their
+// formats, and the listing of redacted attributes.
+// 2. the v2 schema additionally describes the v2 only fields.
+// 3. unknown schema versions are rejected.
+func TestJSONSchema(t *testing.T) {
+	decode := func(version int) map[string]any {
+		b, err := JSONSchema(version)
+		require.NoError(t, err)
+		schema := map[string]any{}
+		require.NoError(t, json.Unmarshal(b, &schema))
+		return schema
+	}
+
+	v3 := decode(SchemaVersionV3)
+	assert.Equal(t, jsonSchemaDialect, v3["$schema"])
+	properties := v3["properties"].(map[string]any)
+	var names []string
+	for name := range properties {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"identity", "event_attributes", "asset_attributes", "operation", "behaviour",
+		"timestamp_declared", "timestamp_accepted", "timestamp_committed",
+		"principal_accepted", "principal_declared", "tenant_identity", "_redacted_attributes",
+	}, names)
+	assert.Equal(t, "object", properties["event_attributes"].(map[string]any)["type"])
+	assert.Contains(t, properties["principal_declared"].(map[string]any)["properties"], "display_name")
+
+	v2 := decode(SchemaVersionV2)
+	for _, field := range v2Fields {
+		assert.Contains(t, v2["properties"], field)
+	}
+
+	_, err := JSONSchema(1)
+	assert.ErrorIs(t, err, ErrUnknownSchema)
+}
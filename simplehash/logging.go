@@ -0,0 +1,69 @@
+package simplehash
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+)
+
+// WithLogger logs what is hashed to logger, so services can trace exactly
+// which events contributed to a digest when diagnosing a mismatch. Each
+// hashed event is logged at debug level with its identity, schema and the
+// size of its pre-image. Batches and streams additionally log each failed or
+// quarantined event at warn level, batches log a summary at info level, and
+// streams log each checkpoint at debug level. Nothing is logged by default.
+func WithLogger(logger *slog.Logger) HashOption {
+	return func(o *HashOptions) {
+		o.logger = logger
+	}
+}
+
+// logEvent logs an event written to the hasher
+func (o *HashOptions) logEvent(schema string, event any, preimageSize int) {
+	if o.logger == nil || !o.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	identity := ""
+	switch e := event.(type) {
+	case V3Event:
+		identity = e.Identity
+	case V2Event:
+		identity = e.Identity
+	case AssetV1:
+		identity = e.Identity
+	}
+	o.logger.Debug("hashed event",
+		"identity", identity,
+		"schema", schema,
+		"preimage_bytes", preimageSize,
+		"accumulate", o.accumulateHash,
+	)
+}
+
+// logBatchEvent logs a batch event which failed, and was quarantined if
+// quarantined is true
+func (o *HashOptions) logBatchEvent(i int, identity string, err error, quarantined bool) {
+	if o.logger == nil {
+		return
+	}
+	msg := "batch event failed"
+	if quarantined {
+		msg = "batch event quarantined"
+	}
+	o.logger.Warn(msg, "index", i, "identity", identity, "error", err)
+}
+
+// logBatch logs the summary of a completed or canceled batch
+func (o *HashOptions) logBatch(result BatchResult) {
+	if o.logger == nil {
+		return
+	}
+	o.logger.Info("hashed batch",
+		"events", result.Count,
+		"failed", result.Failed,
+		"quarantined", result.Quarantined,
+		"duplicates", result.Duplicates,
+		"partial", result.Partial,
+		"digest", hex.EncodeToString(result.Digest),
+	)
+}
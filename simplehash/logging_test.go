@@ -0,0 +1,69 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithLogger tests:
+//
+// 1. each hashed event is logged at debug level with its identity.
+// 2. a failed batch event is logged at warn level.
+// 3. the batch summary is logged with the digest of the batch.
+// 4. the digest is unchanged by logging.
+func TestWithLogger(t *testing.T) {
+	marshaler := NewEventMarshaler()
+
+	var events [][]byte
+	for _, event := range validEventsV2 {
+		eventJson, err := marshaler.Marshal(event)
+		require.NoError(t, err)
+		events = append(events, eventJson)
+	}
+	events = append(events, []byte(`{"identity": `))
+
+	h := NewHasherV3()
+	expected, err := h.HashEventsFromJSON(events, WithContinueOnError())
+	require.Error(t, err)
+
+	var b bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	result, err := h.HashEventsFromJSON(events, WithContinueOnError(), WithLogger(logger))
+	require.Error(t, err)
+	assert.Equal(t, expected.Digest, result.Digest)
+
+	var entries []map[string]any
+	decoder := json.NewDecoder(&b)
+	for decoder.More() {
+		entry := map[string]any{}
+		require.NoError(t, decoder.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, len(events)+1)
+
+	for i, event := range validEventsV2 {
+		assert.Equal(t, "DEBUG", entries[i]["level"])
+		assert.Equal(t, "hashed event", entries[i]["msg"])
+		assert.Equal(t, event.Identity, entries[i]["identity"])
+		assert.Equal(t, "EventSimpleHashV3", entries[i]["schema"])
+	}
+
+	failed := entries[len(validEventsV2)]
+	assert.Equal(t, "WARN", failed["level"])
+	assert.Equal(t, "batch event failed", failed["msg"])
+	assert.Equal(t, float64(len(validEventsV2)), failed["index"])
+
+	summary := entries[len(entries)-1]
+	assert.Equal(t, "INFO", summary["level"])
+	assert.Equal(t, float64(len(validEventsV2)), summary["events"])
+	assert.Equal(t, float64(1), summary["failed"])
+	assert.Equal(t, hex.EncodeToString(result.Digest), summary["digest"])
+}
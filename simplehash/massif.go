@@ -0,0 +1,143 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNoMerklelogEntry   = errors.New("event has no merklelog commit")
+	ErrInvalidIDTimestamp = errors.New("invalid idtimestamp")
+	ErrLeafMismatch       = errors.New("event leaf hash does not match the log")
+	ErrNotInLog           = errors.New("event is not included in the log")
+	ErrInvalidPeaks       = errors.New("invalid trusted peaks")
+)
+
+// MassifReader provides read access to the nodes of a tenant's merkle log. It
+// is satisfied by the merklelog massif context.
+type MassifReader interface {
+	// Get returns the value of the node at mmr index i
+	Get(i uint64) ([]byte, error)
+	// RangeCount returns the number of mmr nodes available to Get
+	RangeCount() uint64
+}
+
+// ParseIDTimestampHex parses the hex idtimestamp found in an event's
+// merklelog commit. The optional 0x prefix is accepted, and if present, the
+// leading byte beyond the 8 byte idtimestamp is the log epoch.
+func ParseIDTimestampHex(s string) (uint64, uint8, error) {
+	s = strings.TrimPrefix(s, "0x")
+	if len(s) == 0 || len(s) > 18 {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidIDTimestamp, s)
+	}
+
+	var epoch uint64
+	var err error
+	if len(s) > 16 {
+		if epoch, err = strconv.ParseUint(s[:len(s)-16], 16, 8); err != nil {
+			return 0, 0, fmt.Errorf("%w: %v", ErrInvalidIDTimestamp, err)
+		}
+		s = s[len(s)-16:]
+	}
+
+	id, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", ErrInvalidIDTimestamp, err)
+	}
+	return id, uint8(epoch), nil
+}
+
+// mmrRootFromLeaf walks from the node at index i up to the peak which
+// includes it, reading the siblings from the log. It returns the index of the
+// peak and the value computed for it.
+func mmrRootFromLeaf(massifReader MassifReader, mmrSize uint64, i uint64, node []byte) (uint64, []byte, error) {
+
+	for g := uint64(0); ; g++ {
+
+		var left, right []byte
+
+		siblingOffset := (uint64(2) << g) - 1
+
+		if mmrIndexHeight(i+1) > g {
+			// i is a right child, its sibling is to the left and its parent
+			// immediately follows it.
+			sibling, err := massifReader.Get(i - siblingOffset)
+			if err != nil {
+				return 0, nil, err
+			}
+			left, right = sibling, node
+			i++
+		} else {
+			// i is a left child, its sibling is to the right. If that is
+			// beyond the end of the log, i is a peak.
+			if i+siblingOffset >= mmrSize {
+				return i, node, nil
+			}
+			sibling, err := massifReader.Get(i + siblingOffset)
+			if err != nil {
+				return 0, nil, err
+			}
+			left, right = node, sibling
+			i += siblingOffset + 1
+		}
+
+		if i >= mmrSize {
+			return 0, nil, fmt.Errorf("%w: incomplete mmr of size %d", ErrNotInLog, mmrSize)
+		}
+		node = mmrHashPosPair(i+1, left, right)
+	}
+}
+
+// mmrPeaks returns the mmr indices of the peaks of an mmr of size mmrSize,
+// highest first. It returns nil if mmrSize is not the size of a complete mmr.
+func mmrPeaks(mmrSize uint64) []uint64 {
+
+	var peaks []uint64
+	var offset uint64
+	treeSize := ^uint64(0)
+
+	for mmrSize > 0 {
+		// the largest perfect tree which fits is the next peak, each peak
+		// must be smaller than the last.
+		next := (uint64(1) << (bits.Len64(mmrSize+1) - 1)) - 1
+		if next >= treeSize {
+			return nil
+		}
+		treeSize = next
+		offset += treeSize
+		peaks = append(peaks, offset-1)
+		mmrSize -= treeSize
+	}
+	return peaks
+}
+
+// mmrHashPosPair returns H(pos || left || right), the interior node hash used
+// by the merkle log. pos is the one based position of the parent node.
+func mmrHashPosPair(pos uint64, left []byte, right []byte) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], pos)
+	h := sha256.New()
+	h.Write(b[:])
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// mmrIndexHeight returns the height of the node at mmr index i, leaves are at
+// height zero.
+func mmrIndexHeight(i uint64) uint64 {
+	pos := i + 1
+	for !mmrAllOnes(pos) {
+		pos = pos - (uint64(1) << (bits.Len64(pos) - 1)) + 1
+	}
+	return uint64(bits.Len64(pos) - 1)
+}
+
+func mmrAllOnes(pos uint64) bool {
+	return pos != 0 && pos&(pos+1) == 0
+}
@@ -0,0 +1,96 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"bytes"
+	"fmt"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+)
+
+// V3LeafHash returns the merkle log leaf hash for the event. This is the V3
+// simple hash, domain separated by the plain leaf type and prefixed with the
+// idtimestamp assigned when the event was committed to the log. The
+// idtimestamp is validated against its log epoch, see WithIDCommittedEpoch.
+func V3LeafHash(event *v2assets.EventResponse) ([]byte, error) {
+
+	commit := event.GetMerklelogEntry().GetCommit()
+	if commit == nil {
+		return nil, ErrNoMerklelogEntry
+	}
+
+	idcommitted, epoch, err := ParseIDTimestampHex(commit.GetIdtimestamp())
+	if err != nil {
+		return nil, err
+	}
+
+	h := NewHasherV3()
+	err = h.HashEvent(
+		event,
+		WithDomain(DomainLeafPlain),
+		WithIDCommittedEpoch(idcommitted, epoch),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// VerifyEventInLog computes the V3 leaf hash of the event and checks it is
+// included in the log of size mmrSize committed to by trustedPeaks. The peaks
+// are the node values of the mmr, highest first, and must come from a source
+// trusted independently of massifReader, such as a signed log checkpoint of
+// that size. The log read by massifReader provides only the leaf and the
+// proof path, so a log which is merely consistent with itself does not
+// verify.
+//
+// The leaf is located using the mmr index recorded in the event's merklelog
+// commit. The leaf must match the stored value, and the path from the leaf
+// must reproduce the trusted peak which commits it.
+func VerifyEventInLog(event *v2assets.EventResponse, massifReader MassifReader, mmrSize uint64, trustedPeaks [][]byte) error {
+
+	peaks := mmrPeaks(mmrSize)
+	if peaks == nil {
+		return fmt.Errorf("%w: %d is not a valid mmr size", ErrInvalidPeaks, mmrSize)
+	}
+	if len(trustedPeaks) != len(peaks) {
+		return fmt.Errorf("%w: an mmr of size %d has %d peaks, not %d", ErrInvalidPeaks, mmrSize, len(peaks), len(trustedPeaks))
+	}
+	if mmrSize > massifReader.RangeCount() {
+		return fmt.Errorf("%w: mmr size %d is beyond the log size %d", ErrInvalidPeaks, mmrSize, massifReader.RangeCount())
+	}
+
+	leafHash, err := V3LeafHash(event)
+	if err != nil {
+		return err
+	}
+
+	mmrIndex := event.GetMerklelogEntry().GetCommit().GetIndex()
+	if mmrIndex >= mmrSize {
+		return fmt.Errorf("%w: mmr index %d is beyond the mmr size %d", ErrNotInLog, mmrIndex, mmrSize)
+	}
+
+	stored, err := massifReader.Get(mmrIndex)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(stored, leafHash) {
+		return fmt.Errorf("%w: mmr index %d", ErrLeafMismatch, mmrIndex)
+	}
+
+	peakIndex, peak, err := mmrRootFromLeaf(massifReader, mmrSize, mmrIndex, leafHash)
+	if err != nil {
+		return err
+	}
+
+	for i, index := range peaks {
+		if index == peakIndex {
+			if !bytes.Equal(trustedPeaks[i], peak) {
+				return fmt.Errorf("%w: peak %d does not commit mmr index %d", ErrNotInLog, peakIndex, mmrIndex)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: mmr index %d reaches node %d, which is not a peak", ErrNotInLog, mmrIndex, peakIndex)
+}
@@ -0,0 +1,87 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"fmt"
+	"testing"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+type testMassif [][]byte
+
+func (m testMassif) Get(i uint64) ([]byte, error) {
+	if i >= uint64(len(m)) {
+		return nil, fmt.Errorf("index %d out of range", i)
+	}
+	return m[i], nil
+}
+
+func (m testMassif) RangeCount() uint64 { return uint64(len(m)) }
+
+func committedEvent(event *v2assets.EventResponse, mmrIndex uint64, idtimestamp string) *v2assets.EventResponse {
+	event = proto.Clone(event).(*v2assets.EventResponse)
+	event.MerklelogEntry = &v2assets.MerkleLogEntry{
+		Commit: &v2assets.MerkleLogCommitMongoDB{Index: mmrIndex, Idtimestamp: idtimestamp},
+	}
+	return event
+}
+
+// TestVerifyEventInLog tests:
+//
+// 1. leaves at every position of a small mmr verify against its peaks.
+// 2. a modified event is rejected.
+// 3. a log which is consistent with itself, but not with the trusted peaks,
+// is rejected.
+// 4. peaks which don't match the mmr size are rejected with ErrInvalidPeaks.
+// 5. an event without a merklelog commit is rejected.
+// 6. an event whose idtimestamp is in the future in its epoch is rejected.
+func TestVerifyEventInLog(t *testing.T) {
+	events := []*v2assets.EventResponse{
+		committedEvent(validEventsV2[0], 0, "0x01931acb7b14043b00"),
+		committedEvent(validEventsV2[1], 1, "018e3f48610b0899"),
+		committedEvent(validEventsV2[0], 3, "0x018e3f48610b089a"),
+	}
+
+	var leaves [][]byte
+	for _, event := range events {
+		leaf, err := V3LeafHash(event)
+		require.NoError(t, err)
+		leaves = append(leaves, leaf)
+	}
+	massif := testMassif{leaves[0], leaves[1], mmrHashPosPair(3, leaves[0], leaves[1]), leaves[2]}
+	peaks := [][]byte{massif[2], massif[3]}
+
+	for _, event := range events {
+		assert.NoError(t, VerifyEventInLog(event, massif, 4, peaks))
+	}
+	assert.NoError(t, VerifyEventInLog(events[0], massif[:3], 3, peaks[:1]))
+
+	tampered := proto.Clone(events[1]).(*v2assets.EventResponse)
+	tampered.Operation = "Tampered"
+	assert.ErrorIs(t, VerifyEventInLog(tampered, massif, 4, peaks), ErrLeafMismatch)
+
+	badPath := append(testMassif{}, massif...)
+	badPath[0] = leaves[2]
+	assert.ErrorIs(t, VerifyEventInLog(events[1], badPath, 4, peaks), ErrNotInLog)
+
+	forged := append(testMassif{}, massif...)
+	forged[0] = leaves[2]
+	forged[2] = mmrHashPosPair(3, forged[0], forged[1])
+	assert.ErrorIs(t, VerifyEventInLog(events[1], forged, 4, peaks), ErrNotInLog)
+	assert.NoError(t, VerifyEventInLog(events[1], forged, 4, [][]byte{forged[2], forged[3]}))
+
+	assert.ErrorIs(t, VerifyEventInLog(events[0], massif, 2, peaks[:1]), ErrInvalidPeaks)
+	assert.ErrorIs(t, VerifyEventInLog(events[0], massif, 4, peaks[:1]), ErrInvalidPeaks)
+	assert.ErrorIs(t, VerifyEventInLog(events[0], massif, 7, [][]byte{peaks[0]}), ErrInvalidPeaks)
+	assert.ErrorIs(t, VerifyEventInLog(events[2], massif[:3], 3, peaks[:1]), ErrNotInLog)
+
+	assert.ErrorIs(t, VerifyEventInLog(validEventsV2[1], massif, 4, peaks), ErrNoMerklelogEntry)
+
+	future := committedEvent(validEventsV2[0], 0, "0x02931acb7b14043b00")
+	assert.ErrorIs(t, VerifyEventInLog(future, massif, 4, peaks), ErrInvalidOption)
+}
@@ -0,0 +1,106 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/datatrails/go-datatrails-simplehash/internal/msgpack"
+)
+
+// V3FromMsgPack decodes an event carried as MessagePack, a map with the
+// fields of the api json format, into the event struct. The event is
+// normalized exactly as by V3FromEventJSON, so it hashes identically to the
+// json form of the same event.
+func V3FromMsgPack(data []byte) (V3Event, error) {
+	eventJson, err := msgpackEventJSON(data, HashOptions{})
+	if err != nil {
+		return V3Event{}, err
+	}
+	return V3FromEventJSON(eventJson)
+}
+
+// V2FromMsgPack decodes an event carried as MessagePack into the event
+// struct, normalized as by V2FromEventJSON
+func V2FromMsgPack(data []byte) (V2Event, error) {
+	eventJson, err := msgpackEventJSON(data, HashOptions{})
+	if err != nil {
+		return V2Event{}, err
+	}
+	return V2FromEventJSON(eventJson)
+}
+
+// HashEventFromMsgPack hashes a single event carried as MessagePack. The
+// digest is that of the json form of the same event.
+//
+// Options: as for HashEventFromJSON. WithMaxEventSize applies to the
+// MessagePack payload, and WithUseNumber hashes integers exactly.
+func (h *HasherV3) HashEventFromMsgPack(data []byte, opts ...HashOption) error {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	eventJson, err := msgpackEventJSON(data, o)
+	if err != nil {
+		return err
+	}
+	// the size limit has been applied to the payload, not its json form
+	o.maxEventSize = 0
+	v3Event, err := v3FromEventJSON(eventJson, o)
+	if err != nil {
+		return err
+	}
+
+	if err := h.applyEventOptions(o, &v3Event); err != nil {
+		return err
+	}
+
+	return skipDuplicate(h.hashV3Event(v3Event, o))
+}
+
+// msgpackEventJSON converts a MessagePack event to json, so that it is
+// decoded and normalized by the json path. Integers become json integers,
+// which WithUseNumber hashes exactly. Values with no json equivalent, such as
+// bin, are rejected with ErrInvalidEvent.
+func msgpackEventJSON(data []byte, o HashOptions) ([]byte, error) {
+	if o.maxEventSize > 0 && len(data) > o.maxEventSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds %d", ErrEventTooLarge, len(data), o.maxEventSize)
+	}
+	event, err := msgpack.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if _, ok := event.(map[string]any); !ok {
+		return nil, fmt.Errorf("%w: msgpack event is %T, not a map", ErrInvalidEvent, event)
+	}
+	if err = checkMsgPackValue(event); err != nil {
+		return nil, err
+	}
+	eventJson, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return eventJson, nil
+}
+
+// checkMsgPackValue rejects the decoded values json can not represent
+func checkMsgPackValue(v any) error {
+	switch value := v.(type) {
+	case []byte:
+		return fmt.Errorf("%w: msgpack bin values are not supported", ErrInvalidEvent)
+	case map[string]any:
+		for _, item := range value {
+			if err := checkMsgPackValue(item); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range value {
+			if err := checkMsgPackValue(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeMsgPack encodes the values json decodes to, and int64 and []byte, as
+// MessagePack
+func encodeMsgPack(t *testing.T, v any) []byte {
+	var b []byte
+	switch value := v.(type) {
+	case nil:
+		b = append(b, 0xc0)
+	case bool:
+		if value {
+			b = append(b, 0xc3)
+		} else {
+			b = append(b, 0xc2)
+		}
+	case int64:
+		b = append(b, 0xd3)
+		b = binary.BigEndian.AppendUint64(b, uint64(value))
+	case float64:
+		b = append(b, 0xcb)
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(value))
+	case string:
+		b = append(b, 0xdb)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(value)))
+		b = append(b, value...)
+	case []byte:
+		b = append(b, 0xc6)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(value)))
+		b = append(b, value...)
+	case []any:
+		b = append(b, 0xdd)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(value)))
+		for _, item := range value {
+			b = append(b, encodeMsgPack(t, item)...)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		// the decoded map does not depend on the encoded order
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+		b = append(b, 0xdf)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(value)))
+		for _, k := range keys {
+			b = append(b, encodeMsgPack(t, k)...)
+			b = append(b, encodeMsgPack(t, value[k])...)
+		}
+	default:
+		t.Fatalf("can not encode %T", v)
+	}
+	return b
+}
+
+// TestMsgPack tests:
+//
+// 1. the MessagePack form of an event decodes to the same V2 and V3 events as
+// its json form, and hashes to the same digest.
+// 2. with WithUseNumber a MessagePack integer attribute hashes as the json
+// integer does.
+// 3. bin values and payloads which are not maps are rejected with
+// ErrInvalidEvent.
+// 4. WithMaxEventSize applies to the MessagePack payload.
+func TestMsgPack(t *testing.T) {
+	eventJson, err := NewEventMarshaler().Marshal(validEventsV2[0])
+	require.NoError(t, err)
+	event := map[string]any{}
+	require.NoError(t, json.Unmarshal(eventJson, &event))
+	data := encodeMsgPack(t, event)
+
+	expectedV3, err := V3FromEventJSON(eventJson)
+	require.NoError(t, err)
+	v3Event, err := V3FromMsgPack(data)
+	require.NoError(t, err)
+	assert.Equal(t, expectedV3, v3Event)
+
+	expectedV2, err := V2FromEventJSON(eventJson)
+	require.NoError(t, err)
+	v2Event, err := V2FromMsgPack(data)
+	require.NoError(t, err)
+	assert.Equal(t, expectedV2, v2Event)
+
+	expected, err := DigestEventFromJSON(eventJson)
+	require.NoError(t, err)
+	h := NewHasherV3()
+	require.NoError(t, h.HashEventFromMsgPack(data))
+	assert.Equal(t, expected, h.Sum(nil))
+
+	event["event_attributes"].(map[string]any)["count"] = int64(9007199254740993)
+	data = encodeMsgPack(t, event)
+	eventJson, err = json.Marshal(event)
+	require.NoError(t, err)
+	expected, err = DigestEventFromJSON(eventJson, WithUseNumber())
+	require.NoError(t, err)
+	require.NoError(t, h.HashEventFromMsgPack(data, WithUseNumber()))
+	assert.Equal(t, expected, h.Sum(nil))
+
+	require.ErrorIs(t, h.HashEventFromMsgPack(data, WithUseNumber(), WithMaxEventSize(len(data)-1)), ErrEventTooLarge)
+	require.NoError(t, h.HashEventFromMsgPack(data, WithUseNumber(), WithMaxEventSize(len(data))))
+
+	event["event_attributes"].(map[string]any)["count"] = []byte("raw")
+	_, err = V3FromMsgPack(encodeMsgPack(t, event))
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+
+	_, err = V3FromMsgPack(encodeMsgPack(t, []any{"not", "a", "map"}))
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+}
@@ -0,0 +1,148 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// multihashSHA256 is the multihash code for sha2-256, the digest function of
+// every simple hash schema.
+const multihashSHA256 = 0x12
+
+var (
+	ErrInvalidMultihash = errors.New("invalid multihash")
+	ErrInvalidMultibase = errors.New("invalid multibase string")
+)
+
+// Multibase is the prefix character identifying a multibase encoding
+type Multibase byte
+
+// The supported multibase encodings
+const (
+	MultibaseBase16    Multibase = 'f'
+	MultibaseBase32    Multibase = 'b'
+	MultibaseBase58BTC Multibase = 'z'
+	MultibaseBase64URL Multibase = 'u'
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// SumMultihash returns the current digest as a sha2-256 multihash, the
+// digest prefixed by the hash function code and digest length.
+func (h *Hasher) SumMultihash() []byte {
+	return Multihash(h.hasher.Sum(nil))
+}
+
+// SumMultibase returns the current digest as a multibase encoded multihash,
+// eg for base58btc the familiar "zQm..." form.
+func (h *Hasher) SumMultibase(base Multibase) (string, error) {
+	return EncodeMultibase(base, h.SumMultihash())
+}
+
+// Multihash wraps a sha256 digest as a multihash
+func Multihash(digest []byte) []byte {
+	return append([]byte{multihashSHA256, byte(len(digest))}, digest...)
+}
+
+// DigestFromMultihash returns the digest from a sha2-256 multihash
+func DigestFromMultihash(mh []byte) ([]byte, error) {
+	if len(mh) != 2+sha256.Size || mh[0] != multihashSHA256 || mh[1] != sha256.Size {
+		return nil, fmt.Errorf("%w: expected a sha2-256 multihash", ErrInvalidMultihash)
+	}
+	return mh[2:], nil
+}
+
+// EncodeMultibase encodes data with the multibase prefix for base
+func EncodeMultibase(base Multibase, data []byte) (string, error) {
+	var encoded string
+	switch base {
+	case MultibaseBase16:
+		encoded = hex.EncodeToString(data)
+	case MultibaseBase32:
+		encoded = base32Lower.EncodeToString(data)
+	case MultibaseBase58BTC:
+		encoded = base58Encode(data)
+	case MultibaseBase64URL:
+		encoded = base64.RawURLEncoding.EncodeToString(data)
+	default:
+		return "", fmt.Errorf("%w: unsupported base %q", ErrInvalidMultibase, base)
+	}
+	return string(base) + encoded, nil
+}
+
+// DecodeMultibase decodes a multibase string in any of the supported
+// encodings
+func DecodeMultibase(s string) ([]byte, error) {
+	if s == "" {
+		return nil, ErrInvalidMultibase
+	}
+	var data []byte
+	var err error
+	switch Multibase(s[0]) {
+	case MultibaseBase16:
+		data, err = hex.DecodeString(s[1:])
+	case MultibaseBase32:
+		data, err = base32Lower.DecodeString(s[1:])
+	case MultibaseBase58BTC:
+		data, err = base58Decode(s[1:])
+	case MultibaseBase64URL:
+		data, err = base64.RawURLEncoding.DecodeString(s[1:])
+	default:
+		return nil, fmt.Errorf("%w: unsupported base %q", ErrInvalidMultibase, s[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMultibase, err)
+	}
+	return data, nil
+}
+
+func base58Encode(data []byte) string {
+	var sb strings.Builder
+	// each leading zero byte is encoded as the zero digit
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		sb.WriteByte(base58Alphabet[0])
+	}
+
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var digits []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		digits = append(digits, base58Alphabet[mod.Int64()])
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		sb.WriteByte(digits[i])
+	}
+	return sb.String()
+}
+
+func base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := zeros; i < len(s); i++ {
+		digit := strings.IndexByte(base58Alphabet, s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
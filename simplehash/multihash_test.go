@@ -0,0 +1,79 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHasher_SumMultihash tests:
+//
+// 1. the multihash is the sha2-256 code and length followed by the digest.
+// 2. each multibase encoding round trips to the digest.
+// 3. the base58btc form has the familiar Qm prefix.
+func TestHasher_SumMultihash(t *testing.T) {
+	h := NewHasherV3()
+	_, err := h.HashEvents(validEventsV2)
+	require.NoError(t, err)
+
+	mh := h.SumMultihash()
+	assert.Equal(t, "1220"+expectedHashAllV3, hex.EncodeToString(mh))
+
+	for _, base := range []Multibase{MultibaseBase16, MultibaseBase32, MultibaseBase58BTC, MultibaseBase64URL} {
+		t.Run(string(base), func(t *testing.T) {
+			s, err := h.SumMultibase(base)
+			require.NoError(t, err)
+			assert.Equal(t, byte(base), s[0])
+
+			decoded, err := DecodeMultibase(s)
+			require.NoError(t, err)
+			digest, err := DigestFromMultihash(decoded)
+			require.NoError(t, err)
+			assert.Equal(t, expectedHashAllV3, hex.EncodeToString(digest))
+		})
+	}
+
+	s, err := h.SumMultibase(MultibaseBase58BTC)
+	require.NoError(t, err)
+	assert.Equal(t, "zQm", s[:3])
+}
+
+// TestMultibase_Vectors tests:
+//
+// 1. the encodings match the multibase specification test vectors.
+// 2. unsupported and malformed inputs are rejected.
+func TestMultibase_Vectors(t *testing.T) {
+	data := []byte("yes mani !")
+	tests := []struct {
+		base     Multibase
+		expected string
+	}{
+		{MultibaseBase16, "f796573206d616e692021"},
+		{MultibaseBase32, "bpfsxgidnmfxgsibb"},
+		{MultibaseBase58BTC, "z7paNL19xttacUY"},
+		{MultibaseBase64URL, "ueWVzIG1hbmkgIQ"},
+	}
+	for _, test := range tests {
+		actual, err := EncodeMultibase(test.base, data)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, actual)
+	}
+
+	leadingZeros, err := EncodeMultibase(MultibaseBase58BTC, []byte{0, 0, 1})
+	require.NoError(t, err)
+	assert.Equal(t, "z112", leadingZeros)
+	decoded, err := DecodeMultibase(leadingZeros)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 1}, decoded)
+
+	_, err = EncodeMultibase('m', data)
+	assert.ErrorIs(t, err, ErrInvalidMultibase)
+	_, err = DecodeMultibase("z0OIl")
+	assert.ErrorIs(t, err, ErrInvalidMultibase)
+	_, err = DigestFromMultihash([]byte{0x11, 0x14})
+	assert.ErrorIs(t, err, ErrInvalidMultihash)
+}
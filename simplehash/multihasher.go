@@ -0,0 +1,99 @@
+package simplehash
+
+import (
+	"fmt"
+	"hash"
+)
+
+// multiHash is a hash.Hash which writes to several hashes at once. Its Sum is
+// the concatenation of the individual sums, in the order the hashes were given.
+type multiHash []hash.Hash
+
+func (m multiHash) Write(p []byte) (int, error) {
+	for _, h := range m {
+		if _, err := h.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (m multiHash) Sum(b []byte) []byte {
+	for _, h := range m {
+		b = h.Sum(b)
+	}
+	return b
+}
+
+func (m multiHash) Reset() {
+	for _, h := range m {
+		h.Reset()
+	}
+}
+
+func (m multiHash) Size() int {
+	size := 0
+	for _, h := range m {
+		size += h.Size()
+	}
+	return size
+}
+
+func (m multiHash) BlockSize() int {
+	if len(m) == 0 {
+		return 0
+	}
+	return m[0].BlockSize()
+}
+
+// MultiHasherV3 encodes each event once and writes the canonical bytes to
+// several hash algorithms, eg SHA-256 and SHA3-256. This allows digests to be
+// dual published while migrating to a new algorithm. The HasherV3 hashing
+// methods and options are available, with these exceptions:
+//
+//   - MarshalBinary, UnmarshalBinary and Clone fail with
+//     ErrStateNotSupported, the hash state of several algorithms is not saved,
+//     so an accumulation can not be checkpointed or branched. StreamHasher
+//     and RollingRoots, which rely on them, hash with SHA-256 alone.
+//   - SumMultihash and SumMultibase fail with ErrInvalidMultihash, the
+//     algorithms of the hashes are not known, so Sum can not be labelled as a
+//     multihash. Label each of Sums with the code of its algorithm instead.
+type MultiHasherV3 struct {
+	HasherV3
+	hashers []hash.Hash
+}
+
+// NewMultiHasherV3 creates a hasher which writes to each of the provided hashes
+func NewMultiHasherV3(hashers ...hash.Hash) MultiHasherV3 {
+	h := MultiHasherV3{
+		HasherV3: HasherV3{
+			Hasher: Hasher{
+				hasher:    multiHash(hashers),
+				marshaler: newEventMarshaler(),
+			},
+		},
+		hashers: hashers,
+	}
+	return h
+}
+
+// Sums returns the digest from each hash, in the order they were provided to
+// NewMultiHasherV3. Sum returns the same digests concatenated.
+func (h *MultiHasherV3) Sums() [][]byte {
+	sums := make([][]byte, 0, len(h.hashers))
+	for _, hh := range h.hashers {
+		sums = append(sums, hh.Sum(nil))
+	}
+	return sums
+}
+
+// SumMultihash fails with ErrInvalidMultihash, see MultiHasherV3
+func (h *MultiHasherV3) SumMultihash() ([]byte, error) {
+	return nil, fmt.Errorf("%w: the sums of a MultiHasherV3 have no single algorithm", ErrInvalidMultihash)
+}
+
+// SumMultibase fails with ErrInvalidMultihash, see MultiHasherV3
+func (h *MultiHasherV3) SumMultibase(base Multibase) (string, error) {
+	_, err := h.SumMultihash()
+	return "", err
+}
@@ -0,0 +1,47 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMultiHasherV3 tests:
+//
+// 1. each digest matches hashing with that algorithm alone.
+// 2. the hash state can not be saved or cloned, and the sums can not be
+// labelled as a multihash.
+func TestMultiHasherV3(t *testing.T) {
+	h := NewMultiHasherV3(sha256.New(), sha512.New())
+	for _, event := range validEventsV2 {
+		require.NoError(t, h.HashEvent(event, WithAccumulate()))
+	}
+
+	single := Hasher{hasher: sha512.New(), marshaler: NewEventMarshaler()}
+	single512 := HasherV3{Hasher: single}
+	for _, event := range validEventsV2 {
+		require.NoError(t, single512.HashEvent(event, WithAccumulate()))
+	}
+
+	sums := h.Sums()
+	require.Len(t, sums, 2)
+	assert.Equal(t, expectedHashAllV3, hex.EncodeToString(sums[0]))
+	assert.Equal(t, single512.Sum(nil), sums[1])
+	assert.Equal(t, append(sums[0], sums[1]...), h.Sum(nil))
+
+	_, err := h.MarshalBinary()
+	assert.ErrorIs(t, err, ErrStateNotSupported)
+	assert.ErrorIs(t, h.UnmarshalBinary([]byte{}), ErrStateNotSupported)
+	_, err = h.Clone()
+	assert.ErrorIs(t, err, ErrStateNotSupported)
+	_, err = h.SumMultihash()
+	assert.ErrorIs(t, err, ErrInvalidMultihash)
+	_, err = h.SumMultibase(MultibaseBase58BTC)
+	assert.ErrorIs(t, err, ErrInvalidMultihash)
+}
@@ -0,0 +1,58 @@
+package simplehash
+
+import (
+	"strings"
+)
+
+// principalKeys are the fields the api always emits for a principal, even if
+// they are unset. The order is that of the Principal fields.
+var principalKeys = []string{"issuer", "subject", "display_name", "email"}
+
+// NormalizeEvent applies the formatting the api performs when it returns an
+// event, so that a locally constructed event hashes identically to the api
+// representation of the same event:
+//
+//   - timestamps are formatted as for TimestampFormatAPI. Empty timestamps
+//     are left unset.
+//   - the event and tenant identities are lower cased, the platform only
+//     issues lower case identities.
+//   - nil attribute maps become empty maps, the api never emits null
+//     attributes.
+//   - nil principals become principals with every field set to the empty
+//     string, and missing or null principal fields are set to empty strings,
+//     see NormalizePrincipal.
+//
+// The principal maps are copied rather than modified in place.
+func NormalizeEvent(e *V3Event) error {
+
+	if err := e.FormatTimestamps(TimestampFormatAPI); err != nil {
+		return err
+	}
+
+	e.Identity = strings.ToLower(e.Identity)
+	e.TenantIdentity = strings.ToLower(e.TenantIdentity)
+
+	if e.EventAttributes == nil {
+		e.EventAttributes = map[string]any{}
+	}
+	if e.AssetAttributes == nil {
+		e.AssetAttributes = map[string]any{}
+	}
+	e.PrincipalAccepted = normalizePrincipal(e.PrincipalAccepted)
+	e.PrincipalDeclared = normalizePrincipal(e.PrincipalDeclared)
+
+	return nil
+}
+
+func normalizePrincipal(principal map[string]any) map[string]any {
+	normalized := make(map[string]any, len(principalKeys))
+	for k, v := range principal {
+		normalized[k] = v
+	}
+	for _, k := range principalKeys {
+		if v, ok := normalized[k]; !ok || v == nil {
+			normalized[k] = ""
+		}
+	}
+	return normalized
+}
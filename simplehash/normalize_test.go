@@ -0,0 +1,59 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"testing"
+	"time"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TestNormalizeEvent tests:
+//
+// 1. a locally constructed event normalizes to the api representation of the same event.
+// 2. an unparsable timestamp is rejected.
+func TestNormalizeEvent(t *testing.T) {
+
+	accepted := time.Date(2024, 1, 31, 11, 29, 19, 43000000, time.UTC)
+	declared := time.Date(2024, 1, 31, 11, 29, 19, 43500000, time.UTC)
+	committed := time.Date(2024, 1, 31, 11, 29, 19, 0, time.UTC)
+
+	apiJson, err := NewEventMarshaler().Marshal(&v2assets.EventResponse{
+		Identity:           "assets/03c60f22-588c-4f12-b3c2-e98c9f6a3e38/events/a022f458-8e55-4d63-a200-4172a42fc2aa",
+		Operation:          "Record",
+		TimestampAccepted:  timestamppb.New(accepted),
+		TimestampDeclared:  timestamppb.New(declared),
+		TimestampCommitted: timestamppb.New(committed),
+		PrincipalDeclared:  &v2assets.Principal{Issuer: "idp.example.com"},
+	})
+	require.NoError(t, err)
+	apiEvent, err := V3FromEventJSON(apiJson)
+	require.NoError(t, err)
+
+	local := V3Event{
+		Identity:           "assets/03C60F22-588C-4F12-B3C2-E98C9F6A3E38/events/A022F458-8E55-4D63-A200-4172A42FC2AA",
+		Operation:          "Record",
+		TimestampAccepted:  accepted.In(time.FixedZone("x", 3600)).Format(time.RFC3339Nano),
+		TimestampDeclared:  declared.Format(time.RFC3339Nano),
+		TimestampCommitted: committed.Format("2006-01-02T15:04:05.000Z07:00"),
+		PrincipalDeclared:  map[string]any{"issuer": "idp.example.com"},
+	}
+	require.NoError(t, NormalizeEvent(&local))
+
+	assert.Equal(t, "2024-01-31T11:29:19.043Z", local.TimestampAccepted)
+	assert.Equal(t, "2024-01-31T11:29:19.043500Z", local.TimestampDeclared)
+	assert.Equal(t, "2024-01-31T11:29:19Z", local.TimestampCommitted)
+
+	expected, err := V3EncodeEvent(apiEvent)
+	require.NoError(t, err)
+	actual, err := V3EncodeEvent(local)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+
+	bad := V3Event{TimestampAccepted: "31/01/2024"}
+	assert.ErrorIs(t, NormalizeEvent(&bad), ErrInvalidTimestamp)
+}
@@ -0,0 +1,73 @@
+package simplehash
+
+import (
+	"fmt"
+)
+
+// OptionError reports an option which an entry point does not support. Such
+// options would otherwise be silently ignored, producing a digest the caller
+// did not ask for. It wraps ErrInvalidOption.
+type OptionError struct {
+	// Method is the entry point, eg "HasherV2.HashEventJSON", or empty if the
+	// option was given an invalid value
+	Method string
+	// Option is the rejected option, eg "WithTimestampCommitted"
+	Option string
+	// Reason explains why the option is rejected
+	Reason string
+}
+
+func (e *OptionError) Error() string {
+	if e.Method == "" {
+		return fmt.Sprintf("%s: %v: %s", e.Option, ErrInvalidOption, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s: %v: %s", e.Method, e.Option, ErrInvalidOption, e.Reason)
+}
+
+func (e *OptionError) Unwrap() error { return ErrInvalidOption }
+
+// optionCheck detects a set option
+type optionCheck struct {
+	option string
+	isSet  func(o *HashOptions) bool
+}
+
+var (
+	optPublicFromPermissioned = optionCheck{"WithPublicFromPermissioned", func(o *HashOptions) bool { return o.publicFromPermissioned }}
+	optTimestampCommitted     = optionCheck{"WithTimestampCommitted", func(o *HashOptions) bool { return o.committed != nil }}
+	optIDCommitted            = optionCheck{"WithIDCommitted", func(o *HashOptions) bool { return o.idcommitted != nil }}
+	optChain                  = optionCheck{"WithChain", func(o *HashOptions) bool { return o.chain != nil }}
+	optAccumulate             = optionCheck{"WithAccumulate", func(o *HashOptions) bool { return o.accumulateHash }}
+	optQuarantine             = optionCheck{"WithQuarantine", func(o *HashOptions) bool { return o.quarantine != nil }}
+	optOrder                  = optionCheck{"WithOrder", func(o *HashOptions) bool { return o.order != OrderAsReceived }}
+	optProgress               = optionCheck{"WithProgress", func(o *HashOptions) bool { return o.progress != nil }}
+	optEventDigests           = optionCheck{"WithEventDigests", func(o *HashOptions) bool { return o.eventDigests }}
+	optContinueOnError        = optionCheck{"WithContinueOnError", func(o *HashOptions) bool { return o.continueOnError }}
+	optCache                  = optionCheck{"WithCache", func(o *HashOptions) bool { return o.cache != nil }}
+)
+
+// batchOptions only apply to the batch entry points, the single event entry
+// points reject them
+var batchOptions = []optionCheck{
+	optQuarantine, optOrder, optProgress, optEventDigests, optContinueOnError, optCache,
+}
+
+// checkOptions is the option compatibility check for every entry point. It
+// returns an *OptionError for the first of the unsupported options which is
+// set. reason explains, for the caller, why they are unsupported.
+func (o *HashOptions) checkOptions(method string, reason string, unsupported ...optionCheck) error {
+	for _, check := range unsupported {
+		if check.isSet(o) {
+			return &OptionError{Method: method, Option: check.option, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// checkValues returns the *OptionError for any option given an invalid value
+func (o *HashOptions) checkValues() error {
+	if o.invalid != nil {
+		return o.invalid
+	}
+	return nil
+}
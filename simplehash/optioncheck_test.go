@@ -0,0 +1,221 @@
+package simplehash
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOptionError tests:
+//
+// 1. unsupported options are rejected with an *OptionError naming the entry
+// point and the option, which wraps ErrInvalidOption.
+// 2. supported options are accepted.
+func TestOptionError(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2"}`)
+
+	h2 := NewHasherV2()
+	err := h2.HashEventJSON(eventJson, WithPublicFromPermissioned())
+	var optionErr *OptionError
+	require.True(t, errors.As(err, &optionErr))
+	assert.Equal(t, "HasherV2.HashEventJSON", optionErr.Method)
+	assert.Equal(t, "WithPublicFromPermissioned", optionErr.Option)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	assert.NoError(t, h2.HashEventJSON(eventJson, WithIDCommitted(1)))
+
+	h := NewHasherAssetV1()
+	err = h.HashAssetFromJSON([]byte(`{"identity": "assets/1"}`), WithIDCommitted(1))
+	require.True(t, errors.As(err, &optionErr))
+	assert.Equal(t, "HasherAssetV1.HashAssetFromJSON", optionErr.Method)
+	assert.Equal(t, "WithIDCommitted", optionErr.Option)
+
+	var bundle bytes.Buffer
+	err = WriteBundle(&bundle, [][]byte{eventJson}, WithAccumulate())
+	require.True(t, errors.As(err, &optionErr))
+	assert.Equal(t, "WithAccumulate", optionErr.Option)
+}
+
+// TestWithIDCommitted_Zero tests that hashing an event, or a batch, with a
+// zero idcommitted fails with an *OptionError.
+func TestWithIDCommitted_Zero(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2"}`)
+
+	h := NewHasherV3()
+	err := h.HashEventFromJSON(eventJson, WithIDCommitted(0))
+	var optionErr *OptionError
+	require.True(t, errors.As(err, &optionErr))
+	assert.Equal(t, "WithIDCommitted", optionErr.Option)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = DigestEventFromJSON(eventJson, WithIDCommitted(0))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = h.HashEventsFromJSON([][]byte{eventJson}, WithIDCommitted(0), WithContinueOnError())
+	require.True(t, errors.As(err, &optionErr))
+
+	h2 := NewHasherV2()
+	assert.ErrorIs(t, h2.HashEventJSON(eventJson, WithIDCommitted(0)), ErrInvalidOption)
+
+	// a later valid value replaces the invalid one
+	assert.NoError(t, h.HashEventFromJSON(eventJson, WithIDCommitted(0), WithIDCommitted(1)))
+	assert.ErrorIs(t, h.HashEventFromJSON(eventJson, WithIDCommitted(1), WithIDCommitted(0)), ErrInvalidOption)
+}
+
+// TestWithIDCommittedEpoch tests:
+//
+// 1. an idtimestamp of its epoch hashes as WithIDCommitted.
+// 2. an idtimestamp which is after the current time in its epoch, or zero,
+// fails with an *OptionError.
+// 3. a later valid value replaces an invalid one.
+func TestWithIDCommittedEpoch(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2"}`)
+	id, epoch, err := ParseIDTimestampHex("0x01931acb7b14043b00")
+	require.NoError(t, err)
+	assert.Equal(t, 2024, IDTimestampTime(id, epoch).Year())
+
+	expected, err := DigestEventFromJSON(eventJson, WithIDCommitted(id))
+	require.NoError(t, err)
+	digest, err := DigestEventFromJSON(eventJson, WithIDCommittedEpoch(id, epoch))
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	_, err = DigestEventFromJSON(eventJson, WithIDCommittedEpoch(id, epoch+1))
+	var optionErr *OptionError
+	require.True(t, errors.As(err, &optionErr), err)
+	assert.Equal(t, "WithIDCommittedEpoch", optionErr.Option)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = DigestEventFromJSON(eventJson, WithIDCommittedEpoch(0, epoch))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	digest, err = DigestEventFromJSON(eventJson, WithIDCommittedEpoch(id, epoch+1), WithIDCommittedEpoch(id, epoch))
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+}
+
+// TestBatchOptions_SingleEvent tests:
+//
+// 1. the single event entry points reject each batch option with an
+// *OptionError naming the entry point and the option.
+// 2. DigestEventFromJSON accepts WithCache but rejects the other batch
+// options.
+func TestBatchOptions_SingleEvent(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2"}`)
+	v3Event, err := V3FromEventJSON(eventJson)
+	require.NoError(t, err)
+	cache, err := NewLRUHashCache(1)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		option string
+		opt    HashOption
+	}{
+		{"WithQuarantine", WithQuarantine(quarantineDiscard{})},
+		{"WithOrder", WithOrder(OrderByIdentity)},
+		{"WithProgress", WithProgress(func(int, int, string) {})},
+		{"WithEventDigests", WithEventDigests()},
+		{"WithContinueOnError", WithContinueOnError()},
+		{"WithCache", WithCache(cache)},
+	} {
+		t.Run(tt.option, func(t *testing.T) {
+			h := NewHasherV3()
+			var optionErr *OptionError
+
+			err := h.HashEventFromJSON(eventJson, tt.opt)
+			require.True(t, errors.As(err, &optionErr), err)
+			assert.Equal(t, "HasherV3.HashEventFromJSON", optionErr.Method)
+			assert.Equal(t, tt.option, optionErr.Option)
+			assert.ErrorIs(t, err, ErrInvalidOption)
+
+			err = h.HashEventFromV3(v3Event, tt.opt)
+			require.True(t, errors.As(err, &optionErr), err)
+			assert.Equal(t, "HasherV3.HashEventFromV3", optionErr.Method)
+			assert.Equal(t, tt.option, optionErr.Option)
+
+			_, err = DigestEventFromJSON(eventJson, tt.opt)
+			if tt.option == "WithCache" {
+				assert.NoError(t, err)
+				return
+			}
+			require.True(t, errors.As(err, &optionErr), err)
+			assert.Equal(t, "DigestEventFromJSON", optionErr.Method)
+		})
+	}
+}
+
+// TestBatchOptions_Composite tests:
+//
+// 1. the entry points which take the batch options, but hash each event with
+// the single event entry points, ignore WithProgress and WithContinueOnError
+// rather than fail, and produce the digests they produce without them.
+func TestBatchOptions_Composite(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2", "timestamp_accepted": "2024-01-31T11:29:19Z"}`)
+	v2Json := []byte(`{"identity": "assets/1/events/2", "asset_identity": "assets/1"}`)
+	v3Event, err := V3FromEventJSON(eventJson)
+	require.NoError(t, err)
+	batchOpts := []HashOption{WithProgress(func(int, int, string) {}), WithContinueOnError()}
+
+	for _, tt := range []struct {
+		name string
+		run  func(opts ...HashOption) (any, error)
+	}{
+		{"WriteBundle", func(opts ...HashOption) (any, error) {
+			var buf bytes.Buffer
+			if err := WriteBundle(&buf, [][]byte{eventJson}, opts...); err != nil {
+				return nil, err
+			}
+			verification, err := VerifyBundle(&buf)
+			return verification.Verified, err
+		}},
+		{"RollingRoots", func(opts ...HashOption) (any, error) {
+			r, err := NewRollingRoots(WindowHourly, false, opts...)
+			if err != nil {
+				return nil, err
+			}
+			if _, err = r.AddJSON(eventJson); err != nil {
+				return nil, err
+			}
+			return r.Flush(), nil
+		}},
+		{"TenantAccumulator", func(opts ...HashOption) (any, error) {
+			a := NewTenantAccumulator(opts...)
+			err := a.AddJSON(eventJson)
+			return a.Digests(), err
+		}},
+		{"HashEventWithAttachments", func(opts ...HashOption) (any, error) {
+			h := NewHasherV3()
+			report, err := h.HashEventWithAttachments(v3Event, nil, opts...)
+			return report.Digest, err
+		}},
+		{"HashEventJSONAuto/v3", func(opts ...HashOption) (any, error) {
+			_, digest, err := HashEventJSONAuto(eventJson, opts...)
+			return digest, err
+		}},
+		{"HashEventJSONAuto/v2", func(opts ...HashOption) (any, error) {
+			_, digest, err := HashEventJSONAuto(v2Json, opts...)
+			return digest, err
+		}},
+		{"AnalyzeEventsFS", func(opts ...HashOption) (any, error) {
+			fsys := fstest.MapFS{"events/1.json": {Data: eventJson}}
+			return AnalyzeEventsFS(context.Background(), fsys, "events/*.json", opts...)
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := tt.run()
+			require.NoError(t, err)
+			actual, err := tt.run(batchOpts...)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+// quarantineDiscard is a QuarantineWriter which discards the events
+type quarantineDiscard struct{}
+
+func (quarantineDiscard) Quarantine(QuarantinedEvent) error { return nil }
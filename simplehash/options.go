@@ -3,24 +3,58 @@ package simplehash
 import (
 	"encoding/binary"
 	"errors"
-
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
 )
 
 // These options are not part of the event schema. The can be used to adjust how
 // the schema is applied to produce a hash for  different purposes.
 
-type EventOptionApplier interface {
+// eventOptionApplier is implemented by the events the options can be applied
+// to. It is internal so that the exported EventOptionApplier, which external
+// event types may implement, does not grow with each new option.
+type eventOptionApplier interface {
 	ToPublicIdentity()
-	SetTimestampCommitted(*timestamppb.Timestamp)
+	setTimestampCommitted(time.Time)
+	setTenantIdentity(string)
+	StripRedacted()
+	FormatTimestamps(TimestampFormat) error
 }
 
 type HashOptions struct {
 	accumulateHash         bool
 	publicFromPermissioned bool
 	prefix                 []byte
-	committed              *timestamppb.Timestamp
+	committed              *time.Time
+	tenantIdentity         *string
 	idcommitted            []byte
+	quarantine             QuarantineWriter
+	chain                  []byte
+	useNumber              bool
+	maxEventSize           int
+	maxDepth               int
+	redactionMode          RedactionMode
+	excludeFields          []string
+	timestampFormat        TimestampFormat
+	encoding               Encoding
+	progress               ProgressFunc
+	continueOnError        bool
+	duplicates             duplicatePolicy
+	order                  OrderPolicy
+	recordIdentities       bool
+	fastEncoding           bool
+	cache                  HashCache
+	eventDigests           bool
+	digestStore            DigestStore
+	logger                 *slog.Logger
+	telemetry              Telemetry
+	trace                  io.Writer
+
+	// invalid records an option given a value it can not accept, as
+	// options can not return errors
+	invalid *OptionError
 }
 
 type HashOption func(*HashOptions)
@@ -30,14 +64,64 @@ var (
 )
 
 // WithIDCommitted includes the snowflakeid unique commitment timestamp in the hash
-// idcommitted is never (legitimately) zero
+// idcommitted is never (legitimately) zero, so hashing with a zero
+// idcommitted fails with an *OptionError rather than produce a leaf hash
+// which can never match the log. A later WithIDCommitted or
+// WithIDCommittedEpoch replaces the value, and any error.
 func WithIDCommitted(idcommitted uint64) HashOption {
 	return func(o *HashOptions) {
-		o.idcommitted = make([]byte, 8)
-		binary.BigEndian.PutUint64(o.idcommitted, idcommitted)
+		reason := ""
+		if idcommitted == 0 {
+			reason = "idcommitted is never zero"
+		}
+		o.setIDCommitted("WithIDCommitted", idcommitted, reason)
+	}
+}
+
+// idTimestampTimeShift is the position of the milliseconds since the start
+// of the epoch in a snowflake idtimestamp, below it are the sequence and
+// generator bits
+const idTimestampTimeShift = 24
+
+// IDTimestampTime returns the commitment time of a snowflake idtimestamp of
+// the log epoch, as returned by ParseIDTimestampHex. Each epoch is 2^40
+// milliseconds, the first starting at the unix epoch.
+func IDTimestampTime(idtimestamp uint64, epoch uint8) time.Time {
+	ms := uint64(epoch)<<40 + idtimestamp>>idTimestampTimeShift
+	return time.UnixMilli(int64(ms)).UTC()
+}
+
+// WithIDCommittedEpoch is WithIDCommitted for an idtimestamp of a known log
+// epoch, as returned by ParseIDTimestampHex. The epoch is not hashed, it
+// validates the idtimestamp: hashing fails with an *OptionError if the
+// idtimestamp is zero, or if its time bits, in that epoch, are after the
+// current time. Ids of the wrong epoch, or which are not idtimestamps, would
+// otherwise produce a leaf hash which can never match the log.
+func WithIDCommittedEpoch(idcommitted uint64, epoch uint8) HashOption {
+	return func(o *HashOptions) {
+		reason := ""
+		if idcommitted == 0 {
+			reason = "idcommitted is never zero"
+		} else if committed := IDTimestampTime(idcommitted, epoch); committed.After(time.Now()) {
+			reason = fmt.Sprintf("idcommitted is after the current time in epoch %d, at %s",
+				epoch, committed.Format(time.RFC3339))
+		}
+		o.setIDCommitted("WithIDCommittedEpoch", idcommitted, reason)
 	}
 }
 
+// setIDCommitted sets idcommitted, recording the invalid option if reason is
+// not empty, and otherwise clearing any invalid idcommitted it replaces.
+func (o *HashOptions) setIDCommitted(option string, idcommitted uint64, reason string) {
+	if reason != "" {
+		o.invalid = &OptionError{Option: option, Reason: reason}
+	} else if o.invalid != nil && (o.invalid.Option == "WithIDCommitted" || o.invalid.Option == "WithIDCommittedEpoch") {
+		o.invalid = nil
+	}
+	o.idcommitted = make([]byte, 8)
+	binary.BigEndian.PutUint64(o.idcommitted, idcommitted)
+}
+
 // WithPrefix pre-pends the provided bytes to the hash. This option can be used
 // multiple times and the successive bytes are appended to the prefix. This is
 // typically used to provide hash domain seperation where second pre-image
@@ -48,9 +132,35 @@ func WithPrefix(b []byte) HashOption {
 	}
 }
 
-func WithTimestampCommitted(committed *timestamppb.Timestamp) HashOption {
+// WithTimestampCommittedTime sets the timestamp_committed of the event before
+// hashing, as WithTimestampCommitted does, without requiring a proto
+// timestamp. It applies uniformly to every V2 and V3 event hashing method,
+// whether the event is given as json, as a proto or pre-decoded. It is only
+// useful to the service which is actually doing the committing, public
+// consumers only ever see confirmed events with the timestamp in place.
+func WithTimestampCommittedTime(committed time.Time) HashOption {
 	return func(o *HashOptions) {
-		o.committed = committed
+		o.committed = &committed
+	}
+}
+
+// WithTenantIdentity overrides the tenant_identity of the event before
+// hashing, for migration tooling which must predict the digests of events
+// being re-homed to a new tenant. The identity is hashed as given, eg
+// "tenant/{uuid}", and an empty identity hashes the event without a tenant.
+func WithTenantIdentity(tenantIdentity string) HashOption {
+	return func(o *HashOptions) {
+		o.tenantIdentity = &tenantIdentity
+	}
+}
+
+// WithChain includes the digest of the previous event in the hash, immediately
+// after any prefix. Hashing each event of a stream with the digest of the one
+// before it produces a tamper evident hash chain. An empty prevHash, for the
+// first event in the chain, adds nothing to the hash.
+func WithChain(prevHash []byte) HashOption {
+	return func(o *HashOptions) {
+		o.chain = prevHash
 	}
 }
 
@@ -65,3 +175,146 @@ func WithPublicFromPermissioned() HashOption {
 		o.publicFromPermissioned = true
 	}
 }
+
+// WithQuarantine enables soft-fail batch processing. Events which can not be
+// decoded are written to the provided QuarantineWriter, with the reason, and
+// the batch continues without them. The batch result is marked Partial if
+// any event was quarantined. Only batch methods honour this option.
+func WithQuarantine(w QuarantineWriter) HashOption {
+	return func(o *HashOptions) {
+		o.quarantine = w
+	}
+}
+
+// withoutAccumulate cancels any WithAccumulate, for internal callers which
+// need a separate digest per event.
+func withoutAccumulate() HashOption {
+	return func(o *HashOptions) {
+		o.accumulateHash = false
+	}
+}
+
+// withoutBatch returns opts followed by an option cancelling the batch
+// options, for internal entry points which take the batch options but hash
+// each event with the single event entry points, which reject them. opts is
+// not modified.
+func withoutBatch(opts []HashOption) []HashOption {
+	return append(opts[:len(opts):len(opts)], func(o *HashOptions) {
+		o.quarantine = nil
+		o.order = OrderAsReceived
+		o.progress = nil
+		o.eventDigests = false
+		o.continueOnError = false
+		o.cache = nil
+	})
+}
+
+// WithUseNumber decodes json numbers without converting them to float64, and
+// bencodes integers exactly as the python implementation does, eg i123e. By
+// default numeric attribute values can not be hashed. Numbers which are not
+// integers are rejected with ErrNonIntegerNumber.
+func WithUseNumber() HashOption {
+	return func(o *HashOptions) {
+		o.useNumber = true
+	}
+}
+
+// WithMaxEventSize rejects event json larger than maxBytes with
+// ErrEventTooLarge, before it is decoded. Services which hash untrusted input
+// should set this.
+func WithMaxEventSize(maxBytes int) HashOption {
+	return func(o *HashOptions) {
+		o.maxEventSize = maxBytes
+	}
+}
+
+// WithMaxDepth rejects event json whose objects and arrays nest more than
+// maxDepth deep with ErrEventTooDeep, before it is decoded. The event object
+// is at depth 1 and its attribute maps at depth 2. Services which hash
+// untrusted input should set this.
+func WithMaxDepth(maxDepth int) HashOption {
+	return func(o *HashOptions) {
+		o.maxDepth = maxDepth
+	}
+}
+
+// WithExcludeFields removes the named top level fields, eg "timestamp_committed",
+// from the hash. This is EXPERIMENTAL and produces a NON STANDARD digest which
+// will never verify against the platform. It exists for internal comparison
+// workflows which need to isolate the field responsible for a mismatch. To
+// make such digests unmistakable, the sorted list of excluded names is hashed
+// in their place, under the key "_excluded_fields". This option can be used
+// multiple times, the names accumulate.
+func WithExcludeFields(fields ...string) HashOption {
+	return func(o *HashOptions) {
+		o.excludeFields = append(o.excludeFields, fields...)
+	}
+}
+
+// WithContinueOnError continues a batch past events which fail, collecting
+// the failures into a *BatchError returned with the result for the remaining
+// events.
+func WithContinueOnError() HashOption {
+	return func(o *HashOptions) {
+		o.continueOnError = true
+	}
+}
+
+type duplicatePolicy int
+
+const (
+	duplicatesAllowed duplicatePolicy = iota
+	duplicatesRejected
+	duplicatesSkipped
+)
+
+// errDuplicateSkipped signals an event skipped by WithSkipDuplicates. It never
+// escapes the package.
+var errDuplicateSkipped = errors.New("duplicate event skipped")
+
+func skipDuplicate(err error) error {
+	if err == errDuplicateSkipped {
+		return nil
+	}
+	return err
+}
+
+// WithDuplicateDetection tracks the identities hashed during an accumulation
+// and fails with ErrDuplicateIdentity if an identity is hashed twice.
+// Duplicate pages from the api otherwise silently corrupt the reproduction of
+// an anchor. Tracking starts afresh whenever the hasher is reset. The tracked
+// identities are not included in the state saved by MarshalBinary.
+func WithDuplicateDetection() HashOption {
+	return func(o *HashOptions) {
+		o.duplicates = duplicatesRejected
+	}
+}
+
+// WithSkipDuplicates tracks identities as for WithDuplicateDetection, but
+// silently skips repeated events rather than failing.
+func WithSkipDuplicates() HashOption {
+	return func(o *HashOptions) {
+		o.duplicates = duplicatesSkipped
+	}
+}
+
+// ProgressFunc receives the progress of a batch. done is the number of events
+// consumed so far, total is the size of the batch or -1 if it is not known
+// in advance, and lastIdentity is the identity of the most recent event, if
+// it could be determined.
+type ProgressFunc func(done int, total int, lastIdentity string)
+
+// WithProgress reports the progress of batch operations, after each event,
+// so callers can display progress or emit heartbeat logs. The function is
+// called synchronously and should return promptly.
+func WithProgress(progress ProgressFunc) HashOption {
+	return func(o *HashOptions) {
+		o.progress = progress
+	}
+}
+
+func (o *HashOptions) reportProgress(done int, total int, lastIdentity string) {
+	if o.progress != nil {
+		o.progress(done, total, lastIdentity)
+	}
+}
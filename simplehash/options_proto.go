@@ -0,0 +1,26 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type EventOptionApplier interface {
+	ToPublicIdentity()
+	SetTimestampCommitted(*timestamppb.Timestamp)
+}
+
+// WithTimestampCommitted sets the timestamp_committed of the event before
+// hashing, see WithTimestampCommittedTime. A nil timestamp cancels any
+// earlier committed timestamp option.
+func WithTimestampCommitted(committed *timestamppb.Timestamp) HashOption {
+	return func(o *HashOptions) {
+		if committed == nil {
+			o.committed = nil
+			return
+		}
+		t := committed.AsTime()
+		o.committed = &t
+	}
+}
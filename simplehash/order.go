@@ -0,0 +1,114 @@
+package simplehash
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// OrderPolicy selects the order in which the events of a batch are
+// accumulated. Anchor reproduction depends on the order, so the policy used
+// is recorded in the BatchResult.
+type OrderPolicy int
+
+const (
+	// OrderAsReceived accumulates events in the order provided. This is the
+	// default.
+	OrderAsReceived OrderPolicy = iota
+	// OrderByTimestampAccepted accumulates events by their accepted time,
+	// earliest first. Events accepted at the same time are ordered by
+	// identity. An event without a valid timestamp_accepted fails.
+	OrderByTimestampAccepted
+	// OrderByIdentity accumulates events by their permissioned identity
+	OrderByIdentity
+)
+
+func (p OrderPolicy) String() string {
+	switch p {
+	case OrderAsReceived:
+		return "as-received"
+	case OrderByTimestampAccepted:
+		return "timestamp-accepted"
+	case OrderByIdentity:
+		return "identity"
+	default:
+		return fmt.Sprintf("OrderPolicy(%d)", int(p))
+	}
+}
+
+// WithOrder sorts the events of a batch before accumulating them. Sorting
+// requires every event of the batch to be decoded first.
+func WithOrder(policy OrderPolicy) HashOption {
+	return func(o *HashOptions) {
+		o.order = policy
+	}
+}
+
+// OrderedBatchResult is the outcome of OrderedBatchHash
+type OrderedBatchResult struct {
+	BatchResult
+	// Identities lists the identities of the events in Digest, in the order
+	// they were accumulated
+	Identities []string
+}
+
+// OrderedBatchHash sorts a batch of api formatted events according to the
+// policy, accumulates them, and returns the digest along with the ordered
+// identities used, as audit evidence of how the digest was produced.
+//
+// Options: as for HashEventsFromJSON. WithOrder is overridden by order.
+func OrderedBatchHash(events [][]byte, order OrderPolicy, opts ...HashOption) (OrderedBatchResult, error) {
+
+	opts = append(opts[:len(opts):len(opts)], WithOrder(order), func(o *HashOptions) {
+		o.recordIdentities = true
+	})
+
+	h := NewHasherV3()
+	result, err := h.HashEventsFromJSON(events, opts...)
+	return OrderedBatchResult{BatchResult: result, Identities: result.identities}, err
+}
+
+// batchEntry is a decoded batch event and its position in the batch
+type batchEntry struct {
+	index    int
+	identity string
+	raw      []byte
+	v3Event  V3Event
+	err      error
+
+	accepted time.Time
+}
+
+// sortBatchEntries orders the entries according to the policy. Entries which
+// failed to decode can not be ordered, they are placed first, in their
+// original order, so they are quarantined or reported before any hashing.
+func sortBatchEntries(entries []batchEntry, policy OrderPolicy) {
+
+	if policy == OrderByTimestampAccepted {
+		for i := range entries {
+			if entries[i].err != nil {
+				continue
+			}
+			accepted, err := time.Parse(time.RFC3339Nano, entries[i].v3Event.TimestampAccepted)
+			if err != nil {
+				entries[i].err = fmt.Errorf("%w: timestamp_accepted: %v", ErrInvalidTimestamp, err)
+				continue
+			}
+			entries[i].accepted = accepted
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if (a.err != nil) != (b.err != nil) {
+			return a.err != nil
+		}
+		if a.err != nil {
+			return false
+		}
+		if policy == OrderByTimestampAccepted && !a.accepted.Equal(b.accepted) {
+			return a.accepted.Before(b.accepted)
+		}
+		return a.v3Event.Identity < b.v3Event.Identity
+	})
+}
@@ -0,0 +1,78 @@
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithOrder tests:
+//
+// 1. each policy accumulates the events in its order, and is recorded in the result.
+// 2. timestamps are compared as times, not strings.
+// 3. an event without a valid timestamp_accepted fails when ordering by time.
+func TestWithOrder(t *testing.T) {
+	a := []byte(`{"identity": "assets/1/events/a", "timestamp_accepted": "2024-01-31T11:29:19.5Z"}`)
+	b := []byte(`{"identity": "assets/1/events/b", "timestamp_accepted": "2024-01-31T11:29:19Z"}`)
+	c := []byte(`{"identity": "assets/1/events/c", "timestamp_accepted": "2024-01-31T12:29:19+01:00"}`)
+	events := [][]byte{c, a, b}
+
+	tests := []struct {
+		policy   OrderPolicy
+		expected [][]byte
+	}{
+		{OrderAsReceived, [][]byte{c, a, b}},
+		{OrderByIdentity, [][]byte{a, b, c}},
+		// c is the same time as b, and is ordered after it by identity
+		{OrderByTimestampAccepted, [][]byte{b, c, a}},
+	}
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			h := NewHasherV3()
+			result, err := h.HashEventsFromJSON(events, WithOrder(test.policy))
+			require.NoError(t, err)
+			assert.Equal(t, test.policy, result.Order)
+
+			expected := NewHasherV3()
+			_, err = expected.HashEventsFromJSON(test.expected)
+			require.NoError(t, err)
+			assert.Equal(t, expected.Sum(nil), result.Digest)
+		})
+	}
+
+	h := NewHasherV3()
+	_, err := h.HashEventsFromJSON(append(events, []byte(`{"identity": "assets/1/events/d"}`)),
+		WithOrder(OrderByTimestampAccepted))
+	assert.ErrorIs(t, err, ErrInvalidTimestamp)
+}
+
+// TestOrderedBatchHash tests:
+//
+// 1. the digest is that of the sorted batch.
+// 2. the identities are listed in the order accumulated.
+// 3. the spare capacity of the callers option slice is not written to.
+func TestOrderedBatchHash(t *testing.T) {
+	events := [][]byte{
+		[]byte(`{"identity": "publicassets/1/events/b"}`),
+		[]byte(`{"identity": "assets/1/events/a"}`),
+	}
+
+	result, err := OrderedBatchHash(events, OrderByIdentity)
+	require.NoError(t, err)
+	assert.Equal(t, OrderByIdentity, result.Order)
+	assert.Equal(t, []string{"assets/1/events/a", "assets/1/events/b"}, result.Identities)
+
+	expected := NewHasherV3()
+	_, err = expected.HashEventsFromJSON([][]byte{events[1], events[0]})
+	require.NoError(t, err)
+	assert.Equal(t, expected.Sum(nil), result.Digest)
+
+	opts := make([]HashOption, 3)
+	opts[0], opts[1], opts[2] = WithPrefix([]byte{1}), WithUseNumber(), WithUseNumber()
+	_, err = OrderedBatchHash(events, OrderByIdentity, opts[:1]...)
+	require.NoError(t, err)
+	o := HashOptions{}
+	opts[1](&o)
+	assert.True(t, o.useNumber)
+}
@@ -0,0 +1,37 @@
+package simplehash
+
+import (
+	"sync"
+)
+
+// HasherV3Pool provides V3 hashers for concurrent use. A Hasher is not safe
+// for concurrent use, but the pool is, and it avoids constructing a marshaler
+// for every request in services which hash concurrently.
+type HasherV3Pool struct {
+	pool sync.Pool
+}
+
+// NewHasherV3Pool creates a pool of hashers constructed with opts
+func NewHasherV3Pool(opts ...HasherOption) *HasherV3Pool {
+	return &HasherV3Pool{
+		pool: sync.Pool{
+			New: func() any {
+				h := NewHasherV3(opts...)
+				return &h
+			},
+		},
+	}
+}
+
+// Get returns a reset hasher from the pool. The caller has exclusive use of
+// the hasher until it is returned with Put.
+func (p *HasherV3Pool) Get() *HasherV3 {
+	h := p.pool.Get().(*HasherV3)
+	h.Reset()
+	return h
+}
+
+// Put returns a hasher to the pool. The hasher must not be used after it is returned.
+func (p *HasherV3Pool) Put(h *HasherV3) {
+	p.pool.Put(h)
+}
@@ -0,0 +1,35 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHasherV3Pool tests:
+//
+// 1. concurrent users of the pool each get the expected digest.
+func TestHasherV3Pool(t *testing.T) {
+	pool := NewHasherV3Pool()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			h := pool.Get()
+			defer pool.Put(h)
+
+			for _, event := range validEventsV2 {
+				assert.NoError(t, h.HashEvent(event, WithAccumulate()))
+			}
+			assert.Equal(t, expectedHashAllV3, hex.EncodeToString(h.Sum(nil)))
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,58 @@
+package simplehash
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPrefix = errors.New("invalid structured prefix")
+)
+
+// prefixSize is the serialized size of a Prefix: the domain byte, the
+// version byte and the 16 byte tenant uuid
+const prefixSize = 2 + 16
+
+// Prefix is a structured hash prefix, for services which compose a prefix
+// from a domain, a version and a tenant rather than concatenating bytes ad
+// hoc. It serializes deterministically, to a fixed 18 bytes, so a prefix
+// recorded in stored metadata can be parsed back with ParsePrefix.
+type Prefix struct {
+	Domain  Domain
+	Version uint8
+	// Tenant is the uuid of the tenant, the nil uuid if the prefix is not
+	// tenant specific
+	Tenant uuid.UUID
+}
+
+// Bytes returns the serialized prefix: the domain byte, the version byte and
+// then the tenant uuid
+func (p Prefix) Bytes() []byte {
+	b := make([]byte, 0, prefixSize)
+	b = append(b, byte(p.Domain), p.Version)
+	return append(b, p.Tenant[:]...)
+}
+
+// ParsePrefix parses a prefix serialized by Prefix.Bytes
+func ParsePrefix(b []byte) (Prefix, error) {
+	if len(b) != prefixSize {
+		return Prefix{}, fmt.Errorf("%w: %d bytes, expected %d", ErrInvalidPrefix, len(b), prefixSize)
+	}
+	p := Prefix{Domain: Domain(b[0]), Version: b[1]}
+	copy(p.Tenant[:], b[2:])
+	return p, nil
+}
+
+// WithStructuredPrefix prefixes the hash with the serialized prefix. It is
+// built on WithPrefix, so it is appended to any prefix already given.
+func WithStructuredPrefix(p Prefix) HashOption {
+	return WithPrefix(p.Bytes())
+}
+
+// WithPrefixString prefixes the hash with the utf-8 bytes of s, for prefixes
+// which are configured as text. It is built on WithPrefix.
+func WithPrefixString(s string) HashOption {
+	return WithPrefix([]byte(s))
+}
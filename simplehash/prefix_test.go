@@ -0,0 +1,43 @@
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrefix tests:
+//
+// 1. the prefix serializes to the domain, version and tenant bytes, and
+// parses back.
+// 2. WithStructuredPrefix and WithPrefixString hash as WithPrefix of their
+// bytes.
+// 3. a prefix of the wrong size fails to parse.
+func TestPrefix(t *testing.T) {
+	tenant := uuid.MustParse("7dfaa5ef-226f-4f40-90a5-c015e59998a8")
+	p := Prefix{Domain: Domain(2), Version: 3, Tenant: tenant}
+
+	b := p.Bytes()
+	assert.Equal(t, append([]byte{2, 3}, tenant[:]...), b)
+	parsed, err := ParsePrefix(b)
+	require.NoError(t, err)
+	assert.Equal(t, p, parsed)
+
+	eventJson := []byte(`{"identity": "assets/1/events/2"}`)
+	expected, err := DigestEventFromJSON(eventJson, WithPrefix(b))
+	require.NoError(t, err)
+	digest, err := DigestEventFromJSON(eventJson, WithStructuredPrefix(p))
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	expected, err = DigestEventFromJSON(eventJson, WithPrefix([]byte("domain")))
+	require.NoError(t, err)
+	digest, err = DigestEventFromJSON(eventJson, WithPrefixString("domain"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	_, err = ParsePrefix(b[:17])
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+}
@@ -0,0 +1,55 @@
+package simplehash
+
+import (
+	"fmt"
+)
+
+// Principal is the identity of the principal which declared or accepted an
+// event. The api always emits every field, as the empty string if it is
+// unset, so use Map to build the principal maps of a V3Event rather than
+// assembling them by hand.
+type Principal struct {
+	Issuer      string
+	Subject     string
+	DisplayName string
+	Email       string
+}
+
+// Map returns the principal as the api represents it, with every field
+// present. The keys are ordered by the encoding, so the order they are set
+// in does not affect the digest.
+func (p Principal) Map() map[string]any {
+	return map[string]any{
+		"issuer":       p.Issuer,
+		"subject":      p.Subject,
+		"display_name": p.DisplayName,
+		"email":        p.Email,
+	}
+}
+
+// PrincipalFromMap reads a principal map, as decoded from api json. Missing
+// and null fields are read as the empty string. Fields which are not strings
+// are rejected with ErrInvalidEvent, and fields the api does not emit are
+// ignored.
+func PrincipalFromMap(principal map[string]any) (Principal, error) {
+	fields := make([]string, len(principalKeys))
+	for i, k := range principalKeys {
+		v, ok := principal[k]
+		if !ok || v == nil {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			return Principal{}, fmt.Errorf("%w: principal %s is %T, not a string", ErrInvalidEvent, k, v)
+		}
+		fields[i] = s
+	}
+	return Principal{Issuer: fields[0], Subject: fields[1], DisplayName: fields[2], Email: fields[3]}, nil
+}
+
+// NormalizePrincipal returns a copy of the principal map as the api would
+// return it: missing and null fields are set to the empty string, and any
+// other fields are kept as they are.
+func NormalizePrincipal(principal map[string]any) map[string]any {
+	return normalizePrincipal(principal)
+}
@@ -0,0 +1,38 @@
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrincipal tests:
+//
+// 1. a principal built with Map hashes the same as the api json for it.
+// 2. PrincipalFromMap reads missing and null fields as empty, and rejects
+// fields which are not strings.
+// 3. NormalizePrincipal fills missing and null fields without modifying the
+// original.
+func TestPrincipal(t *testing.T) {
+	p := Principal{Issuer: "https://idp", Subject: "alice", Email: "alice@example.com"}
+
+	expected, err := DigestEventFromJSON([]byte(`{"identity": "assets/1/events/2", "principal_declared": ` +
+		`{"email": "alice@example.com", "display_name": "", "subject": "alice", "issuer": "https://idp"}}`))
+	require.NoError(t, err)
+	digest, err := HashOfV3(V3Event{Identity: "assets/1/events/2", PrincipalDeclared: p.Map()})
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	read, err := PrincipalFromMap(map[string]any{"issuer": "https://idp", "subject": "alice", "display_name": nil, "email": "alice@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, p, read)
+	_, err = PrincipalFromMap(map[string]any{"issuer": 1})
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+
+	original := map[string]any{"issuer": "https://idp", "email": nil, "extra": "x"}
+	assert.Equal(t, map[string]any{
+		"issuer": "https://idp", "subject": "", "display_name": "", "email": "", "extra": "x",
+	}, NormalizePrincipal(original))
+	assert.Nil(t, original["email"])
+}
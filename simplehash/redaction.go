@@ -0,0 +1,257 @@
+package simplehash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Redacted is the type of RedactedValue. No json value decodes to it, so a
+// redacted attribute can never be mistaken for an attribute value.
+type Redacted struct{}
+
+// MarshalJSON fails, as a redacted attribute has no json value. Redactions
+// are listed outside the attribute maps, see RedactedValue.
+func (Redacted) MarshalJSON() ([]byte, error) {
+	return nil, fmt.Errorf("%w: redacted attributes are listed under %s", ErrInvalidEvent, redactedAttributesKey)
+}
+
+// RedactedValue is the marker which replaces the value of a redacted
+// attribute. A public event may carry redacted attributes in place of values
+// which are only visible to the permissioned owner.
+//
+// A redacted attribute is not held in its attribute map in json, or in the
+// pre-image. Instead the names of the redacted attributes are listed under
+// the top level key "_redacted_attributes", which is not an event field, eg
+//
+//	{"event_attributes": {"public": "a"},
+//	 "_redacted_attributes": {"event_attributes": ["secret"]}}
+//
+// so the pre-image of a redacted event differs from that of every event
+// without redactions, whatever its attribute values. The key is described by
+// JSONSchema.
+//
+// By default the redactions are hashed, so a redacted event only verifies
+// against a digest of the same redacted event. To check a redacted event
+// against its permissioned original, apply the same redactions to the
+// original with RedactLike before hashing, or hash both using
+// WithRedactionMode(RedactionOmit).
+var RedactedValue = Redacted{}
+
+// redactedAttributesKey lists the redacted attributes in json events and in
+// the hashed dictionary, see RedactedValue
+const redactedAttributesKey = "_redacted_attributes"
+
+// redactedAttributes is the json form of the redactions of an event
+type redactedAttributes struct {
+	EventAttributes []string `json:"event_attributes,omitempty"`
+	AssetAttributes []string `json:"asset_attributes,omitempty"`
+}
+
+// RedactionMode selects how attributes carrying RedactedValue are hashed
+type RedactionMode int
+
+const (
+	// RedactionHashMarker hashes the names of the redacted attributes, see
+	// RedactedValue. This is the default and the only mode which produces
+	// standard digests.
+	RedactionHashMarker RedactionMode = iota
+	// RedactionOmit removes redacted attributes before hashing.
+	RedactionOmit
+)
+
+// WithRedactionMode selects how redacted attributes are hashed
+func WithRedactionMode(mode RedactionMode) HashOption {
+	return func(o *HashOptions) {
+		o.redactionMode = mode
+	}
+}
+
+// Redact replaces the values of the named event and asset attributes with
+// RedactedValue. New attribute maps are created, so maps shared with other
+// events are not modified.
+func (e *V3Event) Redact(eventAttributes []string, assetAttributes []string) {
+	e.EventAttributes = redactAttributes(e.EventAttributes, eventAttributes)
+	e.AssetAttributes = redactAttributes(e.AssetAttributes, assetAttributes)
+}
+
+// RedactedAttributes returns the sorted names of the redacted event and asset attributes
+func (e *V3Event) RedactedAttributes() ([]string, []string) {
+	return redactedKeys(e.EventAttributes), redactedKeys(e.AssetAttributes)
+}
+
+// RedactLike applies the redactions present in other to this event, so that
+// a permissioned original hashes the same as its redacted public counterpart.
+func (e *V3Event) RedactLike(other V3Event) {
+	e.Redact(other.RedactedAttributes())
+}
+
+// StripRedacted removes every redacted attribute. New attribute maps are
+// created, so maps shared with other events are not modified.
+func (e *V3Event) StripRedacted() {
+	e.EventAttributes = stripRedacted(e.EventAttributes)
+	e.AssetAttributes = stripRedacted(e.AssetAttributes)
+}
+
+// Redact replaces the values of the named event and asset attributes with RedactedValue
+func (e *V2Event) Redact(eventAttributes []string, assetAttributes []string) {
+	e.EventAttributes = redactAttributes(e.EventAttributes, eventAttributes)
+	e.AssetAttributes = redactAttributes(e.AssetAttributes, assetAttributes)
+}
+
+// RedactedAttributes returns the sorted names of the redacted event and asset attributes
+func (e *V2Event) RedactedAttributes() ([]string, []string) {
+	return redactedKeys(e.EventAttributes), redactedKeys(e.AssetAttributes)
+}
+
+// RedactLike applies the redactions present in other to this event
+func (e *V2Event) RedactLike(other V2Event) {
+	e.Redact(other.RedactedAttributes())
+}
+
+// StripRedacted removes every redacted attribute
+func (e *V2Event) StripRedacted() {
+	e.EventAttributes = stripRedacted(e.EventAttributes)
+	e.AssetAttributes = stripRedacted(e.AssetAttributes)
+}
+
+func redactAttributes(attributes map[string]any, keys []string) map[string]any {
+	if len(keys) == 0 || attributes == nil {
+		return attributes
+	}
+	redacted := make(map[string]any, len(attributes))
+	for k, v := range attributes {
+		redacted[k] = v
+	}
+	for _, k := range keys {
+		if _, ok := redacted[k]; ok {
+			redacted[k] = RedactedValue
+		}
+	}
+	return redacted
+}
+
+func redactedKeys(attributes map[string]any) []string {
+	var keys []string
+	for k, v := range attributes {
+		if v == RedactedValue {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stripRedacted(attributes map[string]any) map[string]any {
+	if attributes == nil {
+		return nil
+	}
+	stripped := make(map[string]any, len(attributes))
+	for k, v := range attributes {
+		if v != RedactedValue {
+			stripped[k] = v
+		}
+	}
+	return stripped
+}
+
+// withoutRedactions returns the event without its redacted attributes, and
+// the names of those attributes as they are hashed, or nil if it has none.
+// The event is a copy, so the attribute maps are replaced, not modified.
+func withoutRedactions(event any) (any, map[string]any) {
+	var r redactedAttributes
+	switch e := event.(type) {
+	case V3Event:
+		r.EventAttributes, r.AssetAttributes = e.RedactedAttributes()
+		if !r.redacted() {
+			return event, nil
+		}
+		e.StripRedacted()
+		event = e
+	case V2Event:
+		r.EventAttributes, r.AssetAttributes = e.RedactedAttributes()
+		if !r.redacted() {
+			return event, nil
+		}
+		e.StripRedacted()
+		event = e
+	default:
+		return event, nil
+	}
+
+	redactions := map[string]any{}
+	for name, keys := range map[string][]string{
+		"event_attributes": r.EventAttributes, "asset_attributes": r.AssetAttributes,
+	} {
+		if len(keys) == 0 {
+			continue
+		}
+		names := make([]any, 0, len(keys))
+		for _, k := range keys {
+			names = append(names, k)
+		}
+		redactions[name] = names
+	}
+	return event, redactions
+}
+
+func (r redactedAttributes) redacted() bool {
+	return len(r.EventAttributes) != 0 || len(r.AssetAttributes) != 0
+}
+
+// decodeRedactions marks the attributes listed under redactedAttributesKey
+// in the json event as redacted
+func decodeRedactions(eventJson []byte, eventAttributes *map[string]any, assetAttributes *map[string]any) error {
+	if !bytes.Contains(eventJson, []byte(redactedAttributesKey)) {
+		return nil
+	}
+	var event struct {
+		Redacted *redactedAttributes `json:"_redacted_attributes"`
+	}
+	if err := json.Unmarshal(eventJson, &event); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidEvent, redactedAttributesKey, err)
+	}
+	if event.Redacted == nil {
+		return nil
+	}
+	var err error
+	if *eventAttributes, err = markRedacted(*eventAttributes, event.Redacted.EventAttributes); err != nil {
+		return err
+	}
+	*assetAttributes, err = markRedacted(*assetAttributes, event.Redacted.AssetAttributes)
+	return err
+}
+
+// decodeMapRedactions is decodeRedactions for an event decoded as a map
+func decodeMapRedactions(m map[string]any, eventAttributes *map[string]any, assetAttributes *map[string]any) error {
+	redactions, ok := m[redactedAttributesKey]
+	if !ok {
+		return nil
+	}
+	eventJson, err := json.Marshal(map[string]any{redactedAttributesKey: redactions})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidEvent, redactedAttributesKey, err)
+	}
+	return decodeRedactions(eventJson, eventAttributes, assetAttributes)
+}
+
+// markRedacted returns a copy of the attributes with the named attributes
+// redacted. An attribute which is both listed and present is ambiguous, and
+// is rejected.
+func markRedacted(attributes map[string]any, names []string) (map[string]any, error) {
+	if len(names) == 0 {
+		return attributes, nil
+	}
+	marked := make(map[string]any, len(attributes)+len(names))
+	for k, v := range attributes {
+		marked[k] = v
+	}
+	for _, k := range names {
+		if _, ok := marked[k]; ok {
+			return nil, fmt.Errorf("%w: %s: %s is both redacted and present", ErrInvalidEvent, redactedAttributesKey, k)
+		}
+		marked[k] = RedactedValue
+	}
+	return marked, nil
+}
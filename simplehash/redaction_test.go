@@ -0,0 +1,85 @@
+package simplehash
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestV3Event_Redaction tests:
+//
+// 1. a redacted event does not hash the same as its original by default.
+// 2. RedactLike makes the original hash the same as the redacted event.
+// 3. RedactionOmit hashes both the same, without the redacted attribute.
+// 4. redaction does not modify attribute maps shared with the original.
+// 5. a redacted event hashes differently from events with any value for the
+// attribute, including a string equal to a marker, and from the event
+// without the attribute.
+// 6. redactions listed in json decode as RedactedValue, and an attribute both
+// listed and present is rejected.
+func TestV3Event_Redaction(t *testing.T) {
+	original := V3Event{
+		Identity:        "assets/1/events/2",
+		EventAttributes: map[string]any{"public": "yes", "secret": "value"},
+	}
+	redacted := original
+	redacted.Redact([]string{"secret"}, nil)
+
+	assert.Equal(t, "value", original.EventAttributes["secret"])
+	eventKeys, assetKeys := redacted.RedactedAttributes()
+	assert.Equal(t, []string{"secret"}, eventKeys)
+	assert.Empty(t, assetKeys)
+
+	digest := func(e V3Event, opts ...HashOption) []byte {
+		h := NewHasherV3()
+		require.NoError(t, h.HashEventFromV3(e, opts...))
+		return h.Sum(nil)
+	}
+
+	assert.NotEqual(t, digest(original), digest(redacted))
+
+	matched := original
+	matched.RedactLike(redacted)
+	assert.Equal(t, digest(redacted), digest(matched))
+
+	stripped := original
+	stripped.EventAttributes = map[string]any{"public": "yes"}
+	assert.Equal(t, digest(stripped), digest(redacted, WithRedactionMode(RedactionOmit)))
+	assert.Equal(t, "value", original.EventAttributes["secret"])
+
+	assert.NotEqual(t, digest(stripped), digest(redacted))
+	assert.Equal(t, digest(redacted), digest(redacted, WithFastEncoding()))
+	for _, value := range []any{"__datatrails_redacted__", "", map[string]any{}, []any{}} {
+		collision := original
+		collision.EventAttributes = map[string]any{"public": "yes", "secret": value}
+		assert.NotEqual(t, digest(collision), digest(redacted), value)
+	}
+	// an attribute map can't reproduce the listing, as it is not at the top
+	// level of the pre-image
+	listed := stripped
+	listed.EventAttributes = map[string]any{
+		"public": "yes", redactedAttributesKey: map[string]any{"event_attributes": []any{"secret"}},
+	}
+	assert.NotEqual(t, digest(listed), digest(redacted))
+
+	decoded, err := V3FromEventJSON([]byte(`{
+		"identity": "assets/1/events/2",
+		"event_attributes": {"public": "yes"},
+		"_redacted_attributes": {"event_attributes": ["secret"]}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, redacted, decoded)
+	assert.Equal(t, digest(redacted), digest(decoded))
+
+	_, err = V3FromEventJSON([]byte(`{
+		"identity": "assets/1/events/2",
+		"event_attributes": {"public": "yes", "secret": "value"},
+		"_redacted_attributes": {"event_attributes": ["secret"]}
+	}`))
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+
+	_, err = json.Marshal(redacted)
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+}
@@ -0,0 +1,102 @@
+package simplehash
+
+// AssetV1 is a struct that contains ONLY the asset fields we want to hash for
+// the asset schema v1. An asset snapshot is identified by its identity and
+// at_time.
+type AssetV1 struct {
+	Identity       string         `json:"identity"`
+	Attributes     map[string]any `json:"attributes"`
+	Tracked        string         `json:"tracked"`
+	AtTime         string         `json:"at_time"`
+	TenantIdentity string         `json:"tenant_identity"`
+}
+
+// AssetV1EncodeAsset produces the canonical bencoded pre-image for the asset
+func AssetV1EncodeAsset(asset AssetV1) ([]byte, error) {
+	return encodeEvent("AssetSimpleHashV1", asset, HashOptions{})
+}
+
+// AssetV1FromAssetJSON unmarshals rest api formated json into the asset struct
+func AssetV1FromAssetJSON(assetJson []byte) (AssetV1, error) {
+	return assetV1FromAssetJSON(assetJson, HashOptions{})
+}
+
+func assetV1FromAssetJSON(assetJson []byte, o HashOptions) (AssetV1, error) {
+	asset := AssetV1{}
+	if err := decodeEventJSON(assetJson, &asset, o); err != nil {
+		return AssetV1{}, err
+	}
+
+	// as for v3 events, only permissioned identities are hashed
+	asset.Identity = permissionedIdentity(asset.Identity)
+
+	return asset, nil
+}
+
+type HasherAssetV1 struct {
+	Hasher
+}
+
+func NewHasherAssetV1(opts ...HasherOption) HasherAssetV1 {
+	h := HasherAssetV1{
+		Hasher: Hasher{
+			hasher:    newSHA256(),
+			marshaler: newAssetMarshaler(),
+		},
+	}
+	for _, opt := range opts {
+		opt(&h.Hasher)
+	}
+	return h
+}
+
+// HashAssetFromJSON hashes a single api formatted asset snapshot.
+//
+// Options: as for HashAsset, and additionally
+//   - WithMaxEventSize and WithMaxDepth limit the size and nesting of
+//     untrusted json before it is decoded.
+func (h *HasherAssetV1) HashAssetFromJSON(assetJson []byte, opts ...HashOption) error {
+
+	o, err := assetV1Options("HasherAssetV1.HashAssetFromJSON", opts)
+	if err != nil {
+		return err
+	}
+
+	assetV1, err := assetV1FromAssetJSON(assetJson, o)
+	if err != nil {
+		return err
+	}
+	return h.hashAssetV1(assetV1, o)
+}
+
+// HashAssetFromV1 hashes a pre decoded asset snapshot.
+// Options: same as HashAsset
+func (h *HasherAssetV1) HashAssetFromV1(assetV1 AssetV1, opts ...HashOption) error {
+
+	o, err := assetV1Options("HasherAssetV1.HashAssetFromV1", opts)
+	if err != nil {
+		return err
+	}
+	return h.hashAssetV1(assetV1, o)
+}
+
+func assetV1Options(method string, opts []HashOption) (HashOptions, error) {
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.checkOptions(method, "assets have no event identity or commitment",
+		optPublicFromPermissioned, optTimestampCommitted, optIDCommitted); err != nil {
+		return HashOptions{}, err
+	}
+	return o, nil
+}
+
+// hashAssetV1 encodes the asset and, only if that succeeds, applies the
+// hashing options and writes the encoded asset to the hasher.
+func (h *HasherAssetV1) hashAssetV1(assetV1 AssetV1, o HashOptions) error {
+
+	return writeEvent(hashWriter{&h.Hasher}, "AssetSimpleHashV1", assetV1, o, func() {
+		h.applyHashingOptions(o)
+	})
+}
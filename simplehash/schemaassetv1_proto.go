@@ -0,0 +1,52 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-common-api-gen/marshalers/simpleoneof"
+)
+
+// NewAssetMarshaler creates a flat marshaler to transform assets to api format.
+func NewAssetMarshaler() *simpleoneof.Marshaler {
+	return v2assets.NewFlatMarshalerForAssets(nil)
+}
+
+// AssetV1FromAssetResponse transforms a single asset in grpc proto format to
+// the api format. If marshaler is nil the default, NewAssetMarshaler, is used.
+func AssetV1FromAssetResponse(marshaler ProtoEventMarshaler, asset *v2assets.AssetResponse) (AssetV1, error) {
+	if marshaler == nil {
+		marshaler = NewAssetMarshaler()
+	}
+	assetJson, err := marshaler.Marshal(asset)
+	if err != nil {
+		return AssetV1{}, err
+	}
+	return AssetV1FromAssetJSON(assetJson)
+}
+
+// HashAsset hashes a single asset snapshot, in grpc proto format, according
+// to the canonical asset schema v1.
+//
+// Options:
+//   - WithPrefix, WithAccumulate, WithChain, WithUseNumber, WithExcludeFields
+//     as for HasherV3.HashEventFromJSON
+//
+// The event specific options WithPublicFromPermissioned,
+// WithTimestampCommitted and WithIDCommitted are rejected with
+// ErrInvalidOption.
+func (h *HasherAssetV1) HashAsset(asset *v2assets.AssetResponse, opts ...HashOption) error {
+
+	o, err := assetV1Options("HasherAssetV1.HashAsset", opts)
+	if err != nil {
+		return err
+	}
+
+	assetV1, err := AssetV1FromAssetResponse(h.marshaler, asset)
+	if err != nil {
+		return err
+	}
+	return h.hashAssetV1(assetV1, o)
+}
+
+func newAssetMarshaler() eventMarshaler { return NewAssetMarshaler() }
@@ -0,0 +1,66 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"testing"
+	"time"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/datatrails/go-datatrails-common-api-gen/attribute/v2/attribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TestHasherAssetV1_HashAsset tests:
+//
+// 1. the proto, api json and public json forms of an asset hash the same.
+// 2. the canonical pre-image holds only the schema fields.
+// 3. a change of tracked status changes the hash.
+// 4. event options are rejected.
+func TestHasherAssetV1_HashAsset(t *testing.T) {
+	asset := &v2assets.AssetResponse{
+		Identity: "assets/9ccdc19b-44a1-434c-afab-14f8eac3405c",
+		Attributes: map[string]*attribute.Attribute{
+			"arc_display_type": {Value: &attribute.Attribute_StrVal{StrVal: "car"}},
+		},
+		Tracked:        v2assets.TrackedStatus_TRACKED,
+		AtTime:         timestamppb.New(time.Date(2024, 1, 31, 11, 29, 19, 43000000, time.UTC)),
+		Owner:          "0x1234",
+		TenantIdentity: "tenant/0684984b-654d-4301-ad10-a508126e187d",
+	}
+
+	h := NewHasherAssetV1()
+	require.NoError(t, h.HashAsset(asset))
+	expected := h.Sum(nil)
+
+	assetJson, err := NewAssetMarshaler().Marshal(asset)
+	require.NoError(t, err)
+	require.NoError(t, h.HashAssetFromJSON(assetJson))
+	assert.Equal(t, expected, h.Sum(nil))
+
+	publicJson, err := PublicFromPermissionedJSON(assetJson)
+	require.NoError(t, err)
+	require.NoError(t, h.HashAssetFromJSON(publicJson))
+	assert.Equal(t, expected, h.Sum(nil))
+
+	assetV1, err := AssetV1FromAssetJSON(assetJson)
+	require.NoError(t, err)
+	encoded, err := AssetV1EncodeAsset(assetV1)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"d7:at_time24:2024-01-31T11:29:19.043Z"+
+			"10:attributesd16:arc_display_type3:care"+
+			"8:identity43:assets/9ccdc19b-44a1-434c-afab-14f8eac3405c"+
+			"15:tenant_identity43:tenant/0684984b-654d-4301-ad10-a508126e187d"+
+			"7:tracked7:TRACKEDe",
+		string(encoded))
+
+	assetV1.Tracked = v2assets.TrackedStatus_NOT_TRACKED.String()
+	require.NoError(t, h.HashAssetFromV1(assetV1))
+	assert.NotEqual(t, expected, h.Sum(nil))
+
+	assert.ErrorIs(t, h.HashAsset(asset, WithPublicFromPermissioned()), ErrInvalidOption)
+	assert.ErrorIs(t, h.HashAssetFromV1(assetV1, WithIDCommitted(1)), ErrInvalidOption)
+}
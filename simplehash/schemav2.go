@@ -3,15 +3,8 @@ package simplehash
 // Public go lang implementation of the simplehash DataTrails event encoding scheme
 
 import (
-	"encoding/json"
-	"fmt"
 	"hash"
 	"time"
-
-	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
-	"github.com/datatrails/go-datatrails-common-api-gen/marshalers/simpleoneof"
-	"github.com/zeebo/bencode"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // V2Event is a struct that contains ONLY the event fields we want to hash for schema v2
@@ -34,62 +27,37 @@ type V2Event struct {
 
 // ToPublicIdentity converts the identity of the event into a public identity
 func (e *V2Event) ToPublicIdentity() {
-	e.AssetIdentity = v2assets.PublicIdentityFromPermissioned(e.AssetIdentity)
-	e.Identity = v2assets.PublicIdentityFromPermissioned(e.Identity)
+	e.AssetIdentity = publicIdentity(e.AssetIdentity)
+	e.Identity = publicIdentity(e.Identity)
+}
+
+func (e *V2Event) setTimestampCommitted(t time.Time) {
+	e.TimestampCommitted = t.Format(time.RFC3339Nano)
 }
 
-// SetTimestampCommitted sets the timestamp committed to the given timestamp
-func (e *V2Event) SetTimestampCommitted(timestamp *timestamppb.Timestamp) {
-	e.TimestampCommitted = timestamp.AsTime().Format(time.RFC3339Nano)
+func (e *V2Event) setTenantIdentity(tenantIdentity string) {
+	e.TenantIdentity = tenantIdentity
 }
 
 type HasherV2 struct {
 	Hasher
 }
 
-func NewHasherV2() HasherV2 {
+func NewHasherV2(opts ...HasherOption) HasherV2 {
 
 	h := HasherV2{
-		Hasher: NewHasher(),
+		Hasher: NewHasher(opts...),
 	}
 	return h
 }
 
-// HashEvent hashes a single event according to the canonical simple hash event
-// format available to api consumers. The source event is in the grpc proto buf
-// format. GRPC endpoints are not presently exposed by the platform.
-//
-// Options:
-//   - WithTimestampCommitted set the timestamp_commited before hashing
-//   - WithPrefix is used to provide domain seperation, the provided bytes are
-//     pre-pended to the data to be hashed.  Eg H(prefix || data)
-//     This option can be used multiple times, the prefix bytes are appended to
-//     any previously supplied.
-//   - WithAccumulate callers wishing to implement batched hashing of multiple
-//     events in series should set this. They should call Reset() at their batch
-//     boundaries.
-//   - WithPublicFromPermissioned should be set if the event is the
-//     permissioned (owner) counter part of a public attestation.
-//     be publicly verifiable.
-func (h *HasherV2) HashEvent(event *v2assets.EventResponse, opts ...HashOption) error {
-	o := HashOptions{}
-	for _, opt := range opts {
-		opt(&o)
-	}
-
-	// Note that we _don't_ take any notice of confirmation status.
-
-	v2Event, err := V2FromEventResponse(h.marshaler, event)
+// Clone returns an independent copy of the hasher, including any accumulated state
+func (h *HasherV2) Clone() (HasherV2, error) {
+	c, err := h.Hasher.Clone()
 	if err != nil {
-		return err
+		return HasherV2{}, err
 	}
-
-	h.Hasher.applyEventOptions(o, &v2Event)
-
-	// Hash data accumulation starts here
-	h.Hasher.applyHashingOptions(o)
-
-	return V2HashEvent(h.hasher, v2Event)
+	return HasherV2{Hasher: c}, nil
 }
 
 // HashEventJSON hashes a single event according to the canonical simple hash
@@ -102,24 +70,49 @@ func (h *HasherV2) HashEvent(event *v2assets.EventResponse, opts ...HashOption)
 //     boundaries.
 //   - WithAsConfirmed should be set if the caller wishes to anticipate the hash
 //     of a confirmed event based on a pending response
+//   - WithUseNumber preserves integer attribute values exactly, and hashes
+//     them as bencode integers.
+//   - WithTimestampCommitted or WithTimestampCommittedTime set the
+//     timestamp_committed before hashing.
+//
+// WithPublicFromPermissioned is rejected with an *OptionError, as api
+// responses have their public identities in place.
 func (h *HasherV2) HashEventJSON(event []byte, opts ...HashOption) error {
 	o := HashOptions{}
 	for _, opt := range opts {
 		opt(&o)
 	}
-	if o.publicFromPermissioned {
-		// It is api response data, so the details of protected vs public should already have been dealt with.
-		return ErrInvalidOption
+	// It is api response data, so the details of protected vs public should
+	// already have been dealt with.
+	if err := o.checkOptions("HasherV2.HashEventJSON", "api responses are hashed as returned",
+		optPublicFromPermissioned); err != nil {
+		return err
 	}
 
-	v2Event, err := V2FromEventJSON(event)
+	v2Event, err := v2FromEventJSON(event, o)
 	if err != nil {
 		return err
 	}
 
-	h.Hasher.applyHashingOptions(o)
+	if err := h.applyEventOptions(o, &v2Event); err != nil {
+		return err
+	}
+	return h.hashV2Event(v2Event, o)
+}
+
+// HashEventFromV2 hashes a single pre decoded V2Event.
+//
+// Options: as for HashEvent
+func (h *HasherV2) HashEventFromV2(v2Event V2Event, opts ...HashOption) error {
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-	return V2HashEvent(h.hasher, v2Event)
+	if err := h.applyEventOptions(o, &v2Event); err != nil {
+		return err
+	}
+	return h.hashV2Event(v2Event, o)
 }
 
 func (h *HasherV2) Sum() []byte {
@@ -128,73 +121,56 @@ func (h *HasherV2) Sum() []byte {
 
 // V2FromEventJSON unmarshals rest api formated json into the event struct
 func V2FromEventJSON(eventJson []byte) (V2Event, error) {
+	return v2FromEventJSON(eventJson, HashOptions{})
+}
+
+func v2FromEventJSON(eventJson []byte, o HashOptions) (V2Event, error) {
 	var err error
 
 	eventShashV2 := V2Event{}
-	err = json.Unmarshal(eventJson, &eventShashV2)
+	err = decodeEventJSON(eventJson, &eventShashV2, o)
 	if err != nil {
 		return V2Event{}, err
 	}
-	return eventShashV2, nil
-}
-
-// V2FromEventResponse transforms a single event in grpc proto format (message bus
-// compatible) to the canonical, publicly verifiable, api format.
-func V2FromEventResponse(marshaler *simpleoneof.Marshaler, event *v2assets.EventResponse) (V2Event, error) {
-	eventJson, err := marshaler.Marshal(event)
+	err = decodeRedactions(eventJson, &eventShashV2.EventAttributes, &eventShashV2.AssetAttributes)
 	if err != nil {
 		return V2Event{}, err
 	}
-	return V2FromEventJSON(eventJson)
+	return eventShashV2, nil
 }
 
-// EventSimpleHashV2 hashes a single event according to the canonical simple hash event format
-// available to api consumers.
-//
-//   - If the event is the permissioned (owner) counter part of a public
-//     attestation, you must call PublicFromPermissionedEvent first.
-//   - No special treatment is given to confirmation status (PENDING vs
-//     CONFIRMED). Because the rules for forestrie and PENDING events are *NOT
-//     THE SAME* as those for proof_mechanism simplehash.
-func EventSimpleHashV2(hasher hash.Hash, marshaler *simpleoneof.Marshaler, event *v2assets.EventResponse) error {
-
-	var err error
-
-	// Note that we _don't_ take any notice of confirmation status.
-
-	v2Event, err := V2FromEventResponse(marshaler, event)
-	if err != nil {
-		return err
+// V2FromMap converts an event which has already been decoded from api
+// formatted json, without re-encoding it. The attribute and principal maps
+// are shared with the provided map.
+func V2FromMap(event map[string]any) (V2Event, error) {
+	v2Event := V2Event{}
+	if err := decodeEventMap(event, &v2Event); err != nil {
+		return V2Event{}, err
 	}
-
-	return V2HashEvent(hasher, v2Event)
+	if err := decodeMapRedactions(event, &v2Event.EventAttributes, &v2Event.AssetAttributes); err != nil {
+		return V2Event{}, err
+	}
+	return v2Event, nil
 }
 
 func V2HashEvent(hasher hash.Hash, v2Event V2Event) error {
 
-	var err error
-
-	// Note that we _don't_ take any notice of confirmation status.
-
-	// XXX: TODO I don't think the following step is necessary (we should get snake case due to the struct tags)
-	//    we get the correct fields by the definition of our structure, but we need to marshal and unmarshal our struct
-	//    into a generic []any, in order to get the correct field names, otherwise they would be camelcase
-	eventJson, err := json.Marshal(v2Event)
-	if err != nil {
-		return fmt.Errorf("EventSimpleHashV2: failed to marshal event : %v", err)
-	}
+	return writeEvent(hasher, "EventSimpleHashV2", v2Event, HashOptions{}, nil)
+}
 
-	var jsonAny any
+func v2EncodeEvent(v2Event V2Event, o HashOptions) ([]byte, error) {
+	return encodeEvent("EventSimpleHashV2", v2Event, o)
+}
 
-	if err = json.Unmarshal(eventJson, &jsonAny); err != nil {
-		return fmt.Errorf("EventSimpleHashV2: failed to unmarshal events: %v", err)
-	}
+// hashV2Event encodes the event and, only if that succeeds, applies the
+// hashing options and writes the encoded event to the hasher.
+func (h *HasherV2) hashV2Event(v2Event V2Event, o HashOptions) error {
 
-	bencodeEvent, err := bencode.EncodeBytes(jsonAny)
-	if err != nil {
-		return fmt.Errorf("EventSimpleHashV2: failed to bencode events: %v", err)
+	if err := o.checkValues(); err != nil {
+		return err
 	}
 
-	hasher.Write(bencodeEvent)
-	return nil
+	return writeEvent(hashWriter{&h.Hasher}, "EventSimpleHashV2", v2Event, o, func() {
+		h.Hasher.applyHashingOptions(o)
+	})
 }
@@ -0,0 +1,87 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"hash"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SetTimestampCommitted sets the timestamp committed to the given timestamp
+func (e *V2Event) SetTimestampCommitted(timestamp *timestamppb.Timestamp) {
+	e.setTimestampCommitted(timestamp.AsTime())
+}
+
+// HashEvent hashes a single event according to the canonical simple hash event
+// format available to api consumers. The source event is in the grpc proto buf
+// format. GRPC endpoints are not presently exposed by the platform.
+//
+// Options:
+//   - WithTimestampCommitted or WithTimestampCommittedTime set the
+//     timestamp_commited before hashing
+//   - WithPrefix is used to provide domain seperation, the provided bytes are
+//     pre-pended to the data to be hashed.  Eg H(prefix || data)
+//     This option can be used multiple times, the prefix bytes are appended to
+//     any previously supplied.
+//   - WithAccumulate callers wishing to implement batched hashing of multiple
+//     events in series should set this. They should call Reset() at their batch
+//     boundaries.
+//   - WithPublicFromPermissioned should be set if the event is the
+//     permissioned (owner) counter part of a public attestation.
+//     be publicly verifiable.
+func (h *HasherV2) HashEvent(event *v2assets.EventResponse, opts ...HashOption) error {
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Note that we _don't_ take any notice of confirmation status.
+
+	v2Event, err := V2FromEventResponse(h.marshaler, event)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Hasher.applyEventOptions(o, &v2Event); err != nil {
+		return err
+	}
+
+	// Hash data accumulation starts here
+	return h.hashV2Event(v2Event, o)
+}
+
+// V2FromEventResponse transforms a single event in grpc proto format (message bus
+// compatible) to the canonical, publicly verifiable, api format. If marshaler
+// is nil the default, NewEventMarshaler, is used.
+func V2FromEventResponse(marshaler ProtoEventMarshaler, event *v2assets.EventResponse) (V2Event, error) {
+	eventJson, err := defaultEventMarshaler(marshaler).Marshal(event)
+	if err != nil {
+		return V2Event{}, err
+	}
+	return V2FromEventJSON(eventJson)
+}
+
+// EventSimpleHashV2 hashes a single event according to the canonical simple hash event format
+// available to api consumers.
+//
+//   - If the event is the permissioned (owner) counter part of a public
+//     attestation, you must convert it with ToPublicIdentity, or
+//     PublicFromPermissionedJSON, first.
+//   - No special treatment is given to confirmation status (PENDING vs
+//     CONFIRMED). Because the rules for forestrie and PENDING events are *NOT
+//     THE SAME* as those for proof_mechanism simplehash.
+func EventSimpleHashV2(hasher hash.Hash, marshaler ProtoEventMarshaler, event *v2assets.EventResponse) error {
+
+	var err error
+
+	// Note that we _don't_ take any notice of confirmation status.
+
+	v2Event, err := V2FromEventResponse(marshaler, event)
+	if err != nil {
+		return err
+	}
+
+	return V2HashEvent(hasher, v2Event)
+}
@@ -1,3 +1,5 @@
+//go:build !simplehash_noproto
+
 package simplehash
 
 import (
@@ -1,15 +1,9 @@
 package simplehash
 
 import (
-	"encoding/json"
 	"fmt"
 	"hash"
 	"time"
-
-	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
-	"github.com/datatrails/go-datatrails-common-api-gen/marshalers/simpleoneof"
-	"github.com/zeebo/bencode"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // V3Event is a struct that contains ONLY the event fields we want to hash for schema v3
@@ -29,84 +23,114 @@ type V3Event struct {
 
 // ToPublicIdentity converts the identity of the event into a public identity
 func (e *V3Event) ToPublicIdentity() {
-	e.Identity = v2assets.PublicIdentityFromPermissioned(e.Identity)
+	e.Identity = publicIdentity(e.Identity)
 }
 
-// SetTimestampCommitted sets the timestamp committed to the given timestamp
-func (e *V3Event) SetTimestampCommitted(timestamp *timestamppb.Timestamp) {
-	e.TimestampCommitted = timestamp.AsTime().Format(time.RFC3339Nano)
+func (e *V3Event) setTimestampCommitted(t time.Time) {
+	e.TimestampCommitted = t.Format(time.RFC3339Nano)
 }
 
-func V3HashEvent(hasher hash.Hash, v3Event V3Event) error {
-
-	var err error
-
-	// Note that we _don't_ take any notice of confirmation status.
-
-	// TODO: we ought to be able to avoid this double encode decode, but it is fiddly
-	eventJson, err := json.Marshal(v3Event)
-	if err != nil {
-		return fmt.Errorf("EventSimpleHashV3: failed to marshal event : %v", err)
-	}
-
-	var jsonAny any
+func (e *V3Event) setTenantIdentity(tenantIdentity string) {
+	e.TenantIdentity = tenantIdentity
+}
 
-	if err = json.Unmarshal(eventJson, &jsonAny); err != nil {
-		return fmt.Errorf("EventSimpleHashV3: failed to unmarshal events: %v", err)
-	}
+func V3HashEvent(hasher hash.Hash, v3Event V3Event) error {
 
-	bencodeEvent, err := bencode.EncodeBytes(jsonAny)
-	if err != nil {
-		return fmt.Errorf("EventSimpleHashV3: failed to bencode events: %v", err)
-	}
+	return writeEvent(hasher, "EventSimpleHashV3", v3Event, HashOptions{}, nil)
+}
 
-	hasher.Write(bencodeEvent)
+// V3EncodeEvent produces the canonical bencoded pre-image for the event, the
+// bytes V3HashEvent writes to the hasher. Prefixes and other hashing options
+// are not included.
+func V3EncodeEvent(v3Event V3Event) ([]byte, error) {
+	return v3EncodeEvent(v3Event, HashOptions{})
+}
 
-	return nil
+func v3EncodeEvent(v3Event V3Event, o HashOptions) ([]byte, error) {
+	return encodeEvent("EventSimpleHashV3", v3Event, o)
 }
 
 type HasherV3 struct {
 	Hasher
+
+	// seen holds the identities hashed since the last reset, when duplicate
+	// detection is enabled
+	seen map[string]struct{}
 }
 
-func NewHasherV3() HasherV3 {
+// Reset resets the hasher state, including the identities tracked by
+// WithDuplicateDetection
+func (h *HasherV3) Reset() {
+	h.Hasher.Reset()
+	h.seen = nil
+}
+
+func NewHasherV3(opts ...HasherOption) HasherV3 {
 
 	h := HasherV3{
-		Hasher: NewHasher(),
+		Hasher: NewHasher(opts...),
 	}
 	return h
 }
 
+// Clone returns an independent copy of the hasher, including any accumulated state
+func (h *HasherV3) Clone() (HasherV3, error) {
+	c, err := h.Hasher.Clone()
+	if err != nil {
+		return HasherV3{}, err
+	}
+	clone := HasherV3{Hasher: c}
+	if h.seen != nil {
+		clone.seen = make(map[string]struct{}, len(h.seen))
+		for identity := range h.seen {
+			clone.seen[identity] = struct{}{}
+		}
+	}
+	return clone, nil
+}
+
 // V3FromEventJSON unmarshals rest api formated json into the event struct
 func V3FromEventJSON(eventJson []byte) (V3Event, error) {
+	return v3FromEventJSON(eventJson, HashOptions{})
+}
+
+func v3FromEventJSON(eventJson []byte, o HashOptions) (V3Event, error) {
 	var err error
 
 	eventShashV3 := V3Event{}
-	err = json.Unmarshal(eventJson, &eventShashV3)
+	err = decodeEventJSON(eventJson, &eventShashV3, o)
+	if err != nil {
+		return V3Event{}, err
+	}
+	err = decodeRedactions(eventJson, &eventShashV3.EventAttributes, &eventShashV3.AssetAttributes)
 	if err != nil {
 		return V3Event{}, err
 	}
 
 	// change all instances of public identities to permissioned identities
 	// we only use permissioned identities as part of the v3 hash schema
-	eventShashV3.Identity = v2assets.PermissionedIdentityFromPublic(eventShashV3.Identity)
+	eventShashV3.Identity = permissionedIdentity(eventShashV3.Identity)
 
 	return eventShashV3, nil
 }
 
-// V3FromEventResponse transforms a single event in grpc proto format (message bus
-// compatible) to the canonical, publicly verifiable, api format.
-func V3FromEventResponse(marshaler *simpleoneof.Marshaler, event *v2assets.EventResponse) (V3Event, error) {
-	eventJson, err := marshaler.Marshal(event)
-	if err != nil {
+// V3FromMap converts an event which has already been decoded from api
+// formatted json, for example by a webhook framework, without re-encoding it.
+// The attribute and principal maps are shared with the provided map.
+func V3FromMap(event map[string]any) (V3Event, error) {
+	v3Event := V3Event{}
+	if err := decodeEventMap(event, &v3Event); err != nil {
 		return V3Event{}, err
 	}
-	return V3FromEventJSON(eventJson)
+	if err := decodeMapRedactions(event, &v3Event.EventAttributes, &v3Event.AssetAttributes); err != nil {
+		return V3Event{}, err
+	}
+	v3Event.Identity = permissionedIdentity(v3Event.Identity)
+	return v3Event, nil
 }
 
-// HashEvent hashes a single event according to the canonical simple hash event
-// format available to api consumers. The source event is in the grpc proto buf
-// format. GRPC endpoints are not presently exposed by the platform.
+// HashEventFromJson hashes a single event according to the canonical simple hash event
+// format available to api consumers. The source event is in json format.
 //
 // Options:
 //   - WithIDCommitted prefix the data to hash with the bigendian encoding of
@@ -121,73 +145,211 @@ func V3FromEventResponse(marshaler *simpleoneof.Marshaler, event *v2assets.Event
 //   - WithPublicFromPermissioned should be set if the event is the
 //     permissioned (owner) counter part of a public attestation.
 //     NOTE: should not be used for valid v3 schema
-func (h *HasherV3) HashEvent(event *v2assets.EventResponse, opts ...HashOption) error {
+//   - WithChain includes the previous event digest after any prefix, to
+//     produce a hash chain over a stream of events.
+//   - WithUseNumber preserves integer attribute values exactly, and hashes
+//     them as bencode integers.
+//   - WithMaxEventSize and WithMaxDepth limit the size and nesting of
+//     untrusted json before it is decoded.
+//   - WithTimestampCommitted or WithTimestampCommittedTime set the
+//     timestamp_committed before hashing.
+//   - WithTenantIdentity overrides the tenant identity before hashing.
+//   - WithTimestampFormat(TimestampFormatAPI) formats the timestamps exactly
+//     as the api does, including any set by WithTimestampCommitted.
+//   - WithFastEncoding encodes the event without reflection, producing the
+//     same digest.
+//   - WithLogger logs each hashed event at debug level.
+//   - WithTrace copies the bytes written to the hash to a writer.
+//
+// The batch options, WithQuarantine, WithOrder, WithProgress,
+// WithEventDigests, WithContinueOnError and WithCache, are rejected with an
+// *OptionError.
+func (h *HasherV3) HashEventFromJSON(eventJson []byte, opts ...HashOption) error {
 
 	o := HashOptions{}
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if err := o.checkOptions("HasherV3.HashEventFromJSON", "the option applies to batches of events",
+		batchOptions...); err != nil {
+		return err
+	}
 
-	v3Event, err := V3FromEventResponse(h.marshaler, event)
+	v3Event, err := v3FromEventJSON(eventJson, o)
 	if err != nil {
 		return err
 	}
 
-	h.applyEventOptions(o, &v3Event)
+	if err := h.applyEventOptions(o, &v3Event); err != nil {
+		return err
+	}
+
+	return skipDuplicate(h.hashV3Event(v3Event, o))
+}
+
+// HashEventFromV3 hashes a single event according to the canonical simple hash event
+// format available to api consumers. The source event a pre decoded V3Event type
+// The options are applied to a copy, the event and its maps are never
+// modified.
+// Options: same as HashEventFromJSON
+func (h *HasherV3) HashEventFromV3(v3Event V3Event, opts ...HashOption) error {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.checkOptions("HasherV3.HashEventFromV3", "the option applies to batches of events",
+		batchOptions...); err != nil {
+		return err
+	}
 
-	h.applyHashingOptions(o)
+	if err := h.applyEventOptions(o, &v3Event); err != nil {
+		return err
+	}
 
-	return V3HashEvent(h.hasher, v3Event)
+	return skipDuplicate(h.hashV3Event(v3Event, o))
 }
 
-// HashEventFromJson hashes a single event according to the canonical simple hash event
-// format available to api consumers. The source event is in json format.
+// DigestEventFromJSON returns the V3 digest of a single api formatted event.
 //
-// Options:
-//   - WithIDCommitted prefix the data to hash with the bigendian encoding of
-//     idtimestamp before hashing.
-//   - WithPrefix is used to provide domain separation, the provided bytes are
-//     pre-pended to the data to be hashed.  Eg H(prefix || data)
-//     This option can be used multiple times, the prefix bytes are appended to
-//     any previously supplied.
-//   - WithAccumulate callers wishing to implement batched hashing of multiple
-//     events in series should set this. They should call Reset() at their batch
-//     boundaries.
-//   - WithPublicFromPermissioned should be set if the event is the
-//     permissioned (owner) counter part of a public attestation.
-//     NOTE: should not be used for valid v3 schema
-func (h *HasherV3) HashEventFromJSON(eventJson []byte, opts ...HashOption) error {
+// Options: as for HashEventFromJSON, except WithAccumulate which is ignored,
+// and additionally WithCache.
+func DigestEventFromJSON(eventJson []byte, opts ...HashOption) ([]byte, error) {
 
 	o := HashOptions{}
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if err := o.checkOptions("DigestEventFromJSON", "the option applies to batches of events",
+		optQuarantine, optOrder, optProgress, optEventDigests, optContinueOnError); err != nil {
+		return nil, err
+	}
 
-	v3Event, err := V3FromEventJSON(eventJson)
+	v3Event, err := v3FromEventJSON(eventJson, o)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	h.applyEventOptions(o, &v3Event)
-
-	h.applyHashingOptions(o)
+	h := NewHasherV3()
+	return h.digestV3Event(v3Event, eventJson, o, opts)
+}
 
-	return V3HashEvent(h.hasher, v3Event)
+// EventDigest is the digest of a single event, identified by its permissioned
+// identity
+type EventDigest struct {
+	Identity string
+	Digest   []byte
 }
 
-// HashEventFromV3 hashes a single event according to the canonical simple hash event
-// format available to api consumers. The source event a pre decoded V3Event type
-// Options: same as HashEventFromJSON
-func (h *HasherV3) HashEventFromV3(v3Event V3Event, opts ...HashOption) error {
+// DigestEventsFromJSON returns the identity and V3 digest of each api
+// formatted event, in order, digesting each individually.
+//
+// Options: as for DigestEventFromJSON, except WithAccumulate, which would make
+// each digest depend on the events before it, and is rejected with an
+// *OptionError.
+func DigestEventsFromJSON(events [][]byte, opts ...HashOption) ([]EventDigest, error) {
 
 	o := HashOptions{}
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if err := o.checkOptions("DigestEventsFromJSON", "each event is digested individually",
+		optAccumulate); err != nil {
+		return nil, err
+	}
+	if err := o.checkOptions("DigestEventsFromJSON", "the option applies to batches of events",
+		optQuarantine, optOrder, optProgress, optEventDigests, optContinueOnError); err != nil {
+		return nil, err
+	}
+
+	digests := make([]EventDigest, 0, len(events))
+	h := NewHasherV3()
+	for i, eventJson := range events {
+		v3Event, err := v3FromEventJSON(eventJson, o)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		digest, err := h.digestV3Event(v3Event, eventJson, o, opts)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		digests = append(digests, EventDigest{Identity: v3Event.Identity, Digest: digest})
+	}
+	return digests, nil
+}
+
+// HashOfV3 returns the V3 digest of a single event. It is a pure function: a
+// new hasher is used for every call, so there is no accumulated state and
+// nothing to Reset, and the event is not modified.
+//
+// Options: as for HashEventFromV3, except WithAccumulate which is ignored.
+func HashOfV3(v3Event V3Event, opts ...HashOption) ([]byte, error) {
+	h := NewHasherV3()
+	if err := h.HashEventFromV3(v3Event, append(opts[:len(opts):len(opts)], withoutAccumulate())...); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// digestV3Event returns the digest of a single decoded event, from the cache
+// if there is one. Each event gets its own digest, so accumulation is
+// switched off regardless of the callers options, as are the batch options
+// of the batch entry points using it.
+func (h *HasherV3) digestV3Event(v3Event V3Event, eventJson []byte, o HashOptions, opts []HashOption) ([]byte, error) {
+	compute := func() ([]byte, error) {
+		if err := h.HashEventFromV3(v3Event, append(withoutBatch(opts), withoutAccumulate())...); err != nil {
+			return nil, err
+		}
+		return h.Sum(nil), nil
+	}
+	if o.cache == nil {
+		return compute()
+	}
+	return cachedDigest(o.cache, newCacheKey(v3Event.Identity, SchemaVersionV3, eventJson, o), compute)
+}
 
-	h.applyEventOptions(o, &v3Event)
+// hashV3Event encodes the event and, only if that succeeds, applies the
+// hashing options and writes the encoded event to the hasher.
+func (h *HasherV3) hashV3Event(v3Event V3Event, o HashOptions) error {
 
-	h.applyHashingOptions(o)
+	if err := o.checkValues(); err != nil {
+		return err
+	}
+
+	if !o.accumulateHash {
+		h.seen = nil
+	}
+	if o.duplicates != duplicatesAllowed {
+		if _, ok := h.seen[v3Event.Identity]; ok {
+			if o.duplicates == duplicatesSkipped {
+				return errDuplicateSkipped
+			}
+			return fmt.Errorf("%w: %s", ErrDuplicateIdentity, v3Event.Identity)
+		}
+	}
+
+	applyOptions := func() {
+		h.applyHashingOptions(o)
+
+		if o.duplicates != duplicatesAllowed {
+			if h.seen == nil {
+				h.seen = map[string]struct{}{}
+			}
+			h.seen[v3Event.Identity] = struct{}{}
+		}
+	}
+
+	if o.fastEncodable() {
+		b := getEncodeBuffers()
+		defer putEncodeBuffers(b)
+		if err := b.encodeV3EventFast(v3Event, o); err == nil {
+			applyOptions()
+			hashWriter{&h.Hasher}.Write(b.event.Bytes())
+			o.traceWrite(b.event.Bytes())
+			o.observeEvent("EventSimpleHashV3", v3Event, b.event.Len())
+			return nil
+		}
+	}
 
-	return V3HashEvent(h.hasher, v3Event)
+	return writeEvent(hashWriter{&h.Hasher}, "EventSimpleHashV3", v3Event, o, applyOptions)
 }
@@ -0,0 +1,84 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SetTimestampCommitted sets the timestamp committed to the given timestamp
+func (e *V3Event) SetTimestampCommitted(timestamp *timestamppb.Timestamp) {
+	e.setTimestampCommitted(timestamp.AsTime())
+}
+
+// V3FromEventResponse transforms a single event in grpc proto format (message bus
+// compatible) to the canonical, publicly verifiable, api format. If marshaler
+// is nil the default, NewEventMarshaler, is used.
+func V3FromEventResponse(marshaler ProtoEventMarshaler, event *v2assets.EventResponse) (V3Event, error) {
+	eventJson, err := defaultEventMarshaler(marshaler).Marshal(event)
+	if err != nil {
+		return V3Event{}, err
+	}
+	return V3FromEventJSON(eventJson)
+}
+
+// V3FromProtoJSON transforms a single event in protojson format, as found on
+// some message buses, to the canonical api format. The event is decoded as an
+// EventResponse, unknown fields are ignored, and then converted exactly as
+// V3FromEventResponse does.
+func V3FromProtoJSON(eventJson []byte) (V3Event, error) {
+	event := &v2assets.EventResponse{}
+	err := protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(eventJson, event)
+	if err != nil {
+		return V3Event{}, err
+	}
+	return V3FromEventResponse(NewEventMarshaler(), event)
+}
+
+// HashEvent hashes a single event according to the canonical simple hash event
+// format available to api consumers. The source event is in the grpc proto buf
+// format. GRPC endpoints are not presently exposed by the platform.
+//
+// Options:
+//   - WithIDCommitted prefix the data to hash with the bigendian encoding of
+//     idtimestamp before hashing.
+//   - WithPrefix is used to provide domain separation, the provided bytes are
+//     pre-pended to the data to be hashed.  Eg H(prefix || data)
+//     This option can be used multiple times, the prefix bytes are appended to
+//     any previously supplied.
+//   - WithAccumulate callers wishing to implement batched hashing of multiple
+//     events in series should set this. They should call Reset() at their batch
+//     boundaries.
+//   - WithPublicFromPermissioned should be set if the event is the
+//     permissioned (owner) counter part of a public attestation.
+//     NOTE: should not be used for valid v3 schema
+//   - WithChain includes the previous event digest after any prefix, to
+//     produce a hash chain over a stream of events.
+//
+// The batch options are rejected, as for HashEventFromJSON.
+//
+// The options are applied to a copy, the event is never modified.
+func (h *HasherV3) HashEvent(event *v2assets.EventResponse, opts ...HashOption) error {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.checkOptions("HasherV3.HashEvent", "the option applies to batches of events",
+		batchOptions...); err != nil {
+		return err
+	}
+
+	v3Event, err := V3FromEventResponse(h.marshaler, event)
+	if err != nil {
+		return err
+	}
+
+	if err := h.applyEventOptions(o, &v3Event); err != nil {
+		return err
+	}
+
+	return skipDuplicate(h.hashV3Event(v3Event, o))
+}
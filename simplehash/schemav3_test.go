@@ -1,13 +1,18 @@
+//go:build !simplehash_noproto
+
 package simplehash
 
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"testing"
 	"time"
 
 	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -34,8 +39,8 @@ func TestHasherV3_HashEvent(t *testing.T) {
 			"valid events [:1] (both together)",
 			fields{
 				Hasher: Hasher{
-					sha256.New(),
-					NewEventMarshaler(),
+					hasher:    sha256.New(),
+					marshaler: NewEventMarshaler(),
 				},
 			},
 			args{
@@ -221,3 +226,177 @@ func TestV3FromEventResponse_ConvertsPublicIdentityToPermissioned(t *testing.T)
 		})
 	}
 }
+
+// TestV3FromProtoJSON tests:
+//
+// 1. a protojson encoded event converts to the same v3 event as the proto event.
+// 2. unknown fields are ignored.
+// 3. json which is not an event is rejected.
+func TestV3FromProtoJSON(t *testing.T) {
+	for _, event := range validEventsV2 {
+		expected, err := V3FromEventResponse(NewEventMarshaler(), event)
+		require.NoError(t, err)
+
+		protoJson, err := protojson.Marshal(event)
+		require.NoError(t, err)
+		actual, err := V3FromProtoJSON(protoJson)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+
+		withUnknown := append([]byte(`{"not_a_field": 1, `), protoJson[1:]...)
+		actual, err = V3FromProtoJSON(withUnknown)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	_, err := V3FromProtoJSON([]byte(`{"identity": 1}`))
+	assert.Error(t, err)
+}
+
+// fixedMarshaler is a ProtoEventMarshaler which ignores the event and
+// returns fixed json, standing in for a marshaler from another generated api
+type fixedMarshaler struct {
+	eventJson []byte
+	err       error
+}
+
+func (m fixedMarshaler) Marshal(v any) ([]byte, error) { return m.eventJson, m.err }
+
+// TestV3FromEventResponse_Marshaler tests:
+//
+// 1. a caller provided marshaler is used to produce the api json.
+// 2. a marshaler error is returned.
+// 3. a nil marshaler uses the default event marshaler.
+func TestV3FromEventResponse_Marshaler(t *testing.T) {
+	event := &v2assets.EventResponse{Identity: "assets/1234/events/5678"}
+
+	actual, err := V3FromEventResponse(fixedMarshaler{eventJson: []byte(`{"identity": "assets/abcd/events/ef01"}`)}, event)
+	require.NoError(t, err)
+	assert.Equal(t, "assets/abcd/events/ef01", actual.Identity)
+
+	_, err = V3FromEventResponse(fixedMarshaler{err: assert.AnError}, event)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	expected, err := V3FromEventResponse(NewEventMarshaler(), event)
+	require.NoError(t, err)
+	actual, err = V3FromEventResponse(nil, event)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+// TestNewHasherV3WithMarshaler tests:
+//
+// 1. proto events are converted with the injected marshaler.
+// 2. clones and pooled hashers keep the injected marshaler.
+// 3. a nil marshaler leaves the default in place.
+func TestNewHasherV3WithMarshaler(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/abcd/events/ef01", "event_attributes": {"foo": "bar"}}`)
+	event := &v2assets.EventResponse{Identity: "assets/1234/events/5678"}
+
+	expected := NewHasherV3()
+	require.NoError(t, expected.HashEventFromJSON(eventJson))
+
+	h := NewHasherV3WithMarshaler(fixedMarshaler{eventJson: eventJson})
+	require.NoError(t, h.HashEvent(event))
+	assert.Equal(t, expected.Sum(nil), h.Sum(nil))
+
+	c, err := h.Clone()
+	require.NoError(t, err)
+	require.NoError(t, c.HashEvent(event))
+	assert.Equal(t, expected.Sum(nil), c.Sum(nil))
+
+	pool := NewHasherV3Pool(WithMarshaler(fixedMarshaler{eventJson: eventJson}))
+	p := pool.Get()
+	require.NoError(t, p.HashEvent(event))
+	assert.Equal(t, expected.Sum(nil), p.Sum(nil))
+	pool.Put(p)
+
+	expected = NewHasherV3()
+	require.NoError(t, expected.HashEvent(event))
+	h = NewHasherV3(WithMarshaler(nil))
+	require.NoError(t, h.HashEvent(event))
+	assert.Equal(t, expected.Sum(nil), h.Sum(nil))
+}
+
+// TestHashOfV3 tests:
+//
+// 1. the digest matches HashEventFromV3, with options applied.
+// 2. WithAccumulate is ignored, so repeated calls give the same digest.
+// 3. the event is not modified.
+func TestHashOfV3(t *testing.T) {
+	v3Event := V3Event{
+		Identity:        "assets/1/events/2",
+		EventAttributes: map[string]any{"foo": "bar"},
+	}
+	opts := []HashOption{WithPrefix([]byte{0x01}), WithIDCommitted(7), WithPublicFromPermissioned()}
+
+	h := NewHasherV3()
+	require.NoError(t, h.HashEventFromV3(v3Event, opts...))
+	expected := h.Sum(nil)
+
+	for i := 0; i < 2; i++ {
+		digest, err := HashOfV3(v3Event, append(opts, WithAccumulate())...)
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest)
+	}
+	assert.Equal(t, "assets/1/events/2", v3Event.Identity)
+}
+
+// TestWithTimestampCommitted_Uniform tests that the committed timestamp is
+// applied the same way by every V2 and V3 hashing method, whether the event
+// is given as a proto, as json or pre-decoded.
+func TestWithTimestampCommitted_Uniform(t *testing.T) {
+	committed := time.Unix(1706700559, 43000000).UTC()
+	event := validEventsV2[0]
+	eventJson, err := NewEventMarshaler().Marshal(event)
+	require.NoError(t, err)
+
+	v3Event, err := V3FromEventJSON(eventJson)
+	require.NoError(t, err)
+	withCommitted := v3Event
+	withCommitted.TimestampCommitted = committed.Format(time.RFC3339Nano)
+	expected, err := HashOfV3(withCommitted)
+	require.NoError(t, err)
+	unchanged, err := HashOfV3(v3Event)
+	require.NoError(t, err)
+	require.NotEqual(t, unchanged, expected)
+
+	for _, opt := range []HashOption{WithTimestampCommitted(timestamppb.New(committed)), WithTimestampCommittedTime(committed)} {
+		h := NewHasherV3()
+		require.NoError(t, h.HashEvent(event, opt))
+		assert.Equal(t, expected, h.Sum(nil))
+		require.NoError(t, h.HashEventFromJSON(eventJson, opt))
+		assert.Equal(t, expected, h.Sum(nil))
+		require.NoError(t, h.HashEventFromV3(v3Event, opt))
+		assert.Equal(t, expected, h.Sum(nil))
+	}
+
+	v2Event, err := V2FromEventJSON(eventJson)
+	require.NoError(t, err)
+	v2WithCommitted := v2Event
+	v2WithCommitted.TimestampCommitted = committed.Format(time.RFC3339Nano)
+	h2 := NewHasherV2()
+	require.NoError(t, h2.HashEventFromV2(v2WithCommitted))
+	expected = h2.Sum()
+
+	for _, opt := range []HashOption{WithTimestampCommitted(timestamppb.New(committed)), WithTimestampCommittedTime(committed)} {
+		require.NoError(t, h2.HashEvent(event, opt))
+		assert.Equal(t, expected, h2.Sum())
+		require.NoError(t, h2.HashEventJSON(eventJson, opt))
+		assert.Equal(t, expected, h2.Sum())
+		require.NoError(t, h2.HashEventFromV2(v2Event, opt))
+		assert.Equal(t, expected, h2.Sum())
+	}
+}
+
+// TestHasherV3_HashEvent_BatchOptions tests that HashEvent rejects the batch
+// options with an *OptionError.
+func TestHasherV3_HashEvent_BatchOptions(t *testing.T) {
+	h := NewHasherV3()
+	err := h.HashEvent(validEventsV2[0], WithContinueOnError())
+	var optionErr *OptionError
+	require.True(t, errors.As(err, &optionErr), err)
+	assert.Equal(t, "HasherV3.HashEvent", optionErr.Method)
+	assert.Equal(t, "WithContinueOnError", optionErr.Option)
+	assert.NoError(t, h.HashEvent(validEventsV2[0]))
+}
@@ -0,0 +1,100 @@
+//go:build go1.23 && !simplehash_noproto
+
+package simplehash
+
+import (
+	"context"
+	"iter"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+)
+
+// HashSeq hashes a sequence of events, in the order produced, accumulating
+// them into a single digest. It is the iterator form of HashEvents, for
+// pipelines which stream events from other sources.
+//
+// Options: as for HashEvents, except WithOrder which is rejected with
+// ErrInvalidOption as a sequence is accumulated as it is produced.
+func (h *HasherV3) HashSeq(events iter.Seq[*v2assets.EventResponse], opts ...HashOption) (BatchResult, error) {
+	return h.HashSeqContext(context.Background(), events, opts...)
+}
+
+// HashSeqContext is HashSeq, stopping promptly if ctx is done. On
+// cancellation the partial result is returned with a *CanceledError.
+func (h *HasherV3) HashSeqContext(
+	ctx context.Context, events iter.Seq[*v2assets.EventResponse], opts ...HashOption,
+) (result BatchResult, err error) {
+
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.checkOptions("HasherV3.HashSeq", "a sequence is accumulated as it is produced", optOrder); err != nil {
+		return BatchResult{}, err
+	}
+	if err := o.checkValues(); err != nil {
+		return BatchResult{}, err
+	}
+
+	ctx, span := o.startSpan(ctx, SpanHashBatch)
+	defer func() { endBatchSpan(span, result, err) }()
+
+	o = h.startBatch(o)
+
+	i := 0
+	for event := range events {
+		if err := ctx.Err(); err != nil {
+			return h.cancelBatch(result, i, err, o)
+		}
+		v3Event, err := V3FromEventResponse(h.marshaler, event)
+		if err = h.hashBatchEvent(&result, i, event.GetIdentity(), nil, v3Event, err, o); err != nil {
+			return result, err
+		}
+		i++
+		o.reportProgress(i, -1, event.GetIdentity())
+	}
+
+	return h.finishBatch(result, o)
+}
+
+// DigestSeq hashes each event of a sequence individually, yielding the
+// identity and digest of each, or the error for an event which could not be
+// hashed. Iteration continues after an error until the consumer stops.
+//
+// Options: as for HashEvent, except WithAccumulate and the batch options which
+// are ignored.
+func (h *HasherV3) DigestSeq(
+	events iter.Seq[*v2assets.EventResponse], opts ...HashOption,
+) iter.Seq2[EventDigest, error] {
+	return h.DigestSeqContext(context.Background(), events, opts...)
+}
+
+// DigestSeqContext is DigestSeq, stopping promptly if ctx is done. On
+// cancellation a final *CanceledError is yielded.
+func (h *HasherV3) DigestSeqContext(
+	ctx context.Context, events iter.Seq[*v2assets.EventResponse], opts ...HashOption,
+) iter.Seq2[EventDigest, error] {
+
+	opts = append(withoutBatch(opts), withoutAccumulate())
+
+	return func(yield func(EventDigest, error) bool) {
+		processed := 0
+		for event := range events {
+			if err := ctx.Err(); err != nil {
+				yield(EventDigest{}, &CanceledError{Processed: processed, Err: err})
+				return
+			}
+			processed++
+			identity := permissionedIdentity(event.GetIdentity())
+			if err := h.HashEvent(event, opts...); err != nil {
+				if !yield(EventDigest{Identity: identity}, err) {
+					return
+				}
+				continue
+			}
+			if !yield(EventDigest{Identity: identity, Digest: h.Sum(nil)}, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//go:build go1.23 && !simplehash_noproto
+
+package simplehash
+
+import (
+	"encoding/hex"
+	"slices"
+	"testing"
+
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHasherV3_HashSeq tests:
+//
+// 1. hashing a sequence produces the same digest as the equivalent batch.
+func TestHasherV3_HashSeq(t *testing.T) {
+	h := NewHasherV3()
+
+	result, err := h.HashSeq(slices.Values(validEventsV2))
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedHashAllV3, hex.EncodeToString(result.Digest))
+	assert.Equal(t, 2, result.Count)
+}
+
+// TestHasherV3_DigestSeq tests:
+//
+// 1. each event yields its own digest and identity.
+// 2. the consumer can stop early.
+// 3. the spare capacity of the callers option slice is not written to.
+// 4. the batch options are ignored rather than rejected.
+func TestHasherV3_DigestSeq(t *testing.T) {
+	h := NewHasherV3()
+
+	var digests []EventDigest
+	for digest, err := range h.DigestSeq(slices.Values(validEventsV2)) {
+		require.NoError(t, err)
+		digests = append(digests, digest)
+	}
+	require.Len(t, digests, 2)
+
+	for i, event := range validEventsV2 {
+		single := NewHasherV3()
+		require.NoError(t, single.HashEvent(event))
+		assert.Equal(t, single.Sum(nil), digests[i].Digest)
+		assert.Equal(t, permissionedIdentity(event.Identity), digests[i].Identity)
+	}
+
+	count := 0
+	for range h.DigestSeq(slices.Values([]*v2assets.EventResponse{validEventsV2[0], validEventsV2[1]})) {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+
+	opts := make([]HashOption, 2)
+	opts[0], opts[1] = WithPrefix([]byte{1}), WithUseNumber()
+	for _, err := range h.DigestSeq(slices.Values(validEventsV2), opts[:1]...) {
+		require.NoError(t, err)
+	}
+	o := HashOptions{}
+	opts[1](&o)
+	assert.True(t, o.useNumber)
+
+	i := 0
+	for digest, err := range h.DigestSeq(slices.Values(validEventsV2),
+		WithProgress(func(int, int, string) {}), WithContinueOnError()) {
+		require.NoError(t, err)
+		assert.Equal(t, digests[i], digest)
+		i++
+	}
+	assert.Equal(t, 2, i)
+}
@@ -0,0 +1,12 @@
+//go:build !simplehash_simd
+
+package simplehash
+
+import (
+	"crypto/sha256"
+	"hash"
+)
+
+// newSHA256 creates the digest used by the hashers. Build with the
+// simplehash_simd tag to use a SIMD accelerated implementation instead.
+func newSHA256() hash.Hash { return sha256.New() }
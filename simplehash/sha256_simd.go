@@ -0,0 +1,19 @@
+//go:build simplehash_simd
+
+package simplehash
+
+import (
+	"hash"
+
+	sha256simd "github.com/minio/sha256-simd"
+)
+
+// Built with the simplehash_simd tag, the hashers use a SIMD accelerated
+// sha256, selected at runtime for the cpu (SHA extensions, AVX-512 or NEON).
+// The digests, and the state saved by MarshalBinary, are identical to those
+// of crypto/sha256, so checkpoints may be restored by either build. Recent go
+// releases use the SHA extensions in crypto/sha256 already, so measure with
+// BenchmarkSHA256 on the target hardware before enabling it.
+
+// newSHA256 creates the digest used by the hashers
+func newSHA256() hash.Hash { return sha256simd.New() }
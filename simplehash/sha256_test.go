@@ -0,0 +1,99 @@
+package simplehash
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"hash"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSHA256 tests:
+//
+// 1. the hasher digest agrees with crypto/sha256.
+// 2. state saved by either implementation restores in the other.
+func TestNewSHA256(t *testing.T) {
+	data := []byte("simplehash sha256 state")
+
+	h := newSHA256()
+	h.Write(data)
+	expected := sha256.Sum256(data)
+	assert.Equal(t, expected[:], h.Sum(nil))
+
+	std := sha256.New()
+	std.Write(data[:7])
+	state, err := std.(encoding.BinaryMarshaler).MarshalBinary()
+	require.NoError(t, err)
+	h = newSHA256()
+	require.NoError(t, h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state))
+	h.Write(data[7:])
+	assert.Equal(t, expected[:], h.Sum(nil))
+
+	h = newSHA256()
+	h.Write(data[:7])
+	state, err = h.(encoding.BinaryMarshaler).MarshalBinary()
+	require.NoError(t, err)
+	std = sha256.New()
+	require.NoError(t, std.(encoding.BinaryUnmarshaler).UnmarshalBinary(state))
+	std.Write(data[7:])
+	assert.Equal(t, expected[:], std.Sum(nil))
+}
+
+// BenchmarkSHA256 compares the hasher digest, which is SIMD accelerated when
+// built with the simplehash_simd tag, with crypto/sha256 over a typical
+// event pre-image and a large batch write.
+//
+//	go test -run XXX -bench SHA256 ./simplehash
+//	go test -run XXX -bench SHA256 -tags simplehash_simd ./simplehash
+func BenchmarkSHA256(b *testing.B) {
+	for _, size := range []struct {
+		name string
+		n    int
+	}{
+		{name: "event", n: 1 << 10},
+		{name: "batch", n: 1 << 20},
+	} {
+		data := make([]byte, size.n)
+		for _, impl := range []struct {
+			name string
+			new  func() hash.Hash
+		}{
+			{name: "crypto", new: sha256.New},
+			{name: "hasher", new: newSHA256},
+		} {
+			b.Run(size.name+"/"+impl.name, func(b *testing.B) {
+				b.SetBytes(int64(size.n))
+				for i := 0; i < b.N; i++ {
+					h := impl.new()
+					h.Write(data)
+					h.Sum(nil)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkHasherV3_HashEventFromJSON measures a whole event, encoding and
+// digest, with the standard and fast encoders
+func BenchmarkHasherV3_HashEventFromJSON(b *testing.B) {
+	eventJson := []byte(fastEncodingSeeds[3])
+	for _, bench := range []struct {
+		name string
+		opts []HashOption
+	}{
+		{name: "standard"},
+		{name: "fast", opts: []HashOption{WithFastEncoding()}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			h := NewHasherV3()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := h.HashEventFromJSON(eventJson, bench.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
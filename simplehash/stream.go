@@ -0,0 +1,209 @@
+package simplehash
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// StreamHasher accumulates a live stream of events, checkpointing its state
+// periodically so it can resume after a restart. It is the building block for
+// services which mirror the event firehose and maintain a verifiable digest
+// of everything seen.
+//
+// Failed events are handled as for a batch: with WithQuarantine they are
+// diverted and the stream continues, with WithContinueOnError they are
+// recorded in Result, otherwise Add returns the error.
+//
+// The digest and counts may be read concurrently with Run or Add.
+type StreamHasher struct {
+	mu sync.Mutex
+
+	h HasherV3
+	o HashOptions
+
+	result       BatchResult
+	index        int
+	lastIdentity string
+	metadata     map[string]string
+
+	store           CheckpointStore
+	name            string
+	every           int
+	sinceCheckpoint int
+}
+
+// NewStreamHasher creates a stream hasher. If store is not nil, the stream
+// resumes from the checkpoint saved under name, if there is one, and a
+// checkpoint is saved every checkpointEvery events, and when Run returns. A
+// checkpointEvery of zero only checkpoints when Run returns or Checkpoint is
+// called.
+//
+// Options: as for HashEventsFromJSON. WithAccumulate is implied.
+func NewStreamHasher(store CheckpointStore, name string, checkpointEvery int, opts ...HashOption) (*StreamHasher, error) {
+
+	s := &StreamHasher{
+		h:        NewHasherV3(),
+		metadata: map[string]string{},
+		store:    store,
+		name:     name,
+		every:    checkpointEvery,
+	}
+	for _, opt := range opts {
+		opt(&s.o)
+	}
+	s.o.accumulateHash = true
+
+	if store == nil {
+		return s, nil
+	}
+	checkpoint, err := store.Get(name)
+	if errors.Is(err, ErrCheckpointNotFound) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = checkpoint.Restore(&s.h.Hasher); err != nil {
+		return nil, err
+	}
+	s.result.Count = checkpoint.Count
+	s.index = checkpoint.Count
+	s.lastIdentity = checkpoint.LastIdentity
+	for k, v := range checkpoint.Metadata {
+		s.metadata[k] = v
+	}
+	return s, nil
+}
+
+// Run consumes api formatted events until the channel is closed or the
+// context is done, then saves a final checkpoint.
+func (s *StreamHasher) Run(ctx context.Context, events <-chan []byte) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Join(ctx.Err(), s.Checkpoint())
+		case eventJson, ok := <-events:
+			if !ok {
+				return s.Checkpoint()
+			}
+			if err := s.Add(eventJson, nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Add accumulates an api formatted event. metadata, for example the offset
+// of the event on its message bus, is merged into the metadata saved with
+// the next checkpoint.
+func (s *StreamHasher) Add(eventJson []byte, metadata map[string]string) error {
+	v3Event, err := v3FromEventJSON(eventJson, s.o)
+	return s.add(eventJson, v3Event, err, metadata)
+}
+
+// AddInvalid records an event the caller could not decode, for example a
+// malformed message bus payload. It is quarantined, recorded or returned as
+// for any other failed event, so that the metadata of the stream still
+// advances past it.
+func (s *StreamHasher) AddInvalid(raw []byte, reason error, metadata map[string]string) error {
+	return s.add(raw, V3Event{}, reason, metadata)
+}
+
+func (s *StreamHasher) add(raw []byte, v3Event V3Event, err error, metadata map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.h.hashBatchEvent(&s.result, s.index, v3Event.Identity, raw, v3Event, err, s.o); err != nil {
+		return err
+	}
+	s.index++
+	if v3Event.Identity != "" {
+		s.lastIdentity = v3Event.Identity
+	}
+	for k, v := range metadata {
+		s.metadata[k] = v
+	}
+	s.o.reportProgress(s.index, -1, v3Event.Identity)
+
+	s.sinceCheckpoint++
+	if s.every > 0 && s.sinceCheckpoint >= s.every {
+		return s.checkpoint()
+	}
+	return nil
+}
+
+// Checkpoint saves the current state to the store, if there is one
+func (s *StreamHasher) Checkpoint() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoint()
+}
+
+func (s *StreamHasher) checkpoint() error {
+	if s.store == nil {
+		return nil
+	}
+	checkpoint, err := NewCheckpoint(&s.h.Hasher, s.result.Count, s.lastIdentity)
+	if err != nil {
+		return err
+	}
+	checkpoint.Metadata = make(map[string]string, len(s.metadata))
+	for k, v := range s.metadata {
+		checkpoint.Metadata[k] = v
+	}
+	if err = s.store.Put(s.name, checkpoint); err != nil {
+		return err
+	}
+	if s.o.logger != nil {
+		s.o.logger.Debug("checkpointed stream", "name", s.name, "events", checkpoint.Count, "last_identity", s.lastIdentity)
+	}
+	s.sinceCheckpoint = 0
+	return nil
+}
+
+// Digest returns the digest of all the events accumulated so far
+func (s *StreamHasher) Digest() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h.Sum(nil)
+}
+
+// Count returns the number of events accumulated so far, including those
+// accumulated before the checkpoint the stream resumed from
+func (s *StreamHasher) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.result.Count
+}
+
+// LastIdentity returns the identity of the most recent event
+func (s *StreamHasher) LastIdentity() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastIdentity
+}
+
+// Metadata returns a copy of the metadata accumulated so far, including that
+// restored from the checkpoint, so a consumer can resume its source from the
+// recorded offsets.
+func (s *StreamHasher) Metadata() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metadata := make(map[string]string, len(s.metadata))
+	for k, v := range s.metadata {
+		metadata[k] = v
+	}
+	return metadata
+}
+
+// Result returns the digest and counts of the stream so far, as a BatchResult
+// marked Partial if any events were quarantined or failed. Failures recorded
+// by WithContinueOnError are returned as a *BatchError.
+func (s *StreamHasher) Result() (BatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := s.result
+	result.Digest = s.h.Sum(nil)
+	return result, result.batchErr()
+}
@@ -0,0 +1,28 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+	"google.golang.org/protobuf/proto"
+)
+
+// AddEvent accumulates an event in grpc proto format, as for Add
+func (s *StreamHasher) AddEvent(event *v2assets.EventResponse, metadata map[string]string) error {
+	eventJson, err := s.h.marshaler.Marshal(event)
+	if err != nil {
+		return s.add(nil, V3Event{}, err, metadata)
+	}
+	return s.Add(eventJson, metadata)
+}
+
+// AddProto accumulates an EventResponse in proto wire format, as found on the
+// message bus, as for Add. A payload which can not be unmarshaled is handled
+// as a failed event.
+func (s *StreamHasher) AddProto(body []byte, metadata map[string]string) error {
+	event := &v2assets.EventResponse{}
+	if err := proto.Unmarshal(body, event); err != nil {
+		return s.add(body, V3Event{}, err, metadata)
+	}
+	return s.AddEvent(event, metadata)
+}
@@ -0,0 +1,77 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStreamHasher tests:
+//
+// 1. events consumed from a channel accumulate to the same digest as a batch.
+// 2. a stream restarted from its checkpoint continues the same digest, and
+// restores the metadata saved with it.
+// 3. a failed event stops the stream unless it is quarantined.
+func TestStreamHasher(t *testing.T) {
+	var events [][]byte
+	for i := 1; i <= 5; i++ {
+		events = append(events, []byte(fmt.Sprintf(`{"identity": "assets/1/events/%d"}`, i)))
+	}
+	h := NewHasherV3()
+	expected, err := h.HashEventsFromJSON(events)
+	require.NoError(t, err)
+
+	store, err := NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+
+	s, err := NewStreamHasher(store, "firehose", 2)
+	require.NoError(t, err)
+	for i, e := range events[:3] {
+		require.NoError(t, s.Add(e, map[string]string{"offset": fmt.Sprint(i)}))
+	}
+	// only the first two events have been checkpointed, the third is replayed
+
+	s, err = NewStreamHasher(store, "firehose", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Count())
+	assert.Equal(t, "assets/1/events/2", s.LastIdentity())
+	assert.Equal(t, map[string]string{"offset": "1"}, s.Metadata())
+
+	ch := make(chan []byte, len(events))
+	for _, e := range events[2:] {
+		ch <- e
+	}
+	close(ch)
+	require.NoError(t, s.Run(context.Background(), ch))
+	assert.Equal(t, expected.Digest, s.Digest())
+	assert.Equal(t, 5, s.Count())
+
+	checkpoint, err := store.Get("firehose")
+	require.NoError(t, err)
+	assert.Equal(t, 5, checkpoint.Count)
+
+	bad := []byte(`{"identity": "assets/1/events/6", "event_attributes": {"n": 1.5}}`)
+
+	s, err = NewStreamHasher(nil, "", 0)
+	require.NoError(t, err)
+	assert.Error(t, s.Add(bad, nil))
+
+	var quarantined []QuarantinedEvent
+	s, err = NewStreamHasher(nil, "", 0, WithQuarantine(quarantineFunc(func(q QuarantinedEvent) error {
+		quarantined = append(quarantined, q)
+		return nil
+	})))
+	require.NoError(t, err)
+	require.NoError(t, s.Add(bad, nil))
+	require.NoError(t, s.Add(events[0], nil))
+	result, err := s.Result()
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, 1, result.Quarantined)
+	assert.Len(t, quarantined, 1)
+}
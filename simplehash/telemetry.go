@@ -0,0 +1,95 @@
+package simplehash
+
+import (
+	"context"
+)
+
+// Span and counter names reported to Telemetry
+const (
+	SpanHashBatch  = "simplehash.HashBatch"
+	SpanDiffEvents = "simplehash.DiffEvents"
+
+	// CounterEventsHashed counts the events written to a hasher
+	CounterEventsHashed = "simplehash.events_hashed"
+	// CounterBytesCanonicalized counts the bytes of the canonical pre-images
+	// written to a hasher
+	CounterBytesCanonicalized = "simplehash.bytes_canonicalized"
+	// CounterMismatches counts the events whose digest did not match the
+	// expected digest
+	CounterMismatches = "simplehash.mismatches"
+)
+
+// Telemetry receives spans and counters, for production services which
+// instrument their use of the hashers. The OpenTelemetry implementation is the
+// nested module otel, the package itself has no telemetry dependencies.
+//
+// Implementations must be safe for concurrent use.
+type Telemetry interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+	AddCount(ctx context.Context, counter string, n int64)
+}
+
+// Span is a span started by Telemetry
+type Span interface {
+	SetAttribute(key string, value int64)
+	End(err error)
+}
+
+// WithTelemetry reports spans and counters to telemetry. Batches, including
+// anchor reproduction, and diffs are spans, see SpanHashBatch and
+// SpanDiffEvents. Every event hashed with the option is counted, see
+// CounterEventsHashed and CounterBytesCanonicalized, as are mismatches found
+// by DiffEventsJSON, VerifySetMembership and the ledger and report verifiers,
+// see CounterMismatches.
+func WithTelemetry(telemetry Telemetry) HashOption {
+	return func(o *HashOptions) {
+		o.telemetry = telemetry
+	}
+}
+
+// AddMismatches counts n mismatches to the telemetry of opts, if there is
+// any, for verifiers built on the package.
+func AddMismatches(ctx context.Context, n int, opts ...HashOption) {
+	o := HashOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.addCount(ctx, CounterMismatches, n)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, int64) {}
+func (noopSpan) End(error)                  {}
+
+// startSpan starts a span, which does nothing if there is no telemetry
+func (o *HashOptions) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if o.telemetry == nil {
+		return ctx, noopSpan{}
+	}
+	return o.telemetry.StartSpan(ctx, name)
+}
+
+func (o *HashOptions) addCount(ctx context.Context, counter string, n int) {
+	if o.telemetry != nil && n != 0 {
+		o.telemetry.AddCount(ctx, counter, int64(n))
+	}
+}
+
+// endBatchSpan records the outcome of a batch on its span
+func endBatchSpan(span Span, result BatchResult, err error) {
+	span.SetAttribute("events", int64(result.Count))
+	span.SetAttribute("failed", int64(result.Failed))
+	span.SetAttribute("quarantined", int64(result.Quarantined))
+	span.SetAttribute("duplicates", int64(result.Duplicates))
+	span.End(err)
+}
+
+// observeEvent logs and counts an event written to the hasher
+func (o *HashOptions) observeEvent(schema string, event any, preimageSize int) {
+	o.logEvent(schema, event, preimageSize)
+	if o.telemetry != nil {
+		o.addCount(context.Background(), CounterEventsHashed, 1)
+		o.addCount(context.Background(), CounterBytesCanonicalized, preimageSize)
+	}
+}
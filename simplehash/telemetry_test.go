@@ -0,0 +1,114 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]int64
+	ended      bool
+	err        error
+}
+
+func (s *fakeSpan) SetAttribute(key string, value int64) { s.attributes[key] = value }
+func (s *fakeSpan) End(err error)                        { s.ended, s.err = true, err }
+
+// fakeTelemetry records the spans started and the counter totals
+type fakeTelemetry struct {
+	mu     sync.Mutex
+	spans  []*fakeSpan
+	counts map[string]int64
+}
+
+func newFakeTelemetry() *fakeTelemetry {
+	return &fakeTelemetry{counts: map[string]int64{}}
+}
+
+func (t *fakeTelemetry) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &fakeSpan{name: name, attributes: map[string]int64{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (t *fakeTelemetry) AddCount(ctx context.Context, counter string, n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[counter] += n
+}
+
+// TestWithTelemetry tests:
+//
+// 1. a batch is a span, ended with its outcome.
+// 2. every hashed event, and the size of its pre-image, is counted.
+// 3. a diff is a span and its mismatches are counted.
+// 4. the digest is unchanged by telemetry.
+func TestWithTelemetry(t *testing.T) {
+	marshaler := NewEventMarshaler()
+
+	var events [][]byte
+	preimageBytes := 0
+	for _, event := range validEventsV2 {
+		eventJson, err := marshaler.Marshal(event)
+		require.NoError(t, err)
+		events = append(events, eventJson)
+
+		v3Event, err := V3FromEventJSON(eventJson)
+		require.NoError(t, err)
+		preimage, err := V3EncodeEvent(v3Event)
+		require.NoError(t, err)
+		preimageBytes += len(preimage)
+	}
+	events = append(events, []byte(`{"identity": `))
+
+	h := NewHasherV3()
+	expected, err := h.HashEventsFromJSON(events, WithContinueOnError())
+	require.Error(t, err)
+
+	telemetry := newFakeTelemetry()
+	result, err := h.HashEventsFromJSON(events, WithContinueOnError(), WithTelemetry(telemetry))
+	require.Error(t, err)
+	assert.Equal(t, expected.Digest, result.Digest)
+
+	require.Len(t, telemetry.spans, 1)
+	span := telemetry.spans[0]
+	assert.Equal(t, SpanHashBatch, span.name)
+	assert.True(t, span.ended)
+	assert.Error(t, span.err)
+	assert.Equal(t, int64(len(validEventsV2)), span.attributes["events"])
+	assert.Equal(t, int64(1), span.attributes["failed"])
+	assert.Equal(t, int64(len(validEventsV2)), telemetry.counts[CounterEventsHashed])
+	assert.Equal(t, int64(preimageBytes), telemetry.counts[CounterBytesCanonicalized])
+
+	telemetry = newFakeTelemetry()
+	event := map[string]any{}
+	require.NoError(t, json.Unmarshal(events[0], &event))
+	event["operation"] = "Tampered"
+	tampered, err := json.Marshal(event)
+	require.NoError(t, err)
+	diff, err := DiffEventsJSON(events[:1], [][]byte{tampered}, WithTelemetry(telemetry))
+	require.NoError(t, err)
+	require.Len(t, diff.Mismatched, 1)
+
+	require.NotEmpty(t, telemetry.spans)
+	span = telemetry.spans[0]
+	assert.Equal(t, SpanDiffEvents, span.name)
+	assert.True(t, span.ended)
+	assert.NoError(t, span.err)
+	assert.Equal(t, int64(1), span.attributes["mismatched"])
+	assert.Equal(t, int64(1), telemetry.counts[CounterMismatches])
+
+	AddMismatches(context.Background(), 2, WithTelemetry(telemetry))
+	assert.Equal(t, int64(3), telemetry.counts[CounterMismatches])
+	assert.NotPanics(t, func() { AddMismatches(context.Background(), 1) })
+}
@@ -0,0 +1,81 @@
+package simplehash
+
+// TenantAccumulator accumulates a mixed stream of events into a separate
+// digest for each tenant, so per tenant roots can be produced in a single
+// pass over a multi-tenant export. Each tenant digest is the same as hashing
+// that tenant's events, in the order added, with HashEvents.
+//
+// A TenantAccumulator is not safe for concurrent use.
+type TenantAccumulator struct {
+	opts    []HashOption
+	o       HashOptions
+	tenants map[string]*tenantHasher
+}
+
+type tenantHasher struct {
+	h     HasherV3
+	count int
+}
+
+// NewTenantAccumulator creates an accumulator which applies opts to every
+// event.
+//
+// Options: as for HashEventFromJSON. WithAccumulate is implied, and the batch
+// options are ignored.
+func NewTenantAccumulator(opts ...HashOption) *TenantAccumulator {
+	a := &TenantAccumulator{
+		opts:    append(withoutBatch(opts), WithAccumulate()),
+		tenants: map[string]*tenantHasher{},
+	}
+	for _, opt := range a.opts {
+		opt(&a.o)
+	}
+	return a
+}
+
+// AddJSON accumulates an api formatted event into the digest of its tenant
+func (a *TenantAccumulator) AddJSON(eventJson []byte) error {
+	v3Event, err := v3FromEventJSON(eventJson, a.o)
+	if err != nil {
+		return err
+	}
+	return a.AddV3(v3Event)
+}
+
+// AddV3 accumulates a decoded event into the digest of its tenant. Events
+// without a tenant identity are accumulated under the empty string. With
+// WithTenantIdentity every event is accumulated under the overriding tenant.
+func (a *TenantAccumulator) AddV3(v3Event V3Event) error {
+	if a.o.tenantIdentity != nil {
+		v3Event.TenantIdentity = *a.o.tenantIdentity
+	}
+	t, ok := a.tenants[v3Event.TenantIdentity]
+	if !ok {
+		t = &tenantHasher{h: NewHasherV3()}
+	}
+	if err := t.h.HashEventFromV3(v3Event, a.opts...); err != nil {
+		return err
+	}
+	// the tenant is only recorded once it has an event
+	a.tenants[v3Event.TenantIdentity] = t
+	t.count++
+	return nil
+}
+
+// Digests returns the current digest of each tenant
+func (a *TenantAccumulator) Digests() map[string][]byte {
+	digests := make(map[string][]byte, len(a.tenants))
+	for tenant, t := range a.tenants {
+		digests[tenant] = t.h.Sum(nil)
+	}
+	return digests
+}
+
+// Counts returns the number of events accumulated for each tenant
+func (a *TenantAccumulator) Counts() map[string]int {
+	counts := make(map[string]int, len(a.tenants))
+	for tenant, t := range a.tenants {
+		counts[tenant] = t.count
+	}
+	return counts
+}
@@ -0,0 +1,17 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	v2assets "github.com/datatrails/go-datatrails-common-api-gen/assets/v2/assets"
+)
+
+// Add accumulates an event, in grpc proto format, into the digest of its
+// tenant
+func (a *TenantAccumulator) Add(event *v2assets.EventResponse) error {
+	v3Event, err := V3FromEventResponse(NewEventMarshaler(), event)
+	if err != nil {
+		return err
+	}
+	return a.AddV3(v3Event)
+}
@@ -0,0 +1,64 @@
+package simplehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTenantAccumulator tests:
+//
+// 1. each tenant digest matches a batch of only that tenant's events.
+// 2. events without a tenant are accumulated under the empty string.
+// 3. a bad event is rejected without recording its tenant.
+func TestTenantAccumulator(t *testing.T) {
+	a1 := []byte(`{"identity": "assets/1/events/1", "tenant_identity": "tenant/a"}`)
+	b1 := []byte(`{"identity": "assets/2/events/1", "tenant_identity": "tenant/b"}`)
+	a2 := []byte(`{"identity": "assets/1/events/2", "tenant_identity": "tenant/a"}`)
+	none := []byte(`{"identity": "assets/3/events/1"}`)
+
+	acc := NewTenantAccumulator()
+	for _, event := range [][]byte{a1, b1, a2, none} {
+		require.NoError(t, acc.AddJSON(event))
+	}
+	require.Error(t, acc.AddJSON([]byte(`{"identity": "assets/4/events/1", "tenant_identity": "tenant/c", "event_attributes": {"n": 1.5}}`)))
+
+	expected := func(events ...[]byte) []byte {
+		h := NewHasherV3()
+		result, err := h.HashEventsFromJSON(events)
+		require.NoError(t, err)
+		return result.Digest
+	}
+	assert.Equal(t, map[string][]byte{
+		"tenant/a": expected(a1, a2),
+		"tenant/b": expected(b1),
+		"":         expected(none),
+	}, acc.Digests())
+	assert.Equal(t, map[string]int{"tenant/a": 2, "tenant/b": 1, "": 1}, acc.Counts())
+}
+
+// TestWithTenantIdentity tests:
+//
+// 1. the tenant identity is overridden before hashing, for both schemas.
+// 2. a tenant accumulator accumulates under the overriding tenant.
+func TestWithTenantIdentity(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2", "tenant_identity": "tenant/1"}`)
+	rehomed := []byte(`{"identity": "assets/1/events/2", "tenant_identity": "tenant/2"}`)
+
+	expected, err := DigestEventFromJSON(rehomed)
+	require.NoError(t, err)
+	digest, err := DigestEventFromJSON(eventJson, WithTenantIdentity("tenant/2"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	h2 := NewHasherV2()
+	require.NoError(t, h2.HashEventJSON(rehomed))
+	expected2 := h2.Sum()
+	require.NoError(t, h2.HashEventJSON(eventJson, WithTenantIdentity("tenant/2")))
+	assert.Equal(t, expected2, h2.Sum())
+
+	a := NewTenantAccumulator(WithTenantIdentity("tenant/2"))
+	require.NoError(t, a.AddJSON(eventJson))
+	assert.Equal(t, map[string][]byte{"tenant/2": expected}, a.Digests())
+}
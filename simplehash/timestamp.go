@@ -0,0 +1,82 @@
+package simplehash
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidTimestamp = errors.New("invalid event timestamp")
+)
+
+// TimestampFormat selects how event timestamps are formatted before hashing
+type TimestampFormat int
+
+const (
+	// TimestampFormatAsIs hashes the timestamps as provided. Timestamps set by
+	// WithTimestampCommitted are formatted with time.RFC3339Nano. This is the
+	// default.
+	TimestampFormatAsIs TimestampFormat = iota
+	// TimestampFormatAPI reformats every timestamp exactly as the api emits
+	// it: in UTC, with the fractional seconds omitted if zero, otherwise to
+	// millisecond, microsecond or nanosecond precision, whichever is the
+	// shortest exact representation. Eg "2024-01-31T11:29:19.043Z".
+	TimestampFormatAPI
+)
+
+// WithTimestampFormat selects how the timestamp_declared, timestamp_accepted
+// and timestamp_committed fields are formatted before hashing. Use
+// TimestampFormatAPI to reproduce the api digest of an event whose timestamps
+// were produced locally, including by WithTimestampCommitted.
+func WithTimestampFormat(format TimestampFormat) HashOption {
+	return func(o *HashOptions) {
+		o.timestampFormat = format
+	}
+}
+
+// FormatTimestamps reformats the event timestamps. Empty timestamps are left
+// unset.
+func (e *V3Event) FormatTimestamps(format TimestampFormat) error {
+	return formatTimestamps(format, &e.TimestampDeclared, &e.TimestampAccepted, &e.TimestampCommitted)
+}
+
+// FormatTimestamps reformats the event timestamps. Empty timestamps are left
+// unset.
+func (e *V2Event) FormatTimestamps(format TimestampFormat) error {
+	return formatTimestamps(format, &e.TimestampDeclared, &e.TimestampAccepted, &e.TimestampCommitted)
+}
+
+func formatTimestamps(format TimestampFormat, timestamps ...*string) error {
+	if format != TimestampFormatAPI {
+		return nil
+	}
+	for _, ts := range timestamps {
+		if *ts == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, *ts)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTimestamp, err)
+		}
+		*ts = formatAPITimestamp(t)
+	}
+	return nil
+}
+
+// formatAPITimestamp formats t the way protojson formats a
+// google.protobuf.Timestamp, which is how the api formats every timestamp.
+func formatAPITimestamp(t time.Time) string {
+	t = t.UTC()
+	nanos := t.Nanosecond()
+	switch {
+	case nanos == 0:
+		return t.Format("2006-01-02T15:04:05Z")
+	case nanos%1e6 == 0:
+		return t.Format("2006-01-02T15:04:05.000Z")
+	case nanos%1e3 == 0:
+		return t.Format("2006-01-02T15:04:05.000000Z")
+	default:
+		return t.Format("2006-01-02T15:04:05.000000000Z")
+	}
+}
@@ -0,0 +1,75 @@
+//go:build !simplehash_noproto
+
+package simplehash
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TestFormatAPITimestamp tests:
+//
+// 1. timestamps are formatted with the shortest of 0, 3, 6 or 9 fractional digits, in UTC.
+func TestFormatAPITimestamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       time.Time
+		expected string
+	}{
+		{"whole seconds", time.Date(2024, 1, 31, 11, 29, 19, 0, time.UTC), "2024-01-31T11:29:19Z"},
+		{"milliseconds", time.Date(2024, 1, 31, 11, 29, 19, 43000000, time.UTC), "2024-01-31T11:29:19.043Z"},
+		{"trailing zero millis", time.Date(2024, 1, 31, 11, 29, 19, 40000000, time.UTC), "2024-01-31T11:29:19.040Z"},
+		{"microseconds", time.Date(2024, 1, 31, 11, 29, 19, 43500000, time.UTC), "2024-01-31T11:29:19.043500Z"},
+		{"nanoseconds", time.Date(2024, 1, 31, 11, 29, 19, 43500001, time.UTC), "2024-01-31T11:29:19.043500001Z"},
+		{"offset", time.Date(2024, 1, 31, 12, 29, 19, 43000000, time.FixedZone("", 3600)), "2024-01-31T11:29:19.043Z"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, formatAPITimestamp(test.in))
+			// protojson is the reference for the api format
+			expected, err := protojsonTimestamp(test.in)
+			require.NoError(t, err)
+			assert.Equal(t, expected, formatAPITimestamp(test.in))
+		})
+	}
+}
+
+// TestWithTimestampFormat tests:
+//
+// 1. the api format reproduces the digest of the event as returned by the api.
+// 2. the default formatting of WithTimestampCommitted does not.
+// 3. an unparsable timestamp is rejected in api format mode.
+func TestWithTimestampFormat(t *testing.T) {
+	committed := timestamppb.New(time.Date(2024, 1, 31, 11, 29, 19, 40000000, time.UTC))
+
+	api := V3Event{Identity: "assets/1/events/2", TimestampCommitted: "2024-01-31T11:29:19.040Z"}
+	h := NewHasherV3()
+	require.NoError(t, h.HashEventFromV3(api))
+	expected := hex.EncodeToString(h.Sum(nil))
+
+	local := V3Event{Identity: "assets/1/events/2"}
+
+	require.NoError(t, h.HashEventFromV3(local, WithTimestampCommitted(committed)))
+	assert.NotEqual(t, expected, hex.EncodeToString(h.Sum(nil)))
+
+	require.NoError(t, h.HashEventFromV3(local, WithTimestampCommitted(committed), WithTimestampFormat(TimestampFormatAPI)))
+	assert.Equal(t, expected, hex.EncodeToString(h.Sum(nil)))
+
+	bad := V3Event{Identity: "assets/1/events/2", TimestampDeclared: "yesterday"}
+	assert.ErrorIs(t, h.HashEventFromV3(bad, WithTimestampFormat(TimestampFormatAPI)), ErrInvalidTimestamp)
+}
+
+func protojsonTimestamp(t time.Time) (string, error) {
+	b, err := protojson.Marshal(timestamppb.New(t))
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(string(b), `"`), nil
+}
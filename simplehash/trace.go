@@ -0,0 +1,26 @@
+package simplehash
+
+import (
+	"io"
+)
+
+// WithTrace copies every byte written to the underlying hash to w: the
+// prefix, any chained digest, the idcommitted and the canonical pre-image of
+// each event, in the order they are hashed. Hashing the trace reproduces the
+// digest, so a mismatch with another implementation can be diagnosed byte by
+// byte by comparing its input with the trace. Resets of the hash are not
+// marked in the trace. Errors writing to w are ignored, they never fail the
+// hash.
+func WithTrace(w io.Writer) HashOption {
+	return func(o *HashOptions) {
+		o.trace = w
+	}
+}
+
+// traceWrite copies b, which has just been written to the hash, to the
+// trace writer
+func (o *HashOptions) traceWrite(b []byte) {
+	if o.trace != nil {
+		_, _ = o.trace.Write(b)
+	}
+}
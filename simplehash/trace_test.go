@@ -0,0 +1,47 @@
+package simplehash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithTrace tests:
+//
+// 1. the trace is the prefix, idcommitted and pre-image, in that order.
+// 2. hashing the trace reproduces the digest, with and without the fast
+// encoder.
+func TestWithTrace(t *testing.T) {
+	eventJson := []byte(`{"identity": "assets/1/events/2", "event_attributes": {"foo": "bar"}}`)
+	prefix := []byte{0x01, 0x02}
+
+	for _, fast := range []bool{false, true} {
+		opts := []HashOption{WithPrefix(prefix), WithIDCommitted(7)}
+		if fast {
+			opts = append(opts, WithFastEncoding())
+		}
+		expected, err := DigestEventFromJSON(eventJson, opts...)
+		require.NoError(t, err)
+
+		var trace bytes.Buffer
+		h := NewHasherV3()
+		require.NoError(t, h.HashEventFromJSON(eventJson, append(opts, WithTrace(&trace))...))
+		assert.Equal(t, expected, h.Sum(nil))
+
+		v3Event, err := V3FromEventJSON(eventJson)
+		require.NoError(t, err)
+		preimage, err := V3EncodeEvent(v3Event)
+		require.NoError(t, err)
+
+		want := append(append([]byte(nil), prefix...), binary.BigEndian.AppendUint64(nil, 7)...)
+		want = append(want, preimage...)
+		assert.Equal(t, want, trace.Bytes())
+
+		sum := sha256.Sum256(trace.Bytes())
+		assert.Equal(t, expected, sum[:])
+	}
+}
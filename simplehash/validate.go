@@ -0,0 +1,123 @@
+package simplehash
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const tenantIdentityPrefix = "tenant/"
+
+var (
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+// Validate checks the event has the shape of an event returned by the api, so
+// callers can fail fast rather than produce a digest which can never match an
+// anchor. It checks:
+//
+//   - the identity has the form [public]assets/{uuid}/events/{uuid}
+//   - the tenant identity, if set, has the form tenant/{uuid}
+//   - every timestamp which is set is an RFC3339 timestamp
+//   - every attribute value is a string, a map of strings or a list of maps
+//     of strings
+//
+// Every problem found is reported. The returned error joins one error per
+// problem, each wrapping ErrInvalidEvent.
+func (e *V3Event) Validate() error {
+
+	errs := e.validateFields()
+	errs = append(errs, validateAttributes("event_attributes", e.EventAttributes)...)
+	errs = append(errs, validateAttributes("asset_attributes", e.AssetAttributes)...)
+
+	return errors.Join(errs...)
+}
+
+// validateFields checks the identities and timestamps of the event, see
+// Validate
+func (e *V3Event) validateFields() []error {
+
+	var errs []error
+
+	if e.Identity == "" {
+		errs = append(errs, fmt.Errorf("%w: identity is missing", ErrInvalidEvent))
+	} else if _, err := ParseEventIdentity(e.Identity); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %v", ErrInvalidEvent, err))
+	}
+
+	if e.TenantIdentity != "" {
+		tenant, ok := strings.CutPrefix(e.TenantIdentity, tenantIdentityPrefix)
+		if _, err := uuid.Parse(tenant); !ok || err != nil {
+			errs = append(errs, fmt.Errorf("%w: tenant identity %q is not a tenant identity", ErrInvalidEvent, e.TenantIdentity))
+		}
+	}
+
+	for _, ts := range []struct {
+		name  string
+		value string
+	}{
+		{"timestamp_declared", e.TimestampDeclared},
+		{"timestamp_accepted", e.TimestampAccepted},
+		{"timestamp_committed", e.TimestampCommitted},
+	} {
+		if ts.value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339Nano, ts.value); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %v", ErrInvalidEvent, ts.name, err))
+		}
+	}
+
+	return errs
+}
+
+// validateAttributes checks the attribute values have one of the types an api
+// attribute can hold. The errors are ordered by attribute name.
+func validateAttributes(field string, attributes map[string]any) []error {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if !validAttributeValue(attributes[name]) {
+			errs = append(errs, fmt.Errorf(
+				"%w: %s.%s has unsupported type %T", ErrInvalidEvent, field, name, attributes[name]))
+		}
+	}
+	return errs
+}
+
+func validAttributeValue(value any) bool {
+	switch v := value.(type) {
+	case string, Redacted:
+		return true
+	case map[string]any:
+		return stringValues(v)
+	case []any:
+		for _, item := range v {
+			m, ok := item.(map[string]any)
+			if !ok || !stringValues(m) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func stringValues(m map[string]any) bool {
+	for _, v := range m {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
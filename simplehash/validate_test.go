@@ -0,0 +1,69 @@
+package simplehash
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestV3Event_Validate tests:
+//
+// 1. api shaped events, permissioned and public, are valid.
+// 2. each kind of problem is reported.
+// 3. every problem found is reported in a single error.
+func TestV3Event_Validate(t *testing.T) {
+	valid := func() V3Event {
+		return V3Event{
+			Identity:          "assets/03c60f22-588c-4f12-b3c2-e98c9f6a3e38/events/a022f458-8e55-4d63-a200-4172a42fc2aa",
+			TenantIdentity:    "tenant/0684984b-654d-4301-ad10-a508126e187d",
+			TimestampDeclared: "2024-01-31T11:29:19.043Z",
+			TimestampAccepted: "2024-01-31T11:29:19Z",
+			EventAttributes: map[string]any{
+				"foo":  "bar",
+				"dict": map[string]any{"a": "b"},
+				"list": []any{map[string]any{"a": "b"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(e *V3Event)
+		invalid bool
+	}{
+		{"valid", func(e *V3Event) {}, false},
+		{"public", func(e *V3Event) { e.ToPublicIdentity() }, false},
+		{"no identity", func(e *V3Event) { e.Identity = "" }, true},
+		{"asset identity", func(e *V3Event) { e.Identity = "assets/1" }, true},
+		{"bad tenant", func(e *V3Event) { e.TenantIdentity = "tenant/" }, true},
+		{"bad timestamp", func(e *V3Event) { e.TimestampAccepted = "31/01/2024" }, true},
+		{"number attribute", func(e *V3Event) { e.EventAttributes["n"] = float64(1) }, true},
+		{"nested dict", func(e *V3Event) { e.AssetAttributes = map[string]any{"d": map[string]any{"a": map[string]any{}}} }, true},
+		{"list of strings", func(e *V3Event) { e.EventAttributes["l"] = []any{"a"} }, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := valid()
+			test.mutate(&e)
+			err := e.Validate()
+			if !test.invalid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidEvent)
+		})
+	}
+
+	e := valid()
+	e.Identity = ""
+	e.TimestampDeclared = "soon"
+	e.EventAttributes["n"] = true
+	err := e.Validate()
+	require.Error(t, err)
+	joined, ok := err.(interface{ Unwrap() []error })
+	require.True(t, ok)
+	assert.Len(t, joined.Unwrap(), 3)
+	assert.True(t, errors.Is(err, ErrInvalidEvent))
+}
@@ -0,0 +1,160 @@
+package simplehash
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidWindow = errors.New("window duration must be positive")
+	ErrWindowClosed  = errors.New("event belongs to a window which has already been closed")
+)
+
+const (
+	WindowHourly = time.Hour
+	WindowDaily  = 24 * time.Hour
+)
+
+// WindowRoot is the root of all the events accepted within a single window
+type WindowRoot struct {
+	// Start is the inclusive start of the window, in UTC
+	Start time.Time
+	// End is the exclusive end of the window
+	End time.Time
+	// Root is the accumulated digest of the events in the window
+	Root []byte
+	// Count is the number of events in the window
+	Count int
+	// Previous is the root this root is chained to, nil if the roots are not
+	// chained or this is the first window
+	Previous []byte
+}
+
+// RollingRoots buckets a stream of events into fixed time windows by their
+// timestamp_accepted, producing a root for each window. This supports
+// continuous anchoring workflows where a root is published periodically.
+//
+// Events must be presented in window order, events within a window may be in
+// any order but the root depends on that order. Windows which contain no
+// events produce no root.
+//
+// When chained, the first event of each window is hashed as if by WithChain
+// with the root of the previous window, so each root commits to all of the
+// windows before it.
+//
+// A RollingRoots is not safe for concurrent use.
+type RollingRoots struct {
+	window  time.Duration
+	chained bool
+	opts    []HashOption
+	o       HashOptions
+
+	h        HasherV3
+	started  bool
+	start    time.Time
+	count    int
+	previous []byte
+}
+
+// NewRollingRoots creates a windowed accumulator. Windows are aligned to
+// multiples of the window duration in UTC, so WindowHourly and WindowDaily
+// start on the hour and at midnight respectively.
+//
+// Options: as for HashEventFromJSON. WithAccumulate is implied, WithChain
+// should not be used, and the batch options are ignored.
+func NewRollingRoots(window time.Duration, chained bool, opts ...HashOption) (*RollingRoots, error) {
+	if window <= 0 {
+		return nil, ErrInvalidWindow
+	}
+	r := &RollingRoots{
+		window:  window,
+		chained: chained,
+		opts:    append(withoutBatch(opts), WithAccumulate()),
+		h:       NewHasherV3(),
+	}
+	for _, opt := range r.opts {
+		opt(&r.o)
+	}
+	return r, nil
+}
+
+// AddJSON adds an api formatted event. If the event starts a new window the
+// root of the window it closes is returned, otherwise the root is nil.
+func (r *RollingRoots) AddJSON(eventJson []byte) (*WindowRoot, error) {
+	v3Event, err := v3FromEventJSON(eventJson, r.o)
+	if err != nil {
+		return nil, err
+	}
+	return r.AddV3(v3Event)
+}
+
+// AddV3 adds a decoded event, as for AddJSON
+func (r *RollingRoots) AddV3(v3Event V3Event) (*WindowRoot, error) {
+
+	accepted, err := time.Parse(time.RFC3339Nano, v3Event.TimestampAccepted)
+	if err != nil {
+		return nil, fmt.Errorf("%w: timestamp_accepted: %v", ErrInvalidTimestamp, err)
+	}
+	start := accepted.UTC().Truncate(r.window)
+
+	// a flushed window can not be re-opened
+	if r.started && (start.Before(r.start) || (r.count == 0 && start.Equal(r.start))) {
+		return nil, fmt.Errorf("%w: %s accepted %s, before %s", ErrWindowClosed, v3Event.Identity, v3Event.TimestampAccepted, r.start.Format(time.RFC3339))
+	}
+
+	var closed *WindowRoot
+	if r.count != 0 && start.After(r.start) {
+		closed = r.close()
+	}
+
+	// hash a clone so a failed event leaves the window untouched
+	h, err := r.h.Clone()
+	if err != nil {
+		return nil, err
+	}
+	opts := r.opts
+	if r.count == 0 && r.chained && r.previous != nil {
+		opts = append(opts[:len(opts):len(opts)], WithChain(r.previous))
+	}
+	if err := h.HashEventFromV3(v3Event, opts...); err != nil {
+		if closed != nil {
+			// the closed window is still reported, the failed event is not
+			// part of it
+			return closed, err
+		}
+		return nil, err
+	}
+
+	r.h = h
+	r.started = true
+	r.start = start
+	r.count++
+	return closed, nil
+}
+
+// Flush closes the current window, typically once its end time has passed,
+// and returns its root. nil is returned if no events have been added since
+// the last window was closed.
+func (r *RollingRoots) Flush() *WindowRoot {
+	if r.count == 0 {
+		return nil
+	}
+	return r.close()
+}
+
+func (r *RollingRoots) close() *WindowRoot {
+	root := &WindowRoot{
+		Start: r.start,
+		End:   r.start.Add(r.window),
+		Root:  r.h.Sum(nil),
+		Count: r.count,
+	}
+	if r.chained {
+		root.Previous = r.previous
+		r.previous = root.Root
+	}
+	r.h.Reset()
+	r.count = 0
+	return root
+}
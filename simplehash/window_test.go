@@ -0,0 +1,97 @@
+package simplehash
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRollingRoots tests:
+//
+// 1. events are bucketed into hourly windows, each root matching a batch of
+// the window's events.
+// 2. chained roots bind each window to the root of the previous window.
+// 3. events for a closed or flushed window are rejected.
+// 4. a non positive window is rejected.
+func TestRollingRoots(t *testing.T) {
+	event := func(n int, accepted string) []byte {
+		return []byte(fmt.Sprintf(`{"identity": "assets/1/events/%d", "timestamp_accepted": "%s"}`, n, accepted))
+	}
+	e1 := event(1, "2024-01-31T11:00:00Z")
+	e2 := event(2, "2024-01-31T11:59:59.999Z")
+	e3 := event(3, "2024-01-31T13:29:19Z")
+
+	batch := func(opts []HashOption, events ...[]byte) []byte {
+		h := NewHasherV3()
+		for i, e := range events {
+			eventOpts := append([]HashOption{WithAccumulate()}, opts...)
+			if i != 0 {
+				eventOpts = []HashOption{WithAccumulate()}
+			}
+			require.NoError(t, h.HashEventFromJSON(e, eventOpts...))
+		}
+		return h.Sum(nil)
+	}
+	hour := func(s string) time.Time {
+		start, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return start
+	}
+
+	t.Run("unchained", func(t *testing.T) {
+		r, err := NewRollingRoots(WindowHourly, false)
+		require.NoError(t, err)
+
+		for _, e := range [][]byte{e1, e2} {
+			root, err := r.AddJSON(e)
+			require.NoError(t, err)
+			assert.Nil(t, root)
+		}
+		root, err := r.AddJSON(e3)
+		require.NoError(t, err)
+		assert.Equal(t, &WindowRoot{
+			Start: hour("2024-01-31T11:00:00Z"),
+			End:   hour("2024-01-31T12:00:00Z"),
+			Root:  batch(nil, e1, e2),
+			Count: 2,
+		}, root)
+
+		_, err = r.AddJSON(event(4, "2024-01-31T12:00:00Z"))
+		assert.ErrorIs(t, err, ErrWindowClosed)
+
+		root = r.Flush()
+		require.NotNil(t, root)
+		assert.Equal(t, batch(nil, e3), root.Root)
+		assert.Nil(t, r.Flush())
+
+		_, err = r.AddJSON(event(5, "2024-01-31T13:30:00Z"))
+		assert.ErrorIs(t, err, ErrWindowClosed)
+	})
+
+	t.Run("chained", func(t *testing.T) {
+		r, err := NewRollingRoots(WindowHourly, true)
+		require.NoError(t, err)
+
+		var roots []*WindowRoot
+		for _, e := range [][]byte{e1, e2, e3} {
+			root, err := r.AddJSON(e)
+			require.NoError(t, err)
+			if root != nil {
+				roots = append(roots, root)
+			}
+		}
+		roots = append(roots, r.Flush())
+		require.Len(t, roots, 2)
+
+		assert.Nil(t, roots[0].Previous)
+		assert.Equal(t, batch(nil, e1, e2), roots[0].Root)
+		assert.Equal(t, roots[0].Root, roots[1].Previous)
+		assert.Equal(t, batch([]HashOption{WithChain(roots[0].Root)}, e3), roots[1].Root)
+	})
+
+	_, err := NewRollingRoots(0, false)
+	assert.ErrorIs(t, err, ErrInvalidWindow)
+}